@@ -0,0 +1,30 @@
+package v1
+
+// GetPodCondition 返回pod指定类型的condition，不存在时返回nil
+func GetPodCondition(status *PodStatus, conditionType PodConditionType) *PodCondition {
+	for i := range status.Conditions {
+		if status.Conditions[i].Type == conditionType {
+			return &status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// IsPodReady pod处于Running且Ready condition为True时才能接收流量，正在终止的pod不再接收流量
+func IsPodReady(pod *Pod) bool {
+	if pod.Status.Phase != PodRunning || IsPodTerminating(pod) {
+		return false
+	}
+	condition := GetPodCondition(&pod.Status, PodReady)
+	return condition != nil && condition.Status == ConditionTrue
+}
+
+// IsNodeUnderPressure 节点指定类型的压力condition为True
+func IsNodeUnderPressure(node *Node, conditionType NodeConditionType) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == conditionType {
+			return condition.Status == ConditionTrue
+		}
+	}
+	return false
+}
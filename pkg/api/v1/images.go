@@ -0,0 +1,24 @@
+package v1
+
+import "strings"
+
+// GetImagePullPolicy 与k8s的默认值一致，未指定tag或tag为latest时总是拉取
+func GetImagePullPolicy(c *Container) PullPolicy {
+	if c.ImagePullPolicy != "" {
+		return c.ImagePullPolicy
+	}
+	image := c.Image
+	if i := strings.Index(image, "@"); i >= 0 {
+		// 按digest引用的镜像内容不会变化
+		return PullIfNotPresent
+	}
+	tag := ""
+	// 仓库地址中可能带有端口，tag只出现在最后一个/之后
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		tag = image[i+1:]
+	}
+	if tag == "" || tag == "latest" {
+		return PullAlways
+	}
+	return PullIfNotPresent
+}
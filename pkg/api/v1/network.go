@@ -0,0 +1,121 @@
+package v1
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// 集群域名，service的完整域名为<service>.<namespace>.svc.<ClusterDomain>
+const ClusterDomain = "cluster.local"
+
+// 与k8s一致的resolv.conf限制
+const (
+	MaxDNSNameservers = 3
+	MaxDNSSearches    = 6
+)
+
+// GetServiceDomainName 返回service的完整域名
+func GetServiceDomainName(svc *Service) string {
+	namespace := svc.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+	return fmt.Sprintf("%s.%s.svc.%s", svc.Name, namespace, ClusterDomain)
+}
+
+// GetClusterSearches 返回namespace下pod的搜索域，使svc与svc.ns均可解析
+func GetClusterSearches(namespace string) []string {
+	if namespace == "" {
+		namespace = "default"
+	}
+	return []string{
+		namespace + ".svc." + ClusterDomain,
+		"svc." + ClusterDomain,
+		ClusterDomain,
+	}
+}
+
+// GetDNSPolicy 未设置时为ClusterFirst
+func GetDNSPolicy(pod *Pod) DNSPolicy {
+	if pod.Spec.DNSPolicy == "" {
+		return DNSClusterFirst
+	}
+	return pod.Spec.DNSPolicy
+}
+
+// GetPortProtocol 协议不区分大小写，未指定时为tcp
+func GetPortProtocol(port ContainerPort) Protocol {
+	if port.Protocol == "" {
+		return ProtocolTCP
+	}
+	return Protocol(strings.ToLower(string(port.Protocol)))
+}
+
+// ValidatePodNetwork 检查容器端口与dns配置，pod内相同协议的hostPort不能重复
+func ValidatePodNetwork(pod *Pod) error {
+	used := make(map[ContainerPort]bool)
+	for _, c := range pod.Spec.Containers {
+		for _, port := range c.Ports {
+			protocol := GetPortProtocol(port)
+			if protocol != ProtocolTCP && protocol != ProtocolUDP {
+				return fmt.Errorf("container %s: unsupported protocol %q", c.Name, port.Protocol)
+			}
+			if port.ContainerPort <= 0 || port.ContainerPort > 65535 {
+				return fmt.Errorf("container %s: invalid containerPort %d", c.Name, port.ContainerPort)
+			}
+			if port.HostPort < 0 || port.HostPort > 65535 {
+				return fmt.Errorf("container %s: invalid hostPort %d", c.Name, port.HostPort)
+			}
+			if pod.Spec.HostNetwork && port.HostPort != 0 && port.HostPort != port.ContainerPort {
+				return fmt.Errorf("container %s: hostPort %d must match containerPort %d when hostNetwork is true", c.Name, port.HostPort, port.ContainerPort)
+			}
+		}
+	}
+	for _, port := range GetHostPorts(pod) {
+		key := ContainerPort{HostPort: port.HostPort, Protocol: port.Protocol}
+		if used[key] {
+			return fmt.Errorf("host port %d/%s is declared more than once", port.HostPort, port.Protocol)
+		}
+		used[key] = true
+	}
+	return validatePodDNS(pod)
+}
+
+func validatePodDNS(pod *Pod) error {
+	config := pod.Spec.DNSConfig
+	switch GetDNSPolicy(pod) {
+	case DNSClusterFirst, DNSClusterFirstWithHostNet, DNSDefault:
+	case DNSNone:
+		if config == nil || len(config.Nameservers) == 0 {
+			return fmt.Errorf("dnsConfig.nameservers is required when dnsPolicy is None")
+		}
+	default:
+		return fmt.Errorf("unsupported dnsPolicy %q", pod.Spec.DNSPolicy)
+	}
+	if config == nil {
+		return nil
+	}
+	if len(config.Nameservers) > MaxDNSNameservers {
+		return fmt.Errorf("at most %d nameservers are allowed", MaxDNSNameservers)
+	}
+	for _, server := range config.Nameservers {
+		if net.ParseIP(server) == nil {
+			return fmt.Errorf("invalid nameserver %q", server)
+		}
+	}
+	if len(config.Searches) > MaxDNSSearches {
+		return fmt.Errorf("at most %d search domains are allowed", MaxDNSSearches)
+	}
+	for _, search := range config.Searches {
+		if search == "" || strings.ContainsAny(search, " \t") {
+			return fmt.Errorf("invalid search domain %q", search)
+		}
+	}
+	for _, option := range config.Options {
+		if option.Name == "" || strings.ContainsAny(option.Name, " \t:") {
+			return fmt.Errorf("invalid dns option %q", option.Name)
+		}
+	}
+	return nil
+}
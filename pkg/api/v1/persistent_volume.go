@@ -0,0 +1,142 @@
+package v1
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+const (
+	// 本地目录的动态provisioner
+	LocalPathProvisioner = "minik8s.io/local-path"
+	// 动态创建的pv带有此annotation，值为provisioner，删除pv时一并删除目录
+	ProvisionedByAnnotationKey = "pv.minik8s.io/provisioned-by"
+	// StorageClass参数，指定创建目录的节点
+	StorageClassNodeNameParameter = "nodeName"
+	// StorageClass参数，指定创建目录的根目录
+	StorageClassPathParameter = "path"
+	// local-path provisioner未指定path参数时，在节点上创建目录的根目录
+	DefaultLocalPathRootDir = "/tmp/minikubernetes/persistentvolumes"
+)
+
+// GetLocalPathRootDir 返回storage class动态创建目录的根目录
+func GetLocalPathRootDir(class *StorageClass) string {
+	if class != nil && class.Parameters[StorageClassPathParameter] != "" {
+		return class.Parameters[StorageClassPathParameter]
+	}
+	return DefaultLocalPathRootDir
+}
+
+// GetProvisionedLocalPath 返回为pvc动态创建的目录
+func GetProvisionedLocalPath(rootDir string, claimUID UID) string {
+	return filepath.Join(rootDir, "pvc-"+string(claimUID))
+}
+
+// IsProvisionedLocalPath pv的目录是provisioner在rootDir下为其pvc创建的目录
+// annotation与path均可由用户设置，删除目录前需据此检查，避免删除任意目录
+func IsProvisionedLocalPath(pv *PersistentVolume, rootDir string) bool {
+	if _, ok := pv.Annotations[ProvisionedByAnnotationKey]; !ok {
+		return false
+	}
+	if pv.Spec.Local == nil || pv.Spec.ClaimRef == nil || pv.Spec.ClaimRef.UID == "" || !filepath.IsAbs(rootDir) {
+		return false
+	}
+	return pv.Spec.Local.Path == GetProvisionedLocalPath(filepath.Clean(rootDir), pv.Spec.ClaimRef.UID)
+}
+
+// GetStorageQuantity 返回资源列表中的存储容量（字节），未设置时返回0
+func GetStorageQuantity(list ResourceList) (int64, error) {
+	s, ok := list[ResourceStorage]
+	if !ok {
+		return 0, nil
+	}
+	quantity, err := ParseMemory(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid storage quantity %q", s)
+	}
+	return quantity, nil
+}
+
+// ContainsAccessModes modes包含requested中所有的访问模式
+func ContainsAccessModes(modes, requested []PersistentVolumeAccessMode) bool {
+	for _, r := range requested {
+		found := false
+		for _, m := range modes {
+			if m == r {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// IsReadOnlyVolume 只支持ReadOnlyMany的pv只能以只读方式挂载
+func IsReadOnlyVolume(pv *PersistentVolume) bool {
+	return len(pv.Spec.AccessModes) > 0 && !ContainsAccessModes(pv.Spec.AccessModes, []PersistentVolumeAccessMode{ReadWriteOnce})
+}
+
+// GetReclaimPolicy 未设置回收策略时为Retain
+func GetReclaimPolicy(pv *PersistentVolume) PersistentVolumeReclaimPolicy {
+	if pv.Spec.PersistentVolumeReclaimPolicy == "" {
+		return PersistentVolumeReclaimRetain
+	}
+	return pv.Spec.PersistentVolumeReclaimPolicy
+}
+
+// IsBoundTo pv绑定或预留给了指定的pvc
+func IsBoundTo(pv *PersistentVolume, pvc *PersistentVolumeClaim) bool {
+	ref := pv.Spec.ClaimRef
+	return ref != nil && ref.Namespace == pvc.Namespace && ref.Name == pvc.Name && ref.UID == pvc.UID
+}
+
+// ValidatePersistentVolume 检查pv的容量、访问模式与回收策略
+func ValidatePersistentVolume(pv *PersistentVolume) error {
+	if pv.Name == "" {
+		return fmt.Errorf("persistentvolume name is required")
+	}
+	if capacity, err := GetStorageQuantity(pv.Spec.Capacity); err != nil {
+		return err
+	} else if capacity <= 0 {
+		return fmt.Errorf("persistentvolume capacity is required")
+	}
+	if err := validateAccessModes(pv.Spec.AccessModes); err != nil {
+		return err
+	}
+	switch pv.Spec.PersistentVolumeReclaimPolicy {
+	case "", PersistentVolumeReclaimRetain, PersistentVolumeReclaimDelete:
+	default:
+		return fmt.Errorf("unsupported reclaim policy %q", pv.Spec.PersistentVolumeReclaimPolicy)
+	}
+	if pv.Spec.Local == nil || pv.Spec.Local.Path == "" {
+		return fmt.Errorf("persistentvolume local path is required")
+	}
+	if pv.Spec.NodeName == "" {
+		return fmt.Errorf("persistentvolume nodeName is required")
+	}
+	return nil
+}
+
+// ValidatePersistentVolumeClaim 检查pvc的申请容量与访问模式
+func ValidatePersistentVolumeClaim(pvc *PersistentVolumeClaim) error {
+	if request, err := GetStorageQuantity(pvc.Spec.Resources.Requests); err != nil {
+		return err
+	} else if request <= 0 {
+		return fmt.Errorf("persistentvolumeclaim storage request is required")
+	}
+	return validateAccessModes(pvc.Spec.AccessModes)
+}
+
+func validateAccessModes(modes []PersistentVolumeAccessMode) error {
+	if len(modes) == 0 {
+		return fmt.Errorf("at least one access mode is required")
+	}
+	for _, mode := range modes {
+		if mode != ReadWriteOnce && mode != ReadOnlyMany {
+			return fmt.Errorf("unsupported access mode %q", mode)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package v1
+
+// PodQOSClass 由容器的requests与limits决定，节点资源紧张时BestEffort最先被驱逐
+type PodQOSClass string
+
+const (
+	// 所有容器的cpu与内存都设置了limit，且request等于limit
+	PodQOSGuaranteed PodQOSClass = "Guaranteed"
+	// 至少一个容器设置了request或limit，但不满足Guaranteed
+	PodQOSBurstable PodQOSClass = "Burstable"
+	// 所有容器都没有设置request与limit
+	PodQOSBestEffort PodQOSClass = "BestEffort"
+)
+
+var qosResources = []ResourceName{ResourceCPU, ResourceMemory}
+
+// GetPodQOS 与k8s一致，request未设置时视为与limit相同；无法解析的数量视为未设置
+func GetPodQOS(pod *Pod) PodQOSClass {
+	isBestEffort := true
+	isGuaranteed := true
+	containers := append(append([]Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+	for _, c := range containers {
+		for _, name := range qosResources {
+			request := parseQuantity(name, c.Resources.Requests[name])
+			limit := parseQuantity(name, c.Resources.Limits[name])
+			if request > 0 || limit > 0 {
+				isBestEffort = false
+			}
+			if limit == 0 || (request > 0 && request != limit) {
+				isGuaranteed = false
+			}
+		}
+	}
+	switch {
+	case isBestEffort:
+		return PodQOSBestEffort
+	case isGuaranteed:
+		return PodQOSGuaranteed
+	default:
+		return PodQOSBurstable
+	}
+}
+
+func parseQuantity(name ResourceName, s string) int64 {
+	var value int64
+	var err error
+	if name == ResourceCPU {
+		value, err = ParseCPU(s)
+	} else {
+		value, err = ParseMemory(s)
+	}
+	if err != nil {
+		return 0
+	}
+	return value
+}
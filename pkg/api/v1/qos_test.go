@@ -0,0 +1,35 @@
+package v1
+
+import "testing"
+
+func TestGetPodQOS(t *testing.T) {
+	newPod := func(requests, limits ResourceList) *Pod {
+		return &Pod{Spec: PodSpec{Containers: []Container{
+			{Name: "a", Resources: ResourceRequirements{Requests: requests, Limits: limits}},
+		}}}
+	}
+	full := ResourceList{ResourceCPU: "500m", ResourceMemory: "128Mi"}
+	tests := []struct {
+		name string
+		pod  *Pod
+		want PodQOSClass
+	}{
+		{"empty", newPod(nil, nil), PodQOSBestEffort},
+		{"limits only", newPod(nil, full), PodQOSGuaranteed},
+		{"equal requests", newPod(ResourceList{ResourceCPU: "0.5", ResourceMemory: "128Mi"}, full), PodQOSGuaranteed},
+		{"lower request", newPod(ResourceList{ResourceCPU: "100m"}, full), PodQOSBurstable},
+		{"cpu limit only", newPod(nil, ResourceList{ResourceCPU: "1"}), PodQOSBurstable},
+		{"requests only", newPod(full, nil), PodQOSBurstable},
+	}
+	for _, tt := range tests {
+		if got := GetPodQOS(tt.pod); got != tt.want {
+			t.Errorf("%s: GetPodQOS() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	pod := newPod(nil, full)
+	pod.Spec.Containers = append(pod.Spec.Containers, Container{Name: "b"})
+	if got := GetPodQOS(pod); got != PodQOSBurstable {
+		t.Errorf("container without limits: GetPodQOS() = %v, want %v", got, PodQOSBurstable)
+	}
+}
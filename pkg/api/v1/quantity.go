@@ -0,0 +1,65 @@
+package v1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseCPU 将cpu数量解析为毫核，支持"2"、"0.5"、"500m"
+func ParseCPU(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	if strings.HasSuffix(s, "m") {
+		milli, err := strconv.ParseInt(strings.TrimSuffix(s, "m"), 10, 64)
+		if err != nil || milli < 0 {
+			return 0, fmt.Errorf("invalid cpu quantity %q", s)
+		}
+		return milli, nil
+	}
+	cores, err := strconv.ParseFloat(s, 64)
+	if err != nil || cores < 0 {
+		return 0, fmt.Errorf("invalid cpu quantity %q", s)
+	}
+	return int64(cores * 1000), nil
+}
+
+// 二进制后缀在前，保证"Mi"不会被"M"先匹配
+var memorySuffixes = []struct {
+	suffix     string
+	multiplier int64
+}{
+	{"Ki", 1 << 10},
+	{"Mi", 1 << 20},
+	{"Gi", 1 << 30},
+	{"Ti", 1 << 40},
+	{"K", 1000},
+	{"k", 1000},
+	{"M", 1000 * 1000},
+	{"G", 1000 * 1000 * 1000},
+	{"T", 1000 * 1000 * 1000 * 1000},
+}
+
+// ParseMemory 将内存大小解析为字节数，支持Ki/Mi/Gi/Ti以及K/M/G/T后缀，无后缀为字节
+func ParseMemory(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	number := s
+	multiplier := int64(1)
+	for _, suffix := range memorySuffixes {
+		if strings.HasSuffix(s, suffix.suffix) {
+			number = strings.TrimSuffix(s, suffix.suffix)
+			multiplier = suffix.multiplier
+			break
+		}
+	}
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid memory quantity %q", s)
+	}
+	return int64(value * float64(multiplier)), nil
+}
@@ -0,0 +1,49 @@
+package v1
+
+// GetPodRequests 返回pod所有容器的cpu（毫核）与内存（字节）requests之和，未声明requests时取limits
+func GetPodRequests(pod *Pod) (int64, int64) {
+	var cpu, memory int64
+	for _, c := range pod.Spec.Containers {
+		cpuStr, ok := c.Resources.Requests[ResourceCPU]
+		if !ok {
+			cpuStr = c.Resources.Limits[ResourceCPU]
+		}
+		memStr, ok := c.Resources.Requests[ResourceMemory]
+		if !ok {
+			memStr = c.Resources.Limits[ResourceMemory]
+		}
+		if v, err := ParseCPU(cpuStr); err == nil {
+			cpu += v
+		}
+		if v, err := ParseMemory(memStr); err == nil {
+			memory += v
+		}
+	}
+	return cpu, memory
+}
+
+// GetNodeAllocatable 未上报Allocatable时使用Capacity
+func GetNodeAllocatable(node *Node) ResourceList {
+	if node.Status.Allocatable != nil {
+		return node.Status.Allocatable
+	}
+	return node.Status.Capacity
+}
+
+// GetHostPorts 返回pod占用的节点端口，协议统一为小写，hostNetwork的pod占用所有containerPort
+func GetHostPorts(pod *Pod) []ContainerPort {
+	var ports []ContainerPort
+	for _, c := range pod.Spec.Containers {
+		for _, port := range c.Ports {
+			if pod.Spec.HostNetwork && port.HostPort == 0 {
+				port.HostPort = port.ContainerPort
+			}
+			if port.HostPort <= 0 {
+				continue
+			}
+			port.Protocol = GetPortProtocol(port)
+			ports = append(ports, port)
+		}
+	}
+	return ports
+}
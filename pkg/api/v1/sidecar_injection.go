@@ -0,0 +1,41 @@
+package v1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// pod注解，为"true"时注入envoy sidecar，为"false"时即使namespace开启了注入也不注入
+	SidecarInjectAnnotationKey = "sidecar.minik8s.io/inject"
+	// 注入完成后由apiserver添加，避免重复注入
+	SidecarStatusAnnotationKey = "sidecar.minik8s.io/status"
+	// 逗号分隔的端口列表，到达这些端口的入站流量不经过envoy
+	ExcludeInboundPortsAnnotationKey = "traffic.sidecar.minik8s.io/excludeInboundPorts"
+	// 逗号分隔的端口列表，发往这些端口的出站流量不经过envoy
+	ExcludeOutboundPortsAnnotationKey = "traffic.sidecar.minik8s.io/excludeOutboundPorts"
+
+	// namespace label，为"enabled"时该namespace下新建的pod默认注入sidecar
+	NamespaceInjectionLabelKey = "minik8s-injection"
+	NamespaceInjectionEnabled  = "enabled"
+
+	SidecarInjected = "injected"
+)
+
+// ParsePortList 解析逗号分隔的端口列表，如"22,8080"
+func ParsePortList(s string) ([]int32, error) {
+	var ports []int32
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		port, err := strconv.ParseInt(field, 10, 32)
+		if err != nil || port < 1 || port > 65535 {
+			return nil, fmt.Errorf("invalid port %q", field)
+		}
+		ports = append(ports, int32(port))
+	}
+	return ports, nil
+}
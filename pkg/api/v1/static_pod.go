@@ -0,0 +1,21 @@
+package v1
+
+const (
+	// pod的来源，由节点上manifest目录创建的静态pod为ConfigSourceFile
+	ConfigSourceAnnotationKey = "kubernetes.io/config.source"
+	// 镜像pod带有该注解，值为对应静态pod在节点上的UID
+	MirrorPodAnnotationKey = "kubernetes.io/config.mirror"
+
+	ConfigSourceFile = "file"
+)
+
+// IsStaticPod 静态pod由kubelet直接管理，不经过apiserver与调度器
+func IsStaticPod(pod *Pod) bool {
+	return pod.Annotations[ConfigSourceAnnotationKey] == ConfigSourceFile
+}
+
+// IsMirrorPod 镜像pod是kubelet为静态pod在apiserver中创建的只读副本，只用于展示状态
+func IsMirrorPod(pod *Pod) bool {
+	_, ok := pod.Annotations[MirrorPodAnnotationKey]
+	return ok
+}
@@ -0,0 +1,52 @@
+package v1
+
+// ToleratesTaint 与k8s一致，effect为空时匹配所有effect，key为空且operator为Exists时匹配所有key
+func (t *Toleration) ToleratesTaint(taint *Taint) bool {
+	if t.Effect != "" && t.Effect != taint.Effect {
+		return false
+	}
+	if t.Key != "" && t.Key != taint.Key {
+		return false
+	}
+	switch t.Operator {
+	case TolerationOpExists:
+		return true
+	case "", TolerationOpEqual:
+		return t.Value == taint.Value
+	default:
+		return false
+	}
+}
+
+// FindUntoleratedTaint 返回第一个满足filter且不被容忍的污点
+func FindUntoleratedTaint(taints []Taint, tolerations []Toleration, filter func(taint *Taint) bool) (*Taint, bool) {
+	for i := range taints {
+		taint := &taints[i]
+		if filter != nil && !filter(taint) {
+			continue
+		}
+		tolerated := false
+		for j := range tolerations {
+			if tolerations[j].ToleratesTaint(taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return taint, true
+		}
+	}
+	return nil, false
+}
+
+// 只有PreferNoSchedule以外的污点会阻止pod调度与运行
+func IsHardTaint(taint *Taint) bool {
+	return taint.Effect == TaintEffectNoSchedule || taint.Effect == TaintEffectNoExecute
+}
+
+func (t *Taint) String() string {
+	if t.Value == "" {
+		return t.Key + ":" + string(t.Effect)
+	}
+	return t.Key + "=" + t.Value + ":" + string(t.Effect)
+}
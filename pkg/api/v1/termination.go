@@ -0,0 +1,20 @@
+package v1
+
+// 未指定TerminationGracePeriodSeconds时的宽限期
+const DefaultTerminationGracePeriodSeconds int64 = 30
+
+// IsPodTerminating pod已被优雅删除，等待kubelet终止容器
+func IsPodTerminating(pod *Pod) bool {
+	return pod.DeletionTimestamp != nil
+}
+
+// GetPodGracePeriodSeconds 优先使用删除时指定的宽限期
+func GetPodGracePeriodSeconds(pod *Pod) int64 {
+	if pod.DeletionGracePeriodSeconds != nil {
+		return *pod.DeletionGracePeriodSeconds
+	}
+	if pod.Spec.TerminationGracePeriodSeconds != nil {
+		return *pod.Spec.TerminationGracePeriodSeconds
+	}
+	return DefaultTerminationGracePeriodSeconds
+}
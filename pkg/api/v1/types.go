@@ -26,6 +26,8 @@ const (
 	ResourceCPU ResourceName = "cpu"
 	// 内存大小
 	ResourceMemory ResourceName = "memory"
+	// 持久卷容量
+	ResourceStorage ResourceName = "storage"
 )
 
 // 暂时用string表示资源，动态解析
@@ -39,6 +41,18 @@ const (
 	RestartPolicyNever     RestartPolicy = "Never"
 )
 
+// 镜像拉取策略，未指定时tag为latest或为空的镜像使用Always，其余使用IfNotPresent
+type PullPolicy string
+
+const (
+	// 每次创建容器前都拉取镜像
+	PullAlways PullPolicy = "Always"
+	// 从不拉取，镜像不存在时容器无法启动
+	PullNever PullPolicy = "Never"
+	// 仅在本地不存在时拉取
+	PullIfNotPresent PullPolicy = "IfNotPresent"
+)
+
 type TypeMeta struct {
 	Kind       string `json:"kind,omitempty"`
 	APIVersion string `json:"apiVersion,omitempty"`
@@ -56,6 +70,13 @@ type ObjectMeta struct {
 	CreationTimestamp time.Time `json:"creationTimestamp,omitempty"`
 
 	Labels map[string]string `json:"labels,omitempty"`
+
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// 由apiserver在优雅删除时写入，非空即表示pod正在终止
+	DeletionTimestamp *time.Time `json:"deletionTimestamp,omitempty"`
+	// 本次删除使用的宽限期
+	DeletionGracePeriodSeconds *int64 `json:"deletionGracePeriodSeconds,omitempty"`
 }
 
 type Volume struct {
@@ -64,8 +85,14 @@ type Volume struct {
 }
 
 type VolumeSource struct {
-	HostPath *HostPathVolumeSource `json:"hostPath,omitempty"`
-	EmptyDir *EmptyDirVolumeSource `json:"emptyDir,omitempty"`
+	HostPath  *HostPathVolumeSource  `json:"hostPath,omitempty"`
+	EmptyDir  *EmptyDirVolumeSource  `json:"emptyDir,omitempty"`
+	ConfigMap *ConfigMapVolumeSource `json:"configMap,omitempty"`
+	Secret    *SecretVolumeSource    `json:"secret,omitempty"`
+	// 使用同一namespace下已绑定的pvc
+	PersistentVolumeClaim *PersistentVolumeClaimVolumeSource `json:"persistentVolumeClaim,omitempty"`
+	// 将pod自身的元数据与资源限制写入文件
+	DownwardAPI *DownwardAPIVolumeSource `json:"downwardAPI,omitempty"`
 }
 
 // 挂载主机目录
@@ -77,11 +104,82 @@ type HostPathVolumeSource struct {
 	Path string `json:"path"`
 }
 
-// 自动创建空目录
+// 自动创建空目录，pod删除时一并删除
 // volume:
 //   - name: xxx
-//     emptyDir: {}
+//     emptyDir:
+//     medium: Memory
+//     sizeLimit: 64Mi
 type EmptyDirVolumeSource struct {
+	// 为空时使用节点磁盘，为Memory时使用tmpfs，写入的数据计入内存
+	Medium StorageMedium `json:"medium,omitempty"`
+	// 卷的最大用量，磁盘卷超出时pod被驱逐，内存卷以此作为tmpfs的大小
+	SizeLimit string `json:"sizeLimit,omitempty"`
+}
+
+type StorageMedium string
+
+const (
+	StorageMediumDefault StorageMedium = ""
+	StorageMediumMemory  StorageMedium = "Memory"
+)
+
+// 将configmap的每个key作为文件挂载
+// volume:
+//   - name: xxx
+//     configMap:
+//     name: my-config
+type ConfigMapVolumeSource struct {
+	Name string `json:"name"`
+	// 为空时挂载所有key，否则只挂载指定的key
+	Items []KeyToPath `json:"items,omitempty"`
+	// 为true时configmap不存在也允许创建pod
+	Optional *bool `json:"optional,omitempty"`
+}
+
+// volume:
+//   - name: xxx
+//     secret:
+//     secretName: my-secret
+type SecretVolumeSource struct {
+	SecretName string      `json:"secretName"`
+	Items      []KeyToPath `json:"items,omitempty"`
+	Optional   *bool       `json:"optional,omitempty"`
+}
+
+// volume:
+//   - name: xxx
+//     persistentVolumeClaim:
+//     claimName: my-pvc
+type PersistentVolumeClaimVolumeSource struct {
+	ClaimName string `json:"claimName"`
+	// 为true时以只读方式挂载
+	ReadOnly bool `json:"readOnly,omitempty"`
+}
+
+// labels与annotations的修改会在刷新时反映到文件中
+// volume:
+//   - name: xxx
+//     downwardAPI:
+//     items: [...]
+type DownwardAPIVolumeSource struct {
+	Items []DownwardAPIVolumeFile `json:"items"`
+}
+
+type DownwardAPIVolumeFile struct {
+	// 相对于挂载点的文件路径
+	Path string `json:"path"`
+	// 支持metadata.name、metadata.namespace、metadata.uid、metadata.labels、metadata.annotations
+	// 以及metadata.labels['key']、metadata.annotations['key']
+	FieldRef *ObjectFieldSelector `json:"fieldRef,omitempty"`
+	// 卷不属于某个容器，必须指定containerName
+	ResourceFieldRef *ResourceFieldSelector `json:"resourceFieldRef,omitempty"`
+}
+
+type KeyToPath struct {
+	Key string `json:"key"`
+	// 相对于挂载点的文件路径
+	Path string `json:"path"`
 }
 
 type Container struct {
@@ -89,8 +187,18 @@ type Container struct {
 	Name string `json:"name"`
 	// 容器镜像（包括版本）
 	Image string `json:"image,omitempty"`
-	// entrypoint命令
+	// 镜像拉取策略
+	ImagePullPolicy PullPolicy `json:"imagePullPolicy,omitempty"`
+	// entrypoint命令，覆盖镜像的ENTRYPOINT
 	Command []string `json:"command,omitempty"`
+	// entrypoint的参数，覆盖镜像的CMD
+	Args []string `json:"args,omitempty"`
+	// 容器工作目录，为空时使用镜像默认值
+	WorkingDir string `json:"workingDir,omitempty"`
+	// 环境变量
+	Env []EnvVar `json:"env,omitempty"`
+	// 从configmap或secret导入所有key作为环境变量，与Env重名时以Env为准
+	EnvFrom []EnvFromSource `json:"envFrom,omitempty"`
 	// 容器暴露端口
 	Ports []ContainerPort `json:"ports,omitempty"`
 	// 容器资源限制
@@ -99,13 +207,168 @@ type Container struct {
 	VolumeMounts []VolumeMount `json:"volumeMounts,omitempty"`
 
 	SecurityContext *SecurityContext `json:"securityContext,omitempty"`
+
+	// 存活探针，失败时kubelet杀死容器，之后按重启策略处理
+	LivenessProbe *Probe `json:"livenessProbe,omitempty"`
+	// 就绪探针，失败时pod不作为service的endpoint
+	ReadinessProbe *Probe `json:"readinessProbe,omitempty"`
+	// 启动探针，成功前不执行另外两种探针，失败时同存活探针
+	StartupProbe *Probe `json:"startupProbe,omitempty"`
+	// 容器启动后与终止前执行的回调
+	Lifecycle *Lifecycle `json:"lifecycle,omitempty"`
+}
+
+type Lifecycle struct {
+	// 容器启动后立即执行，失败时杀死容器，之后按重启策略处理
+	PostStart *LifecycleHandler `json:"postStart,omitempty"`
+	// 容器终止前执行，完成后才发送SIGTERM，与之共用pod的宽限期
+	PreStop *LifecycleHandler `json:"preStop,omitempty"`
+}
+
+type LifecycleHandler struct {
+	Exec    *ExecAction    `json:"exec,omitempty"`
+	HTTPGet *HTTPGetAction `json:"httpGet,omitempty"`
+}
+
+// 环境变量，例如:
+//
+//	env:
+//	- name: POD_NAME
+//	  valueFrom:
+//	    fieldRef:
+//	      fieldPath: metadata.name
+type EnvVar struct {
+	Name string `json:"name"`
+	// 与ValueFrom二选一
+	Value     string        `json:"value,omitempty"`
+	ValueFrom *EnvVarSource `json:"valueFrom,omitempty"`
+}
+
+type EnvVarSource struct {
+	// 支持metadata.name、metadata.namespace、metadata.uid、metadata.labels['key']、metadata.annotations['key']、
+	// spec.nodeName、status.podIP、status.hostIP
+	FieldRef *ObjectFieldSelector `json:"fieldRef,omitempty"`
+	// 支持limits.cpu、limits.memory、requests.cpu、requests.memory
+	ResourceFieldRef *ResourceFieldSelector `json:"resourceFieldRef,omitempty"`
+	// 引用同一namespace下configmap的某个key
+	ConfigMapKeyRef *ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	// 引用同一namespace下secret的某个key
+	SecretKeyRef *SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+type ConfigMapKeySelector struct {
+	Name     string `json:"name"`
+	Key      string `json:"key"`
+	Optional *bool  `json:"optional,omitempty"`
+}
+
+type SecretKeySelector struct {
+	Name     string `json:"name"`
+	Key      string `json:"key"`
+	Optional *bool  `json:"optional,omitempty"`
+}
+
+type EnvFromSource struct {
+	// 加在每个变量名前的前缀
+	Prefix       string              `json:"prefix,omitempty"`
+	ConfigMapRef *ConfigMapEnvSource `json:"configMapRef,omitempty"`
+	SecretRef    *SecretEnvSource    `json:"secretRef,omitempty"`
+}
+
+type ConfigMapEnvSource struct {
+	Name     string `json:"name"`
+	Optional *bool  `json:"optional,omitempty"`
+}
+
+type SecretEnvSource struct {
+	Name     string `json:"name"`
+	Optional *bool  `json:"optional,omitempty"`
+}
+
+type ObjectFieldSelector struct {
+	FieldPath string `json:"fieldPath"`
+}
+
+type ResourceFieldSelector struct {
+	// 为空时指当前容器
+	ContainerName string `json:"containerName,omitempty"`
+	Resource      string `json:"resource"`
+	// 输出值的单位，如cpu的"1m"、内存的"1Mi"，默认为"1"
+	Divisor string `json:"divisor,omitempty"`
+}
+
+// livenessProbe:
+//
+//	httpGet:
+//	  path: /healthz
+//	  port: 8080
+//	initialDelaySeconds: 3
+//	periodSeconds: 5
+type Probe struct {
+	ProbeHandler `json:",inline"`
+	// 容器启动后多久开始探测，默认0
+	InitialDelaySeconds int32 `json:"initialDelaySeconds,omitempty"`
+	// 单次探测超时，默认1
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+	// 探测间隔，默认10
+	PeriodSeconds int32 `json:"periodSeconds,omitempty"`
+	// 失败后连续成功多少次视为成功，默认1
+	SuccessThreshold int32 `json:"successThreshold,omitempty"`
+	// 连续失败多少次视为失败，默认3
+	FailureThreshold int32 `json:"failureThreshold,omitempty"`
+}
+
+// 三者只能指定一个
+type ProbeHandler struct {
+	Exec      *ExecAction      `json:"exec,omitempty"`
+	HTTPGet   *HTTPGetAction   `json:"httpGet,omitempty"`
+	TCPSocket *TCPSocketAction `json:"tcpSocket,omitempty"`
+}
+
+// 在容器内执行命令，退出码为0视为成功
+type ExecAction struct {
+	Command []string `json:"command,omitempty"`
+}
+
+type URIScheme string
+
+const (
+	URISchemeHTTP  URIScheme = "HTTP"
+	URISchemeHTTPS URIScheme = "HTTPS"
+)
+
+// 状态码在200~399之间视为成功
+type HTTPGetAction struct {
+	Path string `json:"path,omitempty"`
+	Port int32  `json:"port"`
+	// 默认为pod ip
+	Host string `json:"host,omitempty"`
+	// 默认为HTTP
+	Scheme      URIScheme    `json:"scheme,omitempty"`
+	HTTPHeaders []HTTPHeader `json:"httpHeaders,omitempty"`
+}
+
+type HTTPHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// 能建立tcp连接视为成功
+type TCPSocketAction struct {
+	Port int32 `json:"port"`
+	// 默认为pod ip
+	Host string `json:"host,omitempty"`
 }
 
 // ports:
 //   - containerPort: 80
+//     hostPort: 8080
 type ContainerPort struct {
 	ContainerPort int32    `json:"containerPort"`
 	Protocol      Protocol `json:"protocol,omitempty"`
+	// 在节点上暴露的端口，同一节点上相同协议的hostPort不能冲突
+	// hostNetwork的pod未指定时等于containerPort，指定时须与其相等
+	HostPort int32 `json:"hostPort,omitempty"`
 }
 
 // resources:
@@ -129,6 +392,10 @@ type VolumeMount struct {
 	Name string `json:"name"`
 	// 挂载在容器内的路径
 	MountPath string `json:"mountPath,omitempty"`
+	// 只读挂载
+	ReadOnly bool `json:"readOnly,omitempty"`
+	// 只挂载卷内的子路径，不存在时自动创建，不能为绝对路径或包含..
+	SubPath string `json:"subPath,omitempty"`
 }
 
 type Pod struct {
@@ -147,10 +414,85 @@ type PodSpec struct {
 	InitContainers []Container `json:"initContainers,omitempty"`
 	// 重启策略：仅由kubelet实现
 	RestartPolicy RestartPolicy `json:"restartPolicy,omitempty"`
+	// 终止pod时从发送SIGTERM（包括执行preStop）到强制杀死的时间，默认30秒
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+	// 绑定的节点，由binding子资源写入，非空即表示已被调度
+	NodeName string `json:"nodeName,omitempty"`
+	// 拓扑分布约束：仅由scheduler实现
+	TopologySpreadConstraints []TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+	// 拉取镜像时使用的同一namespace下的kubernetes.io/dockerconfigjson类型secret
+	ImagePullSecrets []LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	// 容忍节点的污点，由scheduler与kubelet共同检查
+	Tolerations []Toleration `json:"tolerations,omitempty"`
+	// 使用节点的网络命名空间，容器端口直接占用节点端口
+	HostNetwork bool `json:"hostNetwork,omitempty"`
+	// 默认为ClusterFirst
+	DNSPolicy DNSPolicy `json:"dnsPolicy,omitempty"`
+	// 合并到dnsPolicy生成的配置中，dnsPolicy为None时为全部配置
+	DNSConfig *PodDNSConfig `json:"dnsConfig,omitempty"`
 
 	//Sidecar *SidecarSpec `json:"sidecar,omitempty"`
 }
 
+type DNSPolicy string
+
+const (
+	// 使用集群dns，并添加service的搜索域；hostNetwork的pod退化为Default
+	DNSClusterFirst DNSPolicy = "ClusterFirst"
+	// hostNetwork的pod也使用集群dns
+	DNSClusterFirstWithHostNet DNSPolicy = "ClusterFirstWithHostNet"
+	// 使用节点的resolv.conf
+	DNSDefault DNSPolicy = "Default"
+	// 只使用dnsConfig
+	DNSNone DNSPolicy = "None"
+)
+
+// dnsConfig:
+//
+//	nameservers: [1.1.1.1]
+//	searches: [example.com]
+//	options:
+//	  - name: ndots
+//	    value: "2"
+type PodDNSConfig struct {
+	Nameservers []string `json:"nameservers,omitempty"`
+	Searches    []string `json:"searches,omitempty"`
+	// 与dnsPolicy生成的同名选项冲突时覆盖之
+	Options []PodDNSConfigOption `json:"options,omitempty"`
+}
+
+type PodDNSConfigOption struct {
+	Name  string  `json:"name"`
+	Value *string `json:"value,omitempty"`
+}
+
+type UnsatisfiableConstraintAction string
+
+const (
+	// 不满足约束的节点不参与调度
+	DoNotSchedule UnsatisfiableConstraintAction = "DoNotSchedule"
+	// 仍可调度，但优先选择偏差更小的节点
+	ScheduleAnyway UnsatisfiableConstraintAction = "ScheduleAnyway"
+)
+
+// topologySpreadConstraints:
+//   - maxSkew: 1
+//     topologyKey: zone
+//     whenUnsatisfiable: DoNotSchedule
+//     labelSelector:
+//     matchLabels:
+//     app: nginx
+type TopologySpreadConstraint struct {
+	// 各拓扑域之间匹配pod数量的最大差值，至少为1
+	MaxSkew int32 `json:"maxSkew"`
+	// 节点label的key，value相同的节点属于同一拓扑域
+	TopologyKey string `json:"topologyKey"`
+	// 默认为DoNotSchedule
+	WhenUnsatisfiable UnsatisfiableConstraintAction `json:"whenUnsatisfiable,omitempty"`
+	// 选择同namespace下参与计数的pod，为空时使用pod自身的label
+	LabelSelector *LabelSelector `json:"labelSelector,omitempty"`
+}
+
 type SecurityContext struct {
 	Privileged *bool  `json:"privileged,omitempty"`
 	RunAsUser  *int64 `json:"runAsUser,omitempty"`
@@ -163,6 +505,292 @@ type SecurityContext struct {
 type PodStatus struct {
 	Phase PodPhase `json:"phase,omitempty"`
 	PodIP string   `json:"podIP,omitempty"`
+	// 由kubelet计算
+	Conditions []PodCondition `json:"conditions,omitempty"`
+	// pod处于当前状态的原因，如Evicted
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+	// pod所在节点的ip
+	HostIP string `json:"hostIP,omitempty"`
+	// kubelet开始处理该pod的时间
+	StartTime time.Time `json:"startTime,omitempty"`
+	// 由容器的requests与limits计算
+	QOSClass PodQOSClass `json:"qosClass,omitempty"`
+
+	InitContainerStatuses []ContainerStatus `json:"initContainerStatuses,omitempty"`
+	ContainerStatuses     []ContainerStatus `json:"containerStatuses,omitempty"`
+}
+
+// kubectl logs的选项，以查询参数的形式经apiserver转发给kubelet
+type PodLogOptions struct {
+	// pod只有一个容器时可省略
+	Container string
+	Follow    bool
+	// 读取上一次重启前的日志
+	Previous     bool
+	SinceSeconds *int64
+	SinceTime    *time.Time
+	Timestamps   bool
+	TailLines    *int64
+}
+
+// kubectl exec的选项
+type PodExecOptions struct {
+	Container string
+	Command   []string
+	Stdin     bool
+	TTY       bool
+}
+
+type ContainerStatus struct {
+	// 与v1.Container.Name相同
+	Name string `json:"name"`
+	// 当前状态
+	State ContainerState `json:"state,omitempty"`
+	// 上一次终止时的状态
+	LastTerminationState ContainerState `json:"lastState,omitempty"`
+	// 是否通过readiness探测
+	Ready        bool   `json:"ready"`
+	RestartCount int32  `json:"restartCount"`
+	Image        string `json:"image"`
+	ImageID      string `json:"imageID,omitempty"`
+	// 运行时生成的容器id
+	ContainerID string `json:"containerID,omitempty"`
+}
+
+// 三种状态至多有一个非空，均为空时视为Waiting
+type ContainerState struct {
+	Waiting    *ContainerStateWaiting    `json:"waiting,omitempty"`
+	Running    *ContainerStateRunning    `json:"running,omitempty"`
+	Terminated *ContainerStateTerminated `json:"terminated,omitempty"`
+}
+
+type ContainerStateWaiting struct {
+	// 如ContainerCreating、PodInitializing
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type ContainerStateRunning struct {
+	StartedAt time.Time `json:"startedAt,omitempty"`
+}
+
+type ContainerStateTerminated struct {
+	ExitCode int32 `json:"exitCode"`
+	// 如Completed、Error
+	Reason     string    `json:"reason,omitempty"`
+	Message    string    `json:"message,omitempty"`
+	StartedAt  time.Time `json:"startedAt,omitempty"`
+	FinishedAt time.Time `json:"finishedAt,omitempty"`
+}
+
+type PodConditionType string
+
+const (
+	// 所有init container均已成功完成
+	PodInitialized PodConditionType = "Initialized"
+	// 所有容器均已就绪
+	ContainersReady PodConditionType = "ContainersReady"
+	// pod可以作为service的endpoint
+	PodReady PodConditionType = "Ready"
+)
+
+type ConditionStatus string
+
+const (
+	ConditionTrue    ConditionStatus = "True"
+	ConditionFalse   ConditionStatus = "False"
+	ConditionUnknown ConditionStatus = "Unknown"
+)
+
+type PodCondition struct {
+	Type   PodConditionType `json:"type"`
+	Status ConditionStatus  `json:"status"`
+	// 机器可读的原因
+	Reason string `json:"reason,omitempty"`
+	// 人类可读的说明
+	Message string `json:"message,omitempty"`
+	// Status上一次变化的时间
+	LastTransitionTime time.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// Namespace 保存namespace的label，如sidecar自动注入的开关
+type Namespace struct {
+	TypeMeta   `json:",inline"`
+	ObjectMeta `json:"metadata,omitempty"`
+}
+
+// ConfigMap 存放非机密的键值配置
+type ConfigMap struct {
+	TypeMeta   `json:",inline"`
+	ObjectMeta `json:"metadata,omitempty"`
+	Data       map[string]string `json:"data,omitempty"`
+}
+
+type SecretType string
+
+const (
+	SecretTypeOpaque SecretType = "Opaque"
+	// 镜像仓库凭据，Data中的DockerConfigJsonKey为~/.docker/config.json格式
+	SecretTypeDockerConfigJson SecretType = "kubernetes.io/dockerconfigjson"
+
+	DockerConfigJsonKey = ".dockerconfigjson"
+)
+
+// 引用同一namespace下的对象
+type LocalObjectReference struct {
+	Name string `json:"name"`
+}
+
+// Secret 存放机密数据，Data在json中以base64表示
+type Secret struct {
+	TypeMeta   `json:",inline"`
+	ObjectMeta `json:"metadata,omitempty"`
+	Type       SecretType        `json:"type,omitempty"`
+	Data       map[string][]byte `json:"data,omitempty"`
+	// 只写字段，便于直接填写明文，写入时合并到Data
+	StringData map[string]string `json:"stringData,omitempty"`
+}
+
+type PersistentVolumeAccessMode string
+
+const (
+	// 可被单个节点读写
+	ReadWriteOnce PersistentVolumeAccessMode = "ReadWriteOnce"
+	// 可被多个pod只读挂载
+	ReadOnlyMany PersistentVolumeAccessMode = "ReadOnlyMany"
+)
+
+type PersistentVolumeReclaimPolicy string
+
+const (
+	// pvc删除后保留pv与数据，需手动删除pv
+	PersistentVolumeReclaimRetain PersistentVolumeReclaimPolicy = "Retain"
+	// pvc删除后由pv所在节点的kubelet删除pv及其数据
+	PersistentVolumeReclaimDelete PersistentVolumeReclaimPolicy = "Delete"
+)
+
+type PersistentVolumePhase string
+
+const (
+	// 动态创建的pv等待节点创建目录
+	VolumePending   PersistentVolumePhase = "Pending"
+	VolumeAvailable PersistentVolumePhase = "Available"
+	VolumeBound     PersistentVolumePhase = "Bound"
+	// 绑定的pvc已被删除，按回收策略处理
+	VolumeReleased PersistentVolumePhase = "Released"
+)
+
+// PersistentVolume 节点上的一块本地存储，不属于任何namespace
+type PersistentVolume struct {
+	TypeMeta   `json:",inline"`
+	ObjectMeta `json:"metadata,omitempty"`
+	Spec       PersistentVolumeSpec   `json:"spec,omitempty"`
+	Status     PersistentVolumeStatus `json:"status,omitempty"`
+}
+
+// spec:
+//
+//	capacity:
+//	  storage: 1Gi
+//	accessModes: [ReadWriteOnce]
+//	persistentVolumeReclaimPolicy: Retain
+//	storageClassName: local-path
+//	local:
+//	  path: /data/pv-0
+//	nodeName: node-0
+type PersistentVolumeSpec struct {
+	Capacity    ResourceList                 `json:"capacity,omitempty"`
+	AccessModes []PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+	// 为空时为Retain
+	PersistentVolumeReclaimPolicy PersistentVolumeReclaimPolicy `json:"persistentVolumeReclaimPolicy,omitempty"`
+	StorageClassName              string                        `json:"storageClassName,omitempty"`
+	Local                         *LocalVolumeSource            `json:"local,omitempty"`
+	// 卷所在的节点，使用该卷的pod只会被调度到此节点
+	NodeName string `json:"nodeName"`
+	// 绑定或预留给的pvc
+	ClaimRef *PersistentVolumeClaimReference `json:"claimRef,omitempty"`
+}
+
+// 节点上的目录
+type LocalVolumeSource struct {
+	Path string `json:"path"`
+}
+
+type PersistentVolumeClaimReference struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	UID       UID    `json:"uid"`
+}
+
+type PersistentVolumeStatus struct {
+	Phase PersistentVolumePhase `json:"phase,omitempty"`
+}
+
+type PersistentVolumeClaimPhase string
+
+const (
+	ClaimPending PersistentVolumeClaimPhase = "Pending"
+	ClaimBound   PersistentVolumeClaimPhase = "Bound"
+	// 绑定的pv已不存在
+	ClaimLost PersistentVolumeClaimPhase = "Lost"
+)
+
+// PersistentVolumeClaim 对存储的申请，由pv controller绑定到满足条件的pv
+type PersistentVolumeClaim struct {
+	TypeMeta   `json:",inline"`
+	ObjectMeta `json:"metadata,omitempty"`
+	Spec       PersistentVolumeClaimSpec   `json:"spec,omitempty"`
+	Status     PersistentVolumeClaimStatus `json:"status,omitempty"`
+}
+
+// spec:
+//
+//	accessModes: [ReadWriteOnce]
+//	resources:
+//	  requests:
+//	    storage: 500Mi
+//	storageClassName: local-path
+type PersistentVolumeClaimSpec struct {
+	AccessModes []PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+	Resources   ResourceRequirements         `json:"resources,omitempty"`
+	// 为空时只绑定同样未指定storage class的pv
+	StorageClassName string `json:"storageClassName,omitempty"`
+	// 绑定的pv名，也可由用户指定要绑定的pv
+	VolumeName string `json:"volumeName,omitempty"`
+}
+
+type PersistentVolumeClaimStatus struct {
+	Phase       PersistentVolumeClaimPhase   `json:"phase,omitempty"`
+	AccessModes []PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+	Capacity    ResourceList                 `json:"capacity,omitempty"`
+}
+
+// StorageClass 描述动态创建pv的方式，不属于任何namespace
+// provisioner: minik8s.io/local-path
+// reclaimPolicy: Delete
+// parameters:
+//
+//	nodeName: node-0
+type StorageClass struct {
+	TypeMeta    `json:",inline"`
+	ObjectMeta  `json:"metadata,omitempty"`
+	Provisioner string `json:"provisioner"`
+	// 动态创建的pv的回收策略，为空时为Delete
+	ReclaimPolicy PersistentVolumeReclaimPolicy `json:"reclaimPolicy,omitempty"`
+	Parameters    map[string]string             `json:"parameters,omitempty"`
+}
+
+// Binding 将pod绑定到目标节点
+// target:
+//
+//	kind: Node
+//	name: node-0
+type Binding struct {
+	TypeMeta   `json:",inline"`
+	ObjectMeta `json:"metadata,omitempty"`
+	// 目标节点
+	Target CrossVersionObjectReference `json:"target"`
 }
 
 type MetricsQuery struct {
@@ -435,11 +1063,81 @@ type Node struct {
 }
 
 type NodeSpec struct {
+	// 由kubelet注册时上报，不容忍的pod不会被调度或运行到该节点
+	Taints []Taint `json:"taints,omitempty"`
+}
+
+type TaintEffect string
+
+const (
+	// 不容忍的pod不会被调度到该节点
+	TaintEffectNoSchedule TaintEffect = "NoSchedule"
+	// scheduler尽量避开该节点，kubelet不检查
+	TaintEffectPreferNoSchedule TaintEffect = "PreferNoSchedule"
+	// 不容忍的pod不会被调度，也不会被kubelet运行
+	TaintEffectNoExecute TaintEffect = "NoExecute"
+)
+
+// taints:
+//   - key: dedicated
+//     value: gpu
+//     effect: NoSchedule
+type Taint struct {
+	Key    string      `json:"key"`
+	Value  string      `json:"value,omitempty"`
+	Effect TaintEffect `json:"effect"`
+}
+
+type TolerationOperator string
+
+const (
+	// 只要key相同即容忍，不比较value
+	TolerationOpExists TolerationOperator = "Exists"
+	TolerationOpEqual  TolerationOperator = "Equal"
+)
+
+// tolerations:
+//   - key: dedicated
+//     operator: Equal
+//     value: gpu
+//     effect: NoSchedule
+type Toleration struct {
+	// 为空且operator为Exists时容忍所有污点
+	Key string `json:"key,omitempty"`
+	// 默认为Equal
+	Operator TolerationOperator `json:"operator,omitempty"`
+	Value    string             `json:"value,omitempty"`
+	// 为空时容忍所有effect
+	Effect TaintEffect `json:"effect,omitempty"`
 }
 
 type NodeStatus struct {
 	// IP地址
 	Address string `json:"address,omitempty"`
+	// 节点资源总量，由kubelet注册时上报
+	Capacity ResourceList `json:"capacity,omitempty"`
+	// 可分配给pod的资源，未设置时与Capacity相同
+	Allocatable ResourceList `json:"allocatable,omitempty"`
+	// 由kubelet的驱逐管理器上报
+	Conditions []NodeCondition `json:"conditions,omitempty"`
+}
+
+type NodeConditionType string
+
+const (
+	// 节点可用内存低于驱逐阈值
+	NodeMemoryPressure NodeConditionType = "MemoryPressure"
+	// 节点根文件系统或镜像文件系统的可用空间低于驱逐阈值
+	NodeDiskPressure NodeConditionType = "DiskPressure"
+)
+
+type NodeCondition struct {
+	Type    NodeConditionType `json:"type"`
+	Status  ConditionStatus   `json:"status"`
+	Reason  string            `json:"reason,omitempty"`
+	Message string            `json:"message,omitempty"`
+	// Status上一次变化的时间
+	LastTransitionTime time.Time `json:"lastTransitionTime,omitempty"`
 }
 
 // ServiceName -> ClusterIP
@@ -0,0 +1,32 @@
+package v1
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ValidateSubPath subPath只能指向卷内的路径
+func ValidateSubPath(subPath string) error {
+	if filepath.IsAbs(subPath) {
+		return fmt.Errorf("subPath %q must be a relative path", subPath)
+	}
+	for _, part := range strings.Split(filepath.ToSlash(subPath), "/") {
+		if part == ".." {
+			return fmt.Errorf("subPath %q must not contain '..'", subPath)
+		}
+	}
+	return nil
+}
+
+// GetEmptyDirSizeLimit 返回emptyDir卷的大小上限，未设置时返回0
+func GetEmptyDirSizeLimit(source *EmptyDirVolumeSource) (int64, error) {
+	if source.SizeLimit == "" {
+		return 0, nil
+	}
+	limit, err := ParseMemory(source.SizeLimit)
+	if err != nil || limit <= 0 {
+		return 0, fmt.Errorf("invalid emptyDir sizeLimit %q", source.SizeLimit)
+	}
+	return limit, nil
+}
@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"minikubernetes/pkg/controller/persistentvolume"
 	"minikubernetes/pkg/controller/podautoscaler"
 	"minikubernetes/pkg/controller/replicaset"
 )
@@ -12,17 +13,19 @@ type ControllerManager interface {
 type controllerManager struct {
 	rsController  replicaset.ReplicaSetController
 	hpaController podautoscaler.HorizonalController
+	pvController  persistentvolume.PersistentVolumeController
 }
 
 func NewControllerManager(apiServerIP string) ControllerManager {
 	manager := &controllerManager{}
 	manager.rsController = replicaset.NewReplicasetManager(apiServerIP)
 	manager.hpaController = podautoscaler.NewHorizonalController(apiServerIP)
+	manager.pvController = persistentvolume.NewPersistentVolumeController(apiServerIP)
 	return manager
 }
 
 func (cm *controllerManager) Run() error {
-	// 同时跑起两个controller
+	// 同时跑起各个controller
 	err := cm.rsController.RunRSC()
 	if err != nil {
 		return err
@@ -31,5 +34,9 @@ func (cm *controllerManager) Run() error {
 	if err != nil {
 		return err
 	}
+	err = cm.pvController.Run()
+	if err != nil {
+		return err
+	}
 	return nil
 }
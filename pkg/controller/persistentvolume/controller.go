@@ -0,0 +1,274 @@
+package persistentvolume
+
+import (
+	"fmt"
+	"log"
+	v1 "minikubernetes/pkg/api/v1"
+	"minikubernetes/pkg/kubeclient"
+	"sort"
+	"time"
+)
+
+const syncPeriod = 5 * time.Second
+
+// PersistentVolumeController 将pvc绑定到满足条件的pv，没有可用的pv时按storage class动态创建，
+// pvc删除后将pv置为Released，Delete策略的pv由所在节点的kubelet删除
+type PersistentVolumeController interface {
+	Run() error
+}
+
+type persistentVolumeController struct {
+	client kubeclient.Client
+}
+
+func NewPersistentVolumeController(apiServerIP string) PersistentVolumeController {
+	return &persistentVolumeController{
+		client: kubeclient.NewClient(apiServerIP),
+	}
+}
+
+func (pc *persistentVolumeController) Run() error {
+	log.Printf("[PV] start sync persistent volumes")
+	go func() {
+		for {
+			if err := pc.sync(); err != nil {
+				log.Printf("[PV] sync failed, error: %s", err.Error())
+			}
+			time.Sleep(syncPeriod)
+		}
+	}()
+	return nil
+}
+
+func (pc *persistentVolumeController) sync() error {
+	volumes, err := pc.client.GetAllPersistentVolumes()
+	if err != nil {
+		return err
+	}
+	claims, err := pc.client.GetAllPersistentVolumeClaims()
+	if err != nil {
+		return err
+	}
+	classes, err := pc.client.GetAllStorageClasses()
+	if err != nil {
+		return err
+	}
+	pc.syncVolumes(volumes, claims)
+	for _, claim := range claims {
+		if err = pc.syncClaim(claim, volumes, classes); err != nil {
+			log.Printf("[PV] sync claim %s/%s failed, error: %s", claim.Namespace, claim.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+// 绑定的pvc已被删除的pv置为Released
+func (pc *persistentVolumeController) syncVolumes(volumes []*v1.PersistentVolume, claims []*v1.PersistentVolumeClaim) {
+	for _, pv := range volumes {
+		if pv.Spec.ClaimRef == nil || pv.Status.Phase == v1.VolumeReleased {
+			continue
+		}
+		found := false
+		for _, claim := range claims {
+			if v1.IsBoundTo(pv, claim) {
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		log.Printf("[PV] claim %s/%s of volume %s is deleted, reclaim policy: %s", pv.Spec.ClaimRef.Namespace, pv.Spec.ClaimRef.Name, pv.Name, v1.GetReclaimPolicy(pv))
+		pv.Status.Phase = v1.VolumeReleased
+		if err := pc.client.UpdatePersistentVolume(*pv); err != nil {
+			log.Printf("[PV] release volume %s failed, error: %s", pv.Name, err.Error())
+		}
+	}
+}
+
+func (pc *persistentVolumeController) syncClaim(claim *v1.PersistentVolumeClaim, volumes []*v1.PersistentVolume, classes []*v1.StorageClass) error {
+	switch claim.Status.Phase {
+	case v1.ClaimBound:
+		for _, pv := range volumes {
+			if pv.Name == claim.Spec.VolumeName {
+				return nil
+			}
+		}
+		log.Printf("[PV] volume %s of claim %s/%s is lost", claim.Spec.VolumeName, claim.Namespace, claim.Name)
+		claim.Status.Phase = v1.ClaimLost
+		return pc.client.UpdatePersistentVolumeClaim(*claim)
+	case v1.ClaimLost:
+		return nil
+	}
+
+	// 已预留给该pvc的pv，包括上次未完成绑定的与动态创建的
+	for _, pv := range volumes {
+		if !v1.IsBoundTo(pv, claim) {
+			continue
+		}
+		if pv.Status.Phase != v1.VolumeAvailable && pv.Status.Phase != v1.VolumeBound {
+			// 等待节点创建目录
+			return nil
+		}
+		return pc.bind(claim, pv)
+	}
+	if claim.Spec.VolumeName != "" {
+		for _, pv := range volumes {
+			if pv.Name == claim.Spec.VolumeName && pv.Spec.ClaimRef == nil && pv.Status.Phase == v1.VolumeAvailable {
+				if err := checkVolumeMatches(claim, pv); err != nil {
+					return err
+				}
+				return pc.bind(claim, pv)
+			}
+		}
+		return nil
+	}
+	if pv := findBestMatch(claim, volumes); pv != nil {
+		return pc.bind(claim, pv)
+	}
+	for _, class := range classes {
+		if class.Name == claim.Spec.StorageClassName && class.Provisioner == v1.LocalPathProvisioner {
+			return pc.provision(claim, class)
+		}
+	}
+	return nil
+}
+
+// pv与pvc相互记录绑定关系，先更新pv，使pvc更新失败时下次同步仍能找到预留的pv
+func (pc *persistentVolumeController) bind(claim *v1.PersistentVolumeClaim, pv *v1.PersistentVolume) error {
+	pv.Spec.ClaimRef = &v1.PersistentVolumeClaimReference{
+		Namespace: claim.Namespace,
+		Name:      claim.Name,
+		UID:       claim.UID,
+	}
+	pv.Status.Phase = v1.VolumeBound
+	if err := pc.client.UpdatePersistentVolume(*pv); err != nil {
+		return err
+	}
+	claim.Spec.VolumeName = pv.Name
+	claim.Status = v1.PersistentVolumeClaimStatus{
+		Phase:       v1.ClaimBound,
+		AccessModes: pv.Spec.AccessModes,
+		Capacity:    pv.Spec.Capacity,
+	}
+	if err := pc.client.UpdatePersistentVolumeClaim(*claim); err != nil {
+		return err
+	}
+	log.Printf("[PV] claim %s/%s is bound to volume %s on node %s", claim.Namespace, claim.Name, pv.Name, pv.Spec.NodeName)
+	return nil
+}
+
+// 在选出的节点上创建预留给pvc的pv，由该节点的kubelet创建目录后置为Available
+func (pc *persistentVolumeController) provision(claim *v1.PersistentVolumeClaim, class *v1.StorageClass) error {
+	nodeName := class.Parameters[v1.StorageClassNodeNameParameter]
+	if nodeName == "" {
+		nodes, err := pc.client.GetAllNodes()
+		if err != nil {
+			return err
+		}
+		volumes, err := pc.client.GetAllPersistentVolumes()
+		if err != nil {
+			return err
+		}
+		nodeName = selectNode(nodes, volumes)
+		if nodeName == "" {
+			return fmt.Errorf("no node available for provisioning")
+		}
+	}
+	reclaimPolicy := class.ReclaimPolicy
+	if reclaimPolicy == "" {
+		reclaimPolicy = v1.PersistentVolumeReclaimDelete
+	}
+	name := "pvc-" + string(claim.UID)
+	pv := v1.PersistentVolume{
+		TypeMeta: v1.TypeMeta{
+			Kind:       "PersistentVolume",
+			APIVersion: "v1",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:        name,
+			Annotations: map[string]string{v1.ProvisionedByAnnotationKey: class.Provisioner},
+		},
+		Spec: v1.PersistentVolumeSpec{
+			Capacity:                      v1.ResourceList{v1.ResourceStorage: claim.Spec.Resources.Requests[v1.ResourceStorage]},
+			AccessModes:                   claim.Spec.AccessModes,
+			PersistentVolumeReclaimPolicy: reclaimPolicy,
+			StorageClassName:              class.Name,
+			Local:                         &v1.LocalVolumeSource{Path: v1.GetProvisionedLocalPath(v1.GetLocalPathRootDir(class), claim.UID)},
+			NodeName:                      nodeName,
+			ClaimRef: &v1.PersistentVolumeClaimReference{
+				Namespace: claim.Namespace,
+				Name:      claim.Name,
+				UID:       claim.UID,
+			},
+		},
+		Status: v1.PersistentVolumeStatus{Phase: v1.VolumePending},
+	}
+	if err := pc.client.AddPersistentVolume(pv); err != nil {
+		return err
+	}
+	log.Printf("[PV] provisioned volume %s on node %s for claim %s/%s", name, nodeName, claim.Namespace, claim.Name)
+	return nil
+}
+
+func checkVolumeMatches(claim *v1.PersistentVolumeClaim, pv *v1.PersistentVolume) error {
+	if pv.Spec.StorageClassName != claim.Spec.StorageClassName {
+		return fmt.Errorf("storage class of volume %s is %q, requested %q", pv.Name, pv.Spec.StorageClassName, claim.Spec.StorageClassName)
+	}
+	if !v1.ContainsAccessModes(pv.Spec.AccessModes, claim.Spec.AccessModes) {
+		return fmt.Errorf("volume %s does not support access modes %v", pv.Name, claim.Spec.AccessModes)
+	}
+	capacity, err := v1.GetStorageQuantity(pv.Spec.Capacity)
+	if err != nil {
+		return err
+	}
+	request, err := v1.GetStorageQuantity(claim.Spec.Resources.Requests)
+	if err != nil {
+		return err
+	}
+	if capacity < request {
+		return fmt.Errorf("capacity of volume %s is less than requested", pv.Name)
+	}
+	return nil
+}
+
+// 在未绑定的可用pv中选出满足条件且容量最小的
+func findBestMatch(claim *v1.PersistentVolumeClaim, volumes []*v1.PersistentVolume) *v1.PersistentVolume {
+	var best *v1.PersistentVolume
+	var bestCapacity int64
+	for _, pv := range volumes {
+		if pv.Spec.ClaimRef != nil || pv.Status.Phase != v1.VolumeAvailable || checkVolumeMatches(claim, pv) != nil {
+			continue
+		}
+		capacity, _ := v1.GetStorageQuantity(pv.Spec.Capacity)
+		if best == nil || capacity < bestCapacity || (capacity == bestCapacity && pv.Name < best.Name) {
+			best, bestCapacity = pv, capacity
+		}
+	}
+	return best
+}
+
+// 选择没有磁盘压力且pv最少的节点
+func selectNode(nodes []*v1.Node, volumes []*v1.PersistentVolume) string {
+	count := make(map[string]int)
+	for _, pv := range volumes {
+		count[pv.Spec.NodeName]++
+	}
+	var candidates []*v1.Node
+	for _, node := range nodes {
+		if !v1.IsNodeUnderPressure(node, v1.NodeDiskPressure) {
+			candidates = append(candidates, node)
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		ci, cj := count[candidates[i].Name], count[candidates[j].Name]
+		if ci != cj {
+			return ci < cj
+		}
+		return candidates[i].Name < candidates[j].Name
+	})
+	return candidates[0].Name
+}
@@ -0,0 +1,67 @@
+package persistentvolume
+
+import (
+	v1 "minikubernetes/pkg/api/v1"
+	"testing"
+)
+
+func newTestVolume(name, capacity string, modes ...v1.PersistentVolumeAccessMode) *v1.PersistentVolume {
+	return &v1.PersistentVolume{
+		ObjectMeta: v1.ObjectMeta{Name: name},
+		Spec: v1.PersistentVolumeSpec{
+			Capacity:         v1.ResourceList{v1.ResourceStorage: capacity},
+			AccessModes:      modes,
+			StorageClassName: "local",
+			NodeName:         "node-0",
+		},
+		Status: v1.PersistentVolumeStatus{Phase: v1.VolumeAvailable},
+	}
+}
+
+func TestFindBestMatch(t *testing.T) {
+	claim := &v1.PersistentVolumeClaim{
+		ObjectMeta: v1.ObjectMeta{Name: "data", Namespace: "default", UID: "claim-1"},
+		Spec: v1.PersistentVolumeClaimSpec{
+			AccessModes:      []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+			Resources:        v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceStorage: "500Mi"}},
+			StorageClassName: "local",
+		},
+	}
+	bound := newTestVolume("bound", "1Gi", v1.ReadWriteOnce)
+	bound.Spec.ClaimRef = &v1.PersistentVolumeClaimReference{Namespace: "default", Name: "other", UID: "claim-2"}
+	otherClass := newTestVolume("other-class", "1Gi", v1.ReadWriteOnce)
+	otherClass.Spec.StorageClassName = ""
+	volumes := []*v1.PersistentVolume{
+		bound,
+		otherClass,
+		newTestVolume("too-small", "100Mi", v1.ReadWriteOnce),
+		newTestVolume("read-only", "1Gi", v1.ReadOnlyMany),
+		newTestVolume("large", "10Gi", v1.ReadWriteOnce, v1.ReadOnlyMany),
+		newTestVolume("fit", "1Gi", v1.ReadWriteOnce),
+	}
+	if pv := findBestMatch(claim, volumes); pv == nil || pv.Name != "fit" {
+		t.Fatalf("best match = %v, want fit", pv)
+	}
+	claim.Spec.AccessModes = []v1.PersistentVolumeAccessMode{v1.ReadOnlyMany}
+	if pv := findBestMatch(claim, volumes); pv == nil || pv.Name != "read-only" {
+		t.Fatalf("best match = %v, want read-only", pv)
+	}
+	claim.Spec.Resources.Requests[v1.ResourceStorage] = "20Gi"
+	if pv := findBestMatch(claim, volumes); pv != nil {
+		t.Fatalf("best match = %v, want none", pv.Name)
+	}
+}
+
+func TestSelectNode(t *testing.T) {
+	pressure := &v1.Node{ObjectMeta: v1.ObjectMeta{Name: "node-0"}}
+	pressure.Status.Conditions = []v1.NodeCondition{{Type: v1.NodeDiskPressure, Status: v1.ConditionTrue}}
+	nodes := []*v1.Node{pressure, {ObjectMeta: v1.ObjectMeta{Name: "node-1"}}, {ObjectMeta: v1.ObjectMeta{Name: "node-2"}}}
+	volumes := []*v1.PersistentVolume{newTestVolume("pv-0", "1Gi")}
+	volumes[0].Spec.NodeName = "node-1"
+	if name := selectNode(nodes, volumes); name != "node-2" {
+		t.Errorf("selected node = %v, want node-2", name)
+	}
+	if name := selectNode(nodes[:1], nil); name != "" {
+		t.Errorf("selected node = %v, want none", name)
+	}
+}
@@ -111,7 +111,8 @@ func (rc *replicaSetController) oneReplicaSetMatch(rep *v1.ReplicaSet, allPods [
 	var podBelonged []*v1.Pod
 
 	for _, pod := range allPods {
-		if pod == nil || pod.Labels == nil {
+		// 正在终止的pod不再计入副本数
+		if pod == nil || pod.Labels == nil || v1.IsPodTerminating(pod) {
 			continue
 		}
 
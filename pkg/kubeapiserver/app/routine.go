@@ -12,6 +12,10 @@ import (
 	"minikubernetes/tools/timestamp"
 	"minikubernetes/tools/uuid"
 	"net"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -56,6 +60,13 @@ const (
 	Namespace_Pods_url = "/api/v1/namespaces/:namespace/pods"
 	Single_pod_url     = "/api/v1/namespaces/:namespace/pods/:podname"
 	Pod_status_url     = "/api/v1/namespaces/:namespace/pods/:podname/status"
+	Pod_binding_url    = "/api/v1/namespaces/:namespace/pods/:podname/binding"
+	Pod_log_url        = "/api/v1/namespaces/:namespace/pods/:podname/log"
+	Pod_exec_url       = "/api/v1/namespaces/:namespace/pods/:podname/exec"
+	Pod_forward_url    = "/api/v1/namespaces/:namespace/pods/:podname/portforward"
+
+	// 与kubelet server的端口一致
+	KubeletServerPort = 10250
 
 	Node_pods_url = "/api/v1/nodes/:nodename/pods"
 
@@ -93,6 +104,27 @@ const (
 	SidecarMappingURL            = "/api/v1/sidecar-mapping"
 	SidecarServiceNameMappingURL = "/api/v1/sidecar-service-name-mapping"
 
+	AllNamespacesURL   = "/api/v1/namespaces"
+	SingleNamespaceURL = "/api/v1/namespaces/:namespace"
+
+	AllConfigMapsURL       = "/api/v1/configmaps"
+	NamespaceConfigMapsURL = "/api/v1/namespaces/:namespace/configmaps"
+	SingleConfigMapURL     = "/api/v1/namespaces/:namespace/configmaps/:configmapname"
+
+	AllSecretsURL       = "/api/v1/secrets"
+	NamespaceSecretsURL = "/api/v1/namespaces/:namespace/secrets"
+	SingleSecretURL     = "/api/v1/namespaces/:namespace/secrets/:secretname"
+
+	AllStorageClassesURL  = "/api/v1/storageclasses"
+	SingleStorageClassURL = "/api/v1/storageclasses/:storageclassname"
+
+	AllPersistentVolumesURL   = "/api/v1/persistentvolumes"
+	SinglePersistentVolumeURL = "/api/v1/persistentvolumes/:pvname"
+
+	AllPersistentVolumeClaimsURL       = "/api/v1/persistentvolumeclaims"
+	NamespacePersistentVolumeClaimsURL = "/api/v1/namespaces/:namespace/persistentvolumeclaims"
+	SinglePersistentVolumeClaimURL     = "/api/v1/namespaces/:namespace/persistentvolumeclaims/:pvcname"
+
 	AllRollingUpdateURL       = "/api/v1/rollingupdates"
 	NamespaceRollingUpdateURL = "/api/v1/namespaces/:namespace/rollingupdates"
 	SingleRollingUpdateURL    = "/api/v1/namespaces/:namespace/rollingupdates/:rollingupdatename"
@@ -113,6 +145,12 @@ type kubeApiServer struct {
 	port        int
 	store_cli   etcd.Store
 	metrics_cli metrics.MetricsDatabase
+	// secret写入etcd前的加密
+	secretTransformer *utils.SecretTransformer
+	// 访问kubelet server的token
+	kubeletToken string
+	// 创建pod时注入envoy sidecar
+	sidecarInjector *utils.SidecarInjector
 
 	lock sync.Mutex
 }
@@ -138,6 +176,16 @@ func (ser *kubeApiServer) init() {
 
 	ser.metrics_cli = metricsDb
 
+	// 设置SECRET_ENCRYPTION_KEY（base64编码的AES密钥）时加密存储secret
+	transformer, err := utils.NewSecretTransformer(os.Getenv("SECRET_ENCRYPTION_KEY"))
+	if err != nil {
+		log.Panicln("secret encryption init failed:", err)
+		return
+	}
+	ser.secretTransformer = transformer
+	ser.kubeletToken = os.Getenv("KUBELET_AUTH_TOKEN")
+	ser.sidecarInjector = utils.NewSidecarInjector(os.Getenv("SIDECAR_IMAGE_HUB"), os.Getenv("SIDECAR_IMAGE_TAG"))
+
 	// assume that node-0 already registered
 
 	// TODO:(unnecessary) 检查etcdcli是否有效
@@ -190,17 +238,21 @@ func (ser *kubeApiServer) binder() {
 
 	ser.router.GET(All_nodes_url, GetNodesHandler)
 	ser.router.POST(All_nodes_url, AddNodeHandler)
-	ser.router.GET(Node_status_url, GetNodeStatusHandler)
-	ser.router.PUT(Node_status_url, PutNodeStatusHandler) // only modify the status of node
+	ser.router.GET(Node_status_url, ser.GetNodeStatusHandler)
+	ser.router.PUT(Node_status_url, ser.PutNodeStatusHandler) // only modify the status of node
 
 	ser.router.GET(All_pods_url, ser.GetAllPodsHandler)
 	ser.router.GET(Namespace_Pods_url, ser.GetPodsByNamespaceHandler)
 	ser.router.GET(Single_pod_url, ser.GetPodHandler)
 	ser.router.POST(Namespace_Pods_url, ser.AddPodHandler) // for single-pod testing
-	ser.router.PUT(Single_pod_url, UpdatePodHandler)
+	ser.router.PUT(Single_pod_url, ser.UpdatePodHandler)
 	ser.router.DELETE(Single_pod_url, ser.DeletePodHandler)
 	ser.router.GET(Pod_status_url, ser.GetPodStatusHandler)
 	ser.router.PUT(Pod_status_url, ser.PutPodStatusHandler) // only modify the status of a single pod
+	ser.router.POST(Pod_binding_url, ser.BindPodHandler)
+	ser.router.GET(Pod_log_url, ser.GetPodLogHandler)
+	ser.router.GET(Pod_exec_url, ser.ExecPodHandler)
+	ser.router.GET(Pod_forward_url, ser.PortForwardPodHandler)
 
 	ser.router.GET(Node_pods_url, ser.GetPodsByNodeHandler) // for single-pod testing
 
@@ -244,6 +296,41 @@ func (ser *kubeApiServer) binder() {
 	ser.router.POST(SidecarMappingURL, ser.SaveSidecarMapping)
 	ser.router.GET(SidecarServiceNameMappingURL, ser.GetSidecarServiceNameMapping)
 
+	ser.router.GET(AllNamespacesURL, ser.GetAllNamespacesHandler)
+	ser.router.POST(AllNamespacesURL, ser.AddNamespaceHandler)
+	ser.router.GET(SingleNamespaceURL, ser.GetNamespaceHandler)
+	ser.router.PUT(SingleNamespaceURL, ser.UpdateNamespaceHandler)
+	ser.router.DELETE(SingleNamespaceURL, ser.DeleteNamespaceHandler)
+
+	ser.router.GET(AllConfigMapsURL, ser.GetAllConfigMapsHandler)
+	ser.router.POST(NamespaceConfigMapsURL, ser.AddConfigMapHandler)
+	ser.router.GET(SingleConfigMapURL, ser.GetConfigMapHandler)
+	ser.router.PUT(SingleConfigMapURL, ser.UpdateConfigMapHandler)
+	ser.router.DELETE(SingleConfigMapURL, ser.DeleteConfigMapHandler)
+
+	ser.router.GET(AllSecretsURL, ser.GetAllSecretsHandler)
+	ser.router.POST(NamespaceSecretsURL, ser.AddSecretHandler)
+	ser.router.GET(SingleSecretURL, ser.GetSecretHandler)
+	ser.router.PUT(SingleSecretURL, ser.UpdateSecretHandler)
+	ser.router.DELETE(SingleSecretURL, ser.DeleteSecretHandler)
+
+	ser.router.GET(AllStorageClassesURL, ser.GetAllStorageClassesHandler)
+	ser.router.POST(AllStorageClassesURL, ser.AddStorageClassHandler)
+	ser.router.GET(SingleStorageClassURL, ser.GetStorageClassHandler)
+	ser.router.DELETE(SingleStorageClassURL, ser.DeleteStorageClassHandler)
+
+	ser.router.GET(AllPersistentVolumesURL, ser.GetAllPersistentVolumesHandler)
+	ser.router.POST(AllPersistentVolumesURL, ser.AddPersistentVolumeHandler)
+	ser.router.GET(SinglePersistentVolumeURL, ser.GetPersistentVolumeHandler)
+	ser.router.PUT(SinglePersistentVolumeURL, ser.UpdatePersistentVolumeHandler)
+	ser.router.DELETE(SinglePersistentVolumeURL, ser.DeletePersistentVolumeHandler)
+
+	ser.router.GET(AllPersistentVolumeClaimsURL, ser.GetAllPersistentVolumeClaimsHandler)
+	ser.router.POST(NamespacePersistentVolumeClaimsURL, ser.AddPersistentVolumeClaimHandler)
+	ser.router.GET(SinglePersistentVolumeClaimURL, ser.GetPersistentVolumeClaimHandler)
+	ser.router.PUT(SinglePersistentVolumeClaimURL, ser.UpdatePersistentVolumeClaimHandler)
+	ser.router.DELETE(SinglePersistentVolumeClaimURL, ser.DeletePersistentVolumeClaimHandler)
+
 	ser.router.GET(AllRollingUpdateURL, ser.GetAllRollingUpdatesHandler)
 	ser.router.POST(NamespaceRollingUpdateURL, ser.AddRollingUpdateHandler)
 	ser.router.POST(SingleRollingUpdateURL, ser.UpdateRollingUpdateStatusHandler)
@@ -483,11 +570,78 @@ func AddNodeHandler(con *gin.Context) {
 
 }
 
-func GetNodeStatusHandler(con *gin.Context) {
+func (ser *kubeApiServer) GetNodeStatusHandler(con *gin.Context) {
+	ser.lock.Lock()
+	defer ser.lock.Unlock()
+	node, _, _, code, err := ser.getNodeByName(con.Param("nodename"))
+	if err != nil {
+		con.JSON(code, v1.BaseResponse[*v1.NodeStatus]{
+			Error: err.Error(),
+		})
+		return
+	}
+	con.JSON(http.StatusOK, v1.BaseResponse[*v1.NodeStatus]{
+		Data: &node.Status,
+	})
+}
 
+// 由kubelet上报condition，capacity非空时一并更新，地址不可修改
+func (ser *kubeApiServer) PutNodeStatusHandler(con *gin.Context) {
+	ser.lock.Lock()
+	defer ser.lock.Unlock()
+	var status v1.NodeStatus
+	if err := con.ShouldBind(&status); err != nil {
+		con.JSON(http.StatusBadRequest, v1.BaseResponse[*v1.NodeStatus]{
+			Error: "invalid node status json",
+		})
+		return
+	}
+	node, nodeKey, oldJson, code, err := ser.getNodeByName(con.Param("nodename"))
+	if err != nil {
+		con.JSON(code, v1.BaseResponse[*v1.NodeStatus]{
+			Error: err.Error(),
+		})
+		return
+	}
+	node.Status.Conditions = status.Conditions
+	if status.Capacity != nil {
+		node.Status.Capacity = status.Capacity
+	}
+	nodeJson, err := json.Marshal(node)
+	if err != nil {
+		con.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.NodeStatus]{
+			Error: "error in json marshal",
+		})
+		return
+	}
+	ok, err := ser.store_cli.CompareAndSwap(nodeKey, oldJson, string(nodeJson))
+	if err != nil || !ok {
+		con.JSON(http.StatusConflict, v1.BaseResponse[*v1.NodeStatus]{
+			Error: "node was modified concurrently",
+		})
+		return
+	}
+	con.JSON(http.StatusOK, v1.BaseResponse[*v1.NodeStatus]{
+		Data: &node.Status,
+	})
 }
-func PutNodeStatusHandler(con *gin.Context) {
 
+// 返回节点、其在etcd中的key与原始json，出错时返回http状态码
+func (ser *kubeApiServer) getNodeByName(nodeName string) (*v1.Node, string, string, int, error) {
+	nodeUID, err := ser.store_cli.Get(fmt.Sprintf("/registry/namespaces/%s/nodes/%s", Default_Namespace, nodeName))
+	if err != nil || nodeUID == "" {
+		return nil, "", "", http.StatusNotFound, fmt.Errorf("node %s not found", nodeName)
+	}
+	nodeKey := fmt.Sprintf("/registry/nodes/%s", nodeUID)
+	nodeJson, err := ser.store_cli.Get(nodeKey)
+	if err != nil || nodeJson == "" {
+		return nil, "", "", http.StatusNotFound, fmt.Errorf("node %s not found", nodeName)
+	}
+	var node v1.Node
+	if err = json.Unmarshal([]byte(nodeJson), &node); err != nil {
+		return nil, "", "", http.StatusInternalServerError, fmt.Errorf("error in json unmarshal")
+	}
+	return &node, nodeKey, nodeJson, http.StatusOK, nil
 }
 
 // For pods
@@ -707,6 +861,32 @@ func (ser *kubeApiServer) AddPodHandler(con *gin.Context) {
 	}
 	pod.Namespace = namespace
 
+	err = v1.ValidatePodNetwork(&pod)
+	if err != nil {
+		con.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ns, err := ser.getNamespaceFromEtcd(namespace)
+	if err != nil {
+		con.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if ser.sidecarInjector.NeedsInjection(&pod, ns) {
+		err = ser.sidecarInjector.Inject(&pod)
+		if err != nil {
+			con.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		log.Printf("Injected envoy sidecar into pod %v\n", pod_name)
+	}
+
 	/* fake store pod to:
 	1. namespace , store the binding of podname and uid
 	2. node , only uid
@@ -783,8 +963,90 @@ func (ser *kubeApiServer) AddPodHandler(con *gin.Context) {
 
 }
 
-func UpdatePodHandler(con *gin.Context) {
+// 只允许修改labels与annotations，spec与status由调度器和kubelet维护
+func (ser *kubeApiServer) UpdatePodHandler(con *gin.Context) {
+	ser.lock.Lock()
+	defer ser.lock.Unlock()
+	log.Println("UpdatePod")
+
+	np := con.Params.ByName("namespace")
+	pod_name := con.Params.ByName("podname")
+
+	var new_pod v1.Pod
+	err := con.ShouldBind(&new_pod)
+	if err != nil {
+		log.Println("something is wrong when parsing Pod")
+		con.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid pod json",
+		})
+		return
+	}
+	if new_pod.Name != pod_name {
+		con.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("name mismatch, spec: %s, url: %s", new_pod.Name, pod_name),
+		})
+		return
+	}
+
+	prefix := "/registry"
+
+	namespace_pod_keystr := prefix + "/namespaces/" + np + "/pods/" + pod_name
+
+	res, err := ser.store_cli.Get(namespace_pod_keystr)
+	if res == "" || err != nil {
+		log.Println("pod name does not exist in namespace")
+		con.JSON(http.StatusNotFound, gin.H{
+			"error": "pod name does not exist in namespace",
+		})
+		return
+	}
+
+	pod_id := res
+	all_pod_keystr := prefix + "/pods/" + pod_id
+
+	res, err = ser.store_cli.Get(all_pod_keystr)
+	if res == "" || err != nil {
+		log.Println("pod does not exist")
+		con.JSON(http.StatusNotFound, gin.H{
+			"error": "pod does not exist",
+		})
+		return
+	}
+
+	var pod v1.Pod
+	err = json.Unmarshal([]byte(res), &pod)
+	if err != nil {
+		log.Println("error in json unmarshal")
+		con.JSON(http.StatusInternalServerError, gin.H{
+			"error": "error in json unmarshal",
+		})
+		return
+	}
+
+	pod.Labels = new_pod.Labels
+	pod.Annotations = new_pod.Annotations
+
+	pod_str, err := json.Marshal(pod)
+	if err != nil {
+		log.Println("error in json marshal")
+		con.JSON(http.StatusInternalServerError, gin.H{
+			"error": "error in json marshal",
+		})
+		return
+	}
+
+	err = ser.store_cli.Set(all_pod_keystr, string(pod_str))
+	if err != nil {
+		log.Println("error in writing to etcd")
+		con.JSON(http.StatusInternalServerError, gin.H{
+			"error": "error in writing to etcd",
+		})
+		return
+	}
 
+	con.JSON(http.StatusOK, gin.H{
+		"data": pod,
+	})
 }
 func (ser *kubeApiServer) DeletePodHandler(con *gin.Context) {
 	ser.lock.Lock()
@@ -813,6 +1075,22 @@ func (ser *kubeApiServer) DeletePodHandler(con *gin.Context) {
 	pod_id := res
 	all_pod_keystr := prefix + "/pods/" + pod_id
 
+	// 已调度的pod先标记为正在终止，由kubelet终止容器后再以gracePeriodSeconds=0删除
+	terminating, code, err := ser.markPodTerminating(all_pod_keystr, con.Query("gracePeriodSeconds"))
+	if err != nil {
+		log.Println(err)
+		con.JSON(code, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if terminating {
+		con.JSON(http.StatusOK, gin.H{
+			"message": "pod is terminating",
+		})
+		return
+	}
+
 	err = ser.store_cli.Delete(namespace_pod_keystr)
 	if err != nil {
 		log.Println("error in deleting from etcd")
@@ -865,6 +1143,51 @@ func (ser *kubeApiServer) DeletePodHandler(con *gin.Context) {
 
 }
 
+// 返回true表示pod需要由kubelet优雅终止，未调度的pod、镜像pod与宽限期为0的删除立即生效
+// 出错时返回http状态码，并发修改返回409，客户端可重试
+func (ser *kubeApiServer) markPodTerminating(podKey string, gracePeriodQuery string) (bool, int, error) {
+	res, err := ser.store_cli.Get(podKey)
+	if res == "" || err != nil {
+		return false, http.StatusOK, nil
+	}
+	var pod v1.Pod
+	err = json.Unmarshal([]byte(res), &pod)
+	if err != nil {
+		return false, http.StatusInternalServerError, fmt.Errorf("error in json unmarshal")
+	}
+	if pod.Spec.NodeName == "" || v1.IsMirrorPod(&pod) {
+		return false, http.StatusOK, nil
+	}
+	gracePeriod := v1.GetPodGracePeriodSeconds(&pod)
+	if gracePeriodQuery != "" {
+		gracePeriod, err = strconv.ParseInt(gracePeriodQuery, 10, 64)
+		if err != nil || gracePeriod < 0 {
+			return false, http.StatusBadRequest, fmt.Errorf("invalid gracePeriodSeconds %q", gracePeriodQuery)
+		}
+	}
+	if gracePeriod == 0 {
+		return false, http.StatusOK, nil
+	}
+	if v1.IsPodTerminating(&pod) {
+		return true, http.StatusOK, nil
+	}
+	now := timestamp.NewTimestamp()
+	pod.DeletionTimestamp = &now
+	pod.DeletionGracePeriodSeconds = &gracePeriod
+	podJson, err := json.Marshal(pod)
+	if err != nil {
+		return false, http.StatusInternalServerError, fmt.Errorf("error in json marshal")
+	}
+	ok, err := ser.store_cli.CompareAndSwap(podKey, res, string(podJson))
+	if err != nil {
+		return false, http.StatusInternalServerError, fmt.Errorf("error in writing to etcd")
+	}
+	if !ok {
+		return false, http.StatusConflict, fmt.Errorf("pod was modified concurrently")
+	}
+	return true, http.StatusOK, nil
+}
+
 func (ser *kubeApiServer) GetPodStatusHandler(con *gin.Context) {
 	ser.lock.Lock()
 	defer ser.lock.Unlock()
@@ -1009,17 +1332,10 @@ func (ser *kubeApiServer) GetPodsByNodeHandler(con *gin.Context) {
 	}
 	log.Printf("getting info of node: %v", node_name)
 
-	all_pod_str := make([]v1.Pod, 0)
-
-	prefix := "/registry"
-
-	node_pod_keystr := prefix + "/host-nodes/" + node_name + "/pods"
-
-	// 以这个前缀去搜索所有的pod
-	// 得调整接口 加一个GetSubKeysValues
-	res, err := ser.store_cli.GetSubKeysValues(node_pod_keystr)
-	//
-	if res == nil || err != nil {
+	// 未注册的节点返回404，避免与没有pod的节点混淆
+	nodeKey := fmt.Sprintf("/registry/namespaces/%s/nodes/%s", Default_Namespace, node_name)
+	nodeUid, err := ser.store_cli.Get(nodeKey)
+	if err != nil || nodeUid == "" {
 		log.Println("node does not exist")
 		con.JSON(http.StatusNotFound, gin.H{
 			"error": "node does not exist",
@@ -1027,39 +1343,19 @@ func (ser *kubeApiServer) GetPodsByNodeHandler(con *gin.Context) {
 		return
 	}
 
-	var keysToDelete []string
-	//res返回的是pod的uid，回到etcd里面找到pod的信息
-	for k, v := range res {
-		pod_id := v
-		all_pod_keystr := prefix + "/pods/" + pod_id
-
-		res, err := ser.store_cli.Get(all_pod_keystr)
-		//if res == "" || err != nil {
-		//	log.Println("pod does not exist")
-		//	con.JSON(http.StatusNotFound, gin.H{
-		//		"error": "pod does not exist",
-		//	})
-		//	return
-		//}
-		if res == "" || err != nil {
-			// lazy deleting
-			keysToDelete = append(keysToDelete, k)
-			continue
-		}
-		var pod v1.Pod
-		err = json.Unmarshal([]byte(res), &pod)
-		if err != nil {
-			log.Println("error in json unmarshal")
-			con.JSON(http.StatusInternalServerError, gin.H{
-				"error": "error in json unmarshal",
-			})
-			return
-		}
-		all_pod_str = append(all_pod_str, pod)
+	// pod与node的绑定关系保存在spec.nodeName中
+	pods, err := ser.getAllPodsFromEtcd()
+	if err != nil {
+		con.JSON(http.StatusInternalServerError, gin.H{
+			"error": "error in reading all pods from etcd",
+		})
+		return
 	}
-
-	for _, mapping := range keysToDelete {
-		_ = ser.store_cli.Delete(mapping)
+	all_pod_str := make([]*v1.Pod, 0)
+	for _, pod := range pods {
+		if pod.Spec.NodeName == node_name {
+			all_pod_str = append(all_pod_str, pod)
+		}
 	}
 
 	// then return all of them
@@ -1070,6 +1366,108 @@ func (ser *kubeApiServer) GetPodsByNodeHandler(con *gin.Context) {
 	)
 }
 
+func (ser *kubeApiServer) getAllPodsFromEtcd() ([]*v1.Pod, error) {
+	allPodKey := "/registry/pods"
+	res, err := ser.store_cli.GetSubKeysValues(allPodKey)
+	if err != nil {
+		return nil, err
+	}
+	pods := make([]*v1.Pod, 0)
+	for _, v := range res {
+		var pod v1.Pod
+		err = json.Unmarshal([]byte(v), &pod)
+		if err != nil {
+			return nil, err
+		}
+		pods = append(pods, &pod)
+	}
+	return pods, nil
+}
+
+// bindPod 将pod绑定到节点，仅当pod尚未绑定时成功，返回值为http状态码
+func (ser *kubeApiServer) bindPod(podUid string, nodeName string) (int, error) {
+	nodeKey := fmt.Sprintf("/registry/namespaces/%s/nodes/%s", Default_Namespace, nodeName)
+	nodeUid, err := ser.store_cli.Get(nodeKey)
+	if err != nil || nodeUid == "" {
+		return http.StatusNotFound, fmt.Errorf("node %s not found", nodeName)
+	}
+	podKey := fmt.Sprintf("/registry/pods/%s", podUid)
+	podJson, err := ser.store_cli.Get(podKey)
+	if err != nil || podJson == "" {
+		return http.StatusNotFound, fmt.Errorf("pod %s not found", podUid)
+	}
+	var pod v1.Pod
+	err = json.Unmarshal([]byte(podJson), &pod)
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("error in json unmarshal")
+	}
+	if pod.Spec.NodeName != "" {
+		return http.StatusConflict, fmt.Errorf("pod %s/%s is already bound to node %s", pod.Namespace, pod.Name, pod.Spec.NodeName)
+	}
+	pod.Spec.NodeName = nodeName
+	newPodJson, err := json.Marshal(pod)
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("error in json marshal")
+	}
+	// 只有pod在读取后未被修改时才写入，防止重复调度
+	ok, err := ser.store_cli.CompareAndSwap(podKey, podJson, string(newPodJson))
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("error in writing to etcd")
+	}
+	if !ok {
+		return http.StatusConflict, fmt.Errorf("pod %s/%s was modified concurrently", pod.Namespace, pod.Name)
+	}
+	return http.StatusOK, nil
+}
+
+func (ser *kubeApiServer) BindPodHandler(con *gin.Context) {
+	ser.lock.Lock()
+	defer ser.lock.Unlock()
+	log.Println("BindPod")
+
+	np := con.Params.ByName("namespace")
+	pod_name := con.Params.ByName("podname")
+
+	var binding v1.Binding
+	err := con.ShouldBind(&binding)
+	if err != nil {
+		con.JSON(http.StatusBadRequest, v1.BaseResponse[interface{}]{
+			Error: "invalid binding json",
+		})
+		return
+	}
+	if binding.Target.Kind != "" && binding.Target.Kind != "Node" {
+		con.JSON(http.StatusBadRequest, v1.BaseResponse[interface{}]{
+			Error: fmt.Sprintf("invalid binding target kind %s", binding.Target.Kind),
+		})
+		return
+	}
+	if binding.Target.Name == "" {
+		con.JSON(http.StatusBadRequest, v1.BaseResponse[interface{}]{
+			Error: "binding target name cannot be empty",
+		})
+		return
+	}
+
+	namespace_pod_keystr := "/registry/namespaces/" + np + "/pods/" + pod_name
+	pod_id, err := ser.store_cli.Get(namespace_pod_keystr)
+	if pod_id == "" || err != nil {
+		con.JSON(http.StatusNotFound, v1.BaseResponse[interface{}]{
+			Error: fmt.Sprintf("pod %s/%s not found", np, pod_name),
+		})
+		return
+	}
+
+	code, err := ser.bindPod(pod_id, binding.Target.Name)
+	if err != nil {
+		con.JSON(code, v1.BaseResponse[interface{}]{
+			Error: err.Error(),
+		})
+		return
+	}
+	con.JSON(http.StatusOK, v1.BaseResponse[interface{}]{})
+}
+
 func (s *kubeApiServer) GetAllServicesHandler(c *gin.Context) {
 	//allSvcKey := "/registry/services"
 	//res, err := s.store_cli.GetSubKeysValues(allSvcKey)
@@ -1757,8 +2155,11 @@ func (s *kubeApiServer) RegisterNodeHandler(c *gin.Context) {
 			CreationTimestamp: timestamp.NewTimestamp(),
 			Labels:            n.Labels,
 		},
+		Spec: n.Spec,
 		Status: v1.NodeStatus{
-			Address: address,
+			Address:     address,
+			Capacity:    n.Status.Capacity,
+			Allocatable: n.Status.Allocatable,
 		},
 	}
 	allNodeKey := fmt.Sprintf("/registry/nodes/%v", node.UID)
@@ -1809,21 +2210,40 @@ func (s *kubeApiServer) UnregisterNodeHandler(c *gin.Context) {
 		return
 	}
 	// 把所有pod变为unscheduled
-	nodePodKey := fmt.Sprintf("/registry/host-nodes/%s/pods", nodeName)
-	err = s.store_cli.DeleteSubKeys(nodePodKey)
+	pods, err := s.getAllPodsFromEtcd()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.Node]{
-			Error: "error in deleting node-pod mappings from etcd",
+			Error: "error in reading all pods from etcd",
 		})
 		return
 	}
-	// 删除pod
-	err = s.store_cli.Delete(namespaceNodeKey)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.Node]{
-			Error: "error in deleting node from etcd",
-		})
-		return
+	for _, pod := range pods {
+		if pod.Spec.NodeName != nodeName {
+			continue
+		}
+		pod.Spec.NodeName = ""
+		podJson, err := json.Marshal(pod)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.Node]{
+				Error: "error in json marshal",
+			})
+			return
+		}
+		err = s.store_cli.Set(fmt.Sprintf("/registry/pods/%s", pod.UID), string(podJson))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.Node]{
+				Error: "error in unbinding pods from etcd",
+			})
+			return
+		}
+	}
+	// 删除pod
+	err = s.store_cli.Delete(namespaceNodeKey)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.Node]{
+			Error: "error in deleting node from etcd",
+		})
+		return
 	}
 	allNodeKey := fmt.Sprintf("/registry/nodes/%s", uid)
 	err = s.store_cli.Delete(allNodeKey)
@@ -1902,48 +2322,11 @@ func (s *kubeApiServer) SchedulePodToNodeHandler(c *gin.Context) {
 		})
 		return
 	}
-	podKey := fmt.Sprintf("/registry/pods/%s", podUid)
-	podJson, err := s.store_cli.Get(podKey)
-	if err != nil || podJson == "" {
-		c.JSON(http.StatusNotFound, v1.BaseResponse[interface{}]{
-			Error: fmt.Sprintf("pod %s not found", podUid),
-		})
-		return
-	}
-
-	var pod v1.Pod
-	err = json.Unmarshal([]byte(podJson), &pod)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, v1.BaseResponse[interface{}]{
-			Error: "error in json unmarshal",
-		})
-		return
-	}
-
-	res, err := s.store_cli.GetSubKeysValues("/registry/host-nodes")
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, v1.BaseResponse[interface{}]{
-			Error: "error in reading from etcd",
-		})
-		return
-	}
-	for k, v := range res {
-		if v == podUid {
-			err = s.store_cli.Delete(k)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, v1.BaseResponse[interface{}]{
-					Error: "error in deleting old node-pod mapping from etcd",
-				})
-				return
-			}
-		}
-	}
-
-	nodePodKey := fmt.Sprintf("/registry/host-nodes/%s/pods/%s_%s", nodeName, pod.Namespace, pod.Name)
-	err = s.store_cli.Set(nodePodKey, podUid)
+	// 与binding子资源语义一致，已绑定的pod不允许再次调度
+	code, err := s.bindPod(podUid, nodeName)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, v1.BaseResponse[interface{}]{
-			Error: "error in writing new node-pod mapping to etcd",
+		c.JSON(code, v1.BaseResponse[interface{}]{
+			Error: err.Error(),
 		})
 		return
 	}
@@ -1954,33 +2337,18 @@ func (s *kubeApiServer) GetUnscheduledPodHandler(c *gin.Context) {
 	// 获取所有pod
 	s.lock.Lock()
 	defer s.lock.Unlock()
-	allPodKey := "/registry/pods"
-	res, err := s.store_cli.GetSubKeysValues(allPodKey)
+	pods, err := s.getAllPodsFromEtcd()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, v1.BaseResponse[[]*v1.Pod]{
 			Error: "error in reading from etcd",
 		})
 		return
 	}
-	// 通过/registry/host-nodes获取所有已被调度的pod uid
-	hostKeyPrefix := "/registry/host-nodes"
-	hostRes, err := s.store_cli.GetSubKeysValues(hostKeyPrefix)
-	scheduledUidSet := make(map[string]struct{})
-	for _, uid := range hostRes {
-		scheduledUidSet[uid] = struct{}{}
-	}
+	// spec.nodeName为空即为未调度
 	var unscheduledPods []*v1.Pod
-	for _, v := range res {
-		var pod v1.Pod
-		err = json.Unmarshal([]byte(v), &pod)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, v1.BaseResponse[[]*v1.Pod]{
-				Error: "error in json unmarshal",
-			})
-			return
-		}
-		if _, ok := scheduledUidSet[string(pod.UID)]; !ok {
-			unscheduledPods = append(unscheduledPods, &pod)
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" {
+			unscheduledPods = append(unscheduledPods, pod)
 		}
 	}
 	c.JSON(http.StatusOK, v1.BaseResponse[[]*v1.Pod]{
@@ -2938,3 +3306,1370 @@ func (s *kubeApiServer) DeleteRollingUpdateHandler(c *gin.Context) {
 		Data: &ru,
 	})
 }
+
+// configmap与secret的key会作为文件名和环境变量名
+var configKeyRegexp = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+
+func validateConfigKeys[T any](data map[string]T) error {
+	for key := range data {
+		if !configKeyRegexp.MatchString(key) || key == "." || key == ".." {
+			return fmt.Errorf("invalid key %q", key)
+		}
+	}
+	return nil
+}
+
+func validateConfigObject(meta *v1.ObjectMeta, kind, expectedKind, urlNamespace string) error {
+	if meta.Name == "" {
+		return fmt.Errorf("%s name is required", strings.ToLower(expectedKind))
+	}
+	if meta.Namespace == "" {
+		if urlNamespace != Default_Namespace {
+			return fmt.Errorf("namespace mismatch, spec: empty(using default), url: %s", urlNamespace)
+		}
+	} else if meta.Namespace != urlNamespace {
+		return fmt.Errorf("namespace mismatch, spec: %s, url: %s", meta.Namespace, urlNamespace)
+	}
+	if kind != expectedKind {
+		return fmt.Errorf("invalid api object kind")
+	}
+	return nil
+}
+
+// namespace对象与/registry/namespaces/下的名称映射分开存放，namespace不存在时返回nil
+func (s *kubeApiServer) getNamespaceFromEtcd(name string) (*v1.Namespace, error) {
+	nsJson, err := s.store_cli.Get(fmt.Sprintf("/registry/ns/%s", name))
+	if err != nil || nsJson == "" {
+		return nil, nil
+	}
+	var ns v1.Namespace
+	err = json.Unmarshal([]byte(nsJson), &ns)
+	if err != nil {
+		return nil, fmt.Errorf("error in json unmarshal")
+	}
+	return &ns, nil
+}
+
+func (s *kubeApiServer) saveNamespaceToEtcd(ns *v1.Namespace) error {
+	nsJson, err := json.Marshal(ns)
+	if err != nil {
+		return fmt.Errorf("error in json marshal")
+	}
+	err = s.store_cli.Set(fmt.Sprintf("/registry/ns/%s", ns.Name), string(nsJson))
+	if err != nil {
+		return fmt.Errorf("error in writing to etcd")
+	}
+	return nil
+}
+
+func (s *kubeApiServer) GetAllNamespacesHandler(c *gin.Context) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	res, err := s.store_cli.GetSubKeysValues("/registry/ns/")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[[]*v1.Namespace]{
+			Error: "error in reading namespaces from etcd",
+		})
+		return
+	}
+	namespaces := make([]*v1.Namespace, 0)
+	for _, v := range res {
+		var ns v1.Namespace
+		err = json.Unmarshal([]byte(v), &ns)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, v1.BaseResponse[[]*v1.Namespace]{
+				Error: "error in json unmarshal",
+			})
+			return
+		}
+		namespaces = append(namespaces, &ns)
+	}
+	c.JSON(http.StatusOK, v1.BaseResponse[[]*v1.Namespace]{
+		Data: namespaces,
+	})
+}
+
+func (s *kubeApiServer) GetNamespaceHandler(c *gin.Context) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	name := c.Param("namespace")
+	ns, err := s.getNamespaceFromEtcd(name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.Namespace]{
+			Error: err.Error(),
+		})
+		return
+	}
+	if ns == nil {
+		c.JSON(http.StatusNotFound, v1.BaseResponse[*v1.Namespace]{
+			Error: fmt.Sprintf("namespace %s not found", name),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, v1.BaseResponse[*v1.Namespace]{
+		Data: ns,
+	})
+}
+
+func (s *kubeApiServer) AddNamespaceHandler(c *gin.Context) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var ns v1.Namespace
+	err := c.ShouldBind(&ns)
+	if err != nil || ns.Name == "" || (ns.Kind != "" && ns.Kind != "Namespace") {
+		c.JSON(http.StatusBadRequest, v1.BaseResponse[*v1.Namespace]{
+			Error: "invalid namespace json",
+		})
+		return
+	}
+	old, err := s.getNamespaceFromEtcd(ns.Name)
+	if err != nil || old != nil {
+		c.JSON(http.StatusConflict, v1.BaseResponse[*v1.Namespace]{
+			Error: fmt.Sprintf("namespace %s already exists", ns.Name),
+		})
+		return
+	}
+	ns.Namespace = ""
+	ns.UID = v1.UID(uuid.NewUUID())
+	ns.CreationTimestamp = timestamp.NewTimestamp()
+	err = s.saveNamespaceToEtcd(&ns)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.Namespace]{
+			Error: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusCreated, v1.BaseResponse[*v1.Namespace]{
+		Data: &ns,
+	})
+}
+
+// 仅更新labels与annotations，修改注入开关只影响之后创建的pod
+func (s *kubeApiServer) UpdateNamespaceHandler(c *gin.Context) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var ns v1.Namespace
+	err := c.ShouldBind(&ns)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, v1.BaseResponse[*v1.Namespace]{
+			Error: "invalid namespace json",
+		})
+		return
+	}
+	name := c.Param("namespace")
+	if ns.Name != name {
+		c.JSON(http.StatusBadRequest, v1.BaseResponse[*v1.Namespace]{
+			Error: fmt.Sprintf("name mismatch, spec: %s, url: %s", ns.Name, name),
+		})
+		return
+	}
+	old, err := s.getNamespaceFromEtcd(name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.Namespace]{
+			Error: err.Error(),
+		})
+		return
+	}
+	if old == nil {
+		c.JSON(http.StatusNotFound, v1.BaseResponse[*v1.Namespace]{
+			Error: fmt.Sprintf("namespace %s not found", name),
+		})
+		return
+	}
+	old.Labels = ns.Labels
+	old.Annotations = ns.Annotations
+	err = s.saveNamespaceToEtcd(old)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.Namespace]{
+			Error: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, v1.BaseResponse[*v1.Namespace]{
+		Data: old,
+	})
+}
+
+// 只删除namespace对象，其中的资源不会被删除
+func (s *kubeApiServer) DeleteNamespaceHandler(c *gin.Context) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	name := c.Param("namespace")
+	ns, err := s.getNamespaceFromEtcd(name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.Namespace]{
+			Error: err.Error(),
+		})
+		return
+	}
+	if ns == nil {
+		c.JSON(http.StatusNotFound, v1.BaseResponse[*v1.Namespace]{
+			Error: fmt.Sprintf("namespace %s not found", name),
+		})
+		return
+	}
+	err = s.store_cli.Delete(fmt.Sprintf("/registry/ns/%s", name))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.Namespace]{
+			Error: "error in deleting namespace from etcd",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, v1.BaseResponse[*v1.Namespace]{
+		Data: ns,
+	})
+}
+
+func (s *kubeApiServer) getConfigMapFromEtcd(namespace, name string) (*v1.ConfigMap, error) {
+	uid, err := s.store_cli.Get(fmt.Sprintf("/registry/namespaces/%s/configmaps/%s", namespace, name))
+	if err != nil || uid == "" {
+		return nil, nil
+	}
+	cmJson, err := s.store_cli.Get(fmt.Sprintf("/registry/configmaps/%s", uid))
+	if err != nil || cmJson == "" {
+		return nil, fmt.Errorf("error in reading configmap from etcd")
+	}
+	var cm v1.ConfigMap
+	err = json.Unmarshal([]byte(cmJson), &cm)
+	if err != nil {
+		return nil, fmt.Errorf("error in json unmarshal")
+	}
+	return &cm, nil
+}
+
+func (s *kubeApiServer) saveConfigMapToEtcd(cm *v1.ConfigMap) error {
+	cmJson, err := json.Marshal(cm)
+	if err != nil {
+		return fmt.Errorf("error in json marshal")
+	}
+	err = s.store_cli.Set(fmt.Sprintf("/registry/namespaces/%s/configmaps/%s", cm.Namespace, cm.Name), string(cm.UID))
+	if err != nil {
+		return fmt.Errorf("error in writing to etcd")
+	}
+	err = s.store_cli.Set(fmt.Sprintf("/registry/configmaps/%s", cm.UID), string(cmJson))
+	if err != nil {
+		return fmt.Errorf("error in writing to etcd")
+	}
+	return nil
+}
+
+func (s *kubeApiServer) GetAllConfigMapsHandler(c *gin.Context) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	res, err := s.store_cli.GetSubKeysValues("/registry/configmaps")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[[]*v1.ConfigMap]{
+			Error: "error in reading configmaps from etcd",
+		})
+		return
+	}
+	configMaps := make([]*v1.ConfigMap, 0)
+	for _, v := range res {
+		var cm v1.ConfigMap
+		err = json.Unmarshal([]byte(v), &cm)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, v1.BaseResponse[[]*v1.ConfigMap]{
+				Error: "error in json unmarshal",
+			})
+			return
+		}
+		configMaps = append(configMaps, &cm)
+	}
+	c.JSON(http.StatusOK, v1.BaseResponse[[]*v1.ConfigMap]{
+		Data: configMaps,
+	})
+}
+
+func (s *kubeApiServer) GetConfigMapHandler(c *gin.Context) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	namespace := c.Param("namespace")
+	name := c.Param("configmapname")
+	cm, err := s.getConfigMapFromEtcd(namespace, name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.ConfigMap]{
+			Error: err.Error(),
+		})
+		return
+	}
+	if cm == nil {
+		c.JSON(http.StatusNotFound, v1.BaseResponse[*v1.ConfigMap]{
+			Error: fmt.Sprintf("configmap %s/%s not found", namespace, name),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, v1.BaseResponse[*v1.ConfigMap]{
+		Data: cm,
+	})
+}
+
+func (s *kubeApiServer) AddConfigMapHandler(c *gin.Context) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var cm v1.ConfigMap
+	err := c.ShouldBind(&cm)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, v1.BaseResponse[*v1.ConfigMap]{
+			Error: "invalid configmap json",
+		})
+		return
+	}
+	namespace := c.Param("namespace")
+	err = validateConfigObject(&cm.ObjectMeta, cm.Kind, "ConfigMap", namespace)
+	if err == nil {
+		err = validateConfigKeys(cm.Data)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, v1.BaseResponse[*v1.ConfigMap]{
+			Error: err.Error(),
+		})
+		return
+	}
+	cm.Namespace = namespace
+
+	old, err := s.getConfigMapFromEtcd(cm.Namespace, cm.Name)
+	if err != nil || old != nil {
+		c.JSON(http.StatusConflict, v1.BaseResponse[*v1.ConfigMap]{
+			Error: fmt.Sprintf("configmap %s/%s already exists", cm.Namespace, cm.Name),
+		})
+		return
+	}
+	cm.UID = v1.UID(uuid.NewUUID())
+	cm.CreationTimestamp = timestamp.NewTimestamp()
+	err = s.saveConfigMapToEtcd(&cm)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.ConfigMap]{
+			Error: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusCreated, v1.BaseResponse[*v1.ConfigMap]{
+		Data: &cm,
+	})
+}
+
+// 仅更新data，挂载了该configmap的pod会在kubelet下次同步时刷新文件
+func (s *kubeApiServer) UpdateConfigMapHandler(c *gin.Context) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var cm v1.ConfigMap
+	err := c.ShouldBind(&cm)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, v1.BaseResponse[*v1.ConfigMap]{
+			Error: "invalid configmap json",
+		})
+		return
+	}
+	namespace := c.Param("namespace")
+	name := c.Param("configmapname")
+	if cm.Name != name {
+		c.JSON(http.StatusBadRequest, v1.BaseResponse[*v1.ConfigMap]{
+			Error: fmt.Sprintf("name mismatch, spec: %s, url: %s", cm.Name, name),
+		})
+		return
+	}
+	err = validateConfigObject(&cm.ObjectMeta, cm.Kind, "ConfigMap", namespace)
+	if err == nil {
+		err = validateConfigKeys(cm.Data)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, v1.BaseResponse[*v1.ConfigMap]{
+			Error: err.Error(),
+		})
+		return
+	}
+	old, err := s.getConfigMapFromEtcd(namespace, name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.ConfigMap]{
+			Error: err.Error(),
+		})
+		return
+	}
+	if old == nil {
+		c.JSON(http.StatusNotFound, v1.BaseResponse[*v1.ConfigMap]{
+			Error: fmt.Sprintf("configmap %s/%s not found", namespace, name),
+		})
+		return
+	}
+	old.Data = cm.Data
+	old.Labels = cm.Labels
+	err = s.saveConfigMapToEtcd(old)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.ConfigMap]{
+			Error: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, v1.BaseResponse[*v1.ConfigMap]{
+		Data: old,
+	})
+}
+
+func (s *kubeApiServer) DeleteConfigMapHandler(c *gin.Context) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	namespace := c.Param("namespace")
+	name := c.Param("configmapname")
+	cm, err := s.getConfigMapFromEtcd(namespace, name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.ConfigMap]{
+			Error: err.Error(),
+		})
+		return
+	}
+	if cm == nil {
+		c.JSON(http.StatusNotFound, v1.BaseResponse[*v1.ConfigMap]{
+			Error: fmt.Sprintf("configmap %s/%s not found", namespace, name),
+		})
+		return
+	}
+	err = s.store_cli.Delete(fmt.Sprintf("/registry/namespaces/%s/configmaps/%s", namespace, name))
+	if err == nil {
+		err = s.store_cli.Delete(fmt.Sprintf("/registry/configmaps/%s", cm.UID))
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.ConfigMap]{
+			Error: "error in deleting configmap from etcd",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, v1.BaseResponse[*v1.ConfigMap]{
+		Data: cm,
+	})
+}
+
+// secret的data在json中为base64，开启加密时整体加密后写入etcd
+func (s *kubeApiServer) getSecretFromEtcd(namespace, name string) (*v1.Secret, error) {
+	uid, err := s.store_cli.Get(fmt.Sprintf("/registry/namespaces/%s/secrets/%s", namespace, name))
+	if err != nil || uid == "" {
+		return nil, nil
+	}
+	stored, err := s.store_cli.Get(fmt.Sprintf("/registry/secrets/%s", uid))
+	if err != nil || stored == "" {
+		return nil, fmt.Errorf("error in reading secret from etcd")
+	}
+	secretJson, err := s.secretTransformer.TransformFromStorage(stored)
+	if err != nil {
+		return nil, fmt.Errorf("error in decrypting secret: %v", err)
+	}
+	var secret v1.Secret
+	err = json.Unmarshal(secretJson, &secret)
+	if err != nil {
+		return nil, fmt.Errorf("error in json unmarshal")
+	}
+	return &secret, nil
+}
+
+func (s *kubeApiServer) getAllSecretsFromEtcd() ([]*v1.Secret, error) {
+	res, err := s.store_cli.GetSubKeysValues("/registry/secrets")
+	if err != nil {
+		return nil, fmt.Errorf("error in reading secrets from etcd")
+	}
+	secrets := make([]*v1.Secret, 0)
+	for _, v := range res {
+		secretJson, err := s.secretTransformer.TransformFromStorage(v)
+		if err != nil {
+			return nil, fmt.Errorf("error in decrypting secret: %v", err)
+		}
+		var secret v1.Secret
+		err = json.Unmarshal(secretJson, &secret)
+		if err != nil {
+			return nil, fmt.Errorf("error in json unmarshal")
+		}
+		secrets = append(secrets, &secret)
+	}
+	return secrets, nil
+}
+
+func (s *kubeApiServer) saveSecretToEtcd(secret *v1.Secret) error {
+	secretJson, err := json.Marshal(secret)
+	if err != nil {
+		return fmt.Errorf("error in json marshal")
+	}
+	stored, err := s.secretTransformer.TransformToStorage(secretJson)
+	if err != nil {
+		return fmt.Errorf("error in encrypting secret: %v", err)
+	}
+	err = s.store_cli.Set(fmt.Sprintf("/registry/namespaces/%s/secrets/%s", secret.Namespace, secret.Name), string(secret.UID))
+	if err != nil {
+		return fmt.Errorf("error in writing to etcd")
+	}
+	err = s.store_cli.Set(fmt.Sprintf("/registry/secrets/%s", secret.UID), stored)
+	if err != nil {
+		return fmt.Errorf("error in writing to etcd")
+	}
+	return nil
+}
+
+// stringData合并进data后清空
+func normalizeSecret(secret *v1.Secret) error {
+	if secret.Type == "" {
+		secret.Type = v1.SecretTypeOpaque
+	}
+	if len(secret.StringData) != 0 && secret.Data == nil {
+		secret.Data = make(map[string][]byte)
+	}
+	for k, v := range secret.StringData {
+		secret.Data[k] = []byte(v)
+	}
+	secret.StringData = nil
+	if secret.Type == v1.SecretTypeDockerConfigJson {
+		if _, ok := secret.Data[v1.DockerConfigJsonKey]; !ok {
+			return fmt.Errorf("secret of type %s must contain key %s", secret.Type, v1.DockerConfigJsonKey)
+		}
+	}
+	return validateConfigKeys(secret.Data)
+}
+
+func (s *kubeApiServer) GetAllSecretsHandler(c *gin.Context) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	secrets, err := s.getAllSecretsFromEtcd()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[[]*v1.Secret]{
+			Error: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, v1.BaseResponse[[]*v1.Secret]{
+		Data: secrets,
+	})
+}
+
+func (s *kubeApiServer) GetSecretHandler(c *gin.Context) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	namespace := c.Param("namespace")
+	name := c.Param("secretname")
+	secret, err := s.getSecretFromEtcd(namespace, name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.Secret]{
+			Error: err.Error(),
+		})
+		return
+	}
+	if secret == nil {
+		c.JSON(http.StatusNotFound, v1.BaseResponse[*v1.Secret]{
+			Error: fmt.Sprintf("secret %s/%s not found", namespace, name),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, v1.BaseResponse[*v1.Secret]{
+		Data: secret,
+	})
+}
+
+func (s *kubeApiServer) AddSecretHandler(c *gin.Context) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var secret v1.Secret
+	err := c.ShouldBind(&secret)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, v1.BaseResponse[*v1.Secret]{
+			Error: "invalid secret json",
+		})
+		return
+	}
+	namespace := c.Param("namespace")
+	err = validateConfigObject(&secret.ObjectMeta, secret.Kind, "Secret", namespace)
+	if err == nil {
+		err = normalizeSecret(&secret)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, v1.BaseResponse[*v1.Secret]{
+			Error: err.Error(),
+		})
+		return
+	}
+	secret.Namespace = namespace
+
+	old, err := s.getSecretFromEtcd(secret.Namespace, secret.Name)
+	if err != nil || old != nil {
+		c.JSON(http.StatusConflict, v1.BaseResponse[*v1.Secret]{
+			Error: fmt.Sprintf("secret %s/%s already exists", secret.Namespace, secret.Name),
+		})
+		return
+	}
+	secret.UID = v1.UID(uuid.NewUUID())
+	secret.CreationTimestamp = timestamp.NewTimestamp()
+	err = s.saveSecretToEtcd(&secret)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.Secret]{
+			Error: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusCreated, v1.BaseResponse[*v1.Secret]{
+		Data: &secret,
+	})
+}
+
+func (s *kubeApiServer) UpdateSecretHandler(c *gin.Context) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var secret v1.Secret
+	err := c.ShouldBind(&secret)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, v1.BaseResponse[*v1.Secret]{
+			Error: "invalid secret json",
+		})
+		return
+	}
+	namespace := c.Param("namespace")
+	name := c.Param("secretname")
+	if secret.Name != name {
+		c.JSON(http.StatusBadRequest, v1.BaseResponse[*v1.Secret]{
+			Error: fmt.Sprintf("name mismatch, spec: %s, url: %s", secret.Name, name),
+		})
+		return
+	}
+	err = validateConfigObject(&secret.ObjectMeta, secret.Kind, "Secret", namespace)
+	if err == nil {
+		err = normalizeSecret(&secret)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, v1.BaseResponse[*v1.Secret]{
+			Error: err.Error(),
+		})
+		return
+	}
+	old, err := s.getSecretFromEtcd(namespace, name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.Secret]{
+			Error: err.Error(),
+		})
+		return
+	}
+	if old == nil {
+		c.JSON(http.StatusNotFound, v1.BaseResponse[*v1.Secret]{
+			Error: fmt.Sprintf("secret %s/%s not found", namespace, name),
+		})
+		return
+	}
+	old.Data = secret.Data
+	old.Type = secret.Type
+	old.Labels = secret.Labels
+	err = s.saveSecretToEtcd(old)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.Secret]{
+			Error: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, v1.BaseResponse[*v1.Secret]{
+		Data: old,
+	})
+}
+
+func (s *kubeApiServer) DeleteSecretHandler(c *gin.Context) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	namespace := c.Param("namespace")
+	name := c.Param("secretname")
+	secret, err := s.getSecretFromEtcd(namespace, name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.Secret]{
+			Error: err.Error(),
+		})
+		return
+	}
+	if secret == nil {
+		c.JSON(http.StatusNotFound, v1.BaseResponse[*v1.Secret]{
+			Error: fmt.Sprintf("secret %s/%s not found", namespace, name),
+		})
+		return
+	}
+	err = s.store_cli.Delete(fmt.Sprintf("/registry/namespaces/%s/secrets/%s", namespace, name))
+	if err == nil {
+		err = s.store_cli.Delete(fmt.Sprintf("/registry/secrets/%s", secret.UID))
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.Secret]{
+			Error: "error in deleting secret from etcd",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, v1.BaseResponse[*v1.Secret]{
+		Data: secret,
+	})
+}
+
+func (s *kubeApiServer) getStorageClassFromEtcd(name string) (*v1.StorageClass, error) {
+	scJson, err := s.store_cli.Get(fmt.Sprintf("/registry/storageclasses/%s", name))
+	if err != nil || scJson == "" {
+		return nil, nil
+	}
+	var sc v1.StorageClass
+	err = json.Unmarshal([]byte(scJson), &sc)
+	if err != nil {
+		return nil, fmt.Errorf("error in json unmarshal")
+	}
+	return &sc, nil
+}
+
+func (s *kubeApiServer) GetAllStorageClassesHandler(c *gin.Context) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	res, err := s.store_cli.GetSubKeysValues("/registry/storageclasses/")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[[]*v1.StorageClass]{
+			Error: "error in reading storageclasses from etcd",
+		})
+		return
+	}
+	classes := make([]*v1.StorageClass, 0)
+	for _, v := range res {
+		var sc v1.StorageClass
+		err = json.Unmarshal([]byte(v), &sc)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, v1.BaseResponse[[]*v1.StorageClass]{
+				Error: "error in json unmarshal",
+			})
+			return
+		}
+		classes = append(classes, &sc)
+	}
+	c.JSON(http.StatusOK, v1.BaseResponse[[]*v1.StorageClass]{
+		Data: classes,
+	})
+}
+
+func (s *kubeApiServer) GetStorageClassHandler(c *gin.Context) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	name := c.Param("storageclassname")
+	sc, err := s.getStorageClassFromEtcd(name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.StorageClass]{
+			Error: err.Error(),
+		})
+		return
+	}
+	if sc == nil {
+		c.JSON(http.StatusNotFound, v1.BaseResponse[*v1.StorageClass]{
+			Error: fmt.Sprintf("storageclass %s not found", name),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, v1.BaseResponse[*v1.StorageClass]{
+		Data: sc,
+	})
+}
+
+func (s *kubeApiServer) AddStorageClassHandler(c *gin.Context) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var sc v1.StorageClass
+	err := c.ShouldBind(&sc)
+	if err != nil || sc.Name == "" || (sc.Kind != "" && sc.Kind != "StorageClass") {
+		c.JSON(http.StatusBadRequest, v1.BaseResponse[*v1.StorageClass]{
+			Error: "invalid storageclass json",
+		})
+		return
+	}
+	if sc.Provisioner == "" {
+		c.JSON(http.StatusBadRequest, v1.BaseResponse[*v1.StorageClass]{
+			Error: "storageclass provisioner is required",
+		})
+		return
+	}
+	switch sc.ReclaimPolicy {
+	case "":
+		sc.ReclaimPolicy = v1.PersistentVolumeReclaimDelete
+	case v1.PersistentVolumeReclaimRetain, v1.PersistentVolumeReclaimDelete:
+	default:
+		c.JSON(http.StatusBadRequest, v1.BaseResponse[*v1.StorageClass]{
+			Error: fmt.Sprintf("unsupported reclaim policy %q", sc.ReclaimPolicy),
+		})
+		return
+	}
+	old, err := s.getStorageClassFromEtcd(sc.Name)
+	if err != nil || old != nil {
+		c.JSON(http.StatusConflict, v1.BaseResponse[*v1.StorageClass]{
+			Error: fmt.Sprintf("storageclass %s already exists", sc.Name),
+		})
+		return
+	}
+	sc.Namespace = ""
+	sc.UID = v1.UID(uuid.NewUUID())
+	sc.CreationTimestamp = timestamp.NewTimestamp()
+	scJson, err := json.Marshal(&sc)
+	if err == nil {
+		err = s.store_cli.Set(fmt.Sprintf("/registry/storageclasses/%s", sc.Name), string(scJson))
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.StorageClass]{
+			Error: "error in writing to etcd",
+		})
+		return
+	}
+	c.JSON(http.StatusCreated, v1.BaseResponse[*v1.StorageClass]{
+		Data: &sc,
+	})
+}
+
+// 已创建的pv不受影响
+func (s *kubeApiServer) DeleteStorageClassHandler(c *gin.Context) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	name := c.Param("storageclassname")
+	sc, err := s.getStorageClassFromEtcd(name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.StorageClass]{
+			Error: err.Error(),
+		})
+		return
+	}
+	if sc == nil {
+		c.JSON(http.StatusNotFound, v1.BaseResponse[*v1.StorageClass]{
+			Error: fmt.Sprintf("storageclass %s not found", name),
+		})
+		return
+	}
+	err = s.store_cli.Delete(fmt.Sprintf("/registry/storageclasses/%s", name))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.StorageClass]{
+			Error: "error in deleting storageclass from etcd",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, v1.BaseResponse[*v1.StorageClass]{
+		Data: sc,
+	})
+}
+
+// pv不属于任何namespace，以名称为key存放
+func (s *kubeApiServer) getPersistentVolumeFromEtcd(name string) (*v1.PersistentVolume, error) {
+	pvJson, err := s.store_cli.Get(fmt.Sprintf("/registry/persistentvolumes/%s", name))
+	if err != nil || pvJson == "" {
+		return nil, nil
+	}
+	var pv v1.PersistentVolume
+	err = json.Unmarshal([]byte(pvJson), &pv)
+	if err != nil {
+		return nil, fmt.Errorf("error in json unmarshal")
+	}
+	return &pv, nil
+}
+
+func (s *kubeApiServer) savePersistentVolumeToEtcd(pv *v1.PersistentVolume) error {
+	pvJson, err := json.Marshal(pv)
+	if err != nil {
+		return fmt.Errorf("error in json marshal")
+	}
+	err = s.store_cli.Set(fmt.Sprintf("/registry/persistentvolumes/%s", pv.Name), string(pvJson))
+	if err != nil {
+		return fmt.Errorf("error in writing to etcd")
+	}
+	return nil
+}
+
+func (s *kubeApiServer) GetAllPersistentVolumesHandler(c *gin.Context) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	res, err := s.store_cli.GetSubKeysValues("/registry/persistentvolumes/")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[[]*v1.PersistentVolume]{
+			Error: "error in reading persistentvolumes from etcd",
+		})
+		return
+	}
+	volumes := make([]*v1.PersistentVolume, 0)
+	for _, v := range res {
+		var pv v1.PersistentVolume
+		err = json.Unmarshal([]byte(v), &pv)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, v1.BaseResponse[[]*v1.PersistentVolume]{
+				Error: "error in json unmarshal",
+			})
+			return
+		}
+		volumes = append(volumes, &pv)
+	}
+	c.JSON(http.StatusOK, v1.BaseResponse[[]*v1.PersistentVolume]{
+		Data: volumes,
+	})
+}
+
+func (s *kubeApiServer) GetPersistentVolumeHandler(c *gin.Context) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	name := c.Param("pvname")
+	pv, err := s.getPersistentVolumeFromEtcd(name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.PersistentVolume]{
+			Error: err.Error(),
+		})
+		return
+	}
+	if pv == nil {
+		c.JSON(http.StatusNotFound, v1.BaseResponse[*v1.PersistentVolume]{
+			Error: fmt.Sprintf("persistentvolume %s not found", name),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, v1.BaseResponse[*v1.PersistentVolume]{
+		Data: pv,
+	})
+}
+
+// 静态创建的pv直接可用，provisioner创建的pv为Pending，等待节点创建目录
+func (s *kubeApiServer) AddPersistentVolumeHandler(c *gin.Context) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var pv v1.PersistentVolume
+	err := c.ShouldBind(&pv)
+	if err != nil || (pv.Kind != "" && pv.Kind != "PersistentVolume") {
+		c.JSON(http.StatusBadRequest, v1.BaseResponse[*v1.PersistentVolume]{
+			Error: "invalid persistentvolume json",
+		})
+		return
+	}
+	err = v1.ValidatePersistentVolume(&pv)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, v1.BaseResponse[*v1.PersistentVolume]{
+			Error: err.Error(),
+		})
+		return
+	}
+	old, err := s.getPersistentVolumeFromEtcd(pv.Name)
+	if err != nil || old != nil {
+		c.JSON(http.StatusConflict, v1.BaseResponse[*v1.PersistentVolume]{
+			Error: fmt.Sprintf("persistentvolume %s already exists", pv.Name),
+		})
+		return
+	}
+	pv.Namespace = ""
+	pv.UID = v1.UID(uuid.NewUUID())
+	pv.CreationTimestamp = timestamp.NewTimestamp()
+	if pv.Status.Phase != v1.VolumePending {
+		pv.Status.Phase = v1.VolumeAvailable
+	}
+	err = s.savePersistentVolumeToEtcd(&pv)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.PersistentVolume]{
+			Error: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusCreated, v1.BaseResponse[*v1.PersistentVolume]{
+		Data: &pv,
+	})
+}
+
+// 由pv controller与kubelet更新绑定关系与状态，容量、路径与节点不可修改
+func (s *kubeApiServer) UpdatePersistentVolumeHandler(c *gin.Context) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var pv v1.PersistentVolume
+	err := c.ShouldBind(&pv)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, v1.BaseResponse[*v1.PersistentVolume]{
+			Error: "invalid persistentvolume json",
+		})
+		return
+	}
+	name := c.Param("pvname")
+	if pv.Name != name {
+		c.JSON(http.StatusBadRequest, v1.BaseResponse[*v1.PersistentVolume]{
+			Error: fmt.Sprintf("name mismatch, spec: %s, url: %s", pv.Name, name),
+		})
+		return
+	}
+	old, err := s.getPersistentVolumeFromEtcd(name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.PersistentVolume]{
+			Error: err.Error(),
+		})
+		return
+	}
+	if old == nil {
+		c.JSON(http.StatusNotFound, v1.BaseResponse[*v1.PersistentVolume]{
+			Error: fmt.Sprintf("persistentvolume %s not found", name),
+		})
+		return
+	}
+	old.Labels = pv.Labels
+	old.Annotations = pv.Annotations
+	old.Spec.ClaimRef = pv.Spec.ClaimRef
+	old.Spec.PersistentVolumeReclaimPolicy = pv.Spec.PersistentVolumeReclaimPolicy
+	old.Status = pv.Status
+	err = v1.ValidatePersistentVolume(old)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, v1.BaseResponse[*v1.PersistentVolume]{
+			Error: err.Error(),
+		})
+		return
+	}
+	err = s.savePersistentVolumeToEtcd(old)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.PersistentVolume]{
+			Error: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, v1.BaseResponse[*v1.PersistentVolume]{
+		Data: old,
+	})
+}
+
+// 已绑定的pv需先删除pvc
+func (s *kubeApiServer) DeletePersistentVolumeHandler(c *gin.Context) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	name := c.Param("pvname")
+	pv, err := s.getPersistentVolumeFromEtcd(name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.PersistentVolume]{
+			Error: err.Error(),
+		})
+		return
+	}
+	if pv == nil {
+		c.JSON(http.StatusNotFound, v1.BaseResponse[*v1.PersistentVolume]{
+			Error: fmt.Sprintf("persistentvolume %s not found", name),
+		})
+		return
+	}
+	if pv.Status.Phase == v1.VolumeBound && pv.Spec.ClaimRef != nil {
+		c.JSON(http.StatusConflict, v1.BaseResponse[*v1.PersistentVolume]{
+			Error: fmt.Sprintf("persistentvolume %s is bound to claim %s/%s", name, pv.Spec.ClaimRef.Namespace, pv.Spec.ClaimRef.Name),
+		})
+		return
+	}
+	err = s.store_cli.Delete(fmt.Sprintf("/registry/persistentvolumes/%s", name))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.PersistentVolume]{
+			Error: "error in deleting persistentvolume from etcd",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, v1.BaseResponse[*v1.PersistentVolume]{
+		Data: pv,
+	})
+}
+
+func (s *kubeApiServer) getPersistentVolumeClaimFromEtcd(namespace, name string) (*v1.PersistentVolumeClaim, error) {
+	uid, err := s.store_cli.Get(fmt.Sprintf("/registry/namespaces/%s/persistentvolumeclaims/%s", namespace, name))
+	if err != nil || uid == "" {
+		return nil, nil
+	}
+	pvcJson, err := s.store_cli.Get(fmt.Sprintf("/registry/persistentvolumeclaims/%s", uid))
+	if err != nil || pvcJson == "" {
+		return nil, fmt.Errorf("error in reading persistentvolumeclaim from etcd")
+	}
+	var pvc v1.PersistentVolumeClaim
+	err = json.Unmarshal([]byte(pvcJson), &pvc)
+	if err != nil {
+		return nil, fmt.Errorf("error in json unmarshal")
+	}
+	return &pvc, nil
+}
+
+func (s *kubeApiServer) savePersistentVolumeClaimToEtcd(pvc *v1.PersistentVolumeClaim) error {
+	pvcJson, err := json.Marshal(pvc)
+	if err != nil {
+		return fmt.Errorf("error in json marshal")
+	}
+	err = s.store_cli.Set(fmt.Sprintf("/registry/namespaces/%s/persistentvolumeclaims/%s", pvc.Namespace, pvc.Name), string(pvc.UID))
+	if err != nil {
+		return fmt.Errorf("error in writing to etcd")
+	}
+	err = s.store_cli.Set(fmt.Sprintf("/registry/persistentvolumeclaims/%s", pvc.UID), string(pvcJson))
+	if err != nil {
+		return fmt.Errorf("error in writing to etcd")
+	}
+	return nil
+}
+
+func (s *kubeApiServer) GetAllPersistentVolumeClaimsHandler(c *gin.Context) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	res, err := s.store_cli.GetSubKeysValues("/registry/persistentvolumeclaims")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[[]*v1.PersistentVolumeClaim]{
+			Error: "error in reading persistentvolumeclaims from etcd",
+		})
+		return
+	}
+	claims := make([]*v1.PersistentVolumeClaim, 0)
+	for _, v := range res {
+		var pvc v1.PersistentVolumeClaim
+		err = json.Unmarshal([]byte(v), &pvc)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, v1.BaseResponse[[]*v1.PersistentVolumeClaim]{
+				Error: "error in json unmarshal",
+			})
+			return
+		}
+		claims = append(claims, &pvc)
+	}
+	c.JSON(http.StatusOK, v1.BaseResponse[[]*v1.PersistentVolumeClaim]{
+		Data: claims,
+	})
+}
+
+func (s *kubeApiServer) GetPersistentVolumeClaimHandler(c *gin.Context) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	namespace := c.Param("namespace")
+	name := c.Param("pvcname")
+	pvc, err := s.getPersistentVolumeClaimFromEtcd(namespace, name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.PersistentVolumeClaim]{
+			Error: err.Error(),
+		})
+		return
+	}
+	if pvc == nil {
+		c.JSON(http.StatusNotFound, v1.BaseResponse[*v1.PersistentVolumeClaim]{
+			Error: fmt.Sprintf("persistentvolumeclaim %s/%s not found", namespace, name),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, v1.BaseResponse[*v1.PersistentVolumeClaim]{
+		Data: pvc,
+	})
+}
+
+func (s *kubeApiServer) AddPersistentVolumeClaimHandler(c *gin.Context) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var pvc v1.PersistentVolumeClaim
+	err := c.ShouldBind(&pvc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, v1.BaseResponse[*v1.PersistentVolumeClaim]{
+			Error: "invalid persistentvolumeclaim json",
+		})
+		return
+	}
+	namespace := c.Param("namespace")
+	err = validateConfigObject(&pvc.ObjectMeta, pvc.Kind, "PersistentVolumeClaim", namespace)
+	if err == nil {
+		err = v1.ValidatePersistentVolumeClaim(&pvc)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, v1.BaseResponse[*v1.PersistentVolumeClaim]{
+			Error: err.Error(),
+		})
+		return
+	}
+	pvc.Namespace = namespace
+
+	old, err := s.getPersistentVolumeClaimFromEtcd(pvc.Namespace, pvc.Name)
+	if err != nil || old != nil {
+		c.JSON(http.StatusConflict, v1.BaseResponse[*v1.PersistentVolumeClaim]{
+			Error: fmt.Sprintf("persistentvolumeclaim %s/%s already exists", pvc.Namespace, pvc.Name),
+		})
+		return
+	}
+	pvc.UID = v1.UID(uuid.NewUUID())
+	pvc.CreationTimestamp = timestamp.NewTimestamp()
+	pvc.Status = v1.PersistentVolumeClaimStatus{Phase: v1.ClaimPending}
+	err = s.savePersistentVolumeClaimToEtcd(&pvc)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.PersistentVolumeClaim]{
+			Error: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusCreated, v1.BaseResponse[*v1.PersistentVolumeClaim]{
+		Data: &pvc,
+	})
+}
+
+// 由pv controller更新绑定的pv与状态，申请的容量与访问模式不可修改
+func (s *kubeApiServer) UpdatePersistentVolumeClaimHandler(c *gin.Context) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var pvc v1.PersistentVolumeClaim
+	err := c.ShouldBind(&pvc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, v1.BaseResponse[*v1.PersistentVolumeClaim]{
+			Error: "invalid persistentvolumeclaim json",
+		})
+		return
+	}
+	namespace := c.Param("namespace")
+	name := c.Param("pvcname")
+	if pvc.Name != name {
+		c.JSON(http.StatusBadRequest, v1.BaseResponse[*v1.PersistentVolumeClaim]{
+			Error: fmt.Sprintf("name mismatch, spec: %s, url: %s", pvc.Name, name),
+		})
+		return
+	}
+	old, err := s.getPersistentVolumeClaimFromEtcd(namespace, name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.PersistentVolumeClaim]{
+			Error: err.Error(),
+		})
+		return
+	}
+	if old == nil {
+		c.JSON(http.StatusNotFound, v1.BaseResponse[*v1.PersistentVolumeClaim]{
+			Error: fmt.Sprintf("persistentvolumeclaim %s/%s not found", namespace, name),
+		})
+		return
+	}
+	old.Labels = pvc.Labels
+	old.Annotations = pvc.Annotations
+	old.Spec.VolumeName = pvc.Spec.VolumeName
+	old.Status = pvc.Status
+	err = s.savePersistentVolumeClaimToEtcd(old)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.PersistentVolumeClaim]{
+			Error: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, v1.BaseResponse[*v1.PersistentVolumeClaim]{
+		Data: old,
+	})
+}
+
+// 仍被未结束的pod使用的pvc不能删除，删除后pv由pv controller按回收策略处理
+func (s *kubeApiServer) DeletePersistentVolumeClaimHandler(c *gin.Context) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	namespace := c.Param("namespace")
+	name := c.Param("pvcname")
+	pvc, err := s.getPersistentVolumeClaimFromEtcd(namespace, name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.PersistentVolumeClaim]{
+			Error: err.Error(),
+		})
+		return
+	}
+	if pvc == nil {
+		c.JSON(http.StatusNotFound, v1.BaseResponse[*v1.PersistentVolumeClaim]{
+			Error: fmt.Sprintf("persistentvolumeclaim %s/%s not found", namespace, name),
+		})
+		return
+	}
+	pods, err := s.getAllPodsFromEtcd()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.PersistentVolumeClaim]{
+			Error: "error in reading pods from etcd",
+		})
+		return
+	}
+	for _, pod := range pods {
+		if pod.Namespace != namespace || pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.ClaimName == name {
+				c.JSON(http.StatusConflict, v1.BaseResponse[*v1.PersistentVolumeClaim]{
+					Error: fmt.Sprintf("persistentvolumeclaim %s/%s is in use by pod %s", namespace, name, pod.Name),
+				})
+				return
+			}
+		}
+	}
+	err = s.store_cli.Delete(fmt.Sprintf("/registry/namespaces/%s/persistentvolumeclaims/%s", namespace, name))
+	if err == nil {
+		err = s.store_cli.Delete(fmt.Sprintf("/registry/persistentvolumeclaims/%s", pvc.UID))
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, v1.BaseResponse[*v1.PersistentVolumeClaim]{
+			Error: "error in deleting persistentvolumeclaim from etcd",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, v1.BaseResponse[*v1.PersistentVolumeClaim]{
+		Data: pvc,
+	})
+}
+
+// 找到pod所在节点上的kubelet server地址
+func (s *kubeApiServer) getPodKubeletLocation(namespace, podName string) (string, *v1.Pod, int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	podUID, err := s.store_cli.Get(fmt.Sprintf("/registry/namespaces/%s/pods/%s", namespace, podName))
+	if err != nil || podUID == "" {
+		return "", nil, http.StatusNotFound, fmt.Errorf("pod %s/%s not found", namespace, podName)
+	}
+	podJson, err := s.store_cli.Get(fmt.Sprintf("/registry/pods/%s", podUID))
+	if err != nil || podJson == "" {
+		return "", nil, http.StatusNotFound, fmt.Errorf("pod %s/%s not found", namespace, podName)
+	}
+	var pod v1.Pod
+	if err = json.Unmarshal([]byte(podJson), &pod); err != nil {
+		return "", nil, http.StatusInternalServerError, fmt.Errorf("error in json unmarshal")
+	}
+	if pod.Spec.NodeName == "" {
+		return "", nil, http.StatusBadRequest, fmt.Errorf("pod %s/%s is not scheduled", namespace, podName)
+	}
+	nodeUID, err := s.store_cli.Get(fmt.Sprintf("/registry/namespaces/%s/nodes/%s", Default_Namespace, pod.Spec.NodeName))
+	if err != nil || nodeUID == "" {
+		return "", nil, http.StatusNotFound, fmt.Errorf("node %s not found", pod.Spec.NodeName)
+	}
+	nodeJson, err := s.store_cli.Get(fmt.Sprintf("/registry/nodes/%s", nodeUID))
+	if err != nil || nodeJson == "" {
+		return "", nil, http.StatusNotFound, fmt.Errorf("node %s not found", pod.Spec.NodeName)
+	}
+	var node v1.Node
+	if err = json.Unmarshal([]byte(nodeJson), &node); err != nil {
+		return "", nil, http.StatusInternalServerError, fmt.Errorf("error in json unmarshal")
+	}
+	return fmt.Sprintf("%s:%d", node.Status.Address, KubeletServerPort), &pod, http.StatusOK, nil
+}
+
+// 将请求转发至kubelet server，日志流与websocket升级均由ReverseProxy透传
+// withContainer为true时，kubelet路径的最后一段为容器名，pod只有一个容器时可省略container参数
+func (s *kubeApiServer) proxyToKubelet(c *gin.Context, kubeletPathPrefix string, withContainer bool) {
+	namespace := c.Param("namespace")
+	podName := c.Param("podname")
+	host, pod, code, err := s.getPodKubeletLocation(namespace, podName)
+	if err != nil {
+		c.JSON(code, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	query := c.Request.URL.Query()
+	path := fmt.Sprintf("%s/%s/%s", kubeletPathPrefix, namespace, podName)
+	if withContainer {
+		containerName := query.Get("container")
+		if containerName == "" {
+			if len(pod.Spec.Containers) != 1 {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": fmt.Sprintf("a container name must be specified for pod %s", podName),
+				})
+				return
+			}
+			containerName = pod.Spec.Containers[0].Name
+		}
+		query.Del("container")
+		path += "/" + containerName
+	}
+	target := &url.URL{
+		Scheme:   "http",
+		Host:     host,
+		Path:     path,
+		RawQuery: query.Encode(),
+	}
+	proxy := &httputil.ReverseProxy{
+		Director: func(req *http.Request) {
+			req.URL = target
+			req.Host = target.Host
+			req.Header.Set("Authorization", "Bearer "+s.kubeletToken)
+		},
+		// 立即转发follow的日志
+		FlushInterval: -1,
+	}
+	proxy.ServeHTTP(c.Writer, c.Request)
+}
+
+func (s *kubeApiServer) GetPodLogHandler(c *gin.Context) {
+	s.proxyToKubelet(c, "/containerLogs", true)
+}
+
+func (s *kubeApiServer) ExecPodHandler(c *gin.Context) {
+	s.proxyToKubelet(c, "/exec", true)
+}
+
+// 每个websocket连接对应一个转发的tcp连接
+func (s *kubeApiServer) PortForwardPodHandler(c *gin.Context) {
+	s.proxyToKubelet(c, "/portForward", false)
+}
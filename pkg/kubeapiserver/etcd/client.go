@@ -31,6 +31,8 @@ type Store interface {
 	Set(key, value string) error
 	// 删除key
 	Delete(key string) error
+	// 仅当key的当前值等于oldValue时写入newValue，返回是否写入成功
+	CompareAndSwap(key, oldValue, newValue string) (bool, error)
 
 	// TODO: cascade 操作
 
@@ -117,6 +119,19 @@ func (s *store) Delete(key string) error {
 	return nil
 }
 
+// 比较并交换，用于需要原子性的写操作（如pod binding）
+func (s *store) CompareAndSwap(key, oldValue, newValue string) (bool, error) {
+	log.Println("compare and swap key in store", key)
+	res, err := s.cli.Txn(context.TODO()).
+		If(cliv3.Compare(cliv3.Value(key), "=", oldValue)).
+		Then(cliv3.OpPut(key, newValue)).
+		Commit()
+	if err != nil {
+		return false, err
+	}
+	return res.Succeeded, nil
+}
+
 func (s *store) GetSubKeysValues(key string) (map[string]string, error) {
 	log.Println("get subkeys values in store", key)
 	kv := cliv3.NewKV(s.cli)
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// 加密后的值带有该前缀，便于区分明文与密文
+const encryptedPrefix = "enc:aesgcm:v1:"
+
+// SecretTransformer 负责secret写入etcd前的加密与读出后的解密
+type SecretTransformer struct {
+	aead cipher.AEAD
+}
+
+// NewSecretTransformer key为空时不加密，否则必须为base64编码的16、24或32字节AES密钥
+func NewSecretTransformer(base64Key string) (*SecretTransformer, error) {
+	if base64Key == "" {
+		return &SecretTransformer{}, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(base64Key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %v", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %v", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &SecretTransformer{aead: aead}, nil
+}
+
+func (t *SecretTransformer) TransformToStorage(data []byte) (string, error) {
+	if t.aead == nil {
+		return string(data), nil
+	}
+	nonce := make([]byte, t.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	sealed := t.aead.Seal(nonce, nonce, data, nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// 未加密的旧数据仍可直接读出
+func (t *SecretTransformer) TransformFromStorage(value string) ([]byte, error) {
+	if !strings.HasPrefix(value, encryptedPrefix) {
+		return []byte(value), nil
+	}
+	if t.aead == nil {
+		return nil, fmt.Errorf("secret is encrypted but no encryption key is configured")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedPrefix))
+	if err != nil {
+		return nil, err
+	}
+	nonceSize := t.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return nil, fmt.Errorf("encrypted secret is too short")
+	}
+	return t.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestSecretTransformer(t *testing.T) {
+	plain := []byte(`{"data":{"password":"MTIzNDU2"}}`)
+
+	noop, err := NewSecretTransformer("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := noop.TransformToStorage(plain)
+	if err != nil || stored != string(plain) {
+		t.Fatalf("stored = %v, err = %v, want plaintext", stored, err)
+	}
+
+	key := base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+	transformer, err := NewSecretTransformer(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err = transformer.TransformToStorage(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(stored, encryptedPrefix) || strings.Contains(stored, "password") {
+		t.Fatalf("stored value is not encrypted: %v", stored)
+	}
+	decrypted, err := transformer.TransformFromStorage(stored)
+	if err != nil || string(decrypted) != string(plain) {
+		t.Fatalf("decrypted = %s, err = %v", decrypted, err)
+	}
+	// 开启加密前写入的明文仍可读出
+	decrypted, err = transformer.TransformFromStorage(string(plain))
+	if err != nil || string(decrypted) != string(plain) {
+		t.Fatalf("decrypted = %s, err = %v", decrypted, err)
+	}
+	if _, err = noop.TransformFromStorage(stored); err == nil {
+		t.Fatalf("expected error when reading encrypted secret without key")
+	}
+	if _, err = NewSecretTransformer(base64.StdEncoding.EncodeToString([]byte("short"))); err == nil {
+		t.Fatalf("expected error for invalid key size")
+	}
+}
@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"fmt"
+	v1 "minikubernetes/pkg/api/v1"
+	"minikubernetes/pkg/microservice/envoy"
+	"strconv"
+	"strings"
+)
+
+const (
+	proxyContainerName = "envoy-proxy"
+	initContainerName  = "envoy-init"
+
+	defaultSidecarHub = "sjtuzc"
+	// 未指定tag时使用的镜像版本
+	defaultProxyTag = "1.2"
+	defaultInitTag  = "latest"
+)
+
+// SidecarInjector 在pod创建时为开启了注入的pod添加envoy-init与envoy-proxy容器
+type SidecarInjector struct {
+	proxyImage string
+	initImage  string
+}
+
+// NewSidecarInjector hub为空时使用sjtuzc，tag为空时使用各镜像的默认版本
+func NewSidecarInjector(hub, tag string) *SidecarInjector {
+	if hub == "" {
+		hub = defaultSidecarHub
+	}
+	proxyTag, initTag := defaultProxyTag, defaultInitTag
+	if tag != "" {
+		proxyTag, initTag = tag, tag
+	}
+	return &SidecarInjector{
+		proxyImage: fmt.Sprintf("%s/envoy:%s", hub, proxyTag),
+		initImage:  fmt.Sprintf("%s/envoy-init:%s", hub, initTag),
+	}
+}
+
+// NeedsInjection pod注解优先于namespace label，ns为nil表示namespace未创建
+// 静态pod、已注入或手动声明了sidecar的pod不会被注入
+func (si *SidecarInjector) NeedsInjection(pod *v1.Pod, ns *v1.Namespace) bool {
+	// hostNetwork的pod中配置iptables会劫持节点的流量
+	if v1.IsMirrorPod(pod) || v1.IsStaticPod(pod) || pod.Spec.HostNetwork || pod.Annotations[v1.SidecarStatusAnnotationKey] != "" {
+		return false
+	}
+	for _, c := range pod.Spec.Containers {
+		if c.Name == proxyContainerName {
+			return false
+		}
+	}
+	for _, c := range pod.Spec.InitContainers {
+		if c.Name == initContainerName {
+			return false
+		}
+	}
+	if value, ok := pod.Annotations[v1.SidecarInjectAnnotationKey]; ok {
+		inject, err := strconv.ParseBool(value)
+		return err == nil && inject
+	}
+	return ns != nil && ns.Labels[v1.NamespaceInjectionLabelKey] == v1.NamespaceInjectionEnabled
+}
+
+// Inject envoy-init需在其他init container之前配置iptables，排除的端口通过环境变量传给envoy-init
+func (si *SidecarInjector) Inject(pod *v1.Pod) error {
+	inbound, err := portListEnv(pod, v1.ExcludeInboundPortsAnnotationKey)
+	if err != nil {
+		return err
+	}
+	outbound, err := portListEnv(pod, v1.ExcludeOutboundPortsAnnotationKey)
+	if err != nil {
+		return err
+	}
+	privileged := true
+	initContainer := v1.Container{
+		Name:            initContainerName,
+		Image:           si.initImage,
+		SecurityContext: &v1.SecurityContext{Privileged: &privileged},
+	}
+	if inbound != "" {
+		initContainer.Env = append(initContainer.Env, v1.EnvVar{Name: envoy.ExcludeInboundPortsEnv, Value: inbound})
+	}
+	if outbound != "" {
+		initContainer.Env = append(initContainer.Env, v1.EnvVar{Name: envoy.ExcludeOutboundPortsEnv, Value: outbound})
+	}
+	uid, err := strconv.ParseInt(envoy.UID, 10, 64)
+	if err != nil {
+		return err
+	}
+	pod.Spec.InitContainers = append([]v1.Container{initContainer}, pod.Spec.InitContainers...)
+	pod.Spec.Containers = append(pod.Spec.Containers, v1.Container{
+		Name:            proxyContainerName,
+		Image:           si.proxyImage,
+		SecurityContext: &v1.SecurityContext{RunAsUser: &uid},
+	})
+	if pod.Annotations == nil {
+		pod.Annotations = make(map[string]string)
+	}
+	pod.Annotations[v1.SidecarStatusAnnotationKey] = v1.SidecarInjected
+	return nil
+}
+
+// 校验注解中的端口列表，返回规范化后的值
+func portListEnv(pod *v1.Pod, key string) (string, error) {
+	ports, err := v1.ParsePortList(pod.Annotations[key])
+	if err != nil {
+		return "", fmt.Errorf("invalid annotation %s: %v", key, err)
+	}
+	values := make([]string, 0, len(ports))
+	for _, port := range ports {
+		values = append(values, strconv.Itoa(int(port)))
+	}
+	return strings.Join(values, ","), nil
+}
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	v1 "minikubernetes/pkg/api/v1"
+	"testing"
+)
+
+func newSidecarTestPod(annotations map[string]string) *v1.Pod {
+	pod := &v1.Pod{}
+	pod.Name = "app"
+	pod.Annotations = annotations
+	pod.Spec.InitContainers = []v1.Container{{Name: "migrate", Image: "busybox"}}
+	pod.Spec.Containers = []v1.Container{{Name: "app", Image: "nginx"}}
+	return pod
+}
+
+func TestSidecarNeedsInjection(t *testing.T) {
+	si := NewSidecarInjector("", "")
+	enabled := &v1.Namespace{ObjectMeta: v1.ObjectMeta{
+		Name:   "mesh",
+		Labels: map[string]string{v1.NamespaceInjectionLabelKey: v1.NamespaceInjectionEnabled},
+	}}
+	for _, tc := range []struct {
+		name        string
+		annotations map[string]string
+		ns          *v1.Namespace
+		want        bool
+	}{
+		{"no opt-in", nil, nil, false},
+		{"pod annotation", map[string]string{v1.SidecarInjectAnnotationKey: "true"}, nil, true},
+		{"namespace label", nil, enabled, true},
+		{"pod opt-out", map[string]string{v1.SidecarInjectAnnotationKey: "false"}, enabled, false},
+		{"already injected", map[string]string{v1.SidecarStatusAnnotationKey: v1.SidecarInjected}, enabled, false},
+	} {
+		if got := si.NeedsInjection(newSidecarTestPod(tc.annotations), tc.ns); got != tc.want {
+			t.Errorf("%s: NeedsInjection = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestSidecarInject(t *testing.T) {
+	si := NewSidecarInjector("registry.local", "2.0")
+	pod := newSidecarTestPod(map[string]string{
+		v1.ExcludeInboundPortsAnnotationKey:  "22, 8080",
+		v1.ExcludeOutboundPortsAnnotationKey: "3306",
+	})
+	if err := si.Inject(pod); err != nil {
+		t.Fatal(err)
+	}
+	init := pod.Spec.InitContainers[0]
+	if init.Name != "envoy-init" || init.Image != "registry.local/envoy-init:2.0" || !*init.SecurityContext.Privileged {
+		t.Errorf("init container = %+v", init)
+	}
+	if len(init.Env) != 2 || init.Env[0].Value != "22,8080" || init.Env[1].Value != "3306" {
+		t.Errorf("init container env = %+v", init.Env)
+	}
+	proxy := pod.Spec.Containers[len(pod.Spec.Containers)-1]
+	if proxy.Image != "registry.local/envoy:2.0" || *proxy.SecurityContext.RunAsUser != 1337 {
+		t.Errorf("proxy container = %+v", proxy)
+	}
+	if si.NeedsInjection(pod, nil) {
+		t.Error("injected pod should not be injected again")
+	}
+
+	bad := newSidecarTestPod(map[string]string{
+		v1.SidecarInjectAnnotationKey:       "true",
+		v1.ExcludeInboundPortsAnnotationKey: "ssh",
+	})
+	if err := si.Inject(bad); err == nil {
+		t.Error("expected error for invalid port annotation")
+	}
+}
@@ -7,14 +7,29 @@ import (
 	"io"
 	v1 "minikubernetes/pkg/api/v1"
 	"minikubernetes/pkg/kubectl/utils"
+	"minikubernetes/pkg/kubelet/server/portforward"
+	"minikubernetes/pkg/kubelet/server/remotecommand"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
 )
 
 type Client interface {
 	GetAllPods() ([]*v1.Pod, error)
 	GetPod(name, namespace string) (*v1.Pod, error)
 	AddPod(pod v1.Pod) error
+	UpdatePod(pod v1.Pod) error
 	DeletePod(name, namespace string) error
+	// 日志写入out，follow时直到连接断开才返回
+	GetPodLogs(name, namespace string, opts *v1.PodLogOptions, out io.Writer) error
+	// 返回命令的退出码
+	ExecInPod(name, namespace string, opts *v1.PodExecOptions, stdin io.Reader, stdout, stderr io.Writer) (int, error)
+	// 建立到pod端口的一个连接
+	PortForward(name, namespace string, port int32) (*portforward.Stream, error)
 
 	GetAllUnscheduledPods() ([]*v1.Pod, error)
 
@@ -23,6 +38,38 @@ type Client interface {
 	AddDNS(dns v1.DNS) error
 	DeleteDNS(name, namespace string) error
 
+	GetAllNamespaces() ([]*v1.Namespace, error)
+	GetNamespace(name string) (*v1.Namespace, error)
+	AddNamespace(namespace v1.Namespace) error
+	UpdateNamespace(namespace v1.Namespace) error
+	DeleteNamespace(name string) error
+
+	GetAllConfigMaps() ([]*v1.ConfigMap, error)
+	GetConfigMap(name, namespace string) (*v1.ConfigMap, error)
+	AddConfigMap(configMap v1.ConfigMap) error
+	UpdateConfigMap(configMap v1.ConfigMap) error
+	DeleteConfigMap(name, namespace string) error
+
+	GetAllSecrets() ([]*v1.Secret, error)
+	GetSecret(name, namespace string) (*v1.Secret, error)
+	AddSecret(secret v1.Secret) error
+	UpdateSecret(secret v1.Secret) error
+	DeleteSecret(name, namespace string) error
+
+	GetAllStorageClasses() ([]*v1.StorageClass, error)
+	AddStorageClass(storageClass v1.StorageClass) error
+	DeleteStorageClass(name string) error
+
+	GetAllPersistentVolumes() ([]*v1.PersistentVolume, error)
+	AddPersistentVolume(pv v1.PersistentVolume) error
+	UpdatePersistentVolume(pv v1.PersistentVolume) error
+	DeletePersistentVolume(name string) error
+
+	GetAllPersistentVolumeClaims() ([]*v1.PersistentVolumeClaim, error)
+	AddPersistentVolumeClaim(pvc v1.PersistentVolumeClaim) error
+	UpdatePersistentVolumeClaim(pvc v1.PersistentVolumeClaim) error
+	DeletePersistentVolumeClaim(name, namespace string) error
+
 	GetAllNodes() ([]*v1.Node, error)
 	AddPodToNode(pod v1.Pod, node v1.Node) error
 
@@ -150,6 +197,15 @@ func (c *client) AddPod(pod v1.Pod) error {
 	return nil
 }
 
+// 只会更新pod的labels与annotations
+func (c *client) UpdatePod(pod v1.Pod) error {
+	namespace := pod.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+	return sendObject(http.MethodPut, fmt.Sprintf("http://%s:8001/api/v1/namespaces/%s/pods/%s", c.apiServerIP, namespace, pod.Name), &pod, http.StatusOK, "pod")
+}
+
 func (c *client) DeletePod(name, namespace string) error {
 	req, err := http.NewRequest("DELETE", fmt.Sprintf("http://%s:8001/api/v1/namespaces/%s/pods/%s", c.apiServerIP, namespace, name), nil)
 	if err != nil {
@@ -165,6 +221,93 @@ func (c *client) DeletePod(name, namespace string) error {
 	return nil
 }
 
+func (c *client) GetPodLogs(name, namespace string, opts *v1.PodLogOptions, out io.Writer) error {
+	query := url.Values{}
+	if opts.Container != "" {
+		query.Set("container", opts.Container)
+	}
+	if opts.Follow {
+		query.Set("follow", "true")
+	}
+	if opts.Previous {
+		query.Set("previous", "true")
+	}
+	if opts.Timestamps {
+		query.Set("timestamps", "true")
+	}
+	if opts.SinceSeconds != nil {
+		query.Set("sinceSeconds", strconv.FormatInt(*opts.SinceSeconds, 10))
+	}
+	if opts.SinceTime != nil {
+		query.Set("sinceTime", opts.SinceTime.Format(time.RFC3339))
+	}
+	if opts.TailLines != nil {
+		query.Set("tailLines", strconv.FormatInt(*opts.TailLines, 10))
+	}
+	resp, err := http.Get(fmt.Sprintf("http://%s:8001/api/v1/namespaces/%s/pods/%s/log?%s", c.apiServerIP, namespace, name, query.Encode()))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		var baseResponse v1.BaseResponse[interface{}]
+		if err = json.Unmarshal(body, &baseResponse); err != nil {
+			return fmt.Errorf("get pod logs error: %v", resp.Status)
+		}
+		return fmt.Errorf("get pod logs error: %v", baseResponse.Error)
+	}
+	_, err = io.Copy(out, resp.Body)
+	return err
+}
+
+func (c *client) ExecInPod(name, namespace string, opts *v1.PodExecOptions, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	query := url.Values{}
+	if opts.Container != "" {
+		query.Set("container", opts.Container)
+	}
+	for _, arg := range opts.Command {
+		query.Add("command", arg)
+	}
+	if opts.Stdin {
+		query.Set("stdin", "true")
+	} else {
+		stdin = nil
+	}
+	if opts.TTY {
+		query.Set("tty", "true")
+	}
+	origin := fmt.Sprintf("http://%s:8001", c.apiServerIP)
+	wsURL := fmt.Sprintf("ws://%s:8001/api/v1/namespaces/%s/pods/%s/exec?%s", c.apiServerIP, namespace, name, query.Encode())
+	ws, err := websocket.Dial(wsURL, remotecommand.Protocol, origin)
+	if err != nil {
+		return -1, fmt.Errorf("exec error: %v", err)
+	}
+	defer ws.Close()
+	ws.PayloadType = websocket.BinaryFrame
+	status, err := remotecommand.Stream(ws, stdin, stdout, stderr)
+	if err != nil {
+		return -1, err
+	}
+	if status.Error != "" {
+		return status.ExitCode, fmt.Errorf("exec error: %v", status.Error)
+	}
+	return status.ExitCode, nil
+}
+
+func (c *client) PortForward(name, namespace string, port int32) (*portforward.Stream, error) {
+	origin := fmt.Sprintf("http://%s:8001", c.apiServerIP)
+	wsURL := fmt.Sprintf("ws://%s:8001/api/v1/namespaces/%s/pods/%s/portforward?port=%d", c.apiServerIP, namespace, name, port)
+	ws, err := websocket.Dial(wsURL, portforward.Protocol, origin)
+	if err != nil {
+		return nil, fmt.Errorf("port forward error: %v", err)
+	}
+	return portforward.NewStream(ws), nil
+}
+
 func (c *client) GetAllUnscheduledPods() ([]*v1.Pod, error) {
 	resp, err := http.Get(fmt.Sprintf("http://%s:8001/api/v1/pods/unscheduled", c.apiServerIP))
 	if err != nil {
@@ -286,6 +429,448 @@ func (c *client) DeleteDNS(name, namespace string) error {
 	return nil
 }
 
+func (c *client) GetAllNamespaces() ([]*v1.Namespace, error) {
+	resp, err := http.Get(fmt.Sprintf("http://%s:8001/api/v1/namespaces", c.apiServerIP))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var baseResponse v1.BaseResponse[[]*v1.Namespace]
+	err = json.Unmarshal(body, &baseResponse)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get namespaces failed, error: %s", baseResponse.Error)
+	}
+	return baseResponse.Data, nil
+}
+
+func (c *client) GetNamespace(name string) (*v1.Namespace, error) {
+	resp, err := http.Get(fmt.Sprintf("http://%s:8001/api/v1/namespaces/%s", c.apiServerIP, name))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var baseResponse v1.BaseResponse[*v1.Namespace]
+	err = json.Unmarshal(body, &baseResponse)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get namespace error: %v", baseResponse.Error)
+	}
+	return baseResponse.Data, nil
+}
+
+func (c *client) AddNamespace(namespace v1.Namespace) error {
+	return c.sendNamespace(http.MethodPost, fmt.Sprintf("http://%s:8001/api/v1/namespaces", c.apiServerIP), &namespace, http.StatusCreated)
+}
+
+func (c *client) UpdateNamespace(namespace v1.Namespace) error {
+	return c.sendNamespace(http.MethodPut, fmt.Sprintf("http://%s:8001/api/v1/namespaces/%s", c.apiServerIP, namespace.Name), &namespace, http.StatusOK)
+}
+
+func (c *client) sendNamespace(method, url string, namespace *v1.Namespace, expectedStatus int) error {
+	namespaceJson, _ := json.Marshal(namespace)
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(namespaceJson))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var baseResponse v1.BaseResponse[*v1.Namespace]
+	err = json.Unmarshal(body, &baseResponse)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != expectedStatus {
+		return fmt.Errorf("%s namespace error: %v", strings.ToLower(method), baseResponse.Error)
+	}
+	return nil
+}
+
+func (c *client) DeleteNamespace(name string) error {
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("http://%s:8001/api/v1/namespaces/%s", c.apiServerIP, name), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var baseResponse v1.BaseResponse[*v1.Namespace]
+	err = json.Unmarshal(body, &baseResponse)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("delete namespace error: %v", baseResponse.Error)
+	}
+	return nil
+}
+
+func (c *client) GetAllConfigMaps() ([]*v1.ConfigMap, error) {
+	resp, err := http.Get(fmt.Sprintf("http://%s:8001/api/v1/configmaps", c.apiServerIP))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var baseResponse v1.BaseResponse[[]*v1.ConfigMap]
+	err = json.Unmarshal(body, &baseResponse)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get configmaps failed, error: %s", baseResponse.Error)
+	}
+	return baseResponse.Data, nil
+}
+
+func (c *client) GetConfigMap(name, namespace string) (*v1.ConfigMap, error) {
+	resp, err := http.Get(fmt.Sprintf("http://%s:8001/api/v1/namespaces/%s/configmaps/%s", c.apiServerIP, namespace, name))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var baseResponse v1.BaseResponse[*v1.ConfigMap]
+	err = json.Unmarshal(body, &baseResponse)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get configmap error: %v", baseResponse.Error)
+	}
+	return baseResponse.Data, nil
+}
+
+func (c *client) AddConfigMap(configMap v1.ConfigMap) error {
+	if configMap.Namespace == "" {
+		configMap.Namespace = "default"
+	}
+	return c.sendConfigMap(http.MethodPost, fmt.Sprintf("http://%s:8001/api/v1/namespaces/%s/configmaps", c.apiServerIP, configMap.Namespace), &configMap, http.StatusCreated)
+}
+
+func (c *client) UpdateConfigMap(configMap v1.ConfigMap) error {
+	if configMap.Namespace == "" {
+		configMap.Namespace = "default"
+	}
+	return c.sendConfigMap(http.MethodPut, fmt.Sprintf("http://%s:8001/api/v1/namespaces/%s/configmaps/%s", c.apiServerIP, configMap.Namespace, configMap.Name), &configMap, http.StatusOK)
+}
+
+func (c *client) sendConfigMap(method, url string, configMap *v1.ConfigMap, expectedStatus int) error {
+	configMapJson, _ := json.Marshal(configMap)
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(configMapJson))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var baseResponse v1.BaseResponse[*v1.ConfigMap]
+	err = json.Unmarshal(body, &baseResponse)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != expectedStatus {
+		return fmt.Errorf("%s configmap error: %v", strings.ToLower(method), baseResponse.Error)
+	}
+	return nil
+}
+
+func (c *client) DeleteConfigMap(name, namespace string) error {
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("http://%s:8001/api/v1/namespaces/%s/configmaps/%s", c.apiServerIP, namespace, name), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var baseResponse v1.BaseResponse[*v1.ConfigMap]
+	err = json.Unmarshal(body, &baseResponse)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("delete configmap error: %v", baseResponse.Error)
+	}
+	return nil
+}
+
+func (c *client) GetAllSecrets() ([]*v1.Secret, error) {
+	resp, err := http.Get(fmt.Sprintf("http://%s:8001/api/v1/secrets", c.apiServerIP))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var baseResponse v1.BaseResponse[[]*v1.Secret]
+	err = json.Unmarshal(body, &baseResponse)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get secrets failed, error: %s", baseResponse.Error)
+	}
+	return baseResponse.Data, nil
+}
+
+func (c *client) GetSecret(name, namespace string) (*v1.Secret, error) {
+	resp, err := http.Get(fmt.Sprintf("http://%s:8001/api/v1/namespaces/%s/secrets/%s", c.apiServerIP, namespace, name))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var baseResponse v1.BaseResponse[*v1.Secret]
+	err = json.Unmarshal(body, &baseResponse)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get secret error: %v", baseResponse.Error)
+	}
+	return baseResponse.Data, nil
+}
+
+func (c *client) AddSecret(secret v1.Secret) error {
+	if secret.Namespace == "" {
+		secret.Namespace = "default"
+	}
+	return c.sendSecret(http.MethodPost, fmt.Sprintf("http://%s:8001/api/v1/namespaces/%s/secrets", c.apiServerIP, secret.Namespace), &secret, http.StatusCreated)
+}
+
+func (c *client) UpdateSecret(secret v1.Secret) error {
+	if secret.Namespace == "" {
+		secret.Namespace = "default"
+	}
+	return c.sendSecret(http.MethodPut, fmt.Sprintf("http://%s:8001/api/v1/namespaces/%s/secrets/%s", c.apiServerIP, secret.Namespace, secret.Name), &secret, http.StatusOK)
+}
+
+func (c *client) sendSecret(method, url string, secret *v1.Secret, expectedStatus int) error {
+	secretJson, _ := json.Marshal(secret)
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(secretJson))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var baseResponse v1.BaseResponse[*v1.Secret]
+	err = json.Unmarshal(body, &baseResponse)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != expectedStatus {
+		return fmt.Errorf("%s secret error: %v", strings.ToLower(method), baseResponse.Error)
+	}
+	return nil
+}
+
+func (c *client) DeleteSecret(name, namespace string) error {
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("http://%s:8001/api/v1/namespaces/%s/secrets/%s", c.apiServerIP, namespace, name), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var baseResponse v1.BaseResponse[*v1.Secret]
+	err = json.Unmarshal(body, &baseResponse)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("delete secret error: %v", baseResponse.Error)
+	}
+	return nil
+}
+
+func (c *client) GetAllStorageClasses() ([]*v1.StorageClass, error) {
+	return getObjectList[v1.StorageClass](fmt.Sprintf("http://%s:8001/api/v1/storageclasses", c.apiServerIP), "storageclasses")
+}
+
+func (c *client) AddStorageClass(storageClass v1.StorageClass) error {
+	return sendObject(http.MethodPost, fmt.Sprintf("http://%s:8001/api/v1/storageclasses", c.apiServerIP), &storageClass, http.StatusCreated, "storageclass")
+}
+
+func (c *client) DeleteStorageClass(name string) error {
+	return deleteObject[v1.StorageClass](fmt.Sprintf("http://%s:8001/api/v1/storageclasses/%s", c.apiServerIP, name), "storageclass")
+}
+
+func (c *client) GetAllPersistentVolumes() ([]*v1.PersistentVolume, error) {
+	return getObjectList[v1.PersistentVolume](fmt.Sprintf("http://%s:8001/api/v1/persistentvolumes", c.apiServerIP), "persistentvolumes")
+}
+
+func (c *client) AddPersistentVolume(pv v1.PersistentVolume) error {
+	return sendObject(http.MethodPost, fmt.Sprintf("http://%s:8001/api/v1/persistentvolumes", c.apiServerIP), &pv, http.StatusCreated, "persistentvolume")
+}
+
+func (c *client) UpdatePersistentVolume(pv v1.PersistentVolume) error {
+	return sendObject(http.MethodPut, fmt.Sprintf("http://%s:8001/api/v1/persistentvolumes/%s", c.apiServerIP, pv.Name), &pv, http.StatusOK, "persistentvolume")
+}
+
+func (c *client) DeletePersistentVolume(name string) error {
+	return deleteObject[v1.PersistentVolume](fmt.Sprintf("http://%s:8001/api/v1/persistentvolumes/%s", c.apiServerIP, name), "persistentvolume")
+}
+
+func (c *client) GetAllPersistentVolumeClaims() ([]*v1.PersistentVolumeClaim, error) {
+	return getObjectList[v1.PersistentVolumeClaim](fmt.Sprintf("http://%s:8001/api/v1/persistentvolumeclaims", c.apiServerIP), "persistentvolumeclaims")
+}
+
+func (c *client) AddPersistentVolumeClaim(pvc v1.PersistentVolumeClaim) error {
+	if pvc.Namespace == "" {
+		pvc.Namespace = "default"
+	}
+	return sendObject(http.MethodPost, fmt.Sprintf("http://%s:8001/api/v1/namespaces/%s/persistentvolumeclaims", c.apiServerIP, pvc.Namespace), &pvc, http.StatusCreated, "persistentvolumeclaim")
+}
+
+func (c *client) UpdatePersistentVolumeClaim(pvc v1.PersistentVolumeClaim) error {
+	if pvc.Namespace == "" {
+		pvc.Namespace = "default"
+	}
+	return sendObject(http.MethodPut, fmt.Sprintf("http://%s:8001/api/v1/namespaces/%s/persistentvolumeclaims/%s", c.apiServerIP, pvc.Namespace, pvc.Name), &pvc, http.StatusOK, "persistentvolumeclaim")
+}
+
+func (c *client) DeletePersistentVolumeClaim(name, namespace string) error {
+	return deleteObject[v1.PersistentVolumeClaim](fmt.Sprintf("http://%s:8001/api/v1/namespaces/%s/persistentvolumeclaims/%s", c.apiServerIP, namespace, name), "persistentvolumeclaim")
+}
+
+// 以下为各类对象通用的请求，kind仅用于错误信息
+func getObjectList[T any](url, kind string) ([]*T, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var baseResponse v1.BaseResponse[[]*T]
+	err = json.Unmarshal(body, &baseResponse)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get %s failed, error: %s", kind, baseResponse.Error)
+	}
+	return baseResponse.Data, nil
+}
+
+func sendObject[T any](method, url string, object *T, expectedStatus int, kind string) error {
+	objectJson, _ := json.Marshal(object)
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(objectJson))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var baseResponse v1.BaseResponse[*T]
+	err = json.Unmarshal(body, &baseResponse)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != expectedStatus {
+		return fmt.Errorf("%s %s error: %v", strings.ToLower(method), kind, baseResponse.Error)
+	}
+	return nil
+}
+
+func deleteObject[T any](url, kind string) error {
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var baseResponse v1.BaseResponse[*T]
+	err = json.Unmarshal(body, &baseResponse)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("delete %s error: %v", kind, baseResponse.Error)
+	}
+	return nil
+}
+
 func (c *client) GetAllNodes() ([]*v1.Node, error) {
 	resp, err := http.Get(fmt.Sprintf("http://%s:8001/api/v1/nodes", c.apiServerIP))
 	if err != nil {
@@ -308,16 +893,46 @@ func (c *client) GetAllNodes() ([]*v1.Node, error) {
 }
 
 func (c *client) AddPodToNode(pod v1.Pod, node v1.Node) error {
-	req, err := http.NewRequest("POST", fmt.Sprintf("http://%s:8001/api/v1/schedule?podUid=%s&nodename=%s", c.apiServerIP, pod.UID, node.Name), nil)
+	binding := v1.Binding{
+		TypeMeta: v1.TypeMeta{
+			Kind:       "Binding",
+			APIVersion: "v1",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+		},
+		Target: v1.CrossVersionObjectReference{
+			Kind: "Node",
+			Name: node.Name,
+		},
+	}
+	bindingJson, err := json.Marshal(binding)
 	if err != nil {
 		return err
 	}
+	url := fmt.Sprintf("http://%s:8001/api/v1/namespaces/%s/pods/%s/binding", c.apiServerIP, pod.Namespace, pod.Name)
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(bindingJson))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return err
 	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var baseResponse v1.BaseResponse[interface{}]
+	err = json.Unmarshal(body, &baseResponse)
+	if err != nil {
+		return err
+	}
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("add pod to node error: %v", resp.Status)
+		return fmt.Errorf("add pod to node error: %v", baseResponse.Error)
 	}
 	return nil
 }
@@ -135,6 +135,66 @@ func apply(filename string) {
 		}
 		applyRolingUpdate(&rollingUpdateGenerated)
 		fmt.Println("Rolling Update Applied")
+	case "Namespace":
+		fmt.Println("Apply Namespace")
+		var namespaceGenerated v1.Namespace
+		err := json.Unmarshal(jsonBytes, &namespaceGenerated)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		applyNamespace(namespaceGenerated)
+		fmt.Println("Namespace Applied")
+	case "ConfigMap":
+		fmt.Println("Apply ConfigMap")
+		var configMapGenerated v1.ConfigMap
+		err := json.Unmarshal(jsonBytes, &configMapGenerated)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		applyConfigMap(configMapGenerated)
+		fmt.Println("ConfigMap Applied")
+	case "Secret":
+		fmt.Println("Apply Secret")
+		var secretGenerated v1.Secret
+		err := json.Unmarshal(jsonBytes, &secretGenerated)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		applySecret(secretGenerated)
+		fmt.Println("Secret Applied")
+	case "StorageClass":
+		fmt.Println("Apply StorageClass")
+		var storageClassGenerated v1.StorageClass
+		err := json.Unmarshal(jsonBytes, &storageClassGenerated)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		applyStorageClass(storageClassGenerated)
+		fmt.Println("StorageClass Applied")
+	case "PersistentVolume":
+		fmt.Println("Apply PersistentVolume")
+		var pvGenerated v1.PersistentVolume
+		err := json.Unmarshal(jsonBytes, &pvGenerated)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		applyPersistentVolume(pvGenerated)
+		fmt.Println("PersistentVolume Applied")
+	case "PersistentVolumeClaim":
+		fmt.Println("Apply PersistentVolumeClaim")
+		var pvcGenerated v1.PersistentVolumeClaim
+		err := json.Unmarshal(jsonBytes, &pvcGenerated)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		applyPersistentVolumeClaim(pvcGenerated)
+		fmt.Println("PersistentVolumeClaim Applied")
 
 	}
 
@@ -193,6 +253,79 @@ func applyDNS(dns v1.DNS) {
 	}
 }
 
+// configmap与secret已存在时更新
+func applyNamespace(namespace v1.Namespace) {
+	cli := kubeclient.NewClient(apiServerIP)
+	var err error
+	if _, getErr := cli.GetNamespace(namespace.Name); getErr == nil {
+		err = cli.UpdateNamespace(namespace)
+	} else {
+		err = cli.AddNamespace(namespace)
+	}
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
+func applyConfigMap(configMap v1.ConfigMap) {
+	if configMap.Namespace == "" {
+		configMap.Namespace = "default"
+	}
+	cli := kubeclient.NewClient(apiServerIP)
+	var err error
+	if _, getErr := cli.GetConfigMap(configMap.Name, configMap.Namespace); getErr == nil {
+		err = cli.UpdateConfigMap(configMap)
+	} else {
+		err = cli.AddConfigMap(configMap)
+	}
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
+func applySecret(secret v1.Secret) {
+	if secret.Namespace == "" {
+		secret.Namespace = "default"
+	}
+	cli := kubeclient.NewClient(apiServerIP)
+	var err error
+	if _, getErr := cli.GetSecret(secret.Name, secret.Namespace); getErr == nil {
+		err = cli.UpdateSecret(secret)
+	} else {
+		err = cli.AddSecret(secret)
+	}
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
+func applyStorageClass(storageClass v1.StorageClass) {
+	err := kubeclient.NewClient(apiServerIP).AddStorageClass(storageClass)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
+func applyPersistentVolume(pv v1.PersistentVolume) {
+	err := kubeclient.NewClient(apiServerIP).AddPersistentVolume(pv)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
+func applyPersistentVolumeClaim(pvc v1.PersistentVolumeClaim) {
+	err := kubeclient.NewClient(apiServerIP).AddPersistentVolumeClaim(pvc)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
 // TODO 增加rolling update的部署
 func applyRolingUpdate(rol *v1.RollingUpdate) {
 	err := kubeclient.NewClient(apiServerIP).AddRollingUpdate(rol)
@@ -52,6 +52,18 @@ var deleteCommand = &cobra.Command{
 				deleteDNS(args[1], "default")
 			case "rollingupdate":
 				deleteRollingUpdate(args[1], "default")
+			case "namespace":
+				deleteNamespace(args[1])
+			case "configmap":
+				deleteConfigMap(args[1], "default")
+			case "secret":
+				deleteSecret(args[1], "default")
+			case "storageclass":
+				deleteStorageClass(args[1])
+			case "pv", "persistentvolume":
+				deletePersistentVolume(args[1])
+			case "pvc", "persistentvolumeclaim":
+				deletePersistentVolumeClaim(args[1], "default")
 
 			}
 		} else if len(args) == 1 {
@@ -82,6 +94,12 @@ var deleteCommand = &cobra.Command{
 				deleteDNS(name, namespace)
 			case "rollingupdate":
 				deleteRollingUpdate(name, namespace)
+			case "configmap":
+				deleteConfigMap(name, namespace)
+			case "secret":
+				deleteSecret(name, namespace)
+			case "pvc", "persistentvolumeclaim":
+				deletePersistentVolumeClaim(name, namespace)
 			}
 
 		} else {
@@ -236,6 +254,75 @@ func deleteFromYAML(filename string) {
 		}
 		deleteDNS(dnsGenerated.Name, dnsGenerated.Namespace)
 		fmt.Println("DNS Deleted")
+	case "Namespace":
+		fmt.Println("Delete Namespace")
+		var namespaceGenerated v1.Namespace
+		err := json.Unmarshal(jsonBytes, &namespaceGenerated)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		deleteNamespace(namespaceGenerated.Name)
+		fmt.Println("Namespace Deleted")
+	case "ConfigMap":
+		fmt.Println("Delete ConfigMap")
+		var configMapGenerated v1.ConfigMap
+		err := json.Unmarshal(jsonBytes, &configMapGenerated)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if configMapGenerated.Namespace == "" {
+			configMapGenerated.Namespace = "default"
+		}
+		deleteConfigMap(configMapGenerated.Name, configMapGenerated.Namespace)
+		fmt.Println("ConfigMap Deleted")
+	case "Secret":
+		fmt.Println("Delete Secret")
+		var secretGenerated v1.Secret
+		err := json.Unmarshal(jsonBytes, &secretGenerated)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if secretGenerated.Namespace == "" {
+			secretGenerated.Namespace = "default"
+		}
+		deleteSecret(secretGenerated.Name, secretGenerated.Namespace)
+		fmt.Println("Secret Deleted")
+	case "StorageClass":
+		fmt.Println("Delete StorageClass")
+		var storageClassGenerated v1.StorageClass
+		err := json.Unmarshal(jsonBytes, &storageClassGenerated)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		deleteStorageClass(storageClassGenerated.Name)
+		fmt.Println("StorageClass Deleted")
+	case "PersistentVolume":
+		fmt.Println("Delete PersistentVolume")
+		var pvGenerated v1.PersistentVolume
+		err := json.Unmarshal(jsonBytes, &pvGenerated)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		deletePersistentVolume(pvGenerated.Name)
+		fmt.Println("PersistentVolume Deleted")
+	case "PersistentVolumeClaim":
+		fmt.Println("Delete PersistentVolumeClaim")
+		var pvcGenerated v1.PersistentVolumeClaim
+		err := json.Unmarshal(jsonBytes, &pvcGenerated)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if pvcGenerated.Namespace == "" {
+			pvcGenerated.Namespace = "default"
+		}
+		deletePersistentVolumeClaim(pvcGenerated.Name, pvcGenerated.Namespace)
+		fmt.Println("PersistentVolumeClaim Deleted")
 	case "RollingUpdate":
 		fmt.Println("Delete RollingUpdate")
 		var rollingUpdateGenerated v1.RollingUpdate
@@ -312,6 +399,54 @@ func deleteDNS(dnsName, nameSpace string) {
 	}
 }
 
+func deleteNamespace(name string) {
+	err := kubeclient.NewClient(apiServerIP).DeleteNamespace(name)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
+func deleteConfigMap(name, nameSpace string) {
+	err := kubeclient.NewClient(apiServerIP).DeleteConfigMap(name, nameSpace)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
+func deleteSecret(name, nameSpace string) {
+	err := kubeclient.NewClient(apiServerIP).DeleteSecret(name, nameSpace)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
+func deleteStorageClass(name string) {
+	err := kubeclient.NewClient(apiServerIP).DeleteStorageClass(name)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
+func deletePersistentVolume(name string) {
+	err := kubeclient.NewClient(apiServerIP).DeletePersistentVolume(name)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
+func deletePersistentVolumeClaim(name, nameSpace string) {
+	err := kubeclient.NewClient(apiServerIP).DeletePersistentVolumeClaim(name, nameSpace)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+}
+
 // TODO: 增加RolliingUpdate的删除
 func deleteRollingUpdate(rollingUpdateName, nameSpace string) {
 	err := kubeclient.NewClient(apiServerIP).DeleteRollingUpdate(rollingUpdateName, nameSpace)
@@ -1,163 +1,280 @@
-package cmd
-
-// 导出原始的json文件
-
-import (
-	"fmt"
-	"minikubernetes/pkg/kubeclient"
-	"os"
-
-	"github.com/olekukonko/tablewriter"
-	"github.com/spf13/cobra"
-)
-
-func init() {
-
-	rootCmd.AddCommand(describeCommand)
-}
-
-var describeCommand = &cobra.Command{
-	Use:   "describe",
-	Short: "Describe resources",
-	Args:  cobra.MinimumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		if len(args) == 2 {
-			if args[0] == "pod" {
-				describePod(args[1], "default")
-			}
-			if args[0] == "service" {
-				describeService(args[1], "default")
-			}
-			if args[0] == "hpa" {
-				describeHPA(args[1], "default")
-			}
-			if args[0] == "replicaset" {
-				describeReplicaSet(args[1], "default")
-			}
-			if args[0] == "virtualservice" {
-				describeVirtualService(args[1], "default")
-			}
-			if args[0] == "subsets" {
-				describeSubset(args[1], "default")
-			}
-			if args[0] == "dns" {
-				describeDNS(args[1], "default")
-			}
-
-		} else if len(args) == 1 {
-			// 指定namespace和name
-			namespace, _ := cmd.Flags().GetString("namespace")
-			name, _ := cmd.Flags().GetString("name")
-			if namespace == "" {
-				namespace = "default"
-			}
-			if name == "" {
-				fmt.Println("Usage: kubectl describe [pod|service|hpa|replicaset] -np [namespace] -n [name]")
-				return
-			}
-			switch args[0] {
-			case "pod":
-				describePod(name, namespace)
-			case "service":
-				describeService(name, namespace)
-			case "hpa":
-				describeHPA(name, namespace)
-			case "replicaset":
-				describeReplicaSet(name, namespace)
-			case "virtualservice":
-				describeVirtualService(name, namespace)
-			case "subsets":
-				describeSubset(name, namespace)
-			case "dns":
-				describeDNS(name, namespace)
-			}
-
-		}
-	},
-}
-
-func describePod(name, namespace string) {
-	pod, err := kubeclient.NewClient(apiServerIP).GetPod(name, namespace)
-	if err != nil {
-		fmt.Println(err)
-		return
-	}
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Name", "Namespace", "Phase", "IP"})
-	table.Append([]string{pod.Name, pod.Namespace, string(pod.Status.Phase), pod.Status.PodIP})
-	table.Render()
-}
-
-func describeService(name, namespace string) {
-	service, err := kubeclient.NewClient(apiServerIP).GetService(name, namespace)
-	if err != nil {
-		fmt.Println(err)
-		return
-	}
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Name", "Namespace", "ClusterIP", "Ports"})
-	table.Append([]string{service.Name, service.Namespace, service.Spec.ClusterIP, fmt.Sprintf("%v", service.Spec.Ports)})
-	table.Render()
-}
-
-func describeHPA(name, namespace string) {
-	hpa, err := kubeclient.NewClient(apiServerIP).GetHPAScaler(name, namespace)
-	if err != nil {
-		fmt.Println(err)
-		return
-	}
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Name", "Namespace", "MinReplicas", "MaxReplicas", "Metrics"})
-	table.Append([]string{hpa.Name, hpa.Namespace, fmt.Sprintf("%v", hpa.Spec.MinReplicas), fmt.Sprintf("%v", hpa.Spec.MaxReplicas), fmt.Sprintf("%v", hpa.Spec.Metrics)})
-	table.Render()
-}
-
-func describeReplicaSet(name, namespace string) {
-	replicaSet, err := kubeclient.NewClient(apiServerIP).GetReplicaSet(name, namespace)
-	if err != nil {
-		fmt.Println(err)
-		return
-	}
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Name", "Namespace", "Replicas", "Selector"})
-	table.Append([]string{replicaSet.Name, replicaSet.Namespace, fmt.Sprintf("%v", replicaSet.Spec.Replicas), fmt.Sprintf("%v", replicaSet.Spec.Selector)})
-	table.Render()
-}
-
-func describeVirtualService(name, namespace string) {
-	virtualService, err := kubeclient.NewClient(apiServerIP).GetVirtualService(name, namespace)
-	if err != nil {
-		fmt.Println(err)
-		return
-	}
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Name", "Namespace", "ServiceRef", "Port", "Subsets"})
-	table.Append([]string{virtualService.Name, virtualService.Namespace, virtualService.Spec.ServiceRef, fmt.Sprintf("%v", virtualService.Spec.Port), fmt.Sprintf("%v", virtualService.Spec.Subsets)})
-	table.Render()
-}
-
-func describeSubset(name, namespace string) {
-	subset, err := kubeclient.NewClient(apiServerIP).GetSubsetByName(name, namespace)
-	if err != nil {
-		fmt.Println(err)
-		return
-	}
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Name", "Namespace", "Labels", "Pods"})
-	table.Append([]string{subset.Name, subset.Namespace, fmt.Sprintf("%v", subset.Labels), fmt.Sprintf("%v", subset.Spec.Pods)})
-	table.Render()
-}
-
-func describeDNS(name, namespace string) {
-	dns, err := kubeclient.NewClient(apiServerIP).GetDNS(name, namespace)
-	if err != nil {
-		fmt.Println(err)
-		return
-	}
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Name", "Namespace", "Rules"})
-	table.Append([]string{dns.Name, dns.Namespace, fmt.Sprintf("%v", dns.Spec.Rules)})
-	table.Render()
-}
-
-// TODO 增加rolling update
+package cmd
+
+// 导出原始的json文件
+
+import (
+	"fmt"
+	v1 "minikubernetes/pkg/api/v1"
+	"minikubernetes/pkg/kubeclient"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+
+	rootCmd.AddCommand(describeCommand)
+}
+
+var describeCommand = &cobra.Command{
+	Use:   "describe",
+	Short: "Describe resources",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 2 {
+			if args[0] == "pod" {
+				describePod(args[1], "default")
+			}
+			if args[0] == "service" {
+				describeService(args[1], "default")
+			}
+			if args[0] == "hpa" {
+				describeHPA(args[1], "default")
+			}
+			if args[0] == "replicaset" {
+				describeReplicaSet(args[1], "default")
+			}
+			if args[0] == "virtualservice" {
+				describeVirtualService(args[1], "default")
+			}
+			if args[0] == "subsets" {
+				describeSubset(args[1], "default")
+			}
+			if args[0] == "dns" {
+				describeDNS(args[1], "default")
+			}
+			if args[0] == "configmap" {
+				describeConfigMap(args[1], "default")
+			}
+			if args[0] == "secret" {
+				describeSecret(args[1], "default")
+			}
+
+		} else if len(args) == 1 {
+			// 指定namespace和name
+			namespace, _ := cmd.Flags().GetString("namespace")
+			name, _ := cmd.Flags().GetString("name")
+			if namespace == "" {
+				namespace = "default"
+			}
+			if name == "" {
+				fmt.Println("Usage: kubectl describe [pod|service|hpa|replicaset] -np [namespace] -n [name]")
+				return
+			}
+			switch args[0] {
+			case "pod":
+				describePod(name, namespace)
+			case "service":
+				describeService(name, namespace)
+			case "hpa":
+				describeHPA(name, namespace)
+			case "replicaset":
+				describeReplicaSet(name, namespace)
+			case "virtualservice":
+				describeVirtualService(name, namespace)
+			case "subsets":
+				describeSubset(name, namespace)
+			case "dns":
+				describeDNS(name, namespace)
+			case "configmap":
+				describeConfigMap(name, namespace)
+			case "secret":
+				describeSecret(name, namespace)
+			}
+
+		}
+	},
+}
+
+func describePod(name, namespace string) {
+	pod, err := kubeclient.NewClient(apiServerIP).GetPod(name, namespace)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Name", "Namespace", "Node", "Phase", "IP", "HostIP", "StartTime", "QoS", "Reason"})
+	table.Append([]string{pod.Name, pod.Namespace, pod.Spec.NodeName, podPhase(pod), pod.Status.PodIP,
+		pod.Status.HostIP, formatTime(pod.Status.StartTime), string(pod.Status.QOSClass), pod.Status.Reason})
+	table.Render()
+
+	if len(pod.Status.Conditions) > 0 {
+		fmt.Println("Conditions:")
+		conditionTable := tablewriter.NewWriter(os.Stdout)
+		conditionTable.SetHeader([]string{"Type", "Status", "LastTransitionTime", "Reason", "Message"})
+		for _, condition := range pod.Status.Conditions {
+			conditionTable.Append([]string{string(condition.Type), string(condition.Status),
+				formatTime(condition.LastTransitionTime), condition.Reason, condition.Message})
+		}
+		conditionTable.Render()
+	}
+	if len(pod.Status.InitContainerStatuses) > 0 {
+		fmt.Println("Init Containers:")
+		renderContainerStatuses(pod.Status.InitContainerStatuses)
+	}
+	if len(pod.Status.ContainerStatuses) > 0 {
+		fmt.Println("Containers:")
+		renderContainerStatuses(pod.Status.ContainerStatuses)
+	}
+}
+
+func renderContainerStatuses(statuses []v1.ContainerStatus) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Name", "Image", "ContainerID", "State", "LastState", "Ready", "Restarts"})
+	for _, status := range statuses {
+		containerID := status.ContainerID
+		if len(containerID) > 12 {
+			containerID = containerID[:12]
+		}
+		table.Append([]string{status.Name, status.Image, containerID, formatContainerState(status.State),
+			formatContainerState(status.LastTerminationState), fmt.Sprintf("%v", status.Ready),
+			fmt.Sprintf("%v", status.RestartCount)})
+	}
+	table.Render()
+}
+
+func formatContainerState(state v1.ContainerState) string {
+	switch {
+	case state.Running != nil:
+		return fmt.Sprintf("Running (started %v)", formatTime(state.Running.StartedAt))
+	case state.Terminated != nil:
+		t := state.Terminated
+		ret := fmt.Sprintf("Terminated: %v (exit code %v, finished %v)", t.Reason, t.ExitCode, formatTime(t.FinishedAt))
+		if t.Message != "" {
+			ret += ": " + t.Message
+		}
+		return ret
+	case state.Waiting != nil:
+		ret := fmt.Sprintf("Waiting: %v", state.Waiting.Reason)
+		if state.Waiting.Message != "" {
+			ret += ": " + state.Waiting.Message
+		}
+		return ret
+	default:
+		return ""
+	}
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func describeService(name, namespace string) {
+	service, err := kubeclient.NewClient(apiServerIP).GetService(name, namespace)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Name", "Namespace", "ClusterIP", "Ports"})
+	table.Append([]string{service.Name, service.Namespace, service.Spec.ClusterIP, fmt.Sprintf("%v", service.Spec.Ports)})
+	table.Render()
+}
+
+func describeHPA(name, namespace string) {
+	hpa, err := kubeclient.NewClient(apiServerIP).GetHPAScaler(name, namespace)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Name", "Namespace", "MinReplicas", "MaxReplicas", "Metrics"})
+	table.Append([]string{hpa.Name, hpa.Namespace, fmt.Sprintf("%v", hpa.Spec.MinReplicas), fmt.Sprintf("%v", hpa.Spec.MaxReplicas), fmt.Sprintf("%v", hpa.Spec.Metrics)})
+	table.Render()
+}
+
+func describeReplicaSet(name, namespace string) {
+	replicaSet, err := kubeclient.NewClient(apiServerIP).GetReplicaSet(name, namespace)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Name", "Namespace", "Replicas", "Selector"})
+	table.Append([]string{replicaSet.Name, replicaSet.Namespace, fmt.Sprintf("%v", replicaSet.Spec.Replicas), fmt.Sprintf("%v", replicaSet.Spec.Selector)})
+	table.Render()
+}
+
+func describeVirtualService(name, namespace string) {
+	virtualService, err := kubeclient.NewClient(apiServerIP).GetVirtualService(name, namespace)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Name", "Namespace", "ServiceRef", "Port", "Subsets"})
+	table.Append([]string{virtualService.Name, virtualService.Namespace, virtualService.Spec.ServiceRef, fmt.Sprintf("%v", virtualService.Spec.Port), fmt.Sprintf("%v", virtualService.Spec.Subsets)})
+	table.Render()
+}
+
+func describeSubset(name, namespace string) {
+	subset, err := kubeclient.NewClient(apiServerIP).GetSubsetByName(name, namespace)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Name", "Namespace", "Labels", "Pods"})
+	table.Append([]string{subset.Name, subset.Namespace, fmt.Sprintf("%v", subset.Labels), fmt.Sprintf("%v", subset.Spec.Pods)})
+	table.Render()
+}
+
+func describeDNS(name, namespace string) {
+	dns, err := kubeclient.NewClient(apiServerIP).GetDNS(name, namespace)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Name", "Namespace", "Rules"})
+	table.Append([]string{dns.Name, dns.Namespace, fmt.Sprintf("%v", dns.Spec.Rules)})
+	table.Render()
+}
+
+func describeConfigMap(name, namespace string) {
+	cm, err := kubeclient.NewClient(apiServerIP).GetConfigMap(name, namespace)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Key", "Value"})
+	for _, key := range sortedKeys(cm.Data) {
+		table.Append([]string{key, cm.Data[key]})
+	}
+	fmt.Printf("Name: %v\nNamespace: %v\n", cm.Name, cm.Namespace)
+	table.Render()
+}
+
+// secret只展示每个key的字节数
+func describeSecret(name, namespace string) {
+	secret, err := kubeclient.NewClient(apiServerIP).GetSecret(name, namespace)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Key", "Size"})
+	for _, key := range sortedKeys(secret.Data) {
+		table.Append([]string{key, fmt.Sprintf("%v bytes", len(secret.Data[key]))})
+	}
+	fmt.Printf("Name: %v\nNamespace: %v\nType: %v\n", secret.Name, secret.Namespace, secret.Type)
+	table.Render()
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// TODO 增加rolling update
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	v1 "minikubernetes/pkg/api/v1"
+	"minikubernetes/pkg/kubeclient"
+	"minikubernetes/pkg/kubectl/utils"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	execCommand.Flags().StringP("namespace", "p", "default", "Namespace of the pod")
+	execCommand.Flags().StringP("container", "c", "", "Container name, can be omitted if the pod has only one container")
+	execCommand.Flags().BoolP("stdin", "i", false, "Pass stdin to the container")
+	execCommand.Flags().BoolP("tty", "t", false, "Stdin is a TTY")
+	rootCmd.AddCommand(execCommand)
+}
+
+var execCommand = &cobra.Command{
+	Use:   "exec <pod> -- <command> [args...]",
+	Short: "Execute a command in a container",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if cmd.ArgsLenAtDash() != 1 {
+			fmt.Println("Usage: kubectl exec <pod> [-c container] [-i] [-t] -- <command> [args...]")
+			return
+		}
+		namespace, _ := cmd.Flags().GetString("namespace")
+		opts := &v1.PodExecOptions{Command: args[1:]}
+		opts.Container, _ = cmd.Flags().GetString("container")
+		opts.Stdin, _ = cmd.Flags().GetBool("stdin")
+		opts.TTY, _ = cmd.Flags().GetBool("tty")
+
+		// 交互式终端需要将本地终端置为raw模式，由容器内的tty负责回显与行编辑
+		restore := func() {}
+		if opts.Stdin && opts.TTY {
+			if r, err := utils.MakeRawTerminal(int(os.Stdin.Fd())); err == nil {
+				restore = r
+			}
+		}
+		exitCode, err := kubeclient.NewClient(apiServerIP).ExecInPod(args[0], namespace, opts, os.Stdin, os.Stdout, os.Stderr)
+		restore()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		if exitCode != 0 {
+			os.Exit(exitCode)
+		}
+	},
+}
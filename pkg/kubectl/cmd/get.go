@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	v1 "minikubernetes/pkg/api/v1"
 	"minikubernetes/pkg/kubeclient"
 	"os"
 	"strings"
@@ -47,6 +48,24 @@ var getCommand = &cobra.Command{
 			if args[0] == "rollingupdates" || args[0] == "rollingupdate" {
 				getAllRollingUpdate()
 			}
+			if args[0] == "namespaces" || args[0] == "namespace" || args[0] == "ns" {
+				getAllNamespaces()
+			}
+			if args[0] == "configmaps" || args[0] == "configmap" {
+				getAllConfigMaps()
+			}
+			if args[0] == "secrets" || args[0] == "secret" {
+				getAllSecrets()
+			}
+			if args[0] == "storageclasses" || args[0] == "storageclass" || args[0] == "sc" {
+				getAllStorageClasses()
+			}
+			if args[0] == "persistentvolumes" || args[0] == "persistentvolume" || args[0] == "pv" {
+				getAllPersistentVolumes()
+			}
+			if args[0] == "persistentvolumeclaims" || args[0] == "persistentvolumeclaim" || args[0] == "pvc" {
+				getAllPersistentVolumeClaims()
+			}
 
 		}
 	},
@@ -61,11 +80,19 @@ func getAllPods() {
 	table := tablewriter.NewWriter(os.Stdout)
 	table.SetHeader([]string{"Kind", "Namespace", "Name", "Phase", "IP"})
 	for _, pod := range pods {
-		table.Append([]string{"pod", pod.Namespace, pod.Name, string(pod.Status.Phase), pod.Status.PodIP})
+		table.Append([]string{"pod", pod.Namespace, pod.Name, podPhase(pod), pod.Status.PodIP})
 	}
 	table.Render()
 }
 
+// 正在终止的pod显示为Terminating
+func podPhase(pod *v1.Pod) string {
+	if v1.IsPodTerminating(pod) {
+		return "Terminating"
+	}
+	return string(pod.Status.Phase)
+}
+
 func getAllNodes() {
 	nodes, err := kubeclient.NewClient(apiServerIP).GetAllNodes()
 	if err != nil {
@@ -237,6 +264,116 @@ func getAllDNS() {
 	table.Render()
 }
 
+func getAllNamespaces() {
+	namespaces, err := kubeclient.NewClient(apiServerIP).GetAllNamespaces()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Kind", "Name", "Sidecar Injection"})
+	for _, ns := range namespaces {
+		injection := ns.Labels[v1.NamespaceInjectionLabelKey]
+		if injection == "" {
+			injection = "disabled"
+		}
+		table.Append([]string{"namespace", ns.Name, injection})
+	}
+	table.Render()
+}
+
+func getAllConfigMaps() {
+	configMaps, err := kubeclient.NewClient(apiServerIP).GetAllConfigMaps()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Kind", "Namespace", "Name", "Data"})
+	for _, cm := range configMaps {
+		table.Append([]string{"configmap", cm.Namespace, cm.Name, fmt.Sprintf("%v", len(cm.Data))})
+	}
+	table.Render()
+}
+
+func getAllSecrets() {
+	secrets, err := kubeclient.NewClient(apiServerIP).GetAllSecrets()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Kind", "Namespace", "Name", "Type", "Data"})
+	for _, secret := range secrets {
+		table.Append([]string{"secret", secret.Namespace, secret.Name, string(secret.Type), fmt.Sprintf("%v", len(secret.Data))})
+	}
+	table.Render()
+}
+
+func getAllStorageClasses() {
+	classes, err := kubeclient.NewClient(apiServerIP).GetAllStorageClasses()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Kind", "Name", "Provisioner", "ReclaimPolicy"})
+	for _, sc := range classes {
+		table.Append([]string{"storageclass", sc.Name, sc.Provisioner, string(sc.ReclaimPolicy)})
+	}
+	table.Render()
+}
+
+func getAllPersistentVolumes() {
+	volumes, err := kubeclient.NewClient(apiServerIP).GetAllPersistentVolumes()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Kind", "Name", "Capacity", "AccessModes", "ReclaimPolicy", "Status", "Claim", "StorageClass", "Node"})
+	for _, pv := range volumes {
+		claim := ""
+		if pv.Spec.ClaimRef != nil {
+			claim = pv.Spec.ClaimRef.Namespace + "/" + pv.Spec.ClaimRef.Name
+		}
+		table.Append([]string{"persistentvolume", pv.Name, pv.Spec.Capacity[v1.ResourceStorage], accessModesString(pv.Spec.AccessModes),
+			string(v1.GetReclaimPolicy(pv)), string(pv.Status.Phase), claim, pv.Spec.StorageClassName, pv.Spec.NodeName})
+	}
+	table.Render()
+}
+
+func getAllPersistentVolumeClaims() {
+	claims, err := kubeclient.NewClient(apiServerIP).GetAllPersistentVolumeClaims()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Kind", "Namespace", "Name", "Status", "Volume", "Capacity", "AccessModes", "StorageClass"})
+	for _, pvc := range claims {
+		table.Append([]string{"persistentvolumeclaim", pvc.Namespace, pvc.Name, string(pvc.Status.Phase), pvc.Spec.VolumeName,
+			pvc.Status.Capacity[v1.ResourceStorage], accessModesString(pvc.Spec.AccessModes), pvc.Spec.StorageClassName})
+	}
+	table.Render()
+}
+
+// 与kubectl一致，使用RWO、ROX缩写
+func accessModesString(modes []v1.PersistentVolumeAccessMode) string {
+	var abbrs []string
+	for _, mode := range modes {
+		switch mode {
+		case v1.ReadWriteOnce:
+			abbrs = append(abbrs, "RWO")
+		case v1.ReadOnlyMany:
+			abbrs = append(abbrs, "ROX")
+		default:
+			abbrs = append(abbrs, string(mode))
+		}
+	}
+	return strings.Join(abbrs, ",")
+}
+
 // TODO 展示rolling update
 func getAllRollingUpdate() {
 	rollingUpdates, err := kubeclient.NewClient(apiServerIP).GetAllRollingUpdates()
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"minikubernetes/pkg/kubeclient"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	for _, c := range []*cobra.Command{labelCommand, annotateCommand} {
+		c.Flags().StringP("namespace", "p", "default", "Namespace of the pod")
+		c.Flags().Bool("overwrite", false, "Allow existing keys to be overwritten")
+		rootCmd.AddCommand(c)
+	}
+}
+
+var labelCommand = &cobra.Command{
+	Use:   "label pod <name> key=value... key-...",
+	Short: "Update the labels of a pod",
+	Args:  cobra.MinimumNArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		updatePodMetadata(cmd, args, "labels", "labeled")
+	},
+}
+
+var annotateCommand = &cobra.Command{
+	Use:   "annotate pod <name> key=value... key-...",
+	Short: "Update the annotations of a pod",
+	Args:  cobra.MinimumNArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		updatePodMetadata(cmd, args, "annotations", "annotated")
+	},
+}
+
+// key=value设置，key-删除
+func updatePodMetadata(cmd *cobra.Command, args []string, field, verb string) {
+	if args[0] != "pod" {
+		fmt.Printf("resource %v is not supported, only pod can be %v\n", args[0], verb)
+		return
+	}
+	namespace, _ := cmd.Flags().GetString("namespace")
+	overwrite, _ := cmd.Flags().GetBool("overwrite")
+	cli := kubeclient.NewClient(apiServerIP)
+	pod, err := cli.GetPod(args[1], namespace)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	m := pod.Labels
+	if field == "annotations" {
+		m = pod.Annotations
+	}
+	if m == nil {
+		m = make(map[string]string)
+	}
+	for _, arg := range args[2:] {
+		if key, ok := strings.CutSuffix(arg, "-"); ok && !strings.Contains(arg, "=") {
+			delete(m, key)
+			continue
+		}
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || key == "" {
+			fmt.Printf("invalid argument %q, expected key=value or key-\n", arg)
+			return
+		}
+		if old, exists := m[key]; exists && old != value && !overwrite {
+			fmt.Printf("%v %v already has a value (%v), use --overwrite to update\n", field, key, old)
+			return
+		}
+		m[key] = value
+	}
+	if field == "annotations" {
+		pod.Annotations = m
+	} else {
+		pod.Labels = m
+	}
+	if err = cli.UpdatePod(*pod); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("pod/%v %v\n", pod.Name, verb)
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	v1 "minikubernetes/pkg/api/v1"
+	"minikubernetes/pkg/kubeclient"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	logsCommand.Flags().StringP("namespace", "p", "default", "Namespace of the pod")
+	logsCommand.Flags().StringP("container", "c", "", "Container name, can be omitted if the pod has only one container")
+	logsCommand.Flags().BoolP("follow", "f", false, "Stream new logs")
+	logsCommand.Flags().Bool("previous", false, "Print the logs of the previous terminated container")
+	logsCommand.Flags().Bool("timestamps", false, "Include timestamps on each line")
+	logsCommand.Flags().Int64("tail", -1, "Lines of recent log to display, -1 shows all lines")
+	logsCommand.Flags().Duration("since", 0, "Only return logs newer than a relative duration like 5s or 2m")
+	logsCommand.Flags().String("since-time", "", "Only return logs after a specific date (RFC3339)")
+	rootCmd.AddCommand(logsCommand)
+}
+
+var logsCommand = &cobra.Command{
+	Use:   "logs <pod>",
+	Short: "Print the logs of a container in a pod",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		namespace, _ := cmd.Flags().GetString("namespace")
+		opts := &v1.PodLogOptions{}
+		opts.Container, _ = cmd.Flags().GetString("container")
+		opts.Follow, _ = cmd.Flags().GetBool("follow")
+		opts.Previous, _ = cmd.Flags().GetBool("previous")
+		opts.Timestamps, _ = cmd.Flags().GetBool("timestamps")
+		if tail, _ := cmd.Flags().GetInt64("tail"); tail >= 0 {
+			opts.TailLines = &tail
+		}
+		if since, _ := cmd.Flags().GetDuration("since"); since > 0 {
+			seconds := int64(since.Seconds())
+			if seconds == 0 {
+				seconds = 1
+			}
+			opts.SinceSeconds = &seconds
+		}
+		if sinceTime, _ := cmd.Flags().GetString("since-time"); sinceTime != "" {
+			if opts.SinceSeconds != nil {
+				fmt.Println("at most one of --since or --since-time may be specified")
+				return
+			}
+			t, err := time.Parse(time.RFC3339, sinceTime)
+			if err != nil {
+				fmt.Printf("invalid --since-time: %v\n", err)
+				return
+			}
+			opts.SinceTime = &t
+		}
+		err := kubeclient.NewClient(apiServerIP).GetPodLogs(args[0], namespace, opts, os.Stdout)
+		if err != nil {
+			fmt.Println(err)
+		}
+	},
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"minikubernetes/pkg/kubeclient"
+	"minikubernetes/pkg/kubelet/server/portforward"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	portForwardCommand.Flags().StringP("namespace", "p", "default", "Namespace of the pod")
+	portForwardCommand.Flags().String("address", "127.0.0.1", "Local address to listen on")
+	rootCmd.AddCommand(portForwardCommand)
+}
+
+var portForwardCommand = &cobra.Command{
+	Use:   "port-forward pod/<name> [LOCAL_PORT:]REMOTE_PORT [...[LOCAL_PORT_N:]REMOTE_PORT_N]",
+	Short: "Forward one or more local ports to a pod",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		namespace, _ := cmd.Flags().GetString("namespace")
+		address, _ := cmd.Flags().GetString("address")
+		podName := strings.TrimPrefix(args[0], "pod/")
+		forwards := make([]portMapping, 0, len(args)-1)
+		for _, arg := range args[1:] {
+			mapping, err := parsePortMapping(arg)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			forwards = append(forwards, mapping)
+		}
+		cli := kubeclient.NewClient(apiServerIP)
+		if _, err := cli.GetPod(podName, namespace); err != nil {
+			fmt.Println(err)
+			return
+		}
+		for _, mapping := range forwards {
+			listener, err := net.Listen("tcp", net.JoinHostPort(address, strconv.Itoa(int(mapping.local))))
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			defer listener.Close()
+			fmt.Printf("Forwarding from %v -> %v\n", listener.Addr(), mapping.remote)
+			go servePortForward(cli, listener, podName, namespace, mapping.remote)
+		}
+		signalCh := make(chan os.Signal, 1)
+		signal.Notify(signalCh, os.Interrupt)
+		<-signalCh
+	},
+}
+
+type portMapping struct {
+	// 为0时由系统分配本地端口
+	local  int32
+	remote int32
+}
+
+// 支持"8080:80"、"80"（本地与远端端口相同）与":80"（随机本地端口）
+func parsePortMapping(s string) (portMapping, error) {
+	localStr, remoteStr, found := strings.Cut(s, ":")
+	if !found {
+		localStr, remoteStr = s, s
+	}
+	remote, err := strconv.ParseInt(remoteStr, 10, 32)
+	if err != nil || remote <= 0 || remote > 65535 {
+		return portMapping{}, fmt.Errorf("invalid remote port in %q", s)
+	}
+	var local int64
+	if localStr != "" {
+		local, err = strconv.ParseInt(localStr, 10, 32)
+		if err != nil || local <= 0 || local > 65535 {
+			return portMapping{}, fmt.Errorf("invalid local port in %q", s)
+		}
+	}
+	return portMapping{local: int32(local), remote: int32(remote)}, nil
+}
+
+// 每个本地连接使用独立的转发连接，互不影响
+func servePortForward(cli kubeclient.Client, listener net.Listener, podName, namespace string, port int32) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			stream, err := cli.PortForward(podName, namespace, port)
+			if err != nil {
+				log.Println(err)
+				conn.Close()
+				return
+			}
+			fmt.Printf("Handling connection for %v\n", port)
+			if err = portforward.Proxy(stream, conn); err != nil {
+				log.Printf("Connection for %v ended: %v\n", port, err)
+			}
+		}()
+	}
+}
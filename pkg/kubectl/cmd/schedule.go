@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	v1 "minikubernetes/pkg/api/v1"
+	"minikubernetes/pkg/kubectl/utils"
+	"minikubernetes/pkg/scheduler"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	scheduleCmd.Flags().StringP("file", "f", "", "YAML file of the pod to schedule")
+	scheduleCmd.Flags().Bool("dry-run", false, "Only print where the pod would be scheduled")
+	scheduleCmd.Flags().String("policy", scheduler.Round_Policy, "Scheduling policy to simulate")
+	rootCmd.AddCommand(scheduleCmd)
+}
+
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Simulate scheduling a pod against the current cluster state",
+	Run: func(cmd *cobra.Command, args []string) {
+		filename, _ := cmd.Flags().GetString("file")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		policy, _ := cmd.Flags().GetString("policy")
+		if filename == "" || !dryRun {
+			fmt.Println("Usage: kubectl schedule --dry-run -f [filename] [--policy policy]")
+			return
+		}
+		simulateSchedule(filename, policy)
+	},
+}
+
+func simulateSchedule(filename, policy string) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if kind := utils.GetKind(content); kind != "Pod" {
+		fmt.Printf("kind %v is not supported, only Pod can be scheduled\n", kind)
+		return
+	}
+	jsonBytes, err := utils.YAML2JSON(content)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	var pod v1.Pod
+	err = json.Unmarshal(jsonBytes, &pod)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if pod.Namespace == "" {
+		pod.Namespace = "default"
+	}
+	result, err := scheduler.Simulate(apiServerIP, policy, &pod)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	// 收集所有score插件名作为表头
+	pluginSet := make(map[string]struct{})
+	for _, candidate := range result.Candidates {
+		for name := range candidate.PluginScores {
+			pluginSet[name] = struct{}{}
+		}
+	}
+	var plugins []string
+	for name := range pluginSet {
+		plugins = append(plugins, name)
+	}
+	sort.Strings(plugins)
+
+	fmt.Printf("Candidate nodes for pod %v/%v (policy %v):\n", pod.Namespace, pod.Name, policy)
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(append([]string{"Rank", "Node", "Total"}, plugins...))
+	for i, candidate := range result.Candidates {
+		row := []string{fmt.Sprint(i + 1), candidate.NodeName, fmt.Sprint(candidate.Total)}
+		for _, name := range plugins {
+			row = append(row, fmt.Sprint(candidate.PluginScores[name]))
+		}
+		table.Append(row)
+	}
+	table.Render()
+
+	if len(result.Filtered) != 0 {
+		fmt.Println("Filtered nodes:")
+		filteredTable := tablewriter.NewWriter(os.Stdout)
+		filteredTable.SetHeader([]string{"Node", "Reasons"})
+		for _, filtered := range result.Filtered {
+			filteredTable.Append([]string{filtered.NodeName, strings.Join(filtered.Reasons, "\n")})
+		}
+		filteredTable.Render()
+	}
+}
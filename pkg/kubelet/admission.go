@@ -0,0 +1,32 @@
+package kubelet
+
+import (
+	"log"
+	v1 "minikubernetes/pkg/api/v1"
+	"minikubernetes/pkg/kubelet/lifecycle"
+)
+
+// 检查pod能否在本节点运行，被拒绝的pod不会被启动，并被标记为Failed
+func (kl *Kubelet) canAdmitPod(pod *v1.Pod) bool {
+	result := kl.admitHandlers.Admit(&lifecycle.PodAdmitAttributes{
+		Pod:       pod,
+		OtherPods: kl.getActivePods(),
+	})
+	if result.Admit {
+		return true
+	}
+	log.Printf("Pod %v is rejected: %v, %v\n", pod.Name, result.Reason, result.Message)
+	kl.rejectPod(pod, result.Reason, result.Message)
+	return false
+}
+
+func (kl *Kubelet) rejectPod(pod *v1.Pod, reason, message string) {
+	status := &v1.PodStatus{
+		Phase:   v1.PodFailed,
+		Reason:  reason,
+		Message: message,
+	}
+	if err := kl.kubeClient.UpdatePodStatus(pod, status); err != nil {
+		log.Printf("Failed to update status of rejected pod %v: %v\n", pod.Name, err)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	v1 "minikubernetes/pkg/api/v1"
 	"minikubernetes/pkg/kubelet"
 	"minikubernetes/pkg/kubelet/client"
+	"minikubernetes/pkg/kubelet/config"
+	"minikubernetes/pkg/kubelet/server"
 	"minikubernetes/pkg/kubelet/types"
 	"minikubernetes/pkg/kubelet/utils"
 	"os"
@@ -21,12 +23,15 @@ type KubeletServer struct {
 	latestLocalPods []*v1.Pod
 	updates         chan types.PodUpdate
 	nodeConfig      *v1.Node
+	// 已作为删除发送给kubelet的正在终止的pod，apiserver确认删除前不再重复发送
+	killingPods map[v1.UID]bool
 }
 
 func NewKubeletServer(apiServerIP string, node *v1.Node) (*KubeletServer, error) {
 	ks := &KubeletServer{}
 	ks.kubeClient = client.NewKubeletClient(apiServerIP)
 	ks.latestLocalPods = make([]*v1.Pod, 0)
+	ks.killingPods = make(map[v1.UID]bool)
 	ks.updates = make(chan types.PodUpdate)
 	ks.nodeConfig = node
 	return ks, nil
@@ -39,17 +44,29 @@ func (kls *KubeletServer) Run() {
 	if err != nil {
 		log.Fatalf("Failed to get host ip: %v", err)
 	}
+	if kls.nodeConfig.Status.Capacity == nil {
+		capacity, err := utils.GetNodeCapacity()
+		if err != nil {
+			log.Printf("Failed to get node capacity: %v", err)
+		} else {
+			kls.nodeConfig.Status.Capacity = capacity
+		}
+	}
+	if kls.nodeConfig.Status.Allocatable == nil {
+		kls.nodeConfig.Status.Allocatable = kls.nodeConfig.Status.Capacity
+	}
 	node, err := kls.kubeClient.RegisterNode(address, kls.nodeConfig)
 	if err != nil {
 		log.Fatalf("Failed to register node: %v", err)
 	}
 	kls.nodeName = node.Name
+	kls.nodeConfig = node
 
 	// context+wait group实现notify和join
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 	var wg sync.WaitGroup
-	wg.Add(2)
+	wg.Add(3)
 
 	// 捕获SIGINT
 	signalCh := make(chan os.Signal, 1)
@@ -58,6 +75,10 @@ func (kls *KubeletServer) Run() {
 	kls.RunKubelet(ctx, &wg)
 
 	go kls.watchApiServer(ctx, &wg)
+	go func() {
+		defer wg.Done()
+		config.NewFileSource(config.DefaultStaticPodPath, kls.nodeName, kls.updates).Run(ctx)
+	}()
 
 	// SIGINT到来，调用cancel()，并等待所有goroutine结束
 	<-signalCh
@@ -75,7 +96,11 @@ func (kls *KubeletServer) RunKubelet(ctx context.Context, wg *sync.WaitGroup) {
 }
 
 func (kls *KubeletServer) createAndInitKubelet() (*kubelet.Kubelet, error) {
-	kl, err := kubelet.NewMainKubelet(kls.nodeName, kls.kubeClient)
+	kl, err := kubelet.NewMainKubelet(kls.nodeName, &kubelet.Dependencies{
+		KubeClient:    kls.kubeClient,
+		StaticPodPath: config.DefaultStaticPodPath,
+		Node:          kls.nodeConfig,
+	})
 	if err != nil {
 		log.Printf("Failed to create kubelet: %v", err)
 		return nil, err
@@ -85,6 +110,19 @@ func (kls *KubeletServer) createAndInitKubelet() (*kubelet.Kubelet, error) {
 
 func (kls *KubeletServer) startKubelet(ctx context.Context, wg *sync.WaitGroup, kl *kubelet.Kubelet) {
 	go kl.Run(ctx, wg, kls.updates)
+	go kls.serveKubelet(kl)
+}
+
+// 提供日志与exec接口，apiserver需使用相同的KUBELET_AUTH_TOKEN访问
+func (kls *KubeletServer) serveKubelet(kl *kubelet.Kubelet) {
+	token := os.Getenv("KUBELET_AUTH_TOKEN")
+	if token == "" {
+		log.Println("KUBELET_AUTH_TOKEN is not set, kubelet server will reject all requests")
+	}
+	err := server.NewServer(kl, token).ListenAndServe()
+	if err != nil {
+		log.Printf("Kubelet server stopped: %v\n", err)
+	}
 }
 
 func (kls *KubeletServer) watchApiServer(ctx context.Context, wg *sync.WaitGroup) {
@@ -106,11 +144,25 @@ func (kls *KubeletServer) watchApiServer(ctx context.Context, wg *sync.WaitGroup
 func (kls *KubeletServer) updateLocalPods() {
 	// Mock
 	// newLocalPods := getMockPods(kls.latestLocalPods)
-	newLocalPods, err := kls.kubeClient.GetPodsByNodeName(kls.nodeName)
+	nodePods, err := kls.kubeClient.GetPodsByNodeName(kls.nodeName)
 	if err != nil {
 		log.Printf("Failed to get pods for node %s: %v", kls.nodeName, err)
 		return
 	}
+	// 镜像pod对应的静态pod来自manifest目录
+	// 正在终止的pod视为已删除，删除时使用其中的宽限期
+	newLocalPods := make([]*v1.Pod, 0, len(nodePods))
+	terminatingPods := make(map[v1.UID]*v1.Pod)
+	for _, pod := range nodePods {
+		if v1.IsMirrorPod(pod) {
+			continue
+		}
+		if v1.IsPodTerminating(pod) {
+			terminatingPods[pod.UID] = pod
+			continue
+		}
+		newLocalPods = append(newLocalPods, pod)
+	}
 
 	// For now, we only allow additions and deletions
 	oldTable := make(map[v1.UID]*v1.Pod)
@@ -125,6 +177,9 @@ func (kls *KubeletServer) updateLocalPods() {
 	deletions := make([]*v1.Pod, 0)
 	for _, pod := range kls.latestLocalPods {
 		if _, ok := newTable[pod.ObjectMeta.UID]; !ok {
+			if terminating, ok := terminatingPods[pod.ObjectMeta.UID]; ok {
+				pod = terminating
+			}
 			deletions = append(deletions, pod)
 		}
 	}
@@ -133,6 +188,12 @@ func (kls *KubeletServer) updateLocalPods() {
 			additions = append(additions, pod)
 		}
 	}
+	// 未在本节点启动过的pod也需由kubelet确认删除，每个pod只发送一次
+	for uid, pod := range terminatingPods {
+		if _, ok := oldTable[uid]; !ok && !kls.killingPods[uid] {
+			deletions = append(deletions, pod)
+		}
+	}
 	if len(additions) != 0 {
 		kls.updates <- types.PodUpdate{
 			Pods: additions,
@@ -146,6 +207,10 @@ func (kls *KubeletServer) updateLocalPods() {
 		}
 	}
 	kls.latestLocalPods = newLocalPods
+	kls.killingPods = make(map[v1.UID]bool, len(terminatingPods))
+	for uid := range terminatingPods {
+		kls.killingPods[uid] = true
+	}
 }
 
 // 以下为fake数据
@@ -0,0 +1,71 @@
+package app
+
+import (
+	v1 "minikubernetes/pkg/api/v1"
+	"minikubernetes/pkg/kubelet/client"
+	"minikubernetes/pkg/kubelet/types"
+	"testing"
+	"time"
+)
+
+type fakeKubeClient struct {
+	client.KubeletClient
+	pods []*v1.Pod
+}
+
+func (c *fakeKubeClient) GetPodsByNodeName(nodeName string) ([]*v1.Pod, error) {
+	return c.pods, nil
+}
+
+func TestUpdateLocalPodsTerminating(t *testing.T) {
+	pod := &v1.Pod{}
+	pod.Name = "pod"
+	pod.Namespace = "default"
+	pod.UID = "uid-pod"
+	kc := &fakeKubeClient{pods: []*v1.Pod{pod}}
+	kls, err := NewKubeletServer("127.0.0.1", &v1.Node{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kls.kubeClient = kc
+	kls.nodeName = "node-0"
+	kls.updates = make(chan types.PodUpdate, 10)
+
+	kls.updateLocalPods()
+	if update := <-kls.updates; update.Op != types.ADD {
+		t.Fatalf("op = %v, want ADD", update.Op)
+	}
+
+	terminating := *pod
+	now := time.Now()
+	terminating.DeletionTimestamp = &now
+	kc.pods = []*v1.Pod{&terminating}
+	// apiserver确认删除前，连续两次轮询都能看到正在终止的pod
+	kls.updateLocalPods()
+	kls.updateLocalPods()
+	deletions := 0
+	for len(kls.updates) > 0 {
+		update := <-kls.updates
+		if update.Op != types.DELETE || len(update.Pods) != 1 || update.Pods[0].UID != pod.UID {
+			t.Fatalf("update = %+v", update)
+		}
+		deletions++
+	}
+	if deletions != 1 {
+		t.Errorf("deletions = %v, want 1", deletions)
+	}
+
+	// 未在本节点启动过的正在终止的pod同样只发送一次
+	other := terminating
+	other.Name = "other"
+	other.UID = "uid-other"
+	kc.pods = []*v1.Pod{&terminating, &other}
+	kls.updateLocalPods()
+	kls.updateLocalPods()
+	if n := len(kls.updates); n != 1 {
+		t.Fatalf("updates = %v, want 1", n)
+	}
+	if update := <-kls.updates; update.Op != types.DELETE || len(update.Pods) != 1 || update.Pods[0].UID != other.UID {
+		t.Errorf("update = %+v, want deletion of %v", update, other.UID)
+	}
+}
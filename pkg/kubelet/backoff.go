@@ -0,0 +1,101 @@
+package kubelet
+
+import (
+	v1 "minikubernetes/pkg/api/v1"
+	"sync"
+	"time"
+)
+
+const (
+	// 容器重启的初始退避时间，每次失败后翻倍
+	initialBackOff = 10 * time.Second
+	// 退避时间上限
+	maxBackOff = 5 * time.Minute
+)
+
+type backOffEntry struct {
+	backOff    time.Duration
+	lastUpdate time.Time
+}
+
+// backOff 按容器记录重启的指数退避
+type backOff struct {
+	lock    sync.Mutex
+	initial time.Duration
+	max     time.Duration
+	entries map[string]*backOffEntry
+	// 便于测试替换
+	now func() time.Time
+}
+
+func newBackOff(initial, max time.Duration) *backOff {
+	return &backOff{
+		initial: initial,
+		max:     max,
+		entries: make(map[string]*backOffEntry),
+		now:     time.Now,
+	}
+}
+
+func backOffKey(podUID v1.UID, containerName string) string {
+	return string(podUID) + "/" + containerName
+}
+
+// Next 记录一次重启，并增大下一次的退避时间
+func (b *backOff) Next(id string, eventTime time.Time) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	entry, ok := b.entries[id]
+	if !ok || b.hasExpired(entry, eventTime) {
+		b.entries[id] = &backOffEntry{backOff: b.initial, lastUpdate: eventTime}
+		return
+	}
+	entry.backOff *= 2
+	if entry.backOff > b.max {
+		entry.backOff = b.max
+	}
+	entry.lastUpdate = eventTime
+}
+
+// Get 返回当前的退避时间，没有记录时为0
+func (b *backOff) Get(id string) time.Duration {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if entry, ok := b.entries[id]; ok {
+		return entry.backOff
+	}
+	return 0
+}
+
+// IsInBackOffSince 自eventTime起是否仍处于退避期
+func (b *backOff) IsInBackOffSince(id string, eventTime time.Time) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	entry, ok := b.entries[id]
+	if !ok || b.hasExpired(entry, b.now()) {
+		return false
+	}
+	return b.now().Sub(eventTime) < entry.backOff
+}
+
+// IsInBackOffSinceUpdate 自上次Next起是否仍处于退避期
+func (b *backOff) IsInBackOffSinceUpdate(id string) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	entry, ok := b.entries[id]
+	if !ok || b.hasExpired(entry, b.now()) {
+		return false
+	}
+	return b.now().Sub(entry.lastUpdate) < entry.backOff
+}
+
+func (b *backOff) Remove(id string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	delete(b.entries, id)
+}
+
+// 容器稳定运行足够久后重置退避
+func (b *backOff) hasExpired(entry *backOffEntry, now time.Time) bool {
+	return now.Sub(entry.lastUpdate) > 2*b.max
+}
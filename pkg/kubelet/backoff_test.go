@@ -0,0 +1,37 @@
+package kubelet
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackOff(t *testing.T) {
+	now := time.Now()
+	b := newBackOff(initialBackOff, maxBackOff)
+	b.now = func() time.Time { return now }
+	id := backOffKey("uid", "c")
+
+	if b.IsInBackOffSince(id, now) {
+		t.Fatalf("first restart should not be delayed")
+	}
+	expected := []time.Duration{10, 20, 40, 80, 160, 300, 300}
+	for i, want := range expected {
+		b.Next(id, now)
+		if got := b.Get(id); got != want*time.Second {
+			t.Fatalf("restart %v: backoff = %v, want %v", i, got, want*time.Second)
+		}
+	}
+	if !b.IsInBackOffSince(id, now) {
+		t.Fatalf("container should be in backoff")
+	}
+	now = now.Add(maxBackOff)
+	if b.IsInBackOffSince(id, now.Add(-maxBackOff)) {
+		t.Fatalf("backoff should have passed")
+	}
+	// 稳定运行超过2*max后重置
+	now = now.Add(2 * maxBackOff)
+	b.Next(id, now)
+	if got := b.Get(id); got != initialBackOff {
+		t.Fatalf("backoff = %v, want reset to %v", got, initialBackOff)
+	}
+}
@@ -12,8 +12,28 @@ import (
 type KubeletClient interface {
 	GetPodsByNodeName(nodeId string) ([]*v1.Pod, error)
 	UpdatePodStatus(pod *v1.Pod, status *v1.PodStatus) error
+	// 上报节点的condition
+	UpdateNodeStatus(nodeName string, status *v1.NodeStatus) error
 	RegisterNode(address string, node *v1.Node) (*v1.Node, error)
 	UnregisterNode(nodeName string) error
+	// 对象不存在时返回nil, nil
+	GetConfigMap(name, namespace string) (*v1.ConfigMap, error)
+	GetSecret(name, namespace string) (*v1.Secret, error)
+	GetPod(name, namespace string) (*v1.Pod, error)
+	// 通过binding子资源将pod绑定到节点，pod已绑定时失败
+	BindPod(pod *v1.Pod, nodeName string) error
+	// 为静态pod在apiserver中创建镜像pod
+	CreateMirrorPod(pod *v1.Pod) error
+	// 以宽限期0立即删除pod，用于删除镜像pod与确认pod已终止，pod不存在时不返回错误
+	DeletePod(name, namespace string) error
+	GetPersistentVolumeClaim(name, namespace string) (*v1.PersistentVolumeClaim, error)
+	GetPersistentVolume(name string) (*v1.PersistentVolume, error)
+	GetAllPersistentVolumes() ([]*v1.PersistentVolume, error)
+	// 更新pv的状态，节点创建目录后置为Available
+	UpdatePersistentVolume(pv *v1.PersistentVolume) error
+	// pv不存在时不返回错误
+	DeletePersistentVolume(name string) error
+	GetStorageClass(name string) (*v1.StorageClass, error)
 }
 
 type kubeletClient struct {
@@ -86,6 +106,28 @@ func (kc *kubeletClient) UpdatePodStatus(pod *v1.Pod, status *v1.PodStatus) erro
 	return nil
 }
 
+func (kc *kubeletClient) UpdateNodeStatus(nodeName string, status *v1.NodeStatus) error {
+	url := fmt.Sprintf("http://%s:8001/api/v1/nodes/%s/status", kc.apiServerIP, nodeName)
+	statusJson, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPut, url, bytes.NewBuffer(statusJson))
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("update node status failed, statusCode: %d", resp.StatusCode)
+	}
+	return nil
+}
+
 func (c *kubeletClient) RegisterNode(address string, node *v1.Node) (*v1.Node, error) {
 	jsonBytes, err := json.Marshal(node)
 	if err != nil {
@@ -148,3 +190,170 @@ func (c *kubeletClient) UnregisterNode(nodeName string) error {
 	}
 	return nil
 }
+
+func (kc *kubeletClient) GetConfigMap(name, namespace string) (*v1.ConfigMap, error) {
+	url := fmt.Sprintf("http://%s:8001/api/v1/namespaces/%s/configmaps/%s", kc.apiServerIP, namespace, name)
+	return getNamespacedObject[v1.ConfigMap](url)
+}
+
+func (kc *kubeletClient) GetSecret(name, namespace string) (*v1.Secret, error) {
+	url := fmt.Sprintf("http://%s:8001/api/v1/namespaces/%s/secrets/%s", kc.apiServerIP, namespace, name)
+	return getNamespacedObject[v1.Secret](url)
+}
+
+func (kc *kubeletClient) GetPod(name, namespace string) (*v1.Pod, error) {
+	url := fmt.Sprintf("http://%s:8001/api/v1/namespaces/%s/pods/%s", kc.apiServerIP, namespace, name)
+	return getNamespacedObject[v1.Pod](url)
+}
+
+func (kc *kubeletClient) BindPod(pod *v1.Pod, nodeName string) error {
+	url := fmt.Sprintf("http://%s:8001/api/v1/namespaces/%s/pods/%s/binding", kc.apiServerIP, pod.Namespace, pod.Name)
+	binding := v1.Binding{
+		TypeMeta: v1.TypeMeta{
+			Kind:       "Binding",
+			APIVersion: "v1",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+		},
+		Target: v1.CrossVersionObjectReference{
+			Kind: "Node",
+			Name: nodeName,
+		},
+	}
+	bindingJson, err := json.Marshal(binding)
+	if err != nil {
+		return err
+	}
+	resp, err := http.Post(url, "application/json", bytes.NewBuffer(bindingJson))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("bind pod failed, statusCode: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (kc *kubeletClient) CreateMirrorPod(pod *v1.Pod) error {
+	url := fmt.Sprintf("http://%s:8001/api/v1/namespaces/%s/pods", kc.apiServerIP, pod.Namespace)
+	podJson, err := json.Marshal(pod)
+	if err != nil {
+		return err
+	}
+	resp, err := http.Post(url, "application/json", bytes.NewBuffer(podJson))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("create mirror pod failed, statusCode: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (kc *kubeletClient) DeletePod(name, namespace string) error {
+	url := fmt.Sprintf("http://%s:8001/api/v1/namespaces/%s/pods/%s?gracePeriodSeconds=0", kc.apiServerIP, namespace, name)
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("delete pod failed, statusCode: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (kc *kubeletClient) GetPersistentVolumeClaim(name, namespace string) (*v1.PersistentVolumeClaim, error) {
+	url := fmt.Sprintf("http://%s:8001/api/v1/namespaces/%s/persistentvolumeclaims/%s", kc.apiServerIP, namespace, name)
+	return getNamespacedObject[v1.PersistentVolumeClaim](url)
+}
+
+func (kc *kubeletClient) GetPersistentVolume(name string) (*v1.PersistentVolume, error) {
+	url := fmt.Sprintf("http://%s:8001/api/v1/persistentvolumes/%s", kc.apiServerIP, name)
+	return getNamespacedObject[v1.PersistentVolume](url)
+}
+
+func (kc *kubeletClient) GetAllPersistentVolumes() ([]*v1.PersistentVolume, error) {
+	url := fmt.Sprintf("http://%s:8001/api/v1/persistentvolumes", kc.apiServerIP)
+	volumes, err := getNamespacedObject[[]*v1.PersistentVolume](url)
+	if err != nil || volumes == nil {
+		return nil, err
+	}
+	return *volumes, nil
+}
+
+func (kc *kubeletClient) UpdatePersistentVolume(pv *v1.PersistentVolume) error {
+	url := fmt.Sprintf("http://%s:8001/api/v1/persistentvolumes/%s", kc.apiServerIP, pv.Name)
+	pvJson, err := json.Marshal(pv)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPut, url, bytes.NewBuffer(pvJson))
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("update persistent volume failed, statusCode: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (kc *kubeletClient) DeletePersistentVolume(name string) error {
+	url := fmt.Sprintf("http://%s:8001/api/v1/persistentvolumes/%s", kc.apiServerIP, name)
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("delete persistent volume failed, statusCode: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (kc *kubeletClient) GetStorageClass(name string) (*v1.StorageClass, error) {
+	url := fmt.Sprintf("http://%s:8001/api/v1/storageclasses/%s", kc.apiServerIP, name)
+	return getNamespacedObject[v1.StorageClass](url)
+}
+
+func getNamespacedObject[T any](url string) (*T, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var baseResponse v1.BaseResponse[*T]
+	err = json.Unmarshal(body, &baseResponse)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get object failed, error: %s", baseResponse.Error)
+	}
+	return baseResponse.Data, nil
+}
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	v1 "minikubernetes/pkg/api/v1"
+	"minikubernetes/pkg/kubectl/utils"
+	"minikubernetes/pkg/kubelet/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// 静态pod的manifest目录
+	DefaultStaticPodPath = "/etc/minik8s/manifests"
+	// 检查manifest目录变化的周期
+	FileCheckPeriod = 5 * time.Second
+)
+
+// 定期读取manifest目录，将静态pod的增删以PodUpdate的形式发送给kubelet
+// manifest内容变化时UID随之变化，表现为删除旧pod并创建新pod
+type FileSource struct {
+	path     string
+	nodeName string
+	updates  chan<- types.PodUpdate
+	pods     map[v1.UID]*v1.Pod
+}
+
+func NewFileSource(path, nodeName string, updates chan<- types.PodUpdate) *FileSource {
+	return &FileSource{
+		path:     path,
+		nodeName: nodeName,
+		updates:  updates,
+		pods:     make(map[v1.UID]*v1.Pod),
+	}
+}
+
+func (s *FileSource) Run(ctx context.Context) {
+	log.Printf("Watching static pod manifests in %s", s.path)
+	ticker := time.NewTicker(FileCheckPeriod)
+	defer ticker.Stop()
+	for {
+		s.sync(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *FileSource) sync(ctx context.Context) {
+	pods, err := ReadStaticPods(s.path, s.nodeName)
+	if err != nil {
+		log.Printf("Failed to read static pods: %v", err)
+		return
+	}
+	current := make(map[v1.UID]*v1.Pod)
+	var additions, deletions []*v1.Pod
+	for _, pod := range pods {
+		current[pod.UID] = pod
+		if _, ok := s.pods[pod.UID]; !ok {
+			additions = append(additions, pod)
+		}
+	}
+	for uid, pod := range s.pods {
+		if _, ok := current[uid]; !ok {
+			deletions = append(deletions, pod)
+		}
+	}
+	// 先删除后创建，使修改后的静态pod不与旧pod同名共存
+	for _, update := range []types.PodUpdate{
+		{Pods: deletions, Op: types.DELETE},
+		{Pods: additions, Op: types.ADD},
+	} {
+		if len(update.Pods) == 0 {
+			continue
+		}
+		select {
+		case s.updates <- update:
+		case <-ctx.Done():
+			return
+		}
+	}
+	s.pods = current
+}
+
+// ReadStaticPods 读取目录下的所有yaml manifest，目录不存在时返回空
+// 单个文件解析失败时跳过该文件
+func ReadStaticPods(path, nodeName string) ([]*v1.Pod, error) {
+	entries, err := os.ReadDir(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var pods []*v1.Pod
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || (!strings.HasSuffix(name, ".yaml") && !strings.HasSuffix(name, ".yml")) {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(path, name))
+		if err != nil {
+			log.Printf("Failed to read static pod manifest %s: %v", name, err)
+			continue
+		}
+		pod, err := decodeStaticPod(name, content, nodeName)
+		if err != nil {
+			log.Printf("Failed to parse static pod manifest %s: %v", name, err)
+			continue
+		}
+		pods = append(pods, pod)
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[i].Name < pods[j].Name
+	})
+	return pods, nil
+}
+
+// 与k8s一致，名称加上节点名后缀以免不同节点的静态pod重名
+func decodeStaticPod(filename string, content []byte, nodeName string) (*v1.Pod, error) {
+	jsonBytes, err := utils.YAML2JSON(content)
+	if err != nil {
+		return nil, err
+	}
+	var pod v1.Pod
+	if err = json.Unmarshal(jsonBytes, &pod); err != nil {
+		return nil, err
+	}
+	if pod.Kind != "" && pod.Kind != "Pod" {
+		return nil, fmt.Errorf("kind %s is not Pod", pod.Kind)
+	}
+	if pod.Name == "" {
+		return nil, fmt.Errorf("pod name is required")
+	}
+	if len(pod.Spec.Containers) == 0 {
+		return nil, fmt.Errorf("pod %s has no containers", pod.Name)
+	}
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\n%s\n", nodeName, filename)
+	hash.Write(content)
+	pod.UID = v1.UID(hex.EncodeToString(hash.Sum(nil))[:32])
+	pod.Name = pod.Name + "-" + nodeName
+	if pod.Namespace == "" {
+		pod.Namespace = "default"
+	}
+	if pod.Annotations == nil {
+		pod.Annotations = make(map[string]string)
+	}
+	pod.Annotations[v1.ConfigSourceAnnotationKey] = v1.ConfigSourceFile
+	pod.Spec.NodeName = nodeName
+	pod.Status = v1.PodStatus{}
+	return &pod, nil
+}
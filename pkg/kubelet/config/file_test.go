@@ -0,0 +1,66 @@
+package config
+
+import (
+	v1 "minikubernetes/pkg/api/v1"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const nginxManifest = `apiVersion: v1
+kind: Pod
+metadata:
+  name: nginx
+spec:
+  containers:
+    - name: nginx
+      image: nginx:latest
+`
+
+func writeManifest(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadStaticPods(t *testing.T) {
+	dir := t.TempDir()
+	writeManifest(t, dir, "nginx.yaml", nginxManifest)
+	writeManifest(t, dir, "README.md", "not a manifest")
+	writeManifest(t, dir, "broken.yaml", "kind: Service\n")
+
+	pods, err := ReadStaticPods(dir, "node-0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pods) != 1 {
+		t.Fatalf("pods = %+v", pods)
+	}
+	pod := pods[0]
+	if pod.Name != "nginx-node-0" || pod.Namespace != "default" || pod.Spec.NodeName != "node-0" || !v1.IsStaticPod(pod) {
+		t.Errorf("pod meta = %+v, nodeName = %q", pod.ObjectMeta, pod.Spec.NodeName)
+	}
+
+	// UID只由节点、文件名与内容决定
+	again, _ := ReadStaticPods(dir, "node-0")
+	if again[0].UID != pod.UID {
+		t.Errorf("uid changed without manifest changes: %v -> %v", pod.UID, again[0].UID)
+	}
+	other, _ := ReadStaticPods(dir, "node-1")
+	if other[0].UID == pod.UID {
+		t.Errorf("static pods on different nodes share uid %v", pod.UID)
+	}
+	writeManifest(t, dir, "nginx.yaml", nginxManifest+"  restartPolicy: Never\n")
+	changed, _ := ReadStaticPods(dir, "node-0")
+	if changed[0].UID == pod.UID {
+		t.Errorf("uid unchanged after manifest changed")
+	}
+}
+
+func TestReadStaticPodsMissingDir(t *testing.T) {
+	pods, err := ReadStaticPods(filepath.Join(t.TempDir(), "missing"), "node-0")
+	if err != nil || len(pods) != 0 {
+		t.Errorf("pods = %v, err = %v", pods, err)
+	}
+}
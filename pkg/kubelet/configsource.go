@@ -0,0 +1,336 @@
+package kubelet
+
+import (
+	"context"
+	"fmt"
+	"log"
+	v1 "minikubernetes/pkg/api/v1"
+	"minikubernetes/pkg/kubelet/runtime"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// configMap、secret和downwardAPI卷的刷新间隔
+const configVolumeRefreshPeriod = 10 * time.Second
+
+// 写文件时使用的临时文件前缀，清理旧文件时跳过
+const tmpFilePrefix = ".tmp-"
+
+var envNameRegexp = regexp.MustCompile(`^[-._a-zA-Z][-._a-zA-Z0-9]*$`)
+
+// 一次同步内缓存已获取的configmap与secret，nil表示对象不存在
+type configSourceGetter struct {
+	kl         *Kubelet
+	namespace  string
+	configMaps map[string]map[string][]byte
+	secrets    map[string]map[string][]byte
+}
+
+func (kl *Kubelet) newConfigSourceGetter(namespace string) *configSourceGetter {
+	return &configSourceGetter{
+		kl:         kl,
+		namespace:  namespace,
+		configMaps: make(map[string]map[string][]byte),
+		secrets:    make(map[string]map[string][]byte),
+	}
+}
+
+func (g *configSourceGetter) configMap(name string) (map[string][]byte, error) {
+	if data, ok := g.configMaps[name]; ok {
+		return data, nil
+	}
+	cm, err := g.kl.kubeClient.GetConfigMap(name, g.namespace)
+	if err != nil {
+		return nil, err
+	}
+	var data map[string][]byte
+	if cm != nil {
+		data = make(map[string][]byte, len(cm.Data))
+		for k, v := range cm.Data {
+			data[k] = []byte(v)
+		}
+	}
+	g.configMaps[name] = data
+	return data, nil
+}
+
+func (g *configSourceGetter) secret(name string) (map[string][]byte, error) {
+	if data, ok := g.secrets[name]; ok {
+		return data, nil
+	}
+	secret, err := g.kl.kubeClient.GetSecret(name, g.namespace)
+	if err != nil {
+		return nil, err
+	}
+	var data map[string][]byte
+	if secret != nil {
+		data = secret.Data
+		if data == nil {
+			data = make(map[string][]byte)
+		}
+	}
+	g.secrets[name] = data
+	return data, nil
+}
+
+func isOptional(optional *bool) bool {
+	return optional != nil && *optional
+}
+
+func hasConfigVolumes(pod *v1.Pod) bool {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.ConfigMap != nil || volume.Secret != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// 将pod的configMap和secret卷写入kubelet的卷目录
+func (kl *Kubelet) syncConfigVolumes(pod *v1.Pod) error {
+	getter := kl.newConfigSourceGetter(pod.Namespace)
+	for _, volume := range pod.Spec.Volumes {
+		var data map[string][]byte
+		var items []v1.KeyToPath
+		var optional bool
+		var err error
+		switch {
+		case volume.ConfigMap != nil:
+			data, err = getter.configMap(volume.ConfigMap.Name)
+			items, optional = volume.ConfigMap.Items, isOptional(volume.ConfigMap.Optional)
+			if err == nil && data == nil && !optional {
+				err = fmt.Errorf("configmap %s not found", volume.ConfigMap.Name)
+			}
+		case volume.Secret != nil:
+			data, err = getter.secret(volume.Secret.SecretName)
+			items, optional = volume.Secret.Items, isOptional(volume.Secret.Optional)
+			if err == nil && data == nil && !optional {
+				err = fmt.Errorf("secret %s not found", volume.Secret.SecretName)
+			}
+		default:
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("volume %s: %v", volume.Name, err)
+		}
+		files, err := projectVolumeFiles(data, items, optional)
+		if err != nil {
+			return fmt.Errorf("volume %s: %v", volume.Name, err)
+		}
+		err = writeVolumeFiles(runtime.GetVolumeDir(pod.UID, volume.Name), files)
+		if err != nil {
+			return fmt.Errorf("volume %s: %v", volume.Name, err)
+		}
+	}
+	return nil
+}
+
+// 由对象数据和items得到卷内的文件，items为空时每个key对应一个同名文件
+func projectVolumeFiles(data map[string][]byte, items []v1.KeyToPath, optional bool) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	if len(items) == 0 {
+		for k, v := range data {
+			files[k] = v
+		}
+		return files, nil
+	}
+	for _, item := range items {
+		value, ok := data[item.Key]
+		if !ok {
+			if optional {
+				continue
+			}
+			return nil, fmt.Errorf("key %s not found", item.Key)
+		}
+		path, ok := cleanVolumeFilePath(item.Path)
+		if !ok {
+			return nil, fmt.Errorf("invalid path %q for key %s", item.Path, item.Key)
+		}
+		files[path] = value
+	}
+	return files, nil
+}
+
+// 卷内文件路径须为不超出挂载点的相对路径
+func cleanVolumeFilePath(path string) (string, bool) {
+	cleaned := filepath.Clean(path)
+	if path == "" || filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", false
+	}
+	return cleaned, true
+}
+
+// 先写临时文件再rename，容器内不会读到写了一半的文件；不再存在的key对应的文件会被删除
+func writeVolumeFiles(dir string, files map[string][]byte) error {
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	for path, content := range files {
+		target := filepath.Join(dir, path)
+		old, err := os.ReadFile(target)
+		if err == nil && string(old) == string(content) {
+			continue
+		}
+		err = os.MkdirAll(filepath.Dir(target), os.ModePerm)
+		if err != nil {
+			return err
+		}
+		tmp, err := os.CreateTemp(filepath.Dir(target), tmpFilePrefix)
+		if err != nil {
+			return err
+		}
+		_, err = tmp.Write(content)
+		if closeErr := tmp.Close(); err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			err = os.Chmod(tmp.Name(), 0644)
+		}
+		if err == nil {
+			err = os.Rename(tmp.Name(), target)
+		}
+		if err != nil {
+			os.Remove(tmp.Name())
+			return err
+		}
+	}
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if _, ok := files[rel]; !ok && !strings.HasPrefix(info.Name(), tmpFilePrefix) {
+			return os.Remove(path)
+		}
+		return nil
+	})
+}
+
+// 将envFrom和configMapKeyRef、secretKeyRef解析为字面值，返回pod的副本
+// fieldRef和resourceFieldRef仍由runtime在创建容器时解析
+func (kl *Kubelet) resolveConfigEnv(pod *v1.Pod) (*v1.Pod, error) {
+	getter := kl.newConfigSourceGetter(pod.Namespace)
+	resolved := *pod
+	resolved.Spec.InitContainers = make([]v1.Container, len(pod.Spec.InitContainers))
+	resolved.Spec.Containers = make([]v1.Container, len(pod.Spec.Containers))
+	for i := range pod.Spec.InitContainers {
+		c, err := resolveContainerEnv(getter, pod.Spec.InitContainers[i])
+		if err != nil {
+			return nil, err
+		}
+		resolved.Spec.InitContainers[i] = c
+	}
+	for i := range pod.Spec.Containers {
+		c, err := resolveContainerEnv(getter, pod.Spec.Containers[i])
+		if err != nil {
+			return nil, err
+		}
+		resolved.Spec.Containers[i] = c
+	}
+	return &resolved, nil
+}
+
+func resolveContainerEnv(getter *configSourceGetter, c v1.Container) (v1.Container, error) {
+	declared := make(map[string]bool, len(c.Env))
+	for _, envVar := range c.Env {
+		declared[envVar.Name] = true
+	}
+	var env []v1.EnvVar
+	// 与k8s一致，env中显式声明的变量优先于envFrom
+	for _, from := range c.EnvFrom {
+		var data map[string][]byte
+		var err error
+		switch {
+		case from.ConfigMapRef != nil:
+			data, err = getter.configMap(from.ConfigMapRef.Name)
+			if err == nil && data == nil && !isOptional(from.ConfigMapRef.Optional) {
+				err = fmt.Errorf("configmap %s not found", from.ConfigMapRef.Name)
+			}
+		case from.SecretRef != nil:
+			data, err = getter.secret(from.SecretRef.Name)
+			if err == nil && data == nil && !isOptional(from.SecretRef.Optional) {
+				err = fmt.Errorf("secret %s not found", from.SecretRef.Name)
+			}
+		default:
+			err = fmt.Errorf("envFrom has no source")
+		}
+		if err != nil {
+			return c, fmt.Errorf("container %s: %v", c.Name, err)
+		}
+		for k, v := range data {
+			name := from.Prefix + k
+			if declared[name] || !envNameRegexp.MatchString(name) {
+				continue
+			}
+			env = append(env, v1.EnvVar{Name: name, Value: string(v)})
+		}
+	}
+	for _, envVar := range c.Env {
+		if envVar.ValueFrom == nil {
+			env = append(env, envVar)
+			continue
+		}
+		var data map[string][]byte
+		var key string
+		var optional bool
+		var err error
+		switch {
+		case envVar.ValueFrom.ConfigMapKeyRef != nil:
+			ref := envVar.ValueFrom.ConfigMapKeyRef
+			key, optional = ref.Key, isOptional(ref.Optional)
+			data, err = getter.configMap(ref.Name)
+		case envVar.ValueFrom.SecretKeyRef != nil:
+			ref := envVar.ValueFrom.SecretKeyRef
+			key, optional = ref.Key, isOptional(ref.Optional)
+			data, err = getter.secret(ref.Name)
+		default:
+			env = append(env, envVar)
+			continue
+		}
+		if err != nil {
+			return c, fmt.Errorf("container %s: env %s: %v", c.Name, envVar.Name, err)
+		}
+		value, ok := data[key]
+		if !ok {
+			if optional {
+				continue
+			}
+			return c, fmt.Errorf("container %s: env %s: key %s not found", c.Name, envVar.Name, key)
+		}
+		env = append(env, v1.EnvVar{Name: envVar.Name, Value: string(value)})
+	}
+	c.Env = env
+	c.EnvFrom = nil
+	return c, nil
+}
+
+// 定期刷新configMap、secret和downwardAPI卷，使对象与pod元数据的修改反映到容器内的文件
+func (kl *Kubelet) configVolumeRefreshLoop(ctx context.Context) {
+	ticker := time.NewTicker(configVolumeRefreshPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			for _, pod := range kl.podManger.GetPods() {
+				if hasDownwardAPIVolumes(pod) {
+					kl.refreshDownwardAPIVolumes(pod, nil)
+				}
+				if !hasConfigVolumes(pod) {
+					continue
+				}
+				if err := kl.syncConfigVolumes(pod); err != nil {
+					log.Printf("Failed to refresh config volumes of pod %v: %v\n", pod.Name, err)
+				}
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
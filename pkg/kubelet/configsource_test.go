@@ -0,0 +1,51 @@
+package kubelet
+
+import (
+	v1 "minikubernetes/pkg/api/v1"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProjectVolumeFiles(t *testing.T) {
+	data := map[string][]byte{"a": []byte("1"), "b": []byte("2")}
+
+	files, err := projectVolumeFiles(data, nil, false)
+	if err != nil || len(files) != 2 {
+		t.Fatalf("all keys: files = %v, err = %v", files, err)
+	}
+
+	files, err = projectVolumeFiles(data, []v1.KeyToPath{{Key: "a", Path: "conf/a.txt"}}, false)
+	if err != nil || len(files) != 1 || string(files["conf/a.txt"]) != "1" {
+		t.Fatalf("items: files = %v, err = %v", files, err)
+	}
+
+	if _, err = projectVolumeFiles(data, []v1.KeyToPath{{Key: "c", Path: "c"}}, false); err == nil {
+		t.Fatalf("missing key should fail")
+	}
+	if files, err = projectVolumeFiles(data, []v1.KeyToPath{{Key: "c", Path: "c"}}, true); err != nil || len(files) != 0 {
+		t.Fatalf("optional missing key: files = %v, err = %v", files, err)
+	}
+	if _, err = projectVolumeFiles(data, []v1.KeyToPath{{Key: "a", Path: "../a"}}, false); err == nil {
+		t.Fatalf("path escaping the volume should fail")
+	}
+}
+
+func TestWriteVolumeFiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "vol")
+	err := writeVolumeFiles(dir, map[string][]byte{"a": []byte("1"), "sub/b": []byte("2")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = writeVolumeFiles(dir, map[string][]byte{"a": []byte("3")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "a"))
+	if err != nil || string(content) != "3" {
+		t.Fatalf("a = %q, err = %v", content, err)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "sub/b")); !os.IsNotExist(err) {
+		t.Fatalf("stale file should be removed, err = %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package kubelet
+
+import (
+	"fmt"
+	"log"
+	v1 "minikubernetes/pkg/api/v1"
+	"minikubernetes/pkg/kubelet/runtime"
+	"sort"
+	"strings"
+)
+
+func hasDownwardAPIVolumes(pod *v1.Pod) bool {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.DownwardAPI != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// 将pod的downwardAPI卷写入kubelet的卷目录，pod ip未知时为空
+func (kl *Kubelet) syncDownwardAPIVolumes(pod *v1.Pod, podIP string) error {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.DownwardAPI == nil {
+			continue
+		}
+		files, err := downwardAPIVolumeFiles(pod, volume.DownwardAPI, podIP)
+		if err == nil {
+			err = writeVolumeFiles(runtime.GetVolumeDir(pod.UID, volume.Name), files)
+		}
+		if err != nil {
+			return fmt.Errorf("volume %s: %v", volume.Name, err)
+		}
+	}
+	return nil
+}
+
+// 本地缓存的pod不随apiserver中labels与annotations的修改更新，刷新时取最新的pod
+// pod ip取自podStatus，为空时取自pleg缓存
+func (kl *Kubelet) refreshDownwardAPIVolumes(pod *v1.Pod, podStatus *runtime.PodStatus) {
+	latest, err := kl.kubeClient.GetPod(pod.Name, pod.Namespace)
+	if err != nil || latest == nil || latest.UID != pod.UID {
+		latest = pod
+	}
+	if podStatus == nil {
+		podStatus, _ = kl.cache.Get(pod.UID)
+	}
+	ip := ""
+	if podStatus != nil {
+		ip = podIP(podStatus)
+	}
+	if err = kl.syncDownwardAPIVolumes(latest, ip); err != nil {
+		log.Printf("Failed to refresh downward api volumes of pod %v: %v\n", pod.Name, err)
+	}
+}
+
+func downwardAPIVolumeFiles(pod *v1.Pod, source *v1.DownwardAPIVolumeSource, podIP string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	for _, item := range source.Items {
+		path, ok := cleanVolumeFilePath(item.Path)
+		if !ok {
+			return nil, fmt.Errorf("invalid path %q", item.Path)
+		}
+		var value string
+		var err error
+		switch {
+		case item.FieldRef != nil && item.ResourceFieldRef != nil:
+			err = fmt.Errorf("fieldRef and resourceFieldRef cannot be both set")
+		case item.FieldRef != nil:
+			value, err = downwardAPIFieldValue(pod, item.FieldRef.FieldPath, podIP)
+		case item.ResourceFieldRef != nil:
+			value, err = runtime.ResourceFieldValue(pod, nil, item.ResourceFieldRef)
+		default:
+			err = fmt.Errorf("item has no source")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("path %s: %v", item.Path, err)
+		}
+		files[path] = []byte(value)
+	}
+	return files, nil
+}
+
+// 卷中支持pod的元数据与pod ip，节点名与节点ip只能通过环境变量获取
+func downwardAPIFieldValue(pod *v1.Pod, fieldPath string, podIP string) (string, error) {
+	switch fieldPath {
+	case "metadata.labels":
+		return formatMap(pod.Labels), nil
+	case "metadata.annotations":
+		return formatMap(pod.Annotations), nil
+	case "spec.nodeName", "status.hostIP":
+		return "", fmt.Errorf("unsupported fieldPath %q", fieldPath)
+	}
+	return runtime.PodFieldValue(pod, fieldPath, podIP)
+}
+
+// 每行一个key="value"，按key排序
+func formatMap(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	lines := make([]string, len(keys))
+	for i, k := range keys {
+		lines[i] = fmt.Sprintf("%s=%q", k, m[k])
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,57 @@
+package kubelet
+
+import (
+	v1 "minikubernetes/pkg/api/v1"
+	"testing"
+)
+
+func TestDownwardAPIVolumeFiles(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: v1.ObjectMeta{
+			Name:        "web",
+			Namespace:   "default",
+			Labels:      map[string]string{"tier": "frontend", "app": "web"},
+			Annotations: map[string]string{"build": "v1"},
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{
+				Name:      "c",
+				Resources: v1.ResourceRequirements{Limits: v1.ResourceList{v1.ResourceMemory: "64Mi"}},
+			}},
+		},
+	}
+	source := &v1.DownwardAPIVolumeSource{Items: []v1.DownwardAPIVolumeFile{
+		{Path: "name", FieldRef: &v1.ObjectFieldSelector{FieldPath: "metadata.name"}},
+		{Path: "labels", FieldRef: &v1.ObjectFieldSelector{FieldPath: "metadata.labels"}},
+		{Path: "meta/build", FieldRef: &v1.ObjectFieldSelector{FieldPath: "metadata.annotations['build']"}},
+		{Path: "mem_limit", ResourceFieldRef: &v1.ResourceFieldSelector{ContainerName: "c", Resource: "limits.memory", Divisor: "1Mi"}},
+		{Path: "ip", FieldRef: &v1.ObjectFieldSelector{FieldPath: "status.podIP"}},
+	}}
+	files, err := downwardAPIVolumeFiles(pod, source, "10.32.0.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"name":       "web",
+		"labels":     "app=\"web\"\ntier=\"frontend\"",
+		"meta/build": "v1",
+		"mem_limit":  "64",
+		"ip":         "10.32.0.5",
+	}
+	for path, content := range want {
+		if string(files[path]) != content {
+			t.Errorf("%s = %q, want %q", path, files[path], content)
+		}
+	}
+
+	for _, item := range []v1.DownwardAPIVolumeFile{
+		{Path: "node", FieldRef: &v1.ObjectFieldSelector{FieldPath: "spec.nodeName"}},
+		{Path: "mem", ResourceFieldRef: &v1.ResourceFieldSelector{Resource: "limits.memory"}},
+		{Path: "../name", FieldRef: &v1.ObjectFieldSelector{FieldPath: "metadata.name"}},
+		{Path: "empty"},
+	} {
+		if _, err = downwardAPIVolumeFiles(pod, &v1.DownwardAPIVolumeSource{Items: []v1.DownwardAPIVolumeFile{item}}, ""); err == nil {
+			t.Errorf("item %s should fail", item.Path)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package kubelet
+
+import (
+	"log"
+	v1 "minikubernetes/pkg/api/v1"
+	"minikubernetes/pkg/kubelet/runtime"
+	"time"
+)
+
+// 被驱逐的pod保留在podManager中，由apiserver删除后才清理，期间上报Failed状态
+func (kl *Kubelet) evictPod(pod *v1.Pod, gracePeriodSeconds int64, message string) error {
+	kl.statusLock.Lock()
+	kl.evictedPods[pod.UID] = message
+	kl.statusLock.Unlock()
+	kl.probeManager.RemovePod(pod)
+	if err := kl.stopPod(pod, time.Duration(gracePeriodSeconds)*time.Second); err != nil {
+		return err
+	}
+	log.Printf("Pod %v evicted: %v\n", pod.Name, message)
+	// 容器已被删除，缓存中不再有该pod的状态
+	kl.updateApiStatus(pod, &runtime.PodStatus{ID: pod.UID, Name: pod.Name, Namespace: pod.Namespace})
+	return nil
+}
+
+// 返回驱逐说明，pod未被驱逐时返回false
+func (kl *Kubelet) getEvictionMessage(uid v1.UID) (string, bool) {
+	kl.statusLock.Lock()
+	defer kl.statusLock.Unlock()
+	message, ok := kl.evictedPods[uid]
+	return message, ok
+}
+
+func (kl *Kubelet) deleteEvictionMessage(uid v1.UID) {
+	kl.statusLock.Lock()
+	defer kl.statusLock.Unlock()
+	delete(kl.evictedPods, uid)
+}
+
+// 尚未被驱逐的pod
+func (kl *Kubelet) getActivePods() []*v1.Pod {
+	var pods []*v1.Pod
+	for _, pod := range kl.podManger.GetPods() {
+		if _, evicted := kl.getEvictionMessage(pod.UID); !evicted {
+			pods = append(pods, pod)
+		}
+	}
+	return pods
+}
+
+func (kl *Kubelet) updateNodeConditions(conditions []v1.NodeCondition) error {
+	return kl.kubeClient.UpdateNodeStatus(kl.nodeName, &v1.NodeStatus{Conditions: conditions})
+}
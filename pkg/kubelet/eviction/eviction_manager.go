@@ -0,0 +1,249 @@
+package eviction
+
+import (
+	"context"
+	"fmt"
+	"log"
+	v1 "minikubernetes/pkg/api/v1"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 驱逐时为pod设置的原因
+const Reason = "Evicted"
+
+// 终止pod，gracePeriodSeconds为0时立即杀死，message作为pod status的说明
+type KillPodFunc func(pod *v1.Pod, gracePeriodSeconds int64, message string) error
+
+// 返回可被驱逐的pod
+type ActivePodsFunc func() []*v1.Pod
+
+// 上报节点condition
+type NodeConditionsFunc func(conditions []v1.NodeCondition) error
+
+// Manager 监控节点资源，低于阈值时设置压力condition并驱逐pod
+type Manager interface {
+	Start(ctx context.Context)
+	// 节点当前的压力condition
+	NodeConditions() []v1.NodeCondition
+}
+
+type manager struct {
+	config           *Config
+	statsProvider    StatsProvider
+	activePods       ActivePodsFunc
+	killPod          KillPodFunc
+	updateConditions NodeConditionsFunc
+	// 可替换的时钟
+	clock func() time.Time
+
+	lock sync.Mutex
+	// 阈值首次被满足的时间，用于软阈值的宽限期
+	thresholdsFirstObservedAt map[Threshold]time.Time
+	// condition最近一次被满足的时间
+	nodeConditionsLastObservedAt map[v1.NodeConditionType]time.Time
+	nodeConditions               []v1.NodeCondition
+	// 最近一次的condition是否已上报成功
+	conditionsReported bool
+}
+
+func NewManager(config *Config, statsProvider StatsProvider, activePods ActivePodsFunc, killPod KillPodFunc, updateConditions NodeConditionsFunc) Manager {
+	return &manager{
+		config:                       config,
+		statsProvider:                statsProvider,
+		activePods:                   activePods,
+		killPod:                      killPod,
+		updateConditions:             updateConditions,
+		clock:                        time.Now,
+		thresholdsFirstObservedAt:    make(map[Threshold]time.Time),
+		nodeConditionsLastObservedAt: make(map[v1.NodeConditionType]time.Time),
+	}
+}
+
+func (m *manager) Start(ctx context.Context) {
+	log.Printf("Eviction manager started with thresholds %v.\n", m.config.Thresholds)
+	ticker := time.NewTicker(m.config.MonitoringInterval)
+	defer ticker.Stop()
+	for {
+		m.synchronize()
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (m *manager) NodeConditions() []v1.NodeCondition {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return append([]v1.NodeCondition{}, m.nodeConditions...)
+}
+
+// 被满足的阈值及对应的观测值
+type thresholdMet struct {
+	threshold   Threshold
+	observation *ResourceStats
+}
+
+// 每次至多驱逐一个pod，下一周期根据新的观测值决定是否继续
+func (m *manager) synchronize() {
+	now := m.clock()
+	pods := m.activePods()
+	stats, err := m.statsProvider.GetStats(pods)
+	if err != nil {
+		log.Printf("Eviction manager failed to get stats: %v\n", err)
+		return
+	}
+
+	var met []thresholdMet
+	for _, threshold := range m.config.Thresholds {
+		observation, ok := stats.Node[threshold.Signal]
+		if !ok {
+			continue
+		}
+		if observation.AvailableBytes < threshold.quantity(observation.CapacityBytes) {
+			met = append(met, thresholdMet{threshold: threshold, observation: observation})
+		}
+	}
+
+	m.lock.Lock()
+	firstObservedAt := make(map[Threshold]time.Time)
+	for _, item := range met {
+		observedAt, ok := m.thresholdsFirstObservedAt[item.threshold]
+		if !ok {
+			observedAt = now
+		}
+		firstObservedAt[item.threshold] = observedAt
+		// 软阈值在宽限期内也会使节点进入压力状态
+		m.nodeConditionsLastObservedAt[signalToNodeCondition[item.threshold.Signal]] = now
+	}
+	m.thresholdsFirstObservedAt = firstObservedAt
+	m.updateNodeConditions(now)
+	m.lock.Unlock()
+
+	// 与k8s一致，超出emptyDir用量上限的pod优先被驱逐
+	if m.localStorageEviction(pods, stats) {
+		return
+	}
+
+	var toEvict []thresholdMet
+	for _, item := range met {
+		if now.Sub(firstObservedAt[item.threshold]) >= item.threshold.GracePeriod {
+			toEvict = append(toEvict, item)
+		}
+	}
+	if len(toEvict) == 0 {
+		return
+	}
+	// 优先处理内存，其次硬阈值
+	sort.SliceStable(toEvict, func(i, j int) bool {
+		mi, mj := toEvict[i].threshold.Signal == SignalMemoryAvailable, toEvict[j].threshold.Signal == SignalMemoryAvailable
+		if mi != mj {
+			return mi
+		}
+		return toEvict[i].threshold.GracePeriod < toEvict[j].threshold.GracePeriod
+	})
+	item := toEvict[0]
+	log.Printf("Eviction threshold %v met, available: %v.\n", item.threshold.String(), item.observation.AvailableBytes)
+	message := fmt.Sprintf("The node was low on resource: %s. Threshold quantity: %v, available: %v.",
+		signalToResource[item.threshold.Signal], item.threshold.quantity(item.observation.CapacityBytes), item.observation.AvailableBytes)
+	for _, pod := range rankPods(item.threshold.Signal, pods, stats) {
+		gracePeriod := int64(0)
+		if item.threshold.GracePeriod > 0 {
+			gracePeriod = min(v1.GetPodGracePeriodSeconds(pod), m.config.MaxPodGracePeriodSeconds)
+		}
+		log.Printf("Evicting pod %v/%v (%v).\n", pod.Namespace, pod.Name, v1.GetPodQOS(pod))
+		if err := m.killPod(pod, gracePeriod, message); err != nil {
+			log.Printf("Failed to evict pod %v: %v\n", pod.Name, err)
+			continue
+		}
+		return
+	}
+	log.Println("No pod can be evicted.")
+}
+
+// 驱逐emptyDir用量超过sizeLimit的pod，返回是否驱逐了pod
+func (m *manager) localStorageEviction(pods []*v1.Pod, stats *Stats) bool {
+	evicted := false
+	for _, pod := range pods {
+		podStats, ok := stats.Pods[pod.UID]
+		if !ok || v1.IsStaticPod(pod) {
+			continue
+		}
+		for _, volume := range pod.Spec.Volumes {
+			if volume.EmptyDir == nil {
+				continue
+			}
+			limit, err := v1.GetEmptyDirSizeLimit(volume.EmptyDir)
+			if err != nil || limit == 0 {
+				continue
+			}
+			used, ok := podStats.EmptyDirBytes[volume.Name]
+			if !ok || used <= uint64(limit) {
+				continue
+			}
+			message := fmt.Sprintf("Usage of EmptyDir volume %q exceeds the limit %q.", volume.Name, volume.EmptyDir.SizeLimit)
+			log.Printf("Evicting pod %v/%v: %v\n", pod.Namespace, pod.Name, message)
+			if err = m.killPod(pod, 0, message); err != nil {
+				log.Printf("Failed to evict pod %v: %v\n", pod.Name, err)
+			} else {
+				evicted = true
+			}
+			break
+		}
+	}
+	return evicted
+}
+
+// 根据各condition最近被满足的时间计算condition，发生变化或上次上报失败时上报
+func (m *manager) updateNodeConditions(now time.Time) {
+	conditions := make([]v1.NodeCondition, 0, 2)
+	for _, conditionType := range []v1.NodeConditionType{v1.NodeMemoryPressure, v1.NodeDiskPressure} {
+		condition := v1.NodeCondition{Type: conditionType, Status: v1.ConditionFalse, LastTransitionTime: now}
+		if observedAt, ok := m.nodeConditionsLastObservedAt[conditionType]; ok {
+			if now.Sub(observedAt) < m.config.PressureTransitionPeriod {
+				condition.Status = v1.ConditionTrue
+			} else {
+				delete(m.nodeConditionsLastObservedAt, conditionType)
+			}
+		}
+		condition.Reason, condition.Message = conditionReason(conditionType, condition.Status)
+		changed := true
+		for _, old := range m.nodeConditions {
+			if old.Type == conditionType && old.Status == condition.Status {
+				condition.LastTransitionTime = old.LastTransitionTime
+				changed = false
+			}
+		}
+		if changed {
+			m.conditionsReported = false
+			log.Printf("Node condition %v is now %v.\n", conditionType, condition.Status)
+		}
+		conditions = append(conditions, condition)
+	}
+	m.nodeConditions = conditions
+	if m.conditionsReported {
+		return
+	}
+	if err := m.updateConditions(conditions); err != nil {
+		log.Printf("Failed to update node conditions: %v\n", err)
+		return
+	}
+	m.conditionsReported = true
+}
+
+func conditionReason(conditionType v1.NodeConditionType, status v1.ConditionStatus) (string, string) {
+	pressure := status == v1.ConditionTrue
+	switch {
+	case conditionType == v1.NodeMemoryPressure && pressure:
+		return "KubeletHasInsufficientMemory", "kubelet has insufficient memory available"
+	case conditionType == v1.NodeMemoryPressure:
+		return "KubeletHasSufficientMemory", "kubelet has sufficient memory available"
+	case pressure:
+		return "KubeletHasDiskPressure", "kubelet has disk pressure"
+	default:
+		return "KubeletHasNoDiskPressure", "kubelet has no disk pressure"
+	}
+}
@@ -0,0 +1,208 @@
+package eviction
+
+import (
+	v1 "minikubernetes/pkg/api/v1"
+	"testing"
+	"time"
+)
+
+type fakeStatsProvider struct {
+	stats *Stats
+}
+
+func (p *fakeStatsProvider) GetStats(pods []*v1.Pod) (*Stats, error) {
+	return p.stats, nil
+}
+
+type evictedPod struct {
+	name        string
+	gracePeriod int64
+}
+
+type testManager struct {
+	*manager
+	provider   *fakeStatsProvider
+	now        time.Time
+	evicted    []evictedPod
+	conditions []v1.NodeCondition
+}
+
+func newTestManager(t *testing.T, thresholds string, soft string, softGracePeriod string, pods []*v1.Pod) *testManager {
+	t.Helper()
+	parsed, err := ParseThresholds(thresholds, soft, softGracePeriod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tm := &testManager{provider: &fakeStatsProvider{}, now: time.Now()}
+	config := &Config{
+		Thresholds:               parsed,
+		MonitoringInterval:       time.Second,
+		PressureTransitionPeriod: time.Minute,
+		MaxPodGracePeriodSeconds: 10,
+	}
+	tm.manager = NewManager(config, tm.provider, func() []*v1.Pod {
+		return pods
+	}, func(pod *v1.Pod, gracePeriodSeconds int64, message string) error {
+		tm.evicted = append(tm.evicted, evictedPod{pod.Name, gracePeriodSeconds})
+		return nil
+	}, func(conditions []v1.NodeCondition) error {
+		tm.conditions = conditions
+		return nil
+	}).(*manager)
+	tm.manager.clock = func() time.Time { return tm.now }
+	return tm
+}
+
+func (tm *testManager) setMemory(available uint64, pods map[v1.UID]uint64) {
+	tm.provider.stats = &Stats{
+		Node: map[Signal]*ResourceStats{
+			SignalMemoryAvailable: {AvailableBytes: available, CapacityBytes: 1 << 30},
+			SignalNodeFsAvailable: {AvailableBytes: 50 << 30, CapacityBytes: 100 << 30},
+		},
+		Pods: make(map[v1.UID]*PodStats),
+	}
+	for uid, memory := range pods {
+		tm.provider.stats.Pods[uid] = &PodStats{MemoryWorkingSetBytes: memory}
+	}
+}
+
+func (tm *testManager) condition(conditionType v1.NodeConditionType) v1.ConditionStatus {
+	for _, c := range tm.conditions {
+		if c.Type == conditionType {
+			return c.Status
+		}
+	}
+	return ""
+}
+
+func newPod(name string, request, limit string) *v1.Pod {
+	pod := &v1.Pod{ObjectMeta: v1.ObjectMeta{Name: name, UID: v1.UID(name)}}
+	c := v1.Container{Name: "c"}
+	if request != "" {
+		c.Resources.Requests = v1.ResourceList{v1.ResourceCPU: "100m", v1.ResourceMemory: request}
+	}
+	if limit != "" {
+		c.Resources.Limits = v1.ResourceList{v1.ResourceCPU: "100m", v1.ResourceMemory: limit}
+	}
+	pod.Spec.Containers = []v1.Container{c}
+	return pod
+}
+
+func TestHardEviction(t *testing.T) {
+	guaranteed := newPod("guaranteed", "", "256Mi")
+	burstableUnder := newPod("burstable-under", "256Mi", "")
+	burstableOver := newPod("burstable-over", "64Mi", "")
+	bestEffort := newPod("best-effort", "", "")
+	pods := []*v1.Pod{guaranteed, burstableUnder, burstableOver, bestEffort}
+	tm := newTestManager(t, "memory.available<100Mi", "", "", pods)
+
+	tm.setMemory(500<<20, nil)
+	tm.synchronize()
+	if len(tm.evicted) != 0 || tm.condition(v1.NodeMemoryPressure) != v1.ConditionFalse {
+		t.Fatalf("evicted = %v, conditions = %+v", tm.evicted, tm.conditions)
+	}
+
+	usage := map[v1.UID]uint64{guaranteed.UID: 200 << 20, burstableUnder.UID: 100 << 20, burstableOver.UID: 128 << 20, bestEffort.UID: 10 << 20}
+	tm.setMemory(50<<20, usage)
+	tm.synchronize()
+	// 每个周期只驱逐一个pod
+	if len(tm.evicted) != 1 {
+		t.Fatalf("evicted = %+v", tm.evicted)
+	}
+	want := []string{"best-effort", "burstable-over", "burstable-under", "guaranteed"}
+	for i, pod := range rankPods(SignalMemoryAvailable, pods, tm.provider.stats) {
+		if pod.Name != want[i] {
+			t.Errorf("rank %v = %v, want %v", i, pod.Name, want[i])
+		}
+	}
+	if tm.evicted[0] != (evictedPod{"best-effort", 0}) {
+		t.Errorf("first eviction = %+v, want best-effort without grace period", tm.evicted[0])
+	}
+	if tm.condition(v1.NodeMemoryPressure) != v1.ConditionTrue || tm.condition(v1.NodeDiskPressure) != v1.ConditionFalse {
+		t.Errorf("conditions = %+v", tm.conditions)
+	}
+
+	// 压力解除后condition在PressureTransitionPeriod之后才恢复
+	tm.setMemory(500<<20, nil)
+	tm.now = tm.now.Add(30 * time.Second)
+	tm.synchronize()
+	if tm.condition(v1.NodeMemoryPressure) != v1.ConditionTrue {
+		t.Errorf("memory pressure cleared before the transition period")
+	}
+	tm.now = tm.now.Add(time.Minute)
+	tm.synchronize()
+	if tm.condition(v1.NodeMemoryPressure) != v1.ConditionFalse {
+		t.Errorf("memory pressure not cleared after the transition period")
+	}
+}
+
+func TestSoftEviction(t *testing.T) {
+	pod := newPod("pod", "", "")
+	grace := int64(60)
+	pod.Spec.TerminationGracePeriodSeconds = &grace
+	static := newPod("static", "", "")
+	static.Annotations = map[string]string{v1.ConfigSourceAnnotationKey: v1.ConfigSourceFile}
+	tm := newTestManager(t, "", "memory.available<300Mi", "memory.available=1m", []*v1.Pod{static, pod})
+
+	tm.setMemory(200<<20, map[v1.UID]uint64{static.UID: 500 << 20, pod.UID: 10 << 20})
+	tm.synchronize()
+	if len(tm.evicted) != 0 {
+		t.Fatalf("evicted %v within the soft grace period", tm.evicted)
+	}
+	if tm.condition(v1.NodeMemoryPressure) != v1.ConditionTrue {
+		t.Errorf("memory pressure not set within the soft grace period")
+	}
+	tm.now = tm.now.Add(time.Minute)
+	tm.synchronize()
+	// 静态pod不被驱逐，宽限期不超过MaxPodGracePeriodSeconds
+	if len(tm.evicted) != 1 || tm.evicted[0] != (evictedPod{"pod", 10}) {
+		t.Errorf("evicted = %+v", tm.evicted)
+	}
+}
+
+func TestEmptyDirSizeLimitEviction(t *testing.T) {
+	limited := newPod("limited", "", "")
+	limited.Spec.Volumes = []v1.Volume{{Name: "cache", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{SizeLimit: "1Mi"}}}}
+	unlimited := newPod("unlimited", "", "")
+	unlimited.Spec.Volumes = []v1.Volume{{Name: "cache", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}}}
+	tm := newTestManager(t, "memory.available<100Mi", "", "", []*v1.Pod{limited, unlimited})
+
+	tm.setMemory(500<<20, nil)
+	tm.provider.stats.Pods["limited"] = &PodStats{EmptyDirBytes: map[string]uint64{"cache": 1 << 20}}
+	tm.provider.stats.Pods["unlimited"] = &PodStats{EmptyDirBytes: map[string]uint64{"cache": 1 << 30}}
+	tm.synchronize()
+	if len(tm.evicted) != 0 {
+		t.Fatalf("evicted %v within the size limit", tm.evicted)
+	}
+	tm.provider.stats.Pods["limited"].EmptyDirBytes["cache"] = 2 << 20
+	tm.synchronize()
+	if len(tm.evicted) != 1 || tm.evicted[0] != (evictedPod{"limited", 0}) {
+		t.Errorf("evicted = %v, want limited immediately", tm.evicted)
+	}
+}
+
+func TestParseThresholds(t *testing.T) {
+	thresholds, err := ParseThresholds(DefaultEvictionHard, "memory.available<1Gi", "memory.available=1m30s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(thresholds) != 4 {
+		t.Fatalf("thresholds = %v", thresholds)
+	}
+	if thresholds[1].Value.Percentage != 0.1 || thresholds[1].quantity(1000) != 100 {
+		t.Errorf("nodefs threshold = %+v", thresholds[1])
+	}
+	if thresholds[3].Value.Quantity != 1<<30 || thresholds[3].GracePeriod != 90*time.Second {
+		t.Errorf("soft threshold = %+v", thresholds[3])
+	}
+	for _, invalid := range [][3]string{
+		{"memory.available>1Gi", "", ""},
+		{"cpu.available<1", "", ""},
+		{"nodefs.available<200%", "", ""},
+		{"", "memory.available<1Gi", ""},
+	} {
+		if _, err := ParseThresholds(invalid[0], invalid[1], invalid[2]); err == nil {
+			t.Errorf("ParseThresholds(%q, %q, %q) succeeded", invalid[0], invalid[1], invalid[2])
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package eviction
+
+import (
+	v1 "minikubernetes/pkg/api/v1"
+	"sort"
+)
+
+// 按驱逐顺序排列pod，静态pod不会被驱逐
+// 内存：BestEffort、用量超过request的Burstable、其余Burstable、Guaranteed，同组内超出request越多越先驱逐
+// 磁盘：按QoS分组，同组内占用本地存储越多越先驱逐
+func rankPods(signal Signal, pods []*v1.Pod, stats *Stats) []*v1.Pod {
+	type candidate struct {
+		pod   *v1.Pod
+		group int
+		usage int64
+	}
+	var candidates []candidate
+	for _, pod := range pods {
+		if v1.IsStaticPod(pod) {
+			continue
+		}
+		podStats, ok := stats.Pods[pod.UID]
+		if !ok {
+			podStats = &PodStats{}
+		}
+		c := candidate{pod: pod, group: qosRank(v1.GetPodQOS(pod))}
+		if signal == SignalMemoryAvailable {
+			c.usage = int64(podStats.MemoryWorkingSetBytes) - podMemoryRequest(pod)
+			// 未超过request的Burstable排在超过request的之后
+			if c.group == 1 && c.usage <= 0 {
+				c.group = 2
+			}
+		} else {
+			c.usage = int64(podStats.LocalStorageBytes)
+		}
+		candidates = append(candidates, c)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].group != candidates[j].group {
+			return candidates[i].group < candidates[j].group
+		}
+		return candidates[i].usage > candidates[j].usage
+	})
+	ranked := make([]*v1.Pod, 0, len(candidates))
+	for _, c := range candidates {
+		ranked = append(ranked, c.pod)
+	}
+	return ranked
+}
+
+func qosRank(qos v1.PodQOSClass) int {
+	switch qos {
+	case v1.PodQOSBestEffort:
+		return 0
+	case v1.PodQOSBurstable:
+		return 1
+	default:
+		return 3
+	}
+}
+
+// 所有容器内存request之和，未设置request时取limit
+func podMemoryRequest(pod *v1.Pod) int64 {
+	var total int64
+	for _, c := range pod.Spec.Containers {
+		s, ok := c.Resources.Requests[v1.ResourceMemory]
+		if !ok {
+			s = c.Resources.Limits[v1.ResourceMemory]
+		}
+		if request, err := v1.ParseMemory(s); err == nil {
+			total += request
+		}
+	}
+	return total
+}
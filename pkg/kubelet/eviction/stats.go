@@ -0,0 +1,153 @@
+package eviction
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"log"
+	v1 "minikubernetes/pkg/api/v1"
+	"minikubernetes/pkg/kubelet/runtime"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// ResourceStats 节点某项资源的可用量与总量，单位为字节
+type ResourceStats struct {
+	AvailableBytes uint64
+	CapacityBytes  uint64
+}
+
+type PodStats struct {
+	MemoryWorkingSetBytes uint64
+	// pod卷目录占用的空间，即emptyDir等写入nodefs的数据
+	LocalStorageBytes uint64
+	// 各emptyDir卷占用的空间
+	EmptyDirBytes map[string]uint64
+}
+
+type Stats struct {
+	// 各信号对应的节点资源
+	Node map[Signal]*ResourceStats
+	Pods map[v1.UID]*PodStats
+}
+
+// StatsProvider 采集驱逐判断所需的节点与pod资源使用情况
+type StatsProvider interface {
+	GetStats(pods []*v1.Pod) (*Stats, error)
+}
+
+type statsProvider struct {
+	runtimeManager runtime.RuntimeManager
+}
+
+func NewStatsProvider(runtimeManager runtime.RuntimeManager) StatsProvider {
+	return &statsProvider{runtimeManager: runtimeManager}
+}
+
+func (sp *statsProvider) GetStats(pods []*v1.Pod) (*Stats, error) {
+	stats := &Stats{
+		Node: make(map[Signal]*ResourceStats),
+		Pods: make(map[v1.UID]*PodStats),
+	}
+	memory, err := readMemoryStats("/proc/meminfo")
+	if err != nil {
+		return nil, err
+	}
+	stats.Node[SignalMemoryAvailable] = memory
+	nodeFs, err := readFsStats("/")
+	if err != nil {
+		return nil, err
+	}
+	stats.Node[SignalNodeFsAvailable] = nodeFs
+	// 无法获取镜像目录时认为与根文件系统相同
+	stats.Node[SignalImageFsAvailable] = nodeFs
+	if path, err := sp.runtimeManager.ImageFsPath(); err != nil {
+		log.Printf("Failed to get image fs path: %v\n", err)
+	} else if imageFs, err := readFsStats(path); err != nil {
+		log.Printf("Failed to get image fs stats: %v\n", err)
+	} else {
+		stats.Node[SignalImageFsAvailable] = imageFs
+	}
+
+	for _, pod := range pods {
+		podStats := &PodStats{}
+		runtimeStats, err := sp.runtimeManager.GetPodStats(pod.UID)
+		if err != nil {
+			log.Printf("Failed to get stats of pod %v: %v\n", pod.Name, err)
+		} else {
+			podStats.MemoryWorkingSetBytes = runtimeStats.MemoryWorkingSetBytes
+		}
+		podStats.LocalStorageBytes = dirSize(filepath.Dir(runtime.GetVolumeDir(pod.UID, "volume")))
+		podStats.EmptyDirBytes = make(map[string]uint64)
+		for _, volume := range pod.Spec.Volumes {
+			if volume.EmptyDir != nil {
+				podStats.EmptyDirBytes[volume.Name] = dirSize(runtime.GetVolumeDir(pod.UID, volume.Name))
+			}
+		}
+		stats.Pods[pod.UID] = podStats
+	}
+	return stats, nil
+}
+
+// 与k8s一致，可用内存取MemAvailable
+func readMemoryStats(path string) (*ResourceStats, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	values := make(map[string]uint64)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// MemAvailable:   12345678 kB
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		value, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		values[strings.TrimSuffix(fields[0], ":")] = value * 1024
+	}
+	total, ok := values["MemTotal"]
+	if !ok {
+		return nil, fmt.Errorf("MemTotal not found in %s", path)
+	}
+	available, ok := values["MemAvailable"]
+	if !ok {
+		return nil, fmt.Errorf("MemAvailable not found in %s", path)
+	}
+	return &ResourceStats{AvailableBytes: available, CapacityBytes: total}, nil
+}
+
+func readFsStats(path string) (*ResourceStats, error) {
+	var statfs syscall.Statfs_t
+	if err := syscall.Statfs(path, &statfs); err != nil {
+		return nil, err
+	}
+	return &ResourceStats{
+		AvailableBytes: statfs.Bavail * uint64(statfs.Bsize),
+		CapacityBytes:  statfs.Blocks * uint64(statfs.Bsize),
+	}, nil
+}
+
+// 目录下所有普通文件的大小之和，目录不存在时为0
+func dirSize(dir string) uint64 {
+	var size uint64
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += uint64(info.Size())
+			}
+		}
+		return nil
+	})
+	return size
+}
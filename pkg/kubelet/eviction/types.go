@@ -0,0 +1,198 @@
+package eviction
+
+import (
+	"fmt"
+	v1 "minikubernetes/pkg/api/v1"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Signal 驱逐所依据的节点资源指标
+type Signal string
+
+const (
+	// 节点可用内存
+	SignalMemoryAvailable Signal = "memory.available"
+	// 根文件系统的可用空间，包括容器日志与emptyDir
+	SignalNodeFsAvailable Signal = "nodefs.available"
+	// 镜像与容器可写层所在文件系统的可用空间
+	SignalImageFsAvailable Signal = "imagefs.available"
+)
+
+// 各信号对应的节点condition
+var signalToNodeCondition = map[Signal]v1.NodeConditionType{
+	SignalMemoryAvailable:  v1.NodeMemoryPressure,
+	SignalNodeFsAvailable:  v1.NodeDiskPressure,
+	SignalImageFsAvailable: v1.NodeDiskPressure,
+}
+
+// 各信号对应的资源名，用于驱逐说明
+var signalToResource = map[Signal]string{
+	SignalMemoryAvailable:  "memory",
+	SignalNodeFsAvailable:  "ephemeral-storage",
+	SignalImageFsAvailable: "ephemeral-storage",
+}
+
+// ThresholdValue 阈值为绝对量或总量的百分比，二者只设置其一
+type ThresholdValue struct {
+	// 字节数
+	Quantity int64
+	// 0~1之间的比例
+	Percentage float64
+}
+
+// Threshold 信号的可用量低于Value时触发，GracePeriod为0表示硬阈值
+type Threshold struct {
+	Signal Signal
+	Value  ThresholdValue
+	// 软阈值需持续满足GracePeriod后才开始驱逐
+	GracePeriod time.Duration
+}
+
+type Config struct {
+	Thresholds []Threshold
+	// 检查阈值的周期
+	MonitoringInterval time.Duration
+	// 阈值不再满足后，节点condition保持为True的时间，避免condition来回振荡
+	PressureTransitionPeriod time.Duration
+	// 软驱逐时pod宽限期的上限
+	MaxPodGracePeriodSeconds int64
+}
+
+const (
+	// 与k8s的默认硬阈值一致
+	DefaultEvictionHard = "memory.available<100Mi,nodefs.available<10%,imagefs.available<15%"
+
+	defaultMonitoringInterval       = 10 * time.Second
+	defaultPressureTransitionPeriod = time.Minute
+	defaultMaxPodGracePeriodSeconds = 30
+)
+
+// ConfigFromEnv 读取环境变量中的驱逐配置，例如：
+//
+//	EVICTION_HARD=memory.available<200Mi,nodefs.available<5%
+//	EVICTION_SOFT=memory.available<500Mi
+//	EVICTION_SOFT_GRACE_PERIOD=memory.available=1m30s
+//	EVICTION_MAX_POD_GRACE_PERIOD=60
+//	EVICTION_PRESSURE_TRANSITION_PERIOD=5m
+func ConfigFromEnv() (*Config, error) {
+	hard := os.Getenv("EVICTION_HARD")
+	if hard == "" {
+		hard = DefaultEvictionHard
+	}
+	thresholds, err := ParseThresholds(hard, os.Getenv("EVICTION_SOFT"), os.Getenv("EVICTION_SOFT_GRACE_PERIOD"))
+	if err != nil {
+		return nil, err
+	}
+	config := &Config{
+		Thresholds:               thresholds,
+		MonitoringInterval:       defaultMonitoringInterval,
+		PressureTransitionPeriod: defaultPressureTransitionPeriod,
+		MaxPodGracePeriodSeconds: defaultMaxPodGracePeriodSeconds,
+	}
+	if s := os.Getenv("EVICTION_MAX_POD_GRACE_PERIOD"); s != "" {
+		config.MaxPodGracePeriodSeconds, err = strconv.ParseInt(s, 10, 64)
+		if err != nil || config.MaxPodGracePeriodSeconds < 0 {
+			return nil, fmt.Errorf("invalid EVICTION_MAX_POD_GRACE_PERIOD %q", s)
+		}
+	}
+	if s := os.Getenv("EVICTION_PRESSURE_TRANSITION_PERIOD"); s != "" {
+		config.PressureTransitionPeriod, err = time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EVICTION_PRESSURE_TRANSITION_PERIOD %q", s)
+		}
+	}
+	return config, nil
+}
+
+// ParseThresholds 解析硬阈值与软阈值，每个软阈值都必须指定宽限期
+func ParseThresholds(hard, soft, softGracePeriod string) ([]Threshold, error) {
+	var thresholds []Threshold
+	hardThresholds, err := parseThresholdList(hard)
+	if err != nil {
+		return nil, err
+	}
+	thresholds = append(thresholds, hardThresholds...)
+
+	softThresholds, err := parseThresholdList(soft)
+	if err != nil {
+		return nil, err
+	}
+	gracePeriods := make(map[Signal]time.Duration)
+	for _, item := range splitList(softGracePeriod) {
+		signal, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid eviction grace period %q", item)
+		}
+		gracePeriod, err := time.ParseDuration(value)
+		if err != nil || gracePeriod <= 0 {
+			return nil, fmt.Errorf("invalid eviction grace period %q", item)
+		}
+		gracePeriods[Signal(signal)] = gracePeriod
+	}
+	for _, threshold := range softThresholds {
+		gracePeriod, ok := gracePeriods[threshold.Signal]
+		if !ok {
+			return nil, fmt.Errorf("grace period must be specified for soft eviction threshold %s", threshold.Signal)
+		}
+		threshold.GracePeriod = gracePeriod
+		thresholds = append(thresholds, threshold)
+	}
+	return thresholds, nil
+}
+
+func parseThresholdList(s string) ([]Threshold, error) {
+	var thresholds []Threshold
+	for _, item := range splitList(s) {
+		signal, value, ok := strings.Cut(item, "<")
+		if !ok {
+			return nil, fmt.Errorf("invalid eviction threshold %q, only < is supported", item)
+		}
+		if _, ok := signalToNodeCondition[Signal(signal)]; !ok {
+			return nil, fmt.Errorf("unsupported eviction signal %s", signal)
+		}
+		threshold := Threshold{Signal: Signal(signal)}
+		if strings.HasSuffix(value, "%") {
+			percentage, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+			if err != nil || percentage <= 0 || percentage > 100 {
+				return nil, fmt.Errorf("invalid eviction threshold %q", item)
+			}
+			threshold.Value.Percentage = percentage / 100
+		} else {
+			quantity, err := v1.ParseMemory(value)
+			if err != nil || quantity <= 0 {
+				return nil, fmt.Errorf("invalid eviction threshold %q", item)
+			}
+			threshold.Value.Quantity = quantity
+		}
+		thresholds = append(thresholds, threshold)
+	}
+	return thresholds, nil
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// 阈值对应的字节数
+func (t *Threshold) quantity(capacity uint64) uint64 {
+	if t.Value.Percentage > 0 {
+		return uint64(t.Value.Percentage * float64(capacity))
+	}
+	return uint64(t.Value.Quantity)
+}
+
+func (t *Threshold) String() string {
+	if t.Value.Percentage > 0 {
+		return fmt.Sprintf("%s<%v%%", t.Signal, t.Value.Percentage*100)
+	}
+	return fmt.Sprintf("%s<%v", t.Signal, t.Value.Quantity)
+}
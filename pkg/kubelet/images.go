@@ -0,0 +1,148 @@
+package kubelet
+
+import (
+	"fmt"
+	"log"
+	v1 "minikubernetes/pkg/api/v1"
+	"minikubernetes/pkg/kubelet/eviction"
+	"minikubernetes/pkg/kubelet/images"
+	"minikubernetes/pkg/kubelet/runtime"
+	"minikubernetes/pkg/kubelet/types"
+	"time"
+)
+
+// 镜像未就绪时容器的等待原因
+const (
+	ErrImagePull      = "ErrImagePull"
+	ImagePullBackOff  = "ImagePullBackOff"
+	ErrImageNeverPull = "ErrImageNeverPull"
+	ErrImageInspect   = "ErrImageInspect"
+)
+
+// 镜像未就绪的pod的重试周期，与k8s的pod同步周期一致，退避期间显示ImagePullBackOff
+const imagePullResyncPeriod = 10 * time.Second
+
+// 按拉取策略准备pod所有容器的镜像，返回是否全部就绪
+// 未就绪时上报各容器的等待原因，并在imagePullResyncPeriod后重试创建
+func (kl *Kubelet) pullImages(pod *v1.Pod) bool {
+	secrets := kl.getPullSecrets(pod)
+	containers := append(append([]v1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+	ready := true
+	for i := range containers {
+		c := &containers[i]
+		waiting := kl.ensureImageExists(pod, c, secrets)
+		kl.setImageWaitingState(pod.UID, c.Name, waiting)
+		if waiting != nil {
+			log.Printf("Image %v of container %v in pod %v is not ready: %v, %v\n", c.Image, c.Name, pod.Name, waiting.Reason, waiting.Message)
+			ready = false
+		}
+	}
+	if ready {
+		return true
+	}
+	// pod尚未创建，运行时中没有其状态
+	kl.updateApiStatus(pod, &runtime.PodStatus{ID: pod.UID, Name: pod.Name, Namespace: pod.Namespace})
+	time.AfterFunc(imagePullResyncPeriod, func() {
+		kl.podWorkers.UpdatePod(pod, types.SyncPodRetryCreate)
+	})
+	return false
+}
+
+// 镜像就绪时返回nil
+func (kl *Kubelet) ensureImageExists(pod *v1.Pod, c *v1.Container, secrets []*v1.Secret) *v1.ContainerStateWaiting {
+	policy := v1.GetImagePullPolicy(c)
+	exists, err := kl.runtimeManager.ImageExists(c.Image)
+	if err != nil {
+		return &v1.ContainerStateWaiting{Reason: ErrImageInspect, Message: fmt.Sprintf("Failed to inspect image %q: %v", c.Image, err)}
+	}
+	if policy == v1.PullNever {
+		if exists {
+			return nil
+		}
+		return &v1.ContainerStateWaiting{
+			Reason:  ErrImageNeverPull,
+			Message: fmt.Sprintf("Container image %q is not present with pull policy of Never", c.Image),
+		}
+	}
+	if policy == v1.PullIfNotPresent && exists {
+		return nil
+	}
+	key := backOffKey(pod.UID, c.Name)
+	if kl.imageBackOff.IsInBackOffSinceUpdate(key) {
+		return &v1.ContainerStateWaiting{Reason: ImagePullBackOff, Message: fmt.Sprintf("Back-off pulling image %q", c.Image)}
+	}
+	auth, err := images.CredentialsFromSecrets(c.Image, secrets)
+	if err != nil {
+		log.Printf("Failed to get credentials for image %v: %v\n", c.Image, err)
+	}
+	// 拉取期间显示进度
+	kl.setImageWaitingState(pod.UID, c.Name, &v1.ContainerStateWaiting{
+		Reason:  "ContainerCreating",
+		Message: fmt.Sprintf("Pulling image %q", c.Image),
+	})
+	kl.updateApiStatus(pod, &runtime.PodStatus{ID: pod.UID, Name: pod.Name, Namespace: pod.Namespace})
+	log.Printf("Pulling image %v for container %v in pod %v.\n", c.Image, c.Name, pod.Name)
+	start := time.Now()
+	if err = kl.runtimeManager.PullImage(c.Image, auth); err != nil {
+		kl.imageBackOff.Next(key, kl.imageBackOff.now())
+		return &v1.ContainerStateWaiting{Reason: ErrImagePull, Message: fmt.Sprintf("Failed to pull image %q: %v", c.Image, err)}
+	}
+	log.Printf("Successfully pulled image %v in %v.\n", c.Image, time.Since(start))
+	return nil
+}
+
+// 获取失败的secret被忽略，与k8s一致
+func (kl *Kubelet) getPullSecrets(pod *v1.Pod) []*v1.Secret {
+	var secrets []*v1.Secret
+	for _, ref := range pod.Spec.ImagePullSecrets {
+		secret, err := kl.kubeClient.GetSecret(ref.Name, pod.Namespace)
+		if err != nil || secret == nil {
+			log.Printf("Failed to get image pull secret %v of pod %v: %v\n", ref.Name, pod.Name, err)
+			continue
+		}
+		secrets = append(secrets, secret)
+	}
+	return secrets
+}
+
+// waiting为nil时清除记录
+func (kl *Kubelet) setImageWaitingState(uid v1.UID, containerName string, waiting *v1.ContainerStateWaiting) {
+	kl.statusLock.Lock()
+	defer kl.statusLock.Unlock()
+	if waiting == nil {
+		delete(kl.imageStates[uid], containerName)
+		return
+	}
+	if kl.imageStates[uid] == nil {
+		kl.imageStates[uid] = make(map[string]*v1.ContainerStateWaiting)
+	}
+	kl.imageStates[uid][containerName] = waiting
+}
+
+func (kl *Kubelet) getImageWaitingState(uid v1.UID, containerName string) *v1.ContainerStateWaiting {
+	kl.statusLock.Lock()
+	defer kl.statusLock.Unlock()
+	return kl.imageStates[uid][containerName]
+}
+
+func (kl *Kubelet) deleteImageStates(pod *v1.Pod) {
+	kl.statusLock.Lock()
+	delete(kl.imageStates, pod.UID)
+	kl.statusLock.Unlock()
+	for _, c := range append(append([]v1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+		kl.imageBackOff.Remove(backOffKey(pod.UID, c.Name))
+	}
+}
+
+// 镜像所在文件系统的总量与可用量
+func (kl *Kubelet) imageFsStats() (uint64, uint64, error) {
+	stats, err := kl.statsProvider.GetStats(nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	fs := stats.Node[eviction.SignalImageFsAvailable]
+	if fs == nil {
+		return 0, 0, fmt.Errorf("image fs stats not available")
+	}
+	return fs.CapacityBytes, fs.AvailableBytes, nil
+}
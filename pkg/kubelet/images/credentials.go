@@ -0,0 +1,71 @@
+package images
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	v1 "minikubernetes/pkg/api/v1"
+	"minikubernetes/pkg/kubelet/runtime"
+	"strings"
+
+	"github.com/containerd/containerd/reference/docker"
+)
+
+// ~/.docker/config.json中的auths部分，例如：
+//
+//	{"auths": {"registry.example.com": {"username": "user", "password": "pass"}}}
+type dockerConfigJson struct {
+	Auths map[string]dockerConfigEntry `json:"auths"`
+}
+
+type dockerConfigEntry struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// base64编码的username:password，username为空时使用
+	Auth string `json:"auth,omitempty"`
+}
+
+// CredentialsFromSecrets 在kubernetes.io/dockerconfigjson类型的secret中查找镜像所在仓库的凭据，未找到时返回nil
+func CredentialsFromSecrets(image string, secrets []*v1.Secret) (*runtime.AuthConfig, error) {
+	named, err := docker.ParseNormalizedNamed(image)
+	if err != nil {
+		return nil, err
+	}
+	registry := docker.Domain(named)
+	for _, secret := range secrets {
+		if secret.Type != v1.SecretTypeDockerConfigJson {
+			continue
+		}
+		var config dockerConfigJson
+		if err = json.Unmarshal(secret.Data[v1.DockerConfigJsonKey], &config); err != nil {
+			return nil, fmt.Errorf("secret %s: invalid %s: %v", secret.Name, v1.DockerConfigJsonKey, err)
+		}
+		for server, entry := range config.Auths {
+			if normalizeRegistry(server) != registry {
+				continue
+			}
+			auth := &runtime.AuthConfig{Username: entry.Username, Password: entry.Password, ServerAddress: server}
+			if auth.Username == "" && entry.Auth != "" {
+				decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+				if err != nil {
+					return nil, fmt.Errorf("secret %s: invalid auth for %s: %v", secret.Name, server, err)
+				}
+				auth.Username, auth.Password, _ = strings.Cut(string(decoded), ":")
+			}
+			return auth, nil
+		}
+	}
+	return nil, nil
+}
+
+// 去掉协议与路径，docker hub的各种写法统一为docker.io
+func normalizeRegistry(server string) string {
+	server = strings.TrimPrefix(server, "https://")
+	server = strings.TrimPrefix(server, "http://")
+	server, _, _ = strings.Cut(server, "/")
+	switch server {
+	case "index.docker.io", "registry-1.docker.io":
+		return "docker.io"
+	}
+	return server
+}
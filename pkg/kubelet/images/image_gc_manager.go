@@ -0,0 +1,177 @@
+package images
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"minikubernetes/pkg/kubelet/runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ImageService 镜像回收所需的运行时操作
+type ImageService interface {
+	ListImages() ([]*runtime.Image, error)
+	RemoveImage(imageID string) error
+	GetImagesInUse() (map[string]bool, error)
+}
+
+// 返回镜像所在文件系统的总量与可用量，单位为字节
+type FsStatsFunc func() (capacity uint64, available uint64, err error)
+
+// ImageGCManager 在镜像所在文件系统的使用率超过高水位时，按最近最少使用的顺序删除未使用的镜像，直到低于低水位
+type ImageGCManager interface {
+	Start(ctx context.Context)
+	GarbageCollect() error
+}
+
+type imageRecord struct {
+	// 首次发现该镜像的时间
+	firstDetected time.Time
+	// 最近一次发现该镜像被容器使用的时间
+	lastUsed time.Time
+	size     uint64
+}
+
+type realImageGCManager struct {
+	service ImageService
+	fsStats FsStatsFunc
+	policy  *GCPolicy
+	// 可替换的时钟
+	clock func() time.Time
+
+	lock         sync.Mutex
+	imageRecords map[string]*imageRecord
+}
+
+func NewImageGCManager(service ImageService, fsStats FsStatsFunc, policy *GCPolicy) ImageGCManager {
+	return &realImageGCManager{
+		service:      service,
+		fsStats:      fsStats,
+		policy:       policy,
+		clock:        time.Now,
+		imageRecords: make(map[string]*imageRecord),
+	}
+}
+
+func (m *realImageGCManager) Start(ctx context.Context) {
+	if m.policy.HighThresholdPercent >= 100 {
+		log.Println("Image garbage collection disabled.")
+		return
+	}
+	log.Printf("Image GC manager started with high threshold %v%%, low threshold %v%%.\n",
+		m.policy.HighThresholdPercent, m.policy.LowThresholdPercent)
+	ticker := time.NewTicker(m.policy.Period)
+	defer ticker.Stop()
+	for {
+		if err := m.GarbageCollect(); err != nil {
+			log.Printf("Image garbage collection failed: %v\n", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (m *realImageGCManager) GarbageCollect() error {
+	now := m.clock()
+	inUse, err := m.detectImages(now)
+	if err != nil {
+		return err
+	}
+	capacity, available, err := m.fsStats()
+	if err != nil {
+		return err
+	}
+	if capacity == 0 {
+		return fmt.Errorf("invalid capacity 0 on image filesystem")
+	}
+	usagePercent := int((capacity - available) * 100 / capacity)
+	if usagePercent < m.policy.HighThresholdPercent {
+		return nil
+	}
+	amountToFree := int64(capacity)*int64(100-m.policy.LowThresholdPercent)/100 - int64(available)
+	log.Printf("Image fs usage %v%% exceeds the high threshold %v%%, trying to free %v bytes.\n",
+		usagePercent, m.policy.HighThresholdPercent, amountToFree)
+	freed := m.freeSpace(amountToFree, now, inUse)
+	if freed < amountToFree {
+		return fmt.Errorf("attempted to free %v bytes, but only found %v bytes eligible to free", amountToFree, freed)
+	}
+	return nil
+}
+
+// 更新镜像的使用记录，返回使用中的镜像
+func (m *realImageGCManager) detectImages(now time.Time) (map[string]bool, error) {
+	images, err := m.service.ListImages()
+	if err != nil {
+		return nil, err
+	}
+	inUse, err := m.service.GetImagesInUse()
+	if err != nil {
+		return nil, err
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	current := make(map[string]bool)
+	for _, img := range images {
+		current[img.ID] = true
+		record, ok := m.imageRecords[img.ID]
+		if !ok {
+			record = &imageRecord{firstDetected: now}
+			m.imageRecords[img.ID] = record
+		}
+		if inUse[img.ID] {
+			record.lastUsed = now
+		}
+		record.size = img.Size
+	}
+	for id := range m.imageRecords {
+		if !current[id] {
+			delete(m.imageRecords, id)
+		}
+	}
+	return inUse, nil
+}
+
+// 按最近使用时间从早到晚删除未使用的镜像，返回释放的空间
+func (m *realImageGCManager) freeSpace(amountToFree int64, now time.Time, inUse map[string]bool) int64 {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	type evictable struct {
+		id string
+		*imageRecord
+	}
+	var candidates []evictable
+	for id, record := range m.imageRecords {
+		if !inUse[id] {
+			candidates = append(candidates, evictable{id, record})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if !candidates[i].lastUsed.Equal(candidates[j].lastUsed) {
+			return candidates[i].lastUsed.Before(candidates[j].lastUsed)
+		}
+		return candidates[i].firstDetected.Before(candidates[j].firstDetected)
+	})
+	var freed int64
+	for _, c := range candidates {
+		if freed >= amountToFree {
+			break
+		}
+		// 刚拉取的镜像可能即将被使用
+		if now.Sub(c.firstDetected) < m.policy.MinAge {
+			continue
+		}
+		log.Printf("Removing image %v to free %v bytes.\n", c.id, c.size)
+		if err := m.service.RemoveImage(c.id); err != nil {
+			log.Printf("Failed to remove image %v: %v\n", c.id, err)
+			continue
+		}
+		delete(m.imageRecords, c.id)
+		freed += int64(c.size)
+	}
+	return freed
+}
@@ -0,0 +1,124 @@
+package images
+
+import (
+	v1 "minikubernetes/pkg/api/v1"
+	"minikubernetes/pkg/kubelet/runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestPod(name, image string) *v1.Pod {
+	pod := &v1.Pod{}
+	pod.Name = name
+	pod.UID = v1.UID(name)
+	pod.Spec.Containers = []v1.Container{{Name: "c", Image: image}}
+	return pod
+}
+
+func TestGarbageCollect(t *testing.T) {
+	rm := runtime.NewFakeRuntimeManager()
+	// 文件系统总量100G，已用空间为镜像大小之和加上otherUsage
+	var otherUsage uint64
+	m := NewImageGCManager(rm, func() (uint64, uint64, error) {
+		images, _ := rm.ListImages()
+		used := otherUsage
+		for _, img := range images {
+			used += img.Size
+		}
+		return 100 << 30, 100<<30 - used, nil
+	}, &GCPolicy{HighThresholdPercent: 85, LowThresholdPercent: 80, MinAge: 2 * time.Minute}).(*realImageGCManager)
+	now := time.Now()
+	m.clock = func() time.Time { return now }
+	gc := m.GarbageCollect
+
+	rm.AddImage("old", 4<<30)
+	rm.AddImage("recent", 4<<30)
+	rm.AddImage("used", 4<<30)
+	if err := gc(); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(2 * time.Minute)
+	rm.AddImage("new", 8<<30)
+	if err := gc(); err != nil {
+		t.Fatal(err)
+	}
+	if err := rm.AddPod(newTestPod("recent", "recent")); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(time.Minute)
+	if err := gc(); err != nil {
+		t.Fatal(err)
+	}
+	if err := rm.DeletePod("recent", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := rm.AddPod(newTestPod("used", "used")); err != nil {
+		t.Fatal(err)
+	}
+
+	// 使用率90%，需释放10G：new未满MinAge，used正在使用，只能释放8G
+	otherUsage = 70 << 30
+	if err := gc(); err == nil {
+		t.Error("expected error when not enough space can be freed")
+	}
+	if removed := removedImages(rm); !slices.Equal(removed, []string{"old", "recent"}) {
+		t.Errorf("removed images = %v, want [old recent]", removed)
+	}
+	// 使用率82%，低于高水位
+	now = now.Add(time.Minute)
+	if err := gc(); err != nil || len(removedImages(rm)) != 2 {
+		t.Errorf("unexpected garbage collection below the high threshold: %v", err)
+	}
+	otherUsage = 75 << 30
+	if err := gc(); err != nil {
+		t.Fatal(err)
+	}
+	if removed := removedImages(rm); !slices.Equal(removed, []string{"old", "recent", "new"}) {
+		t.Errorf("removed images = %v, want [old recent new]", removed)
+	}
+}
+
+func removedImages(rm *runtime.FakeRuntimeManager) []string {
+	var removed []string
+	for _, call := range rm.GetCalls() {
+		if image, ok := strings.CutPrefix(call, "RemoveImage "); ok {
+			removed = append(removed, image)
+		}
+	}
+	return removed
+}
+
+func TestCredentialsFromSecrets(t *testing.T) {
+	secret := &v1.Secret{Type: v1.SecretTypeDockerConfigJson, Data: map[string][]byte{
+		v1.DockerConfigJsonKey: []byte(`{"auths": {
+			"https://index.docker.io/v1/": {"auth": "aHViOnNlY3JldA=="},
+			"registry.example.com:5000": {"username": "user", "password": "pass"}
+		}}`),
+	}}
+	secrets := []*v1.Secret{{Type: v1.SecretTypeOpaque}, secret}
+	for _, tc := range []struct {
+		image    string
+		username string
+		password string
+	}{
+		{"nginx:latest", "hub", "secret"},
+		{"registry.example.com:5000/app:v1", "user", "pass"},
+		{"registry.example.com/app:v1", "", ""},
+	} {
+		auth, err := CredentialsFromSecrets(tc.image, secrets)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.username == "" {
+			if auth != nil {
+				t.Errorf("%v: unexpected credentials %+v", tc.image, auth)
+			}
+			continue
+		}
+		if auth == nil || auth.Username != tc.username || auth.Password != tc.password {
+			t.Errorf("%v: credentials = %+v", tc.image, auth)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package images
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+type GCPolicy struct {
+	// 镜像所在文件系统的使用率达到该百分比时开始回收，为100时不回收
+	HighThresholdPercent int
+	// 回收到使用率低于该百分比为止
+	LowThresholdPercent int
+	// 首次发现后未满该时间的镜像不会被回收
+	MinAge time.Duration
+	// 检查使用率的周期
+	Period time.Duration
+}
+
+// 与k8s的默认值一致
+const (
+	defaultHighThresholdPercent = 85
+	defaultLowThresholdPercent  = 80
+	defaultMinAge               = 2 * time.Minute
+	defaultPeriod               = 5 * time.Minute
+)
+
+// PolicyFromEnv 读取环境变量中的镜像回收配置，例如：
+//
+//	IMAGE_GC_HIGH_THRESHOLD=90
+//	IMAGE_GC_LOW_THRESHOLD=70
+//	IMAGE_MINIMUM_GC_AGE=10m
+func PolicyFromEnv() (*GCPolicy, error) {
+	policy := &GCPolicy{
+		HighThresholdPercent: defaultHighThresholdPercent,
+		LowThresholdPercent:  defaultLowThresholdPercent,
+		MinAge:               defaultMinAge,
+		Period:               defaultPeriod,
+	}
+	var err error
+	if s := os.Getenv("IMAGE_GC_HIGH_THRESHOLD"); s != "" {
+		if policy.HighThresholdPercent, err = strconv.Atoi(s); err != nil {
+			return nil, fmt.Errorf("invalid IMAGE_GC_HIGH_THRESHOLD %q: %v", s, err)
+		}
+	}
+	if s := os.Getenv("IMAGE_GC_LOW_THRESHOLD"); s != "" {
+		if policy.LowThresholdPercent, err = strconv.Atoi(s); err != nil {
+			return nil, fmt.Errorf("invalid IMAGE_GC_LOW_THRESHOLD %q: %v", s, err)
+		}
+	}
+	if s := os.Getenv("IMAGE_MINIMUM_GC_AGE"); s != "" {
+		if policy.MinAge, err = time.ParseDuration(s); err != nil {
+			return nil, fmt.Errorf("invalid IMAGE_MINIMUM_GC_AGE %q: %v", s, err)
+		}
+	}
+	if err = policy.Validate(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (p *GCPolicy) Validate() error {
+	if p.HighThresholdPercent < 0 || p.HighThresholdPercent > 100 {
+		return fmt.Errorf("invalid image GC high threshold %v, must be in range [0-100]", p.HighThresholdPercent)
+	}
+	if p.LowThresholdPercent < 0 || p.LowThresholdPercent > 100 {
+		return fmt.Errorf("invalid image GC low threshold %v, must be in range [0-100]", p.LowThresholdPercent)
+	}
+	if p.LowThresholdPercent > p.HighThresholdPercent {
+		return fmt.Errorf("image GC low threshold %v is greater than high threshold %v", p.LowThresholdPercent, p.HighThresholdPercent)
+	}
+	return nil
+}
@@ -2,19 +2,31 @@ package kubelet
 
 import (
 	"context"
+	"fmt"
 	"log"
 	v1 "minikubernetes/pkg/api/v1"
 	"minikubernetes/pkg/kubelet/client"
+	"minikubernetes/pkg/kubelet/config"
+	"minikubernetes/pkg/kubelet/eviction"
+	"minikubernetes/pkg/kubelet/images"
+	"minikubernetes/pkg/kubelet/lifecycle"
 	kubemetrics "minikubernetes/pkg/kubelet/metrics"
 	"minikubernetes/pkg/kubelet/pleg"
 	kubepod "minikubernetes/pkg/kubelet/pod"
+	"minikubernetes/pkg/kubelet/prober"
 	"minikubernetes/pkg/kubelet/runtime"
 	"minikubernetes/pkg/kubelet/types"
 	"minikubernetes/pkg/kubelet/utils"
+	"minikubernetes/pkg/kubelet/volume"
+	"os"
+	"slices"
 	"sync"
 	"time"
 )
 
+// 恢复状态失败时的重试间隔
+const recoverRetryPeriod = 3 * time.Second
+
 type Kubelet struct {
 	nodeName   string
 	podManger  kubepod.Manager
@@ -25,30 +37,142 @@ type Kubelet struct {
 	runtimeManager runtime.RuntimeManager
 	cache          runtime.Cache
 	nameserverIP   string
+	hostIP         string
+	staticPodPath  string
+	probeManager   prober.Manager
+	backOff        *backOff
+	hookRunner     *lifecycle.HandlerRunner
+	// 注册时的节点信息，用于admit检查
+	node          *v1.Node
+	admitHandlers lifecycle.PodAdmitHandlers
+	// 镜像拉取失败后的退避
+	imageBackOff   *backOff
+	statsProvider  eviction.StatsProvider
+	imageGCManager images.ImageGCManager
+	volumeManager  volume.Manager
+
+	// 最近一次成功上报的api status，用于保留condition的变化时间
+	statusLock   sync.Mutex
+	lastStatuses map[v1.UID]*v1.PodStatus
+	// 被驱逐的pod及驱逐说明
+	evictedPods     map[v1.UID]string
+	evictionManager eviction.Manager
+	// 镜像未就绪的容器的等待状态
+	imageStates map[v1.UID]map[string]*v1.ContainerStateWaiting
+	// 退避结束后重试重启容器的定时器，每个pod最多一个
+	restartTimersLock sync.Mutex
+	restartTimers     map[v1.UID]*restartTimer
 
 	// metrics collector
 	metricsCollector kubemetrics.MetricsCollector
 }
 
-func NewMainKubelet(nodeName string, kubeClient client.KubeletClient) (*Kubelet, error) {
+// kubelet依赖的外部组件，为空的字段使用默认实现，测试时可替换为fake
+type Dependencies struct {
+	KubeClient       client.KubeletClient
+	RuntimeManager   runtime.RuntimeManager
+	MetricsCollector kubemetrics.MetricsCollector
+	// 为空时从环境变量CLUSTER_DNS或coredns容器获取
+	NameserverIP string
+	// 为空时取本机ip
+	HostIP string
+	// 静态pod的manifest目录，为空时不支持静态pod
+	StaticPodPath string
+	// 为空时从环境变量读取驱逐阈值
+	EvictionConfig *eviction.Config
+	// 为空时从procfs与容器运行时采集
+	StatsProvider eviction.StatsProvider
+	// 为空时从环境变量读取镜像回收配置
+	ImageGCPolicy *images.GCPolicy
+	// 注册得到的节点，为空时不检查资源与污点
+	Node *v1.Node
+	// 为空时管理runtime.VolumesRootDir下的卷目录
+	VolumeManager volume.Manager
+}
+
+func NewMainKubelet(nodeName string, deps *Dependencies) (*Kubelet, error) {
 	kl := &Kubelet{}
 
-	nameserverIP, err := runtime.GetContainerBridgeIP("coredns")
-	if err != nil {
-		return nil, err
+	nameserverIP := deps.NameserverIP
+	if nameserverIP == "" {
+		// 节点上没有dockerd时无法查询coredns容器，需通过CLUSTER_DNS指定
+		nameserverIP = os.Getenv("CLUSTER_DNS")
+	}
+	if nameserverIP == "" {
+		var err error
+		nameserverIP, err = runtime.GetContainerBridgeIP("coredns")
+		if err != nil {
+			return nil, err
+		}
 	}
 	kl.nameserverIP = nameserverIP
+	kl.hostIP = deps.HostIP
+	if kl.hostIP == "" {
+		hostIP, err := utils.GetHostIP()
+		if err != nil {
+			return nil, err
+		}
+		kl.hostIP = hostIP
+	}
+	kl.lastStatuses = make(map[v1.UID]*v1.PodStatus)
+	kl.evictedPods = make(map[v1.UID]string)
+	kl.imageStates = make(map[v1.UID]map[string]*v1.ContainerStateWaiting)
+	kl.restartTimers = make(map[v1.UID]*restartTimer)
+	kl.staticPodPath = deps.StaticPodPath
 
 	kl.nodeName = nodeName
 	kl.podManger = kubepod.NewPodManager()
-	kl.kubeClient = kubeClient
-	kl.runtimeManager = runtime.NewRuntimeManager(nameserverIP)
+	kl.kubeClient = deps.KubeClient
+	kl.runtimeManager = deps.RuntimeManager
+	if kl.runtimeManager == nil {
+		runtimeManager, err := runtime.NewRuntimeManager(nameserverIP)
+		if err != nil {
+			return nil, err
+		}
+		kl.runtimeManager = runtimeManager
+	}
 	kl.cache = runtime.NewCache()
 	kl.pleg = pleg.NewPLEG(kl.runtimeManager, kl.cache)
 	kl.podWorkers = NewPodWorkers(kl, kl.cache)
+	kl.probeManager = prober.NewManager(kl.runtimeManager, kl.cache)
+	kl.backOff = newBackOff(initialBackOff, maxBackOff)
+	kl.imageBackOff = newBackOff(initialBackOff, maxBackOff)
+	kl.hookRunner = lifecycle.NewHandlerRunner(kl.runtimeManager)
+	kl.node = deps.Node
+	kl.volumeManager = deps.VolumeManager
+	if kl.volumeManager == nil {
+		kl.volumeManager = volume.NewManager(runtime.VolumesRootDir, volume.NewMounter())
+	}
+	kl.admitHandlers = lifecycle.NewPodAdmitHandlers(func() *v1.Node { return kl.node })
 
-	kl.metricsCollector = kubemetrics.NewMetricsCollector()
-	kl.metricsCollector.Run()
+	evictionConfig := deps.EvictionConfig
+	if evictionConfig == nil {
+		var err error
+		evictionConfig, err = eviction.ConfigFromEnv()
+		if err != nil {
+			return nil, err
+		}
+	}
+	kl.statsProvider = deps.StatsProvider
+	if kl.statsProvider == nil {
+		kl.statsProvider = eviction.NewStatsProvider(kl.runtimeManager)
+	}
+	kl.evictionManager = eviction.NewManager(evictionConfig, kl.statsProvider, kl.getActivePods, kl.evictPod, kl.updateNodeConditions)
+
+	imageGCPolicy := deps.ImageGCPolicy
+	if imageGCPolicy == nil {
+		var err error
+		imageGCPolicy, err = images.PolicyFromEnv()
+		if err != nil {
+			return nil, err
+		}
+	}
+	kl.imageGCManager = images.NewImageGCManager(kl.runtimeManager, kl.imageFsStats, imageGCPolicy)
+
+	kl.metricsCollector = deps.MetricsCollector
+	if kl.metricsCollector == nil {
+		kl.metricsCollector = kubemetrics.NewMetricsCollector()
+	}
 	log.Println("Kubelet initialized.")
 	return kl, nil
 }
@@ -56,12 +180,134 @@ func NewMainKubelet(nodeName string, kubeClient client.KubeletClient) (*Kubelet,
 func (kl *Kubelet) Run(ctx context.Context, wg *sync.WaitGroup, updates <-chan types.PodUpdate) {
 	log.Println("Kubelet running...")
 	// TODO 启动各种组件
+	kl.metricsCollector.Run()
+	// 需在pleg启动前完成，使已有容器的事件能找到对应的pod
+	if !kl.recoverState(ctx) {
+		kl.DoCleanUp()
+		wg.Done()
+		return
+	}
 	kl.pleg.Start()
+	go kl.configVolumeRefreshLoop(ctx)
+	go kl.localVolumeLoop(ctx)
+	go kl.mirrorPodLoop(ctx)
+	go kl.evictionManager.Start(ctx)
+	go kl.imageGCManager.Start(ctx)
 	// kl.statusManager.Start()
 	log.Println("Managers started.")
 	kl.syncLoop(ctx, wg, updates)
 }
 
+// kubelet重启后，接管apiserver中仍分配到本节点或可重新绑定的pod，删除其余的pod，返回false表示ctx已结束
+func (kl *Kubelet) recoverState(ctx context.Context) bool {
+	var runtimePods []*runtime.Pod
+	var desiredPods []*v1.Pod
+	for {
+		var err error
+		runtimePods, err = kl.runtimeManager.RecoverPods()
+		if err == nil {
+			desiredPods, err = kl.getDesiredPods()
+		}
+		if err == nil {
+			var rebound []*v1.Pod
+			rebound, err = kl.rebindPods(runtimePods, desiredPods)
+			desiredPods = append(desiredPods, rebound...)
+		}
+		if err == nil {
+			break
+		}
+		log.Printf("Failed to recover kubelet state: %v, retrying...\n", err)
+		select {
+		case <-time.After(recoverRetryPeriod):
+		case <-ctx.Done():
+			return false
+		}
+	}
+	desired := make(map[v1.UID]*v1.Pod)
+	for _, pod := range desiredPods {
+		desired[pod.UID] = pod
+	}
+	for _, runtimePod := range runtimePods {
+		pod, ok := desired[runtimePod.ID]
+		if ok && v1.IsPodTerminating(pod) {
+			// 留给删除流程执行preStop并确认删除
+			continue
+		}
+		if !ok {
+			log.Printf("Killing orphaned pod %v.\n", runtimePod.Name)
+			if err := kl.runtimeManager.DeletePod(runtimePod.ID, orphanPodGracePeriod); err != nil {
+				log.Printf("Failed to kill orphaned pod %v: %v\n", runtimePod.Name, err)
+			}
+			continue
+		}
+		// 之后HandlePodAdditions见到该pod时不再创建
+		log.Printf("Adopting running pod %v.\n", pod.Name)
+		kl.podManger.UpdatePod(pod)
+		kl.probeManager.AddPod(pod)
+	}
+	// kubelet停止期间被删除的pod的卷目录
+	active := make(map[v1.UID]bool, len(desired))
+	for uid := range desired {
+		active[uid] = true
+	}
+	if err := kl.volumeManager.CleanupOrphanedPodVolumes(active); err != nil {
+		log.Printf("Failed to clean up orphaned pod volumes: %v\n", err)
+	}
+	return true
+}
+
+// kubelet正常停止时注销节点会解除其pod的绑定，按运行时中的PodID找回仍未被调度的pod，重新绑定到本节点
+func (kl *Kubelet) rebindPods(runtimePods []*runtime.Pod, desiredPods []*v1.Pod) ([]*v1.Pod, error) {
+	desired := make(map[v1.UID]bool, len(desiredPods))
+	for _, pod := range desiredPods {
+		desired[pod.UID] = true
+	}
+	var rebound []*v1.Pod
+	for _, runtimePod := range runtimePods {
+		if desired[runtimePod.ID] {
+			continue
+		}
+		pod, err := kl.kubeClient.GetPod(runtimePod.Name, runtimePod.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		// 同名的新pod或已被调度到其他节点的pod仍作为孤儿删除
+		if pod == nil || pod.UID != runtimePod.ID || pod.Spec.NodeName != "" || v1.IsPodTerminating(pod) {
+			continue
+		}
+		if err = kl.kubeClient.BindPod(pod, kl.nodeName); err != nil {
+			log.Printf("Failed to rebind pod %v: %v\n", pod.Name, err)
+			continue
+		}
+		log.Printf("Pod %v rebound to node %v.\n", pod.Name, kl.nodeName)
+		pod.Spec.NodeName = kl.nodeName
+		rebound = append(rebound, pod)
+	}
+	return rebound, nil
+}
+
+// apiserver中分配到本节点的pod与静态pod，不包括镜像pod
+func (kl *Kubelet) getDesiredPods() ([]*v1.Pod, error) {
+	nodePods, err := kl.kubeClient.GetPodsByNodeName(kl.nodeName)
+	if err != nil {
+		return nil, err
+	}
+	var pods []*v1.Pod
+	for _, pod := range nodePods {
+		if !v1.IsMirrorPod(pod) {
+			pods = append(pods, pod)
+		}
+	}
+	if kl.staticPodPath == "" {
+		return pods, nil
+	}
+	staticPods, err := config.ReadStaticPods(kl.staticPodPath, kl.nodeName)
+	if err != nil {
+		return nil, err
+	}
+	return append(pods, staticPods...), nil
+}
+
 func (kl *Kubelet) syncLoop(ctx context.Context, wg *sync.WaitGroup, updates <-chan types.PodUpdate) {
 	defer wg.Done()
 	log.Println("Sync loop started.")
@@ -102,6 +348,13 @@ func (kl *Kubelet) syncLoopIteration(ctx context.Context, configCh <-chan types.
 			return true
 		}
 		kl.HandlePodLifecycleEvent(pod, e)
+	case podUID := <-kl.probeManager.Updates():
+		pod, ok := kl.podManger.GetPodByUid(podUID)
+		if !ok {
+			return true
+		}
+		log.Printf("Readiness of pod %v changed.\n", pod.Name)
+		kl.podWorkers.UpdatePod(pod, types.SyncPodStatus)
 	case <-syncCh:
 		// TODO 定时同步Pod信息到metrics collector
 		allPods, err := kl.runtimeManager.GetAllPods()
@@ -137,9 +390,23 @@ func (kl *Kubelet) HandlePodAdditions(pods []*v1.Pod) {
 	utils.SortPodsByCreationTime(pods)
 	for _, pod := range pods {
 		// log.Printf("new pod %v: %v.\n", i, pod.Name)
+		if _, ok := kl.podManger.GetPodByUid(pod.UID); ok {
+			// 重启前已创建的pod，只需同步状态
+			kl.podManger.UpdatePod(pod)
+			kl.podWorkers.UpdatePod(pod, types.SyncPodAdopt)
+			continue
+		}
+		// 已结束的pod（包括被驱逐的）不再启动
+		if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			log.Printf("Skipping finished pod %v.\n", pod.Name)
+			continue
+		}
+		if !kl.canAdmitPod(pod) {
+			continue
+		}
 		kl.podManger.UpdatePod(pod)
-		// TODO 检查pod是否可以被admit
 		kl.podWorkers.UpdatePod(pod, types.SyncPodCreate)
+		kl.probeManager.AddPod(pod)
 	}
 }
 
@@ -147,24 +414,37 @@ func (kl *Kubelet) HandlePodDeletions(pods []*v1.Pod) {
 	log.Println("Handling pod deletions...")
 	for i, pod := range pods {
 		log.Printf("deleted pod %v: %v.\n", i, pod.Name)
+		if _, ok := kl.podManger.GetPodByUid(pod.UID); !ok {
+			// 没有pod worker，直接终止并确认删除
+			if v1.IsPodTerminating(pod) {
+				go func() {
+					if err := kl.killPod(pod); err != nil {
+						log.Printf("Failed to kill pod %v: %v\n", pod.Name, err)
+					}
+				}()
+			}
+			continue
+		}
 		kl.podManger.DeletePod(pod)
+		kl.probeManager.RemovePod(pod)
+		kl.deleteLastApiStatus(pod.UID)
+		kl.deleteEvictionMessage(pod.UID)
+		kl.deleteImageStates(pod)
+		kl.cancelRestart(pod.UID)
+		for _, c := range pod.Spec.Containers {
+			kl.backOff.Remove(backOffKey(pod.UID, c.Name))
+		}
 		kl.podWorkers.UpdatePod(pod, types.SyncPodKill)
 	}
 }
 
 func (kl *Kubelet) HandlePodLifecycleEvent(pod *v1.Pod, event *pleg.PodLifecycleEvent) {
 	log.Println("Handling pod lifecycle events...")
-	//if event.Type == pleg.ContainerRemoved {
-	//	if pod.Spec.RestartPolicy == v1.RestartPolicyAlways || pod.Spec.RestartPolicy == v1.RestartPolicyOnFailure {
-	//		kl.podWorkers.UpdatePod(pod, types.SyncPodRecreate)
-	//		return
-	//	}
-	//}
-	if event.Type == pleg.ContainerDied && pod.Spec.RestartPolicy == v1.RestartPolicyAlways {
+	if event.Type == pleg.ContainerDied {
+		// 由kubelet按重启策略逐个判断是否重启容器
 		kl.podWorkers.UpdatePod(pod, types.SyncPodRecreate)
 		return
 	}
-	// TODO 实现OnFailure策略
 	kl.podWorkers.UpdatePod(pod, types.SyncPodSync)
 }
 
@@ -172,6 +452,7 @@ func (kl *Kubelet) DoCleanUp() {
 	log.Println("Kubelet cleanup started.")
 	// TODO 停止各种组件
 	kl.pleg.Stop()
+	kl.probeManager.Stop()
 	// unregister node
 	err := kl.kubeClient.UnregisterNode(kl.nodeName)
 	if err != nil {
@@ -182,99 +463,245 @@ func (kl *Kubelet) DoCleanUp() {
 
 func (kl *Kubelet) SyncPod(pod *v1.Pod, syncPodType types.SyncPodType, podStatus *runtime.PodStatus) {
 	switch syncPodType {
-	case types.SyncPodCreate:
+	case types.SyncPodCreate, types.SyncPodRetryCreate:
 		log.Printf("Creating pod %v using container manager.\n", pod.Name)
-		err := kl.runtimeManager.AddPod(pod)
+		resolved, err := kl.preparePod(pod, "")
+		if err != nil {
+			log.Printf("Failed to prepare pod %v: %v\n", pod.Name, err)
+			return
+		}
+		if !kl.pullImages(pod) {
+			return
+		}
+		err = kl.runtimeManager.AddPod(resolved)
 		if err != nil {
 			log.Printf("Failed to create pod %v: %v\n", pod.Name, err)
 			return
 		}
 		log.Printf("Pod %v created.\n", pod.Name)
-	case types.SyncPodSync:
+		if hasDownwardAPIVolumes(pod) {
+			// pod ip在sandbox创建后才可知，立即写入而不等待定期刷新
+			if podStatus, err := kl.runtimeManager.GetPodStatus(pod.UID, pod.Name, pod.Namespace); err == nil {
+				kl.refreshDownwardAPIVolumes(pod, podStatus)
+			}
+		}
+		names := make([]string, 0, len(pod.Spec.Containers))
+		for _, c := range pod.Spec.Containers {
+			names = append(names, c.Name)
+		}
+		kl.runPostStartHooks(pod, names...)
+	case types.SyncPodSync, types.SyncPodStatus:
 		log.Printf("Syncing pod %v\n", pod.Name)
 		if podStatus == nil {
 			log.Printf("Pod %v status is nil.\n", pod.Name)
 			return
 		}
-		apiStatus := kl.computeApiStatus(pod, podStatus)
-		if apiStatus == nil {
-			// log.Printf("Pod %v status is unknown.\n", pod.Name)
+		kl.updateApiStatus(pod, podStatus)
+	case types.SyncPodAdopt:
+		log.Printf("Syncing adopted pod %v\n", pod.Name)
+		if podStatus == nil {
+			log.Printf("Pod %v status is nil.\n", pod.Name)
 			return
 		}
-		err := kl.kubeClient.UpdatePodStatus(pod, apiStatus)
-		log.Printf("Pod %v syncing to apiserver. Phase: %v\n", pod.Name, apiStatus.Phase)
-		if err != nil {
-			log.Printf("Failed to update pod %v status to api server: %v\n", pod.Name, err)
+		// 有容器被重新创建时，之后的ContainerStarted事件会同步状态
+		if missing := missingContainers(pod, podStatus); len(missing) > 0 {
+			kl.recreateMissingContainers(pod, podStatus, missing)
 			return
 		}
-		log.Printf("Pod %v synced\n", pod.Name)
+		kl.updateApiStatus(pod, podStatus)
 	case types.SyncPodKill:
 		log.Printf("Killing pod %v\n", pod.Name)
-		err := kl.runtimeManager.DeletePod(pod.UID)
+		err := kl.killPod(pod)
 		if err != nil {
 			log.Printf("Failed to kill pod %v: %v\n", pod.Name, err)
 			return
 		}
 		log.Printf("Pod %v killed.\n", pod.Name)
 	case types.SyncPodRecreate:
-		log.Printf("Recreating pod %v\n", pod.Name)
-		err := kl.runtimeManager.RestartPod(pod)
-		if err != nil {
-			log.Printf("Failed to recreate pod %v: %v\n", pod.Name, err)
+		log.Printf("Restarting dead containers of pod %v\n", pod.Name)
+		if podStatus == nil {
+			log.Printf("Pod %v status is nil.\n", pod.Name)
 			return
 		}
-		log.Printf("Pod %v recreated.\n", pod.Name)
+		// 有容器被重启时，之后的ContainerStarted事件会同步状态
+		if _, evicted := kl.getEvictionMessage(pod.UID); evicted || kl.restartContainers(pod, podStatus) == 0 {
+			kl.updateApiStatus(pod, podStatus)
+		}
 	default:
 		log.Printf("SyncPodType %v is not implemented.\n", syncPodType)
 	}
 }
 
-func (kl *Kubelet) computeApiStatus(pod *v1.Pod, podStatus *runtime.PodStatus) *v1.PodStatus {
-	running := 0
-	exited := 0
-	succeeded := 0
-	failed := 0
+// 准备pod的卷与环境变量，返回交给runtime创建的副本
+func (kl *Kubelet) preparePod(pod *v1.Pod, podIP string) (*v1.Pod, error) {
+	err := kl.volumeManager.SetUpPodVolumes(pod)
+	if err == nil {
+		err = kl.syncConfigVolumes(pod)
+	}
+	if err == nil {
+		err = kl.syncDownwardAPIVolumes(pod, podIP)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("prepare volumes: %v", err)
+	}
+	resolved, err := kl.resolveConfigEnv(pod)
+	if err != nil {
+		return nil, fmt.Errorf("resolve env: %v", err)
+	}
+	// status.hostIP由runtime从副本中读取
+	resolved.Status.HostIP = kl.hostIP
+	resolved, err = kl.resolvePersistentVolumes(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("resolve persistent volumes: %v", err)
+	}
+	return resolved, nil
+}
 
-	if len(podStatus.ContainerStatuses) != len(pod.Spec.Containers) {
-		log.Printf("Pod %v has untracked container!\n", podStatus.Name)
-		return nil
+// spec中有而运行时中没有的容器，例如kubelet停止期间被删除的容器
+func missingContainers(pod *v1.Pod, podStatus *runtime.PodStatus) []string {
+	var missing []string
+	for _, c := range pod.Spec.Containers {
+		if !slices.ContainsFunc(podStatus.ContainerStatuses, func(cs *runtime.ContainerStatus) bool {
+			return cs.Name == c.Name
+		}) {
+			missing = append(missing, c.Name)
+		}
 	}
+	return missing
+}
 
+// 在原sandbox中重新创建缺失的容器，其余容器不受影响；无法在原sandbox中创建时删除并重新创建整个pod
+func (kl *Kubelet) recreateMissingContainers(pod *v1.Pod, podStatus *runtime.PodStatus, missing []string) {
+	log.Printf("Recreating missing containers %v of pod %v.\n", missing, pod.Name)
+	created, err := kl.createMissingContainers(pod, podStatus, missing)
+	if err == nil {
+		kl.runPostStartHooks(pod, created...)
+		return
+	}
+	log.Printf("Failed to recreate missing containers of pod %v: %v, recreating the pod.\n", pod.Name, err)
+	if err = kl.runtimeManager.DeletePod(pod.UID, 0); err != nil {
+		log.Printf("Failed to delete pod %v: %v\n", pod.Name, err)
+		return
+	}
+	kl.SyncPod(pod, types.SyncPodCreate, nil)
+}
+
+func (kl *Kubelet) createMissingContainers(pod *v1.Pod, podStatus *runtime.PodStatus, missing []string) ([]string, error) {
 	podIP := ""
-	if podStatus.IPs != nil && len(podStatus.IPs) > 0 {
+	if len(podStatus.IPs) > 0 {
 		podIP = podStatus.IPs[0]
 	}
+	resolved, err := kl.preparePod(pod, podIP)
+	if err != nil {
+		return nil, err
+	}
+	secrets := kl.getPullSecrets(pod)
+	for i := range pod.Spec.Containers {
+		c := &pod.Spec.Containers[i]
+		if !slices.Contains(missing, c.Name) {
+			continue
+		}
+		if waiting := kl.ensureImageExists(pod, c, secrets); waiting != nil {
+			return nil, fmt.Errorf("%s: %s", waiting.Reason, waiting.Message)
+		}
+	}
+	return kl.runtimeManager.CreateMissingContainers(resolved)
+}
 
-	for _, containerStatus := range podStatus.ContainerStatuses {
-		if containerStatus.State == runtime.ContainerStateRunning {
-			running++
-		} else if containerStatus.State == runtime.ContainerStateExited {
-			exited++
-			if containerStatus.ExitCode == 0 {
-				succeeded++
-			} else {
-				failed++
-			}
+func (kl *Kubelet) updateApiStatus(pod *v1.Pod, podStatus *runtime.PodStatus) {
+	apiStatus := kl.computeApiStatus(pod, podStatus)
+	err := kl.kubeClient.UpdatePodStatus(pod, apiStatus)
+	log.Printf("Pod %v syncing to apiserver. Phase: %v\n", pod.Name, apiStatus.Phase)
+	if err != nil {
+		log.Printf("Failed to update pod %v status to api server: %v\n", pod.Name, err)
+		return
+	}
+	kl.setLastApiStatus(pod.UID, apiStatus)
+	log.Printf("Pod %v synced\n", pod.Name)
+}
+
+// 按重启策略重启已退出的容器，处于退避期的容器在退避结束后重试，返回重启的容器数
+func (kl *Kubelet) restartContainers(pod *v1.Pod, podStatus *runtime.PodStatus) int {
+	restarted := 0
+	for _, cs := range podStatus.ContainerStatuses {
+		if !shouldRestartContainer(pod, cs) {
+			continue
 		}
+		key := backOffKey(pod.UID, cs.Name)
+		if kl.backOff.IsInBackOffSince(key, cs.FinishedAt) {
+			delay := kl.backOff.Get(key) - time.Since(cs.FinishedAt)
+			log.Printf("Back-off %v restarting failed container %v in pod %v.\n", kl.backOff.Get(key), cs.Name, pod.Name)
+			kl.scheduleRestart(pod.UID, delay)
+			continue
+		}
+		kl.backOff.Next(key, cs.FinishedAt)
+		if err := kl.runtimeManager.RestartContainer(pod.UID, cs.Name); err != nil {
+			log.Printf("Failed to restart container %v in pod %v: %v\n", cs.Name, pod.Name, err)
+			continue
+		}
+		log.Printf("Container %v in pod %v restarted.\n", cs.Name, pod.Name)
+		kl.runPostStartHooks(pod, cs.Name)
+		restarted++
 	}
-	if running != 0 {
-		return &v1.PodStatus{
-			Phase: v1.PodRunning,
-			PodIP: podIP,
+	return restarted
+}
+
+type restartTimer struct {
+	timer    *time.Timer
+	deadline time.Time
+}
+
+// 退避结束后重新同步pod，已有更早到期的定时器时无需再设置
+func (kl *Kubelet) scheduleRestart(podUID v1.UID, delay time.Duration) {
+	kl.restartTimersLock.Lock()
+	defer kl.restartTimersLock.Unlock()
+	deadline := time.Now().Add(delay)
+	if rt, ok := kl.restartTimers[podUID]; ok {
+		if !rt.deadline.After(deadline) {
+			return
 		}
-	} else if exited == len(podStatus.ContainerStatuses) {
-		if failed > 0 {
-			return &v1.PodStatus{
-				Phase: v1.PodFailed,
-				PodIP: podIP,
-			}
+		rt.timer.Stop()
+	}
+	rt := &restartTimer{deadline: deadline}
+	rt.timer = time.AfterFunc(delay, func() {
+		kl.restartTimersLock.Lock()
+		if kl.restartTimers[podUID] != rt {
+			// 已被取消或替换
+			kl.restartTimersLock.Unlock()
+			return
 		}
-		return &v1.PodStatus{
-			Phase: v1.PodSucceeded,
-			PodIP: podIP,
+		delete(kl.restartTimers, podUID)
+		kl.restartTimersLock.Unlock()
+		// 使用最新的pod，pod已删除时不再重启
+		pod, ok := kl.podManger.GetPodByUid(podUID)
+		if !ok {
+			return
 		}
-	} else {
-		log.Printf("Pod %v status unknown!\n", podStatus.Name)
-		return nil
+		kl.podWorkers.UpdatePod(pod, types.SyncPodRecreate)
+	})
+	kl.restartTimers[podUID] = rt
+}
+
+func (kl *Kubelet) cancelRestart(podUID v1.UID) {
+	kl.restartTimersLock.Lock()
+	defer kl.restartTimersLock.Unlock()
+	if rt, ok := kl.restartTimers[podUID]; ok {
+		rt.timer.Stop()
+		delete(kl.restartTimers, podUID)
+	}
+}
+
+func shouldRestartContainer(pod *v1.Pod, cs *runtime.ContainerStatus) bool {
+	if cs.State != runtime.ContainerStateExited {
+		return false
+	}
+	switch pod.Spec.RestartPolicy {
+	case v1.RestartPolicyNever:
+		return false
+	case v1.RestartPolicyOnFailure:
+		return cs.ExitCode != 0
+	default:
+		// 未指定时与k8s一致，默认为Always
+		return true
 	}
 }
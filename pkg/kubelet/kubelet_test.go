@@ -0,0 +1,812 @@
+package kubelet
+
+import (
+	"context"
+	"fmt"
+	v1 "minikubernetes/pkg/api/v1"
+	"minikubernetes/pkg/kubelet/runtime"
+	"minikubernetes/pkg/kubelet/types"
+	"minikubernetes/pkg/kubelet/volume"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeKubeClient struct {
+	lock     sync.Mutex
+	pods     []*v1.Pod
+	statuses map[v1.UID]*v1.PodStatus
+	// 最近一次上报的节点状态
+	nodeStatus *v1.NodeStatus
+	// 以namespace/name为key
+	claims  map[string]*v1.PersistentVolumeClaim
+	volumes map[string]*v1.PersistentVolume
+	classes map[string]*v1.StorageClass
+}
+
+func (c *fakeKubeClient) GetPodsByNodeName(nodeId string) ([]*v1.Pod, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	var pods []*v1.Pod
+	for _, pod := range c.pods {
+		if pod.Spec.NodeName == nodeId {
+			pods = append(pods, pod)
+		}
+	}
+	return pods, nil
+}
+
+func (c *fakeKubeClient) UpdatePodStatus(pod *v1.Pod, status *v1.PodStatus) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.statuses[pod.UID] = status
+	return nil
+}
+
+func (c *fakeKubeClient) UpdateNodeStatus(nodeName string, status *v1.NodeStatus) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.nodeStatus = status
+	return nil
+}
+
+func (c *fakeKubeClient) RegisterNode(address string, node *v1.Node) (*v1.Node, error) {
+	return node, nil
+}
+
+func (c *fakeKubeClient) UnregisterNode(nodeName string) error {
+	return nil
+}
+
+func (c *fakeKubeClient) GetConfigMap(name, namespace string) (*v1.ConfigMap, error) {
+	return nil, nil
+}
+
+func (c *fakeKubeClient) GetSecret(name, namespace string) (*v1.Secret, error) {
+	return nil, nil
+}
+
+func (c *fakeKubeClient) GetPod(name, namespace string) (*v1.Pod, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, pod := range c.pods {
+		if pod.Name == name && pod.Namespace == namespace {
+			return pod, nil
+		}
+	}
+	return nil, nil
+}
+
+func (c *fakeKubeClient) BindPod(pod *v1.Pod, nodeName string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, p := range c.pods {
+		if p.Name == pod.Name && p.Namespace == pod.Namespace {
+			if p.Spec.NodeName != "" {
+				return fmt.Errorf("pod %s is already bound to node %s", p.Name, p.Spec.NodeName)
+			}
+			p.Spec.NodeName = nodeName
+			return nil
+		}
+	}
+	return fmt.Errorf("pod %s not found", pod.Name)
+}
+
+func (c *fakeKubeClient) CreateMirrorPod(pod *v1.Pod) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.pods = append(c.pods, pod)
+	return nil
+}
+
+func (c *fakeKubeClient) DeletePod(name, namespace string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.pods = slices.DeleteFunc(c.pods, func(pod *v1.Pod) bool {
+		return pod.Name == name && pod.Namespace == namespace
+	})
+	return nil
+}
+
+func (c *fakeKubeClient) GetPersistentVolumeClaim(name, namespace string) (*v1.PersistentVolumeClaim, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.claims[namespace+"/"+name], nil
+}
+
+func (c *fakeKubeClient) GetPersistentVolume(name string) (*v1.PersistentVolume, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.volumes[name], nil
+}
+
+func (c *fakeKubeClient) GetAllPersistentVolumes() ([]*v1.PersistentVolume, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	var volumes []*v1.PersistentVolume
+	for _, pv := range c.volumes {
+		copied := *pv
+		volumes = append(volumes, &copied)
+	}
+	return volumes, nil
+}
+
+func (c *fakeKubeClient) UpdatePersistentVolume(pv *v1.PersistentVolume) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.volumes[pv.Name] = pv
+	return nil
+}
+
+func (c *fakeKubeClient) DeletePersistentVolume(name string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.volumes, name)
+	return nil
+}
+
+func (c *fakeKubeClient) GetStorageClass(name string) (*v1.StorageClass, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.classes[name], nil
+}
+
+func (c *fakeKubeClient) getStatus(uid v1.UID) *v1.PodStatus {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.statuses[uid]
+}
+
+type fakeMetricsCollector struct{}
+
+func (fakeMetricsCollector) SetPodInfo(podStats []*runtime.PodStatus) {}
+
+func (fakeMetricsCollector) Run() {}
+
+type testKubelet struct {
+	*Kubelet
+	runtime    *runtime.FakeRuntimeManager
+	kubeClient *fakeKubeClient
+	updates    chan types.PodUpdate
+}
+
+func newTestKubelet(t *testing.T) *testKubelet {
+	return newTestKubeletWithState(t, runtime.NewFakeRuntimeManager(), nil)
+}
+
+// 启动使用fake运行时的sync loop，pleg不自动relist，由测试调用relist驱动
+// rm中已有的pod与apiPods用于模拟kubelet重启
+func newTestKubeletWithState(t *testing.T, rm *runtime.FakeRuntimeManager, apiPods []*v1.Pod) *testKubelet {
+	kc := &fakeKubeClient{
+		pods:     apiPods,
+		statuses: make(map[v1.UID]*v1.PodStatus),
+		claims:   make(map[string]*v1.PersistentVolumeClaim),
+		volumes:  make(map[string]*v1.PersistentVolume),
+		classes:  make(map[string]*v1.StorageClass),
+	}
+	kl, err := NewMainKubelet("node-0", &Dependencies{
+		KubeClient:       kc,
+		RuntimeManager:   rm,
+		MetricsCollector: fakeMetricsCollector{},
+		NameserverIP:     "10.96.0.10",
+		HostIP:           "192.168.1.10",
+		VolumeManager:    volume.NewManager(t.TempDir(), volume.NewMounter()),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tk := &testKubelet{Kubelet: kl, runtime: rm, kubeClient: kc, updates: make(chan types.PodUpdate)}
+	ctx, cancel := context.WithCancel(context.Background())
+	if !kl.recoverState(ctx) {
+		t.Fatal("failed to recover state")
+	}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go kl.syncLoop(ctx, &wg, tk.updates)
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+	return tk
+}
+
+func (tk *testKubelet) relist() {
+	tk.pleg.(interface{ Relist() }).Relist()
+}
+
+// 反复relist直到cond成立
+func (tk *testKubelet) waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s, runtime calls: %v", what, tk.runtime.GetCalls())
+		}
+		tk.relist()
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func (tk *testKubelet) waitForPhase(t *testing.T, pod *v1.Pod, phase v1.PodPhase) *v1.PodStatus {
+	t.Helper()
+	var status *v1.PodStatus
+	tk.waitFor(t, "phase "+string(phase), func() bool {
+		status = tk.kubeClient.getStatus(pod.UID)
+		return status != nil && status.Phase == phase
+	})
+	return status
+}
+
+func (tk *testKubelet) countCalls(call string) int {
+	count := 0
+	for _, c := range tk.runtime.GetCalls() {
+		if c == call {
+			count++
+		}
+	}
+	return count
+}
+
+func newTestPod(name string, policy v1.RestartPolicy) *v1.Pod {
+	pod := &v1.Pod{}
+	pod.Name = name
+	pod.Namespace = "default"
+	pod.UID = v1.UID("uid-" + name)
+	pod.Spec.NodeName = "node-0"
+	pod.Spec.RestartPolicy = policy
+	pod.Spec.Containers = []v1.Container{{Name: "c", Image: "alpine:latest"}}
+	return pod
+}
+
+func TestSyncLoopPodLifecycle(t *testing.T) {
+	tk := newTestKubelet(t)
+	pod := newTestPod("pod", v1.RestartPolicyAlways)
+	tk.updates <- types.PodUpdate{Op: types.ADD, Pods: []*v1.Pod{pod}}
+
+	status := tk.waitForPhase(t, pod, v1.PodRunning)
+	if status.PodIP == "" || status.HostIP != "192.168.1.10" {
+		t.Errorf("status: podIP = %q, hostIP = %q", status.PodIP, status.HostIP)
+	}
+	if len(status.ContainerStatuses) != 1 || !status.ContainerStatuses[0].Ready {
+		t.Errorf("container statuses = %+v", status.ContainerStatuses)
+	}
+
+	tk.updates <- types.PodUpdate{Op: types.DELETE, Pods: []*v1.Pod{pod}}
+	tk.waitFor(t, "pod deletion", func() bool {
+		return tk.countCalls("DeletePod pod 30s") == 1
+	})
+}
+
+func TestRestartPolicy(t *testing.T) {
+	tests := []struct {
+		policy   v1.RestartPolicy
+		exitCode int
+		restart  bool
+		phase    v1.PodPhase
+	}{
+		{v1.RestartPolicyAlways, 0, true, v1.PodRunning},
+		{v1.RestartPolicyOnFailure, 1, true, v1.PodRunning},
+		{v1.RestartPolicyOnFailure, 0, false, v1.PodSucceeded},
+		{v1.RestartPolicyNever, 1, false, v1.PodFailed},
+	}
+	for _, test := range tests {
+		t.Run(string(test.policy), func(t *testing.T) {
+			tk := newTestKubelet(t)
+			pod := newTestPod("pod", test.policy)
+			tk.updates <- types.PodUpdate{Op: types.ADD, Pods: []*v1.Pod{pod}}
+			tk.waitForPhase(t, pod, v1.PodRunning)
+
+			if err := tk.runtime.ExitContainer(pod.UID, "c", test.exitCode); err != nil {
+				t.Fatal(err)
+			}
+			if !test.restart {
+				status := tk.waitForPhase(t, pod, test.phase)
+				if status.ContainerStatuses[0].State.Terminated == nil {
+					t.Errorf("container state = %+v", status.ContainerStatuses[0].State)
+				}
+				if slices.Contains(tk.runtime.GetCalls(), "RestartContainer pod/c") {
+					t.Errorf("container should not be restarted")
+				}
+				return
+			}
+			var status *v1.PodStatus
+			tk.waitFor(t, "restarted container status", func() bool {
+				status = tk.kubeClient.getStatus(pod.UID)
+				return status.ContainerStatuses[0].RestartCount == 1 && status.ContainerStatuses[0].State.Running != nil
+			})
+			last := status.ContainerStatuses[0].LastTerminationState.Terminated
+			if last == nil || last.ExitCode != int32(test.exitCode) {
+				t.Errorf("last termination = %+v", status.ContainerStatuses[0].LastTerminationState)
+			}
+			if status.Phase != test.phase {
+				t.Errorf("phase = %v, want %v", status.Phase, test.phase)
+			}
+		})
+	}
+}
+
+func TestRestartBackOff(t *testing.T) {
+	tk := newTestKubelet(t)
+	pod := newTestPod("pod", v1.RestartPolicyAlways)
+	tk.updates <- types.PodUpdate{Op: types.ADD, Pods: []*v1.Pod{pod}}
+	tk.waitForPhase(t, pod, v1.PodRunning)
+
+	// 第一次退出立即重启，第二次退出进入退避
+	for i := 1; i <= 2; i++ {
+		if err := tk.runtime.ExitContainer(pod.UID, "c", 1); err != nil {
+			t.Fatal(err)
+		}
+		if i == 1 {
+			// 等待pleg观察到容器重新运行
+			tk.waitFor(t, "first restart", func() bool {
+				status := tk.kubeClient.getStatus(pod.UID)
+				return status.ContainerStatuses[0].RestartCount == 1 && status.ContainerStatuses[0].State.Running != nil
+			})
+		}
+	}
+	tk.waitFor(t, "CrashLoopBackOff", func() bool {
+		status := tk.kubeClient.getStatus(pod.UID)
+		waiting := status.ContainerStatuses[0].State.Waiting
+		return waiting != nil && waiting.Reason == "CrashLoopBackOff"
+	})
+	if n := tk.countCalls("RestartContainer pod/c"); n != 1 {
+		t.Errorf("restarts = %v, want 1 during back-off", n)
+	}
+}
+
+func TestRestartTimers(t *testing.T) {
+	tk := newTestKubelet(t)
+	pod := newTestPod("pod", v1.RestartPolicyAlways)
+	tk.updates <- types.PodUpdate{Op: types.ADD, Pods: []*v1.Pod{pod}}
+	tk.waitForPhase(t, pod, v1.PodRunning)
+
+	// 每次退避期内的同步都会请求重试，只保留最早到期的一个定时器
+	for i := 0; i < 3; i++ {
+		tk.scheduleRestart(pod.UID, time.Hour)
+	}
+	tk.scheduleRestart(pod.UID, time.Minute)
+	tk.restartTimersLock.Lock()
+	rt, n := tk.restartTimers[pod.UID], len(tk.restartTimers)
+	tk.restartTimersLock.Unlock()
+	if n != 1 || time.Until(rt.deadline) > time.Minute {
+		t.Fatalf("restart timers = %v, deadline in %v", n, time.Until(rt.deadline))
+	}
+
+	// 删除pod时取消定时器
+	tk.updates <- types.PodUpdate{Op: types.DELETE, Pods: []*v1.Pod{pod}}
+	tk.waitFor(t, "restart timer cancelled", func() bool {
+		tk.restartTimersLock.Lock()
+		defer tk.restartTimersLock.Unlock()
+		return len(tk.restartTimers) == 0
+	})
+}
+
+func TestGracefulTermination(t *testing.T) {
+	tk := newTestKubelet(t)
+	var lock sync.Mutex
+	var hooks []string
+	tk.runtime.ExecFunc = func(containerID string, cmd []string) (int, []byte, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		hooks = append(hooks, cmd[0])
+		// preStop完成前容器不能被停止
+		if cmd[0] == "pre-stop" && tk.countCalls("DeletePod pod 10s") != 0 {
+			t.Errorf("preStop hook ran after the pod was deleted")
+		}
+		return 0, nil, nil
+	}
+	pod := newTestPod("pod", v1.RestartPolicyAlways)
+	grace := int64(10)
+	pod.Spec.TerminationGracePeriodSeconds = &grace
+	pod.Spec.Containers[0].Lifecycle = &v1.Lifecycle{
+		PostStart: &v1.LifecycleHandler{Exec: &v1.ExecAction{Command: []string{"post-start"}}},
+		PreStop:   &v1.LifecycleHandler{Exec: &v1.ExecAction{Command: []string{"pre-stop"}}},
+	}
+	tk.kubeClient.pods = []*v1.Pod{pod}
+	tk.updates <- types.PodUpdate{Op: types.ADD, Pods: []*v1.Pod{pod}}
+	tk.waitForPhase(t, pod, v1.PodRunning)
+	// postStart在后台执行，等其完成后再删除pod
+	tk.waitFor(t, "postStart hook", func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return slices.Contains(hooks, "post-start")
+	})
+
+	terminating := *pod
+	now := time.Now()
+	terminating.DeletionTimestamp = &now
+	tk.updates <- types.PodUpdate{Op: types.DELETE, Pods: []*v1.Pod{&terminating}}
+	tk.waitFor(t, "pod deletion", func() bool {
+		return tk.countCalls("DeletePod pod 10s") == 1
+	})
+	lock.Lock()
+	if !slices.Equal(hooks, []string{"post-start", "pre-stop"}) {
+		t.Errorf("hooks = %v", hooks)
+	}
+	lock.Unlock()
+	// 容器停止后确认删除apiserver中的pod
+	tk.waitFor(t, "pod finalized", func() bool {
+		pods, _ := tk.kubeClient.GetPodsByNodeName("node-0")
+		return len(pods) == 0
+	})
+}
+
+func TestPostStartHookDoesNotBlockSync(t *testing.T) {
+	tk := newTestKubelet(t)
+	release := make(chan struct{})
+	defer close(release)
+	tk.runtime.ExecFunc = func(containerID string, cmd []string) (int, []byte, error) {
+		<-release
+		return 0, nil, nil
+	}
+	pod := newTestPod("pod", v1.RestartPolicyAlways)
+	pod.Spec.Containers[0].Lifecycle = &v1.Lifecycle{
+		PostStart: &v1.LifecycleHandler{Exec: &v1.ExecAction{Command: []string{"post-start"}}},
+	}
+	tk.updates <- types.PodUpdate{Op: types.ADD, Pods: []*v1.Pod{pod}}
+	// postStart未完成时pod worker仍能同步状态
+	tk.waitForPhase(t, pod, v1.PodRunning)
+}
+
+func TestPostStartHookFailure(t *testing.T) {
+	tk := newTestKubelet(t)
+	tk.runtime.ExecFunc = func(containerID string, cmd []string) (int, []byte, error) {
+		return 1, []byte("failed"), nil
+	}
+	pod := newTestPod("pod", v1.RestartPolicyAlways)
+	pod.Spec.Containers[0].Lifecycle = &v1.Lifecycle{
+		PostStart: &v1.LifecycleHandler{Exec: &v1.ExecAction{Command: []string{"post-start"}}},
+	}
+	tk.updates <- types.PodUpdate{Op: types.ADD, Pods: []*v1.Pod{pod}}
+	tk.waitFor(t, "container killed", func() bool {
+		return tk.countCalls("KillContainer pod/c") >= 1
+	})
+}
+
+func TestEvictPod(t *testing.T) {
+	tk := newTestKubelet(t)
+	pod := newTestPod("pod", v1.RestartPolicyAlways)
+	tk.updates <- types.PodUpdate{Op: types.ADD, Pods: []*v1.Pod{pod}}
+	tk.waitForPhase(t, pod, v1.PodRunning)
+
+	if err := tk.evictPod(pod, 0, "The node was low on resource: memory."); err != nil {
+		t.Fatal(err)
+	}
+	status := tk.waitForPhase(t, pod, v1.PodFailed)
+	if status.Reason != "Evicted" || status.Message != "The node was low on resource: memory." {
+		t.Errorf("status reason = %q, message = %q", status.Reason, status.Message)
+	}
+	if len(tk.getActivePods()) != 0 {
+		t.Errorf("evicted pod is still active")
+	}
+	if n := tk.countCalls("DeletePod pod 0s"); n != 1 {
+		t.Errorf("pod deletions = %v, want 1", n)
+	}
+	// 被驱逐的pod不会被重新创建
+	tk.updates <- types.PodUpdate{Op: types.ADD, Pods: []*v1.Pod{pod}}
+	tk.relist()
+	if n := tk.countCalls("AddPod pod"); n != 1 {
+		t.Errorf("pod created %v times, want 1", n)
+	}
+}
+
+func (tk *testKubelet) waitForWaitingReason(t *testing.T, pod *v1.Pod, reason string) *v1.ContainerStateWaiting {
+	t.Helper()
+	var waiting *v1.ContainerStateWaiting
+	tk.waitFor(t, "waiting reason "+reason, func() bool {
+		status := tk.kubeClient.getStatus(pod.UID)
+		if status == nil || len(status.ContainerStatuses) == 0 {
+			return false
+		}
+		waiting = status.ContainerStatuses[0].State.Waiting
+		return waiting != nil && waiting.Reason == reason
+	})
+	return waiting
+}
+
+func TestImagePullPolicy(t *testing.T) {
+	tk := newTestKubelet(t)
+	tk.runtime.AddImage("nginx:1.25", 0)
+	present := newTestPod("present", v1.RestartPolicyAlways)
+	present.Spec.Containers[0].Image = "nginx:1.25"
+	never := newTestPod("never", v1.RestartPolicyAlways)
+	never.Spec.Containers[0].Image = "busybox:1.36"
+	never.Spec.Containers[0].ImagePullPolicy = v1.PullNever
+	tk.updates <- types.PodUpdate{Op: types.ADD, Pods: []*v1.Pod{present, never}}
+
+	tk.waitForPhase(t, present, v1.PodRunning)
+	tk.waitForWaitingReason(t, never, ErrImageNeverPull)
+	if n := tk.countCalls("PullImage nginx:1.25") + tk.countCalls("PullImage busybox:1.36"); n != 0 {
+		t.Errorf("images pulled %v times, want 0", n)
+	}
+	if n := tk.countCalls("AddPod never"); n != 0 {
+		t.Errorf("pod created without its image")
+	}
+}
+
+func TestImagePullBackOff(t *testing.T) {
+	tk := newTestKubelet(t)
+	tk.runtime.PullImageFunc = func(image string, auth *runtime.AuthConfig) error {
+		return fmt.Errorf("manifest unknown")
+	}
+	pod := newTestPod("pod", v1.RestartPolicyAlways)
+	tk.updates <- types.PodUpdate{Op: types.ADD, Pods: []*v1.Pod{pod}}
+
+	waiting := tk.waitForWaitingReason(t, pod, ErrImagePull)
+	if !strings.Contains(waiting.Message, "manifest unknown") {
+		t.Errorf("waiting message = %q", waiting.Message)
+	}
+	// 退避期间不重新拉取
+	tk.podWorkers.UpdatePod(pod, types.SyncPodRetryCreate)
+	tk.waitForWaitingReason(t, pod, ImagePullBackOff)
+	if n := tk.countCalls("PullImage alpine:latest"); n != 1 {
+		t.Errorf("image pulled %v times, want 1", n)
+	}
+
+	tk.runtime.PullImageFunc = nil
+	tk.imageBackOff.now = func() time.Time { return time.Now().Add(initialBackOff) }
+	tk.podWorkers.UpdatePod(pod, types.SyncPodRetryCreate)
+	tk.waitForPhase(t, pod, v1.PodRunning)
+	if n := tk.countCalls("AddPod pod"); n != 1 {
+		t.Errorf("pod created %v times, want 1", n)
+	}
+}
+
+func TestAdmitPod(t *testing.T) {
+	tk := newTestKubelet(t)
+	tk.node = &v1.Node{
+		ObjectMeta: v1.ObjectMeta{Name: "node-0"},
+		Spec:       v1.NodeSpec{Taints: []v1.Taint{{Key: "dedicated", Value: "gpu", Effect: v1.TaintEffectNoSchedule}}},
+		Status:     v1.NodeStatus{Allocatable: v1.ResourceList{v1.ResourceCPU: "1", v1.ResourceMemory: "1Gi"}},
+	}
+	toleration := v1.Toleration{Key: "dedicated", Operator: v1.TolerationOpEqual, Value: "gpu", Effect: v1.TaintEffectNoSchedule}
+	running := newTestPod("running", v1.RestartPolicyAlways)
+	running.Spec.Tolerations = []v1.Toleration{toleration}
+	running.Spec.Containers[0].Ports = []v1.ContainerPort{{ContainerPort: 80, HostPort: 8080}}
+	running.Spec.Containers[0].Resources.Requests = v1.ResourceList{v1.ResourceCPU: "600m"}
+	tk.updates <- types.PodUpdate{Op: types.ADD, Pods: []*v1.Pod{running}}
+	tk.waitForPhase(t, running, v1.PodRunning)
+
+	untolerated := newTestPod("untolerated", v1.RestartPolicyAlways)
+	outOfCPU := newTestPod("out-of-cpu", v1.RestartPolicyAlways)
+	outOfCPU.Spec.Tolerations = []v1.Toleration{toleration}
+	outOfCPU.Spec.Containers[0].Resources.Requests = v1.ResourceList{v1.ResourceCPU: "500m"}
+	portConflict := newTestPod("port-conflict", v1.RestartPolicyAlways)
+	portConflict.Spec.Tolerations = []v1.Toleration{toleration}
+	portConflict.Spec.Containers[0].Ports = []v1.ContainerPort{{ContainerPort: 8080, HostPort: 8080, Protocol: v1.ProtocolTCP}}
+	badVolume := newTestPod("bad-volume", v1.RestartPolicyAlways)
+	badVolume.Spec.Tolerations = []v1.Toleration{toleration}
+	badVolume.Spec.Volumes = []v1.Volume{{Name: "data"}}
+	// 不同协议的hostPort不冲突
+	udp := newTestPod("udp", v1.RestartPolicyAlways)
+	udp.Spec.Tolerations = []v1.Toleration{{Key: "dedicated", Operator: v1.TolerationOpExists}}
+	udp.Spec.Containers[0].Ports = []v1.ContainerPort{{ContainerPort: 53, HostPort: 8080, Protocol: v1.ProtocolUDP}}
+	tk.updates <- types.PodUpdate{Op: types.ADD, Pods: []*v1.Pod{untolerated, outOfCPU, portConflict, badVolume, udp}}
+
+	tk.waitForPhase(t, udp, v1.PodRunning)
+	for pod, reason := range map[*v1.Pod]string{
+		untolerated:  "UntoleratedTaint",
+		outOfCPU:     "OutOfcpu",
+		portConflict: "HostPortConflict",
+		badVolume:    "UnsupportedVolume",
+	} {
+		status := tk.waitForPhase(t, pod, v1.PodFailed)
+		if status.Reason != reason || status.Message == "" {
+			t.Errorf("pod %v: reason = %q, message = %q, want reason %q", pod.Name, status.Reason, status.Message, reason)
+		}
+		if n := tk.countCalls("AddPod " + pod.Name); n != 0 {
+			t.Errorf("rejected pod %v was created", pod.Name)
+		}
+	}
+}
+
+func TestRecoverState(t *testing.T) {
+	rm := runtime.NewFakeRuntimeManager()
+	adopted := newTestPod("adopted", v1.RestartPolicyAlways)
+	orphan := newTestPod("orphan", v1.RestartPolicyAlways)
+	for _, pod := range []*v1.Pod{adopted, orphan} {
+		if err := rm.AddPod(pod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tk := newTestKubeletWithState(t, rm, []*v1.Pod{adopted})
+	if n := tk.countCalls("DeletePod orphan 2s"); n != 1 {
+		t.Errorf("orphan deletions = %v, want 1", n)
+	}
+
+	// 重启后apiserver的pod重新作为新增pod下发
+	tk.updates <- types.PodUpdate{Op: types.ADD, Pods: []*v1.Pod{adopted}}
+	status := tk.waitForPhase(t, adopted, v1.PodRunning)
+	if status.PodIP != "10.32.0.1" {
+		t.Errorf("pod ip = %q, want the ip assigned before restart", status.PodIP)
+	}
+	if n := tk.countCalls("AddPod adopted"); n != 1 {
+		t.Errorf("adopted pod created %v times, want 1", n)
+	}
+}
+
+func TestRecoverStateAfterUnregister(t *testing.T) {
+	rm := runtime.NewFakeRuntimeManager()
+	unbound := newTestPod("unbound", v1.RestartPolicyAlways)
+	scheduled := newTestPod("scheduled", v1.RestartPolicyAlways)
+	for _, pod := range []*v1.Pod{unbound, scheduled} {
+		if err := rm.AddPod(pod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 正常停止时注销节点解除了绑定，其中一个pod在kubelet停止期间已被调度到其他节点
+	unbound.Spec.NodeName = ""
+	scheduled.Spec.NodeName = "node-1"
+	tk := newTestKubeletWithState(t, rm, []*v1.Pod{unbound, scheduled})
+	if n := tk.countCalls("DeletePod unbound 2s"); n != 0 {
+		t.Errorf("unbound pod deleted %v times, want it adopted", n)
+	}
+	if unbound.Spec.NodeName != "node-0" {
+		t.Errorf("unbound pod bound to %q, want node-0", unbound.Spec.NodeName)
+	}
+	if n := tk.countCalls("DeletePod scheduled 2s"); n != 1 {
+		t.Errorf("scheduled pod deletions = %v, want 1", n)
+	}
+	if _, ok := tk.podManger.GetPodByUid(unbound.UID); !ok {
+		t.Error("rebound pod is not adopted")
+	}
+}
+
+func TestAdoptPodMissingContainers(t *testing.T) {
+	rm := runtime.NewFakeRuntimeManager()
+	pod := newTestPod("pod", v1.RestartPolicyAlways)
+	pod.Spec.Containers = append(pod.Spec.Containers, v1.Container{Name: "sidecar", Image: "alpine:latest"})
+	if err := rm.AddPod(pod); err != nil {
+		t.Fatal(err)
+	}
+	// kubelet停止期间容器被删除
+	if err := rm.RemoveContainer(pod.UID, "sidecar"); err != nil {
+		t.Fatal(err)
+	}
+	tk := newTestKubeletWithState(t, rm, []*v1.Pod{pod})
+	tk.updates <- types.PodUpdate{Op: types.ADD, Pods: []*v1.Pod{pod}}
+	tk.waitFor(t, "missing container recreated", func() bool {
+		return tk.countCalls("CreateContainer pod/sidecar") == 1
+	})
+	status := tk.waitForPhase(t, pod, v1.PodRunning)
+	if len(status.ContainerStatuses) != 2 {
+		t.Errorf("container statuses = %v, want 2", len(status.ContainerStatuses))
+	}
+	// 其余容器与pod ip保持不变
+	if n := tk.countCalls("AddPod pod"); n != 1 {
+		t.Errorf("pod created %v times, want 1", n)
+	}
+	if status.PodIP != "10.32.0.1" {
+		t.Errorf("pod ip = %q, want the ip assigned before restart", status.PodIP)
+	}
+}
+
+func TestSyncMirrorPods(t *testing.T) {
+	tk := newTestKubelet(t)
+	static := newTestPod("static-node-0", v1.RestartPolicyAlways)
+	static.Annotations = map[string]string{v1.ConfigSourceAnnotationKey: v1.ConfigSourceFile}
+	stale := newTestPod("removed-node-0", v1.RestartPolicyAlways)
+	stale.Annotations = map[string]string{v1.MirrorPodAnnotationKey: "uid-removed"}
+	tk.kubeClient.pods = []*v1.Pod{stale}
+	tk.updates <- types.PodUpdate{Op: types.ADD, Pods: []*v1.Pod{static}}
+	tk.waitForPhase(t, static, v1.PodRunning)
+
+	tk.syncMirrorPods()
+	pods, _ := tk.kubeClient.GetPodsByNodeName("node-0")
+	if len(pods) != 1 || pods[0].Name != static.Name || pods[0].Annotations[v1.MirrorPodAnnotationKey] != string(static.UID) {
+		t.Fatalf("mirror pods = %+v", pods)
+	}
+	if pods[0].UID != "" || !v1.IsStaticPod(pods[0]) {
+		t.Errorf("mirror pod meta = %+v", pods[0].ObjectMeta)
+	}
+}
+
+func TestPersistentVolumes(t *testing.T) {
+	tk := newTestKubelet(t)
+	rootDir := t.TempDir()
+	tk.kubeClient.classes["local"] = &v1.StorageClass{
+		ObjectMeta:  v1.ObjectMeta{Name: "local"},
+		Provisioner: v1.LocalPathProvisioner,
+		Parameters:  map[string]string{v1.StorageClassPathParameter: rootDir},
+	}
+	path := filepath.Join(rootDir, "pvc-claim-1")
+	tk.kubeClient.volumes["pvc-1"] = &v1.PersistentVolume{
+		ObjectMeta: v1.ObjectMeta{Name: "pvc-1", Annotations: map[string]string{v1.ProvisionedByAnnotationKey: v1.LocalPathProvisioner}},
+		Spec: v1.PersistentVolumeSpec{
+			StorageClassName:              "local",
+			AccessModes:                   []v1.PersistentVolumeAccessMode{v1.ReadOnlyMany},
+			PersistentVolumeReclaimPolicy: v1.PersistentVolumeReclaimDelete,
+			Local:                         &v1.LocalVolumeSource{Path: path},
+			NodeName:                      "node-0",
+			ClaimRef:                      &v1.PersistentVolumeClaimReference{Namespace: "default", Name: "data", UID: "claim-1"},
+		},
+		Status: v1.PersistentVolumeStatus{Phase: v1.VolumePending},
+	}
+	tk.syncLocalPersistentVolumes()
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("volume dir not created: %v", err)
+	}
+	if phase := tk.kubeClient.volumes["pvc-1"].Status.Phase; phase != v1.VolumeAvailable {
+		t.Fatalf("phase = %v, want Available", phase)
+	}
+
+	pod := newTestPod("pv-pod", v1.RestartPolicyAlways)
+	pod.Spec.Volumes = []v1.Volume{{
+		Name:         "data",
+		VolumeSource: v1.VolumeSource{PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: "data"}},
+	}}
+	pod.Spec.Containers[0].VolumeMounts = []v1.VolumeMount{{Name: "data", MountPath: "/data"}}
+	if _, err := tk.resolvePersistentVolumes(pod); err == nil {
+		t.Fatal("expected error for unbound claim")
+	}
+	tk.kubeClient.claims["default/data"] = &v1.PersistentVolumeClaim{
+		ObjectMeta: v1.ObjectMeta{Name: "data", Namespace: "default", UID: "claim-1"},
+		Spec:       v1.PersistentVolumeClaimSpec{VolumeName: "pvc-1"},
+		Status:     v1.PersistentVolumeClaimStatus{Phase: v1.ClaimBound},
+	}
+	resolved, err := tk.resolvePersistentVolumes(pod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source := resolved.Spec.Volumes[0].HostPath; source == nil || source.Path != path {
+		t.Errorf("volume source = %+v, want hostPath %v", resolved.Spec.Volumes[0].VolumeSource, path)
+	}
+	// ReadOnlyMany的pv只读挂载，且不修改原pod
+	if !resolved.Spec.Containers[0].VolumeMounts[0].ReadOnly || pod.Spec.Containers[0].VolumeMounts[0].ReadOnly {
+		t.Errorf("mount readOnly = %v, original = %v, want true/false",
+			resolved.Spec.Containers[0].VolumeMounts[0].ReadOnly, pod.Spec.Containers[0].VolumeMounts[0].ReadOnly)
+	}
+
+	tk.kubeClient.volumes["pvc-1"].Status.Phase = v1.VolumeReleased
+	tk.syncLocalPersistentVolumes()
+	if _, err = os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("volume dir not removed: %v", err)
+	}
+	if _, ok := tk.kubeClient.volumes["pvc-1"]; ok {
+		t.Error("released volume not deleted")
+	}
+}
+
+func TestPersistentVolumeDeleteOutsideRootDir(t *testing.T) {
+	tk := newTestKubelet(t)
+	rootDir := t.TempDir()
+	tk.kubeClient.classes["local"] = &v1.StorageClass{
+		ObjectMeta:  v1.ObjectMeta{Name: "local"},
+		Provisioner: v1.LocalPathProvisioner,
+		Parameters:  map[string]string{v1.StorageClassPathParameter: rootDir},
+	}
+	other := t.TempDir()
+	for name, path := range map[string]string{
+		// 不在根目录下
+		"outside": filepath.Join(other, "pvc-claim-1"),
+		// 不是pvc-<uid>
+		"name": filepath.Join(rootDir, "data"),
+		// 试图跳出根目录
+		"escape": rootDir + "/../" + filepath.Base(other) + "/pvc-claim-1",
+		// 根目录本身
+		"root": rootDir,
+	} {
+		if err := os.MkdirAll(path, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		tk.kubeClient.volumes[name] = &v1.PersistentVolume{
+			ObjectMeta: v1.ObjectMeta{Name: name, Annotations: map[string]string{v1.ProvisionedByAnnotationKey: v1.LocalPathProvisioner}},
+			Spec: v1.PersistentVolumeSpec{
+				StorageClassName:              "local",
+				PersistentVolumeReclaimPolicy: v1.PersistentVolumeReclaimDelete,
+				Local:                         &v1.LocalVolumeSource{Path: path},
+				NodeName:                      "node-0",
+				ClaimRef:                      &v1.PersistentVolumeClaimReference{Namespace: "default", Name: "data", UID: "claim-1"},
+			},
+			Status: v1.PersistentVolumeStatus{Phase: v1.VolumeReleased},
+		}
+		tk.syncLocalPersistentVolumes()
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s: dir %v removed: %v", name, path, err)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package lifecycle
+
+import (
+	"fmt"
+	v1 "minikubernetes/pkg/api/v1"
+)
+
+// pod被拒绝的原因，与k8s一致
+const (
+	OutOfCPU          = "OutOfcpu"
+	OutOfMemory       = "OutOfmemory"
+	HostPortConflict  = "HostPortConflict"
+	UntoleratedTaint  = "UntoleratedTaint"
+	UnsupportedVolume = "UnsupportedVolume"
+)
+
+type PodAdmitAttributes struct {
+	Pod *v1.Pod
+	// 节点上其余正在运行的pod
+	OtherPods []*v1.Pod
+}
+
+type PodAdmitResult struct {
+	Admit   bool
+	Reason  string
+	Message string
+}
+
+// PodAdmitHandler 在kubelet启动pod前检查pod能否在本节点运行
+type PodAdmitHandler interface {
+	Admit(attrs *PodAdmitAttributes) PodAdmitResult
+}
+
+type PodAdmitHandlers []PodAdmitHandler
+
+// Admit 依次执行各handler，返回第一个拒绝结果
+func (handlers PodAdmitHandlers) Admit(attrs *PodAdmitAttributes) PodAdmitResult {
+	for _, handler := range handlers {
+		if result := handler.Admit(attrs); !result.Admit {
+			return result
+		}
+	}
+	return PodAdmitResult{Admit: true}
+}
+
+// NewPodAdmitHandlers 返回kubelet默认的admit检查，getNode返回本节点最新的信息
+func NewPodAdmitHandlers(getNode func() *v1.Node) PodAdmitHandlers {
+	return PodAdmitHandlers{
+		&volumeAdmitHandler{},
+		&taintAdmitHandler{getNode: getNode},
+		&resourceAdmitHandler{getNode: getNode},
+		&hostPortAdmitHandler{},
+	}
+}
+
+// 节点剩余的可分配资源需满足pod的requests
+type resourceAdmitHandler struct {
+	getNode func() *v1.Node
+}
+
+func (h *resourceAdmitHandler) Admit(attrs *PodAdmitAttributes) PodAdmitResult {
+	node := h.getNode()
+	if node == nil {
+		return PodAdmitResult{Admit: true}
+	}
+	allocatable := v1.GetNodeAllocatable(node)
+	cpu, memory := v1.GetPodRequests(attrs.Pod)
+	var usedCPU, usedMemory int64
+	for _, other := range attrs.OtherPods {
+		c, m := v1.GetPodRequests(other)
+		usedCPU += c
+		usedMemory += m
+	}
+	if s, ok := allocatable[v1.ResourceCPU]; ok {
+		if total, err := v1.ParseCPU(s); err == nil && cpu > 0 && usedCPU+cpu > total {
+			return PodAdmitResult{
+				Reason:  OutOfCPU,
+				Message: fmt.Sprintf("Node didn't have enough resource: cpu, requested: %vm, used: %vm, allocatable: %vm", cpu, usedCPU, total),
+			}
+		}
+	}
+	if s, ok := allocatable[v1.ResourceMemory]; ok {
+		if total, err := v1.ParseMemory(s); err == nil && memory > 0 && usedMemory+memory > total {
+			return PodAdmitResult{
+				Reason:  OutOfMemory,
+				Message: fmt.Sprintf("Node didn't have enough resource: memory, requested: %v, used: %v, allocatable: %v", memory, usedMemory, total),
+			}
+		}
+	}
+	return PodAdmitResult{Admit: true}
+}
+
+// 相同协议的hostPort不能被两个pod同时使用
+type hostPortAdmitHandler struct{}
+
+func (h *hostPortAdmitHandler) Admit(attrs *PodAdmitAttributes) PodAdmitResult {
+	ports := v1.GetHostPorts(attrs.Pod)
+	if len(ports) == 0 {
+		return PodAdmitResult{Admit: true}
+	}
+	used := make(map[v1.ContainerPort]string)
+	for _, other := range attrs.OtherPods {
+		for _, port := range v1.GetHostPorts(other) {
+			used[v1.ContainerPort{HostPort: port.HostPort, Protocol: port.Protocol}] = other.Name
+		}
+	}
+	for _, port := range ports {
+		key := v1.ContainerPort{HostPort: port.HostPort, Protocol: port.Protocol}
+		if owner, ok := used[key]; ok {
+			return PodAdmitResult{
+				Reason:  HostPortConflict,
+				Message: fmt.Sprintf("Host port %v/%v is already in use by pod %v", port.HostPort, port.Protocol, owner),
+			}
+		}
+		// pod自身的容器之间也不能冲突
+		used[key] = attrs.Pod.Name
+	}
+	return PodAdmitResult{Admit: true}
+}
+
+// pod需容忍节点上effect为NoSchedule与NoExecute的污点，静态pod不受污点限制
+type taintAdmitHandler struct {
+	getNode func() *v1.Node
+}
+
+func (h *taintAdmitHandler) Admit(attrs *PodAdmitAttributes) PodAdmitResult {
+	node := h.getNode()
+	if node == nil || v1.IsStaticPod(attrs.Pod) {
+		return PodAdmitResult{Admit: true}
+	}
+	if taint, ok := v1.FindUntoleratedTaint(node.Spec.Taints, attrs.Pod.Spec.Tolerations, v1.IsHardTaint); ok {
+		return PodAdmitResult{
+			Reason:  UntoleratedTaint,
+			Message: fmt.Sprintf("Pod does not tolerate taint %v of node %v", taint.String(), node.Name),
+		}
+	}
+	return PodAdmitResult{Admit: true}
+}
+
+// 每个volume需恰好声明一种kubelet支持的类型，volumeMounts只能引用已声明的volume
+type volumeAdmitHandler struct{}
+
+func (h *volumeAdmitHandler) Admit(attrs *PodAdmitAttributes) PodAdmitResult {
+	pod := attrs.Pod
+	declared := make(map[string]bool)
+	for _, volume := range pod.Spec.Volumes {
+		if n := countVolumeSources(&volume.VolumeSource); n != 1 {
+			return PodAdmitResult{
+				Reason:  UnsupportedVolume,
+				Message: fmt.Sprintf("Volume %v must specify exactly one supported volume type, got %v", volume.Name, n),
+			}
+		}
+		if volume.EmptyDir != nil {
+			if _, err := v1.GetEmptyDirSizeLimit(volume.EmptyDir); err != nil {
+				return PodAdmitResult{
+					Reason:  UnsupportedVolume,
+					Message: fmt.Sprintf("Volume %v: %v", volume.Name, err),
+				}
+			}
+		}
+		declared[volume.Name] = true
+	}
+	for _, c := range append(append([]v1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+		for _, mount := range c.VolumeMounts {
+			if !declared[mount.Name] {
+				return PodAdmitResult{
+					Reason:  UnsupportedVolume,
+					Message: fmt.Sprintf("Container %v mounts undeclared volume %v", c.Name, mount.Name),
+				}
+			}
+			if err := v1.ValidateSubPath(mount.SubPath); err != nil {
+				return PodAdmitResult{
+					Reason:  UnsupportedVolume,
+					Message: fmt.Sprintf("Container %v mounts volume %v: %v", c.Name, mount.Name, err),
+				}
+			}
+		}
+	}
+	return PodAdmitResult{Admit: true}
+}
+
+func countVolumeSources(source *v1.VolumeSource) int {
+	n := 0
+	if source.HostPath != nil {
+		n++
+	}
+	if source.EmptyDir != nil {
+		n++
+	}
+	if source.ConfigMap != nil {
+		n++
+	}
+	if source.Secret != nil {
+		n++
+	}
+	if source.PersistentVolumeClaim != nil {
+		n++
+	}
+	if source.DownwardAPI != nil {
+		n++
+	}
+	return n
+}
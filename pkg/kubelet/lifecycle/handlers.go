@@ -0,0 +1,74 @@
+package lifecycle
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	v1 "minikubernetes/pkg/api/v1"
+	"minikubernetes/pkg/kubelet/runtime"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// HandlerRunner 执行容器的postStart与preStop回调
+type HandlerRunner struct {
+	runtimeManager runtime.RuntimeManager
+}
+
+func NewHandlerRunner(runtimeManager runtime.RuntimeManager) *HandlerRunner {
+	return &HandlerRunner{runtimeManager: runtimeManager}
+}
+
+// Run 同步执行回调，超过timeout视为失败
+func (hr *HandlerRunner) Run(handler *v1.LifecycleHandler, containerID string, podIP string, timeout time.Duration) error {
+	switch {
+	case handler.Exec != nil:
+		exitCode, output, err := hr.runtimeManager.ExecInContainer(containerID, handler.Exec.Command, timeout)
+		if err != nil {
+			return err
+		}
+		if exitCode != 0 {
+			return fmt.Errorf("command %v exited with %v: %s", handler.Exec.Command, exitCode, output)
+		}
+		return nil
+	case handler.HTTPGet != nil:
+		return runHTTPGet(handler.HTTPGet, podIP, timeout)
+	default:
+		return fmt.Errorf("lifecycle handler has no action")
+	}
+}
+
+func runHTTPGet(action *v1.HTTPGetAction, podIP string, timeout time.Duration) error {
+	host := action.Host
+	if host == "" {
+		host = podIP
+	}
+	scheme := "http"
+	if action.Scheme == v1.URISchemeHTTPS {
+		scheme = "https"
+	}
+	url := fmt.Sprintf("%s://%s%s", scheme, net.JoinHostPort(host, strconv.Itoa(int(action.Port))), action.Path)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	for _, header := range action.HTTPHeaders {
+		req.Header.Add(header.Name, header.Value)
+	}
+	client := &http.Client{
+		Timeout:   timeout,
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("HTTP lifecycle hook %s returned status %d", url, resp.StatusCode)
+	}
+	return nil
+}
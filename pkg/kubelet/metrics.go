@@ -0,0 +1,58 @@
+package kubelet
+
+import (
+	"minikubernetes/pkg/kubelet/server"
+)
+
+// 供kubelet server的/metrics接口使用
+func (kl *Kubelet) GetMetrics() []*server.Metric {
+	m := kl.pleg.Metrics()
+	evented := 0.0
+	if m.Evented {
+		evented = 1
+	}
+	return []*server.Metric{
+		{
+			Name:  "kubelet_pleg_evented",
+			Help:  "Whether PLEG is driven by container runtime events.",
+			Type:  "gauge",
+			Value: evented,
+		},
+		{
+			Name:  "kubelet_pleg_relist_total",
+			Help:  "Number of PLEG relists.",
+			Type:  "counter",
+			Value: float64(m.RelistCount),
+		},
+		{
+			Name:  "kubelet_pleg_relist_duration_seconds_total",
+			Help:  "Total time spent in PLEG relists.",
+			Type:  "counter",
+			Value: m.RelistDurationTotal.Seconds(),
+		},
+		{
+			Name:  "kubelet_pleg_last_relist_duration_seconds",
+			Help:  "Duration of the last PLEG relist.",
+			Type:  "gauge",
+			Value: m.LastRelistDuration.Seconds(),
+		},
+		{
+			Name:  "kubelet_pleg_events_total",
+			Help:  "Number of container runtime events handled by PLEG.",
+			Type:  "counter",
+			Value: float64(m.EventCount),
+		},
+		{
+			Name:  "kubelet_pleg_event_lag_seconds_total",
+			Help:  "Total delay between container runtime events and the relists they triggered.",
+			Type:  "counter",
+			Value: m.EventLagTotal.Seconds(),
+		},
+		{
+			Name:  "kubelet_pleg_last_event_lag_seconds",
+			Help:  "Delay between the last container runtime event and the relist it triggered.",
+			Type:  "gauge",
+			Value: m.LastEventLag.Seconds(),
+		},
+	}
+}
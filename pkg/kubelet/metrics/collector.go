@@ -8,7 +8,6 @@ import (
 	"minikubernetes/pkg/kubeclient"
 	"minikubernetes/pkg/kubelet/runtime"
 	"os/exec"
-	"strings"
 	"sync"
 	"time"
 
@@ -55,25 +54,6 @@ func NewMetricsCollector() MetricsCollector {
 	}
 	return newMetricsCollector
 }
-func (mc *metricsCollector) tryStartCAdvisor() {
-	cmd := exec.Command("docker", "run",
-		"--volume=/:/rootfs:ro",
-		"--volume=/var/run:/var/run:rw",
-		"--volume=/sys:/sys:ro",
-		"--volume=/var/lib/docker/:/var/lib/docker:ro",
-		"--publish="+fmt.Sprint(mc.port)+":8080",
-		"--detach=true",
-		"--name=cadvisor",
-		"google/cadvisor:latest")
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	err := cmd.Run()
-	if err != nil {
-		log.Printf("start cadvisor err: %v, stderr: %v", err.Error(), stderr.String())
-	}
-}
-
 func (mc *metricsCollector) Run() {
 	// 运行MetricsCollector
 	mc.init()
@@ -90,9 +70,8 @@ func (mc *metricsCollector) Run() {
 			case <-syncTicker.C:
 				err := mc.CheckAlive()
 				if err != nil {
-					// 尝试启动cadvisor
+					// cadvisor由kubelet作为静态pod运行，见test/kubectl/static/cadvisor.yaml
 					log.Printf(err.Error())
-					mc.tryStartCAdvisor()
 				} else {
 					if mc.podStats != nil {
 
@@ -119,11 +98,6 @@ func (mc *metricsCollector) Run() {
 
 func (mc *metricsCollector) init() {
 	log.Printf("init metrics collector")
-	err := mc.CheckAlive()
-	if err != nil {
-		// 尝试启动cadvisor
-		mc.tryStartCAdvisor()
-	}
 	// 初始化MetricsCollector
 	URLStr := fmt.Sprintf("http://%s:%d", mc.ip, mc.port)
 	client, err := CAdvClient.NewClient(URLStr)
@@ -276,30 +250,12 @@ func (mc *metricsCollector) getMetrics() ([]*v1.PodRawMetrics, error) {
 }
 
 func (mc *metricsCollector) CheckAlive() error {
-	// 检查cadvisor是否存活
-	cmd := exec.Command("docker", "ps")
+	// 检查cadvisor是否存活，容器可能由docker或containerd运行，只检查端口
 	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("nc", "-vz", mc.ip, fmt.Sprintf("%d", mc.port))
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-
 	err := cmd.Run()
-	if err != nil {
-		// cadvisor未启动
-		return fmt.Errorf("cadvisor err: %v, stderr: %v", err.Error(), stderr.String())
-	}
-
-	// 检查是否有cadvisor 字段
-	if !strings.Contains(stdout.String(), "cadvisor") {
-		return fmt.Errorf("cadvisor not found in docker ps")
-	}
-
-	// 检查是否能够获取端口信息
-	cmd = exec.Command("nc", "-vz", mc.ip, fmt.Sprintf("%d", mc.port))
-	stdout.Reset()
-	stderr.Reset()
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	err = cmd.Run()
 	if err != nil {
 		return fmt.Errorf("ping cadvisor err: %v, %v , %v", err.Error(), stderr.String(), stdout.String())
 	}
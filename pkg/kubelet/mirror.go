@@ -0,0 +1,93 @@
+package kubelet
+
+import (
+	"context"
+	"log"
+	v1 "minikubernetes/pkg/api/v1"
+	"minikubernetes/pkg/kubelet/types"
+	"time"
+)
+
+// 检查镜像pod的周期，apiserver恢复或镜像pod被删除后据此重建
+const mirrorPodSyncPeriod = 10 * time.Second
+
+func (kl *Kubelet) mirrorPodLoop(ctx context.Context) {
+	ticker := time.NewTicker(mirrorPodSyncPeriod)
+	defer ticker.Stop()
+	for {
+		kl.syncMirrorPods()
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// 使apiserver中的镜像pod与本节点的静态pod一一对应
+func (kl *Kubelet) syncMirrorPods() {
+	nodePods, err := kl.kubeClient.GetPodsByNodeName(kl.nodeName)
+	if err != nil {
+		log.Printf("Failed to get pods for mirror pod sync: %v\n", err)
+		return
+	}
+	staticPods := make(map[string]*v1.Pod)
+	for _, pod := range kl.podManger.GetPods() {
+		if v1.IsStaticPod(pod) {
+			staticPods[pod.Namespace+"/"+pod.Name] = pod
+		}
+	}
+	existing := make(map[string]*v1.Pod)
+	for _, pod := range nodePods {
+		existing[pod.Namespace+"/"+pod.Name] = pod
+	}
+
+	for key, pod := range existing {
+		if !v1.IsMirrorPod(pod) {
+			continue
+		}
+		// 静态pod已删除或manifest已修改
+		staticPod, ok := staticPods[key]
+		if ok && pod.Annotations[v1.MirrorPodAnnotationKey] == string(staticPod.UID) {
+			continue
+		}
+		log.Printf("Deleting outdated mirror pod %v.\n", key)
+		if err := kl.kubeClient.DeletePod(pod.Name, pod.Namespace); err != nil {
+			log.Printf("Failed to delete mirror pod %v: %v\n", key, err)
+			continue
+		}
+		delete(existing, key)
+	}
+
+	for key, staticPod := range staticPods {
+		if pod, ok := existing[key]; ok {
+			if !v1.IsMirrorPod(pod) {
+				log.Printf("Cannot create mirror pod %v: a pod with the same name exists.\n", key)
+			}
+			continue
+		}
+		log.Printf("Creating mirror pod %v.\n", key)
+		if err := kl.kubeClient.CreateMirrorPod(newMirrorPod(staticPod)); err != nil {
+			log.Printf("Failed to create mirror pod %v: %v\n", key, err)
+			continue
+		}
+		// 立即上报状态，否则要等到下一次生命周期事件
+		kl.podWorkers.UpdatePod(staticPod, types.SyncPodStatus)
+	}
+}
+
+func newMirrorPod(staticPod *v1.Pod) *v1.Pod {
+	mirror := &v1.Pod{
+		TypeMeta:   staticPod.TypeMeta,
+		ObjectMeta: staticPod.ObjectMeta,
+		Spec:       staticPod.Spec,
+	}
+	mirror.Kind = "Pod"
+	mirror.UID = ""
+	mirror.Annotations = make(map[string]string, len(staticPod.Annotations)+1)
+	for k, v := range staticPod.Annotations {
+		mirror.Annotations[k] = v
+	}
+	mirror.Annotations[v1.MirrorPodAnnotationKey] = string(staticPod.UID)
+	return mirror
+}
@@ -0,0 +1,121 @@
+package network
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// 不经过docker时，直接调用weave的CNI插件为网络命名空间配置网络
+const (
+	cniBinDir    = "/opt/cni/bin"
+	cniPlugin    = "weave-net"
+	cniInterface = "eth0"
+	cniConfig    = `{"cniVersion":"0.3.0","name":"weave","type":"weave-net","hairpinMode":true}`
+	// 配置hostPort的链式插件
+	portmapPlugin = "portmap"
+)
+
+// 由portmap插件在节点上配置的端口映射，协议为小写的tcp或udp
+type PortMapping struct {
+	HostPort      int32  `json:"hostPort"`
+	ContainerPort int32  `json:"containerPort"`
+	Protocol      string `json:"protocol"`
+}
+
+type cniResult struct {
+	IPs []struct {
+		Address string `json:"address"`
+	} `json:"ips"`
+}
+
+// 为netnsPath指向的网络命名空间分配ip并配置端口映射，返回分配的ip
+func AttachNetns(containerId string, netnsPath string, portMappings []PortMapping) (string, error) {
+	stdout, err := execCNIPlugin(cniPlugin, "ADD", containerId, netnsPath, []byte(cniConfig))
+	if err != nil {
+		return "", err
+	}
+	var result cniResult
+	if err = json.Unmarshal(stdout, &result); err != nil {
+		return "", fmt.Errorf("cni returns invalid result: %v", err)
+	}
+	ip := ""
+	for _, address := range result.IPs {
+		parsed, _, err := net.ParseCIDR(address.Address)
+		if err == nil && parsed.To4() != nil {
+			ip = parsed.String()
+			break
+		}
+	}
+	if ip == "" {
+		return "", fmt.Errorf("cni returns no ipv4 address: %s", string(stdout))
+	}
+	if len(portMappings) != 0 {
+		config, err := portmapConfig(portMappings, stdout)
+		if err == nil {
+			_, err = execCNIPlugin(portmapPlugin, "ADD", containerId, netnsPath, config)
+		}
+		if err != nil {
+			_, _ = execCNIPlugin(cniPlugin, "DEL", containerId, netnsPath, []byte(cniConfig))
+			return "", err
+		}
+	}
+	return ip, nil
+}
+
+// 先删除端口映射再释放ip，portMappings需与AttachNetns时一致
+func DetachNetns(containerId string, netnsPath string, portMappings []PortMapping) error {
+	if len(portMappings) != 0 {
+		config, err := portmapConfig(portMappings, nil)
+		if err != nil {
+			return err
+		}
+		if _, err = execCNIPlugin(portmapPlugin, "DEL", containerId, netnsPath, config); err != nil {
+			return err
+		}
+	}
+	_, err := execCNIPlugin(cniPlugin, "DEL", containerId, netnsPath, []byte(cniConfig))
+	return err
+}
+
+// portmap从runtimeConfig读取端口映射，ADD时需要上一个插件的结果
+func portmapConfig(portMappings []PortMapping, prevResult []byte) ([]byte, error) {
+	config := map[string]interface{}{
+		"cniVersion":   "0.3.0",
+		"name":         "weave",
+		"type":         portmapPlugin,
+		"capabilities": map[string]bool{"portMappings": true},
+		// 使同节点上的pod也能通过节点ip与hostPort访问
+		"snat":          true,
+		"runtimeConfig": map[string]interface{}{"portMappings": portMappings},
+	}
+	if prevResult != nil {
+		config["prevResult"] = json.RawMessage(prevResult)
+	}
+	return json.Marshal(config)
+}
+
+func execCNIPlugin(plugin string, command string, containerId string, netnsPath string, config []byte) ([]byte, error) {
+	cmd := exec.Command(filepath.Join(cniBinDir, plugin))
+	cmd.Env = append(os.Environ(),
+		"CNI_COMMAND="+command,
+		"CNI_CONTAINERID="+containerId,
+		"CNI_NETNS="+netnsPath,
+		"CNI_IFNAME="+cniInterface,
+		"CNI_PATH="+cniBinDir,
+	)
+	cmd.Stdin = bytes.NewBuffer(config)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		// 插件的错误信息输出在stdout
+		return nil, fmt.Errorf("cni %s %s err: %v, output: %v%v", plugin, command, err.Error(), stdout.String(), stderr.String())
+	}
+	return stdout.Bytes(), nil
+}
@@ -41,3 +41,27 @@ func Detach(containerId string) error {
 	}
 	return nil
 }
+
+// 查询已attach的容器的ip，kubelet重启后用于恢复状态
+// weave ps的输出形如"<容器短id> <mac> <ip/cidr>"
+func LookupIP(containerId string) (string, error) {
+	cmd := exec.Command("weave", "ps", containerId)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		return "", fmt.Errorf("weave ps err: %v, stderr: %v", err.Error(), stderr.String())
+	}
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || !strings.HasPrefix(containerId, fields[0]) {
+			continue
+		}
+		IPString, _, _ := strings.Cut(fields[2], "/")
+		if address := net.ParseIP(IPString); address != nil {
+			return IPString, nil
+		}
+	}
+	return "", fmt.Errorf("container %s is not attached to weave", containerId)
+}
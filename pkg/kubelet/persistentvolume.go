@@ -0,0 +1,165 @@
+package kubelet
+
+import (
+	"context"
+	"fmt"
+	"log"
+	v1 "minikubernetes/pkg/api/v1"
+	"os"
+	"time"
+)
+
+// 同步本节点上local pv的间隔
+const localVolumeSyncPeriod = 10 * time.Second
+
+func hasPersistentVolumeClaims(pod *v1.Pod) bool {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// 将pvc卷解析为pv在本节点上的目录，返回pod的副本
+// pvc卷声明了readOnly或pv只支持ReadOnlyMany时，所有挂载该卷的容器均只读
+func (kl *Kubelet) resolvePersistentVolumes(pod *v1.Pod) (*v1.Pod, error) {
+	if !hasPersistentVolumeClaims(pod) {
+		return pod, nil
+	}
+	resolved := *pod
+	resolved.Spec.Volumes = make([]v1.Volume, len(pod.Spec.Volumes))
+	readOnly := make(map[string]bool)
+	for i, volume := range pod.Spec.Volumes {
+		resolved.Spec.Volumes[i] = volume
+		if volume.PersistentVolumeClaim == nil {
+			continue
+		}
+		pv, err := kl.getBoundPersistentVolume(pod.Namespace, volume.PersistentVolumeClaim.ClaimName)
+		if err != nil {
+			return nil, fmt.Errorf("volume %s: %v", volume.Name, err)
+		}
+		resolved.Spec.Volumes[i].VolumeSource = v1.VolumeSource{
+			HostPath: &v1.HostPathVolumeSource{Path: pv.Spec.Local.Path},
+		}
+		if volume.PersistentVolumeClaim.ReadOnly || v1.IsReadOnlyVolume(pv) {
+			readOnly[volume.Name] = true
+		}
+	}
+	resolved.Spec.InitContainers = withReadOnlyMounts(pod.Spec.InitContainers, readOnly)
+	resolved.Spec.Containers = withReadOnlyMounts(pod.Spec.Containers, readOnly)
+	return &resolved, nil
+}
+
+// pvc需已绑定到本节点上的pv
+func (kl *Kubelet) getBoundPersistentVolume(namespace, claimName string) (*v1.PersistentVolume, error) {
+	claim, err := kl.kubeClient.GetPersistentVolumeClaim(claimName, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if claim == nil {
+		return nil, fmt.Errorf("persistentvolumeclaim %s not found", claimName)
+	}
+	if claim.Status.Phase != v1.ClaimBound {
+		return nil, fmt.Errorf("persistentvolumeclaim %s is not bound", claimName)
+	}
+	pv, err := kl.kubeClient.GetPersistentVolume(claim.Spec.VolumeName)
+	if err != nil {
+		return nil, err
+	}
+	if pv == nil || !v1.IsBoundTo(pv, claim) {
+		return nil, fmt.Errorf("persistentvolume %s of claim %s not found", claim.Spec.VolumeName, claimName)
+	}
+	if pv.Spec.NodeName != kl.nodeName || pv.Spec.Local == nil {
+		return nil, fmt.Errorf("persistentvolume %s is not on node %s", pv.Name, kl.nodeName)
+	}
+	return pv, nil
+}
+
+func withReadOnlyMounts(containers []v1.Container, readOnly map[string]bool) []v1.Container {
+	ret := make([]v1.Container, len(containers))
+	for i, c := range containers {
+		c.VolumeMounts = append([]v1.VolumeMount{}, c.VolumeMounts...)
+		for j := range c.VolumeMounts {
+			if readOnly[c.VolumeMounts[j].Name] {
+				c.VolumeMounts[j].ReadOnly = true
+			}
+		}
+		ret[i] = c
+	}
+	return ret
+}
+
+// 为动态创建到本节点的pv创建目录，删除已释放且回收策略为Delete的pv
+// 只有动态创建的pv会连同目录一起删除，静态pv的目录由用户管理
+// 目录须为provisioner根目录下的pvc-<uid>，否则视为静态pv，只删除pv对象
+func (kl *Kubelet) syncLocalPersistentVolumes() {
+	volumes, err := kl.kubeClient.GetAllPersistentVolumes()
+	if err != nil {
+		log.Printf("Failed to get persistent volumes: %v\n", err)
+		return
+	}
+	for _, pv := range volumes {
+		if pv.Spec.NodeName != kl.nodeName || pv.Spec.Local == nil {
+			continue
+		}
+		switch {
+		case pv.Status.Phase == v1.VolumePending:
+			if err = os.MkdirAll(pv.Spec.Local.Path, os.ModePerm); err != nil {
+				log.Printf("Failed to create dir of persistent volume %v: %v\n", pv.Name, err)
+				continue
+			}
+			pv.Status.Phase = v1.VolumeAvailable
+			if err = kl.kubeClient.UpdatePersistentVolume(pv); err != nil {
+				log.Printf("Failed to update persistent volume %v: %v\n", pv.Name, err)
+				continue
+			}
+			log.Printf("Persistent volume %v is available at %v.\n", pv.Name, pv.Spec.Local.Path)
+		case pv.Status.Phase == v1.VolumeReleased && v1.GetReclaimPolicy(pv) == v1.PersistentVolumeReclaimDelete:
+			_, provisioned := pv.Annotations[v1.ProvisionedByAnnotationKey]
+			if provisioned && !kl.isProvisionedVolumeDir(pv) {
+				log.Printf("Refusing to remove dir %v of persistent volume %v: not created by the provisioner.\n", pv.Spec.Local.Path, pv.Name)
+				provisioned = false
+			}
+			if provisioned {
+				if err = os.RemoveAll(pv.Spec.Local.Path); err != nil {
+					log.Printf("Failed to remove dir of persistent volume %v: %v\n", pv.Name, err)
+					continue
+				}
+			}
+			if err = kl.kubeClient.DeletePersistentVolume(pv.Name); err != nil {
+				log.Printf("Failed to delete persistent volume %v: %v\n", pv.Name, err)
+				continue
+			}
+			log.Printf("Persistent volume %v deleted.\n", pv.Name)
+		}
+	}
+}
+
+// 目录位于默认根目录或pv所属storage class的path下
+func (kl *Kubelet) isProvisionedVolumeDir(pv *v1.PersistentVolume) bool {
+	if v1.IsProvisionedLocalPath(pv, v1.DefaultLocalPathRootDir) {
+		return true
+	}
+	if pv.Spec.StorageClassName == "" {
+		return false
+	}
+	class, err := kl.kubeClient.GetStorageClass(pv.Spec.StorageClassName)
+	if err != nil || class == nil {
+		return false
+	}
+	return v1.IsProvisionedLocalPath(pv, v1.GetLocalPathRootDir(class))
+}
+
+func (kl *Kubelet) localVolumeLoop(ctx context.Context) {
+	ticker := time.NewTicker(localVolumeSyncPeriod)
+	defer ticker.Stop()
+	for {
+		kl.syncLocalPersistentVolumes()
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -2,6 +2,7 @@ package pleg
 
 import (
 	"context"
+	"fmt"
 	"log"
 	v1 "minikubernetes/pkg/api/v1"
 	"minikubernetes/pkg/kubelet/runtime"
@@ -23,7 +24,10 @@ const (
 )
 
 const (
+	// 无法订阅容器事件时的relist周期
 	RelistPeriod time.Duration = 5 * time.Second
+	// 订阅容器事件后relist只作为兜底，防止事件丢失
+	EventedRelistPeriod time.Duration = 60 * time.Second
 )
 
 type PodLifecycleEvent struct {
@@ -37,6 +41,22 @@ type PodLifecycleEventGenerator interface {
 	Start()
 	Watch() chan *PodLifecycleEvent
 	Stop()
+	Metrics() Metrics
+}
+
+// pleg的运行指标
+type Metrics struct {
+	// 是否正在使用容器事件
+	Evented     bool
+	RelistCount int64
+	// 最近一次relist的耗时与累计耗时
+	LastRelistDuration  time.Duration
+	RelistDurationTotal time.Duration
+	// 已处理的容器事件数
+	EventCount int64
+	// 事件从运行时产生到据其完成relist的延迟
+	LastEventLag  time.Duration
+	EventLagTotal time.Duration
 }
 
 type pleg struct {
@@ -51,6 +71,9 @@ type pleg struct {
 	wg     sync.WaitGroup
 
 	cacheLock sync.Mutex
+
+	metricsLock sync.Mutex
+	metrics     Metrics
 }
 
 type podRecord struct {
@@ -70,24 +93,117 @@ func NewPLEG(runtimeManager runtime.RuntimeManager, cache runtime.Cache) PodLife
 	}
 }
 
+// 订阅容器事件并完成首次relist后返回，之后kubelet接管的pod在缓存中已有状态
 func (p *pleg) Start() {
 	log.Printf("Starting PLEG...")
+	ready := make(chan struct{})
 	p.wg.Add(1)
 	go func() {
-		timer := time.NewTimer(RelistPeriod)
-		isStopped := false
-		for !isStopped {
+		defer p.wg.Done()
+		var events <-chan *runtime.ContainerEvent
+		var errs <-chan error
+		cancelWatch := func() {}
+		// 订阅失败时退回周期relist，下一次relist时重试
+		watch := func() {
+			ctx, cancel := context.WithCancel(p.ctx)
+			events, errs = p.runtimeManager.WatchContainerEvents(ctx)
+			cancelWatch = cancel
+			p.setEvented(true)
+		}
+		unwatch := func(err error) {
+			log.Printf("PLEG: Container event stream failed, falling back to periodic relist: %v", err)
+			cancelWatch()
+			events, errs = nil, nil
+			p.setEvented(false)
+		}
+		watch()
+		// 首次relist时sync loop尚未运行，只填充缓存，不发送事件
+		p.relist(false)
+		close(ready)
+		timer := time.NewTimer(EventedRelistPeriod)
+		defer timer.Stop()
+		for {
 			select {
 			case <-timer.C:
+				if events == nil {
+					watch()
+				}
 				p.Relist()
-				timer.Reset(RelistPeriod)
+				timer.Reset(p.relistPeriod())
+			case e, ok := <-events:
+				if !ok {
+					unwatch(fmt.Errorf("event channel closed"))
+					resetTimer(timer, RelistPeriod)
+					continue
+				}
+				p.handleContainerEvents(e, events)
+			case err := <-errs:
+				unwatch(err)
+				resetTimer(timer, RelistPeriod)
 			case <-p.ctx.Done():
-				isStopped = true
+				cancelWatch()
+				log.Printf("PLEG stopped.")
+				return
 			}
 		}
-		log.Printf("PLEG stopped.")
-		p.wg.Done()
 	}()
+	<-ready
+}
+
+func resetTimer(timer *time.Timer, d time.Duration) {
+	if !timer.Stop() {
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
+	timer.Reset(d)
+}
+
+func (p *pleg) relistPeriod() time.Duration {
+	if p.Metrics().Evented {
+		return EventedRelistPeriod
+	}
+	return RelistPeriod
+}
+
+// 合并已经到达的事件，一次relist即可全部处理
+func (p *pleg) handleContainerEvents(first *runtime.ContainerEvent, events <-chan *runtime.ContainerEvent) {
+	batch := []*runtime.ContainerEvent{first}
+	for draining := true; draining; {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				draining = false
+				break
+			}
+			batch = append(batch, e)
+		default:
+			draining = false
+		}
+	}
+	p.Relist()
+	now := time.Now()
+	p.metricsLock.Lock()
+	defer p.metricsLock.Unlock()
+	for _, e := range batch {
+		lag := now.Sub(e.Timestamp)
+		p.metrics.EventCount++
+		p.metrics.LastEventLag = lag
+		p.metrics.EventLagTotal += lag
+	}
+}
+
+func (p *pleg) setEvented(evented bool) {
+	p.metricsLock.Lock()
+	defer p.metricsLock.Unlock()
+	p.metrics.Evented = evented
+}
+
+func (p *pleg) Metrics() Metrics {
+	p.metricsLock.Lock()
+	defer p.metricsLock.Unlock()
+	return p.metrics
 }
 
 func (p *pleg) Watch() chan *PodLifecycleEvent {
@@ -95,6 +211,10 @@ func (p *pleg) Watch() chan *PodLifecycleEvent {
 }
 
 func (p *pleg) Relist() {
+	p.relist(true)
+}
+
+func (p *pleg) relist(sendEvents bool) {
 	log.Printf("PLEG: Relisting...")
 	// TODO: Relist
 	ts := time.Now()
@@ -130,6 +250,9 @@ func (p *pleg) Relist() {
 		// 把current移入old，准备下一次relist
 		p.shiftCurrentToOldById(id)
 		// 发送事件
+		if !sendEvents {
+			continue
+		}
 		for i := range events {
 			if events[i].Type == ContainerChanged {
 				continue
@@ -138,6 +261,13 @@ func (p *pleg) Relist() {
 		}
 	}
 	p.cache.UpdateTime(ts)
+
+	duration := time.Since(ts)
+	p.metricsLock.Lock()
+	p.metrics.RelistCount++
+	p.metrics.LastRelistDuration = duration
+	p.metrics.RelistDurationTotal += duration
+	p.metricsLock.Unlock()
 	log.Printf("PLEG: Relisting done.")
 }
 
@@ -0,0 +1,101 @@
+package pleg
+
+import (
+	v1 "minikubernetes/pkg/api/v1"
+	"minikubernetes/pkg/kubelet/runtime"
+	"testing"
+	"time"
+)
+
+func newTestPod() *v1.Pod {
+	pod := &v1.Pod{}
+	pod.Name = "pod"
+	pod.Namespace = "default"
+	pod.UID = "uid-pod"
+	pod.Spec.Containers = []v1.Container{{Name: "c", Image: "alpine:latest"}}
+	return pod
+}
+
+func expectEvent(t *testing.T, p PodLifecycleEventGenerator, eventType PodLifeCycleEventType) {
+	t.Helper()
+	select {
+	case e := <-p.Watch():
+		if e.Type != eventType || e.PodId != "uid-pod" {
+			t.Fatalf("event = %+v, want %v", e, eventType)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for %v", eventType)
+	}
+}
+
+// 容器事件立即触发relist，无需等待周期
+func TestEventedRelist(t *testing.T) {
+	rm := runtime.NewFakeRuntimeManager()
+	p := NewPLEG(rm, runtime.NewCache())
+	p.Start()
+	defer p.Stop()
+
+	waitEvented(t, p, true)
+	pod := newTestPod()
+	if err := rm.AddPod(pod); err != nil {
+		t.Fatal(err)
+	}
+	expectEvent(t, p, ContainerStarted)
+	if err := rm.ExitContainer(pod.UID, "c", 1); err != nil {
+		t.Fatal(err)
+	}
+	expectEvent(t, p, ContainerDied)
+	if err := rm.DeletePod(pod.UID, 0); err != nil {
+		t.Fatal(err)
+	}
+	expectEvent(t, p, ContainerRemoved)
+
+	// 指标在事件发送后更新
+	deadline := time.Now().Add(2 * time.Second)
+	for p.Metrics().EventCount != 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("metrics = %+v", p.Metrics())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if metrics := p.Metrics(); metrics.RelistCount < 3 || metrics.LastEventLag <= 0 {
+		t.Errorf("metrics = %+v", metrics)
+	}
+}
+
+// 启动时同步relist一次，已有pod的状态立即可用
+func TestInitialRelist(t *testing.T) {
+	rm := runtime.NewFakeRuntimeManager()
+	pod := newTestPod()
+	if err := rm.AddPod(pod); err != nil {
+		t.Fatal(err)
+	}
+	cache := runtime.NewCache()
+	p := NewPLEG(rm, cache)
+	p.Start()
+	defer p.Stop()
+
+	if _, err := cache.GetNewerThan(pod.UID, time.Time{}); err != nil {
+		t.Fatalf("pod status not cached after start: %v", err)
+	}
+	if n := p.Metrics().RelistCount; n != 1 {
+		t.Errorf("relist count = %v, want 1", n)
+	}
+	// 首次relist不发送事件
+	select {
+	case e := <-p.Watch():
+		t.Errorf("unexpected event %+v", e)
+	default:
+	}
+}
+
+func waitEvented(t *testing.T, p PodLifecycleEventGenerator, evented bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for p.Metrics().Evented != evented {
+		if time.Now().After(deadline) {
+			t.Fatalf("evented = %v, want %v", !evented, evented)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -49,7 +49,7 @@ func (pw *podWorkers) UpdatePod(pod *v1.Pod, syncPodType types.SyncPodType) {
 	pw.lock.Lock()
 	defer pw.lock.Unlock()
 	if updateCh, ok := pw.podUpdates[pod.ObjectMeta.UID]; !ok {
-		if syncPodType != types.SyncPodCreate {
+		if syncPodType != types.SyncPodCreate && syncPodType != types.SyncPodAdopt {
 			log.Printf("Pod worker goroutine for pod %s does not exist.", pod.ObjectMeta.UID)
 			return
 		}
@@ -61,11 +61,11 @@ func (pw *podWorkers) UpdatePod(pod *v1.Pod, syncPodType types.SyncPodType) {
 			Pod:         pod,
 		}
 	} else {
-		if syncPodType == types.SyncPodCreate {
+		if syncPodType == types.SyncPodCreate || syncPodType == types.SyncPodAdopt {
 			log.Printf("Pod worker goroutine for pod %s already exists.", pod.ObjectMeta.UID)
 			return
 		}
-		if syncPodType == types.SyncPodSync || syncPodType == types.SyncPodKill || syncPodType == types.SyncPodRecreate {
+		if syncPodType == types.SyncPodSync || syncPodType == types.SyncPodKill || syncPodType == types.SyncPodRecreate || syncPodType == types.SyncPodStatus || syncPodType == types.SyncPodRetryCreate {
 			updateCh <- UpdatePodOptions{
 				SyncPodType: syncPodType,
 				Pod:         pod,
@@ -78,15 +78,28 @@ func (pw *podWorkers) workerLoop(updates <-chan UpdatePodOptions) {
 	log.Println("Pod worker started.")
 	var lastSyncTime time.Time
 	for update := range updates {
-		if update.SyncPodType == types.SyncPodCreate {
+		// 以sync开始的时间为准，sync期间完成的relist（例如容器启动事件触发的）对之后的事件仍然有效
+		var syncStartTime time.Time
+		if update.SyncPodType == types.SyncPodCreate || update.SyncPodType == types.SyncPodRetryCreate {
+			syncStartTime = time.Now()
 			pw.podSyncer.SyncPod(update.Pod, update.SyncPodType, nil)
-		} else if update.SyncPodType == types.SyncPodSync || update.SyncPodType == types.SyncPodRecreate {
+		} else if update.SyncPodType == types.SyncPodSync || update.SyncPodType == types.SyncPodRecreate || update.SyncPodType == types.SyncPodAdopt {
 			status, err := pw.cache.GetNewerThan(update.Pod.ObjectMeta.UID, lastSyncTime)
 			if err != nil {
 				log.Printf("Failed to get pod status for pod %s: %v", update.Pod.ObjectMeta.UID, err)
 				continue
 			}
+			syncStartTime = time.Now()
 			pw.podSyncer.SyncPod(update.Pod, update.SyncPodType, status)
+		} else if update.SyncPodType == types.SyncPodStatus {
+			status, err := pw.cache.Get(update.Pod.ObjectMeta.UID)
+			if err != nil {
+				log.Printf("Failed to get pod status for pod %s: %v", update.Pod.ObjectMeta.UID, err)
+				continue
+			}
+			pw.podSyncer.SyncPod(update.Pod, update.SyncPodType, status)
+			// 不更新lastSyncTime，避免后续的生命周期事件等待更新的缓存
+			continue
 		} else if update.SyncPodType == types.SyncPodKill {
 			pw.podSyncer.SyncPod(update.Pod, update.SyncPodType, nil)
 			pw.lock.Lock()
@@ -96,7 +109,7 @@ func (pw *podWorkers) workerLoop(updates <-chan UpdatePodOptions) {
 		} else {
 			continue
 		}
-		lastSyncTime = time.Now()
+		lastSyncTime = syncStartTime
 	}
 	log.Println("Pod worker stopped.")
 }
@@ -0,0 +1,56 @@
+package kubelet
+
+import (
+	v1 "minikubernetes/pkg/api/v1"
+	"minikubernetes/pkg/kubelet/runtime"
+	"minikubernetes/pkg/kubelet/types"
+	"testing"
+	"time"
+)
+
+type syncCall struct {
+	syncPodType types.SyncPodType
+	status      *runtime.PodStatus
+}
+
+type fakePodSyncer struct {
+	calls chan syncCall
+	// 在SyncPodCreate执行期间调用，用于模拟并发完成的relist
+	onCreate func(pod *v1.Pod)
+}
+
+func (s *fakePodSyncer) SyncPod(pod *v1.Pod, syncPodType types.SyncPodType, podStatus *runtime.PodStatus) {
+	if syncPodType == types.SyncPodCreate && s.onCreate != nil {
+		s.onCreate(pod)
+	}
+	s.calls <- syncCall{syncPodType: syncPodType, status: podStatus}
+}
+
+func TestPodWorkersRelistDuringCreate(t *testing.T) {
+	cache := runtime.NewCache()
+	status := &runtime.PodStatus{ID: "uid-pod"}
+	syncer := &fakePodSyncer{calls: make(chan syncCall, 10)}
+	// 容器启动事件触发的relist在create返回前完成，create之后才结束
+	syncer.onCreate = func(pod *v1.Pod) {
+		cache.Set(pod.UID, status, nil, time.Now())
+		time.Sleep(50 * time.Millisecond)
+	}
+	pw := NewPodWorkers(syncer, cache)
+	pod := newTestPod("pod", v1.RestartPolicyAlways)
+	pw.UpdatePod(pod, types.SyncPodCreate)
+	pw.UpdatePod(pod, types.SyncPodSync)
+
+	for _, want := range []types.SyncPodType{types.SyncPodCreate, types.SyncPodSync} {
+		select {
+		case call := <-syncer.calls:
+			if call.syncPodType != want {
+				t.Fatalf("sync type = %v, want %v", call.syncPodType, want)
+			}
+			if want == types.SyncPodSync && call.status != status {
+				t.Errorf("status = %v, want the status cached during create", call.status)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for %v, the status cached during create was not used", want)
+		}
+	}
+}
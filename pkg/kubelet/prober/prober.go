@@ -0,0 +1,99 @@
+package prober
+
+import (
+	"crypto/tls"
+	"fmt"
+	v1 "minikubernetes/pkg/api/v1"
+	"minikubernetes/pkg/kubelet/runtime"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type probeResult string
+
+const (
+	probeSuccess probeResult = "Success"
+	probeFailure probeResult = "Failure"
+)
+
+// prober 执行单次探测
+type prober struct {
+	runtimeManager runtime.RuntimeManager
+}
+
+func (pb *prober) probe(probe *v1.Probe, podIP string, containerID string) (probeResult, string) {
+	timeout := time.Duration(probe.TimeoutSeconds) * time.Second
+	switch {
+	case probe.Exec != nil:
+		return pb.probeExec(probe.Exec, containerID, timeout)
+	case probe.HTTPGet != nil:
+		return probeHTTPGet(probe.HTTPGet, podIP, timeout)
+	case probe.TCPSocket != nil:
+		return probeTCPSocket(probe.TCPSocket, podIP, timeout)
+	default:
+		return probeFailure, "probe has no handler"
+	}
+}
+
+func (pb *prober) probeExec(action *v1.ExecAction, containerID string, timeout time.Duration) (probeResult, string) {
+	exitCode, output, err := pb.runtimeManager.ExecInContainer(containerID, action.Command, timeout)
+	if err != nil {
+		return probeFailure, fmt.Sprintf("exec failed: %v", err)
+	}
+	if exitCode != 0 {
+		return probeFailure, fmt.Sprintf("command exited with %v: %s", exitCode, output)
+	}
+	return probeSuccess, ""
+}
+
+func probeHTTPGet(action *v1.HTTPGetAction, podIP string, timeout time.Duration) (probeResult, string) {
+	host := action.Host
+	if host == "" {
+		host = podIP
+	}
+	scheme := "http"
+	if action.Scheme == v1.URISchemeHTTPS {
+		scheme = "https"
+	}
+	url := fmt.Sprintf("%s://%s%s", scheme, net.JoinHostPort(host, strconv.Itoa(int(action.Port))), action.Path)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return probeFailure, err.Error()
+	}
+	for _, header := range action.HTTPHeaders {
+		req.Header.Add(header.Name, header.Value)
+	}
+	client := &http.Client{
+		Timeout: timeout,
+		// 与k8s一致，https探测不校验证书
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+		// 不跟随重定向，3xx同样视为成功
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return probeFailure, err.Error()
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+		return probeFailure, fmt.Sprintf("HTTP probe failed with statuscode: %d", resp.StatusCode)
+	}
+	return probeSuccess, ""
+}
+
+func probeTCPSocket(action *v1.TCPSocketAction, podIP string, timeout time.Duration) (probeResult, string) {
+	host := action.Host
+	if host == "" {
+		host = podIP
+	}
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(host, strconv.Itoa(int(action.Port))), timeout)
+	if err != nil {
+		return probeFailure, err.Error()
+	}
+	_ = conn.Close()
+	return probeSuccess, ""
+}
@@ -0,0 +1,153 @@
+package prober
+
+import (
+	"log"
+	v1 "minikubernetes/pkg/api/v1"
+	"minikubernetes/pkg/kubelet/runtime"
+	"sync"
+)
+
+type probeType string
+
+const (
+	liveness  probeType = "Liveness"
+	readiness probeType = "Readiness"
+	startup   probeType = "Startup"
+)
+
+type Manager interface {
+	// 为pod中声明了探针的容器启动worker
+	AddPod(pod *v1.Pod)
+	// 停止pod的所有worker
+	RemovePod(pod *v1.Pod)
+	// 容器是否就绪，未声明readiness probe的容器在启动完成后即视为就绪
+	IsContainerReady(podUID v1.UID, containerName string) bool
+	// 容器就绪状态变化时发送pod uid
+	Updates() <-chan v1.UID
+	Stop()
+}
+
+type probeKey struct {
+	podUID        v1.UID
+	containerName string
+	probeType     probeType
+}
+
+type manager struct {
+	lock    sync.RWMutex
+	workers map[probeKey]*worker
+	// 仅记录readiness与startup探针的结果
+	results map[probeKey]bool
+
+	prober  *prober
+	cache   runtime.Cache
+	updates chan v1.UID
+}
+
+func NewManager(runtimeManager runtime.RuntimeManager, cache runtime.Cache) Manager {
+	return &manager{
+		workers: make(map[probeKey]*worker),
+		results: make(map[probeKey]bool),
+		prober:  &prober{runtimeManager: runtimeManager},
+		cache:   cache,
+		updates: make(chan v1.UID, 100),
+	}
+}
+
+func (m *manager) AddPod(pod *v1.Pod) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for i := range pod.Spec.Containers {
+		c := &pod.Spec.Containers[i]
+		probes := map[probeType]*v1.Probe{
+			liveness:  c.LivenessProbe,
+			readiness: c.ReadinessProbe,
+			startup:   c.StartupProbe,
+		}
+		for t, probe := range probes {
+			if probe == nil {
+				continue
+			}
+			key := probeKey{podUID: pod.UID, containerName: c.Name, probeType: t}
+			if _, ok := m.workers[key]; ok {
+				log.Printf("Probe worker for %v/%v %v already exists.", pod.Name, c.Name, t)
+				continue
+			}
+			w := newWorker(m, t, pod, c, probe)
+			m.workers[key] = w
+			go w.run()
+		}
+	}
+}
+
+func (m *manager) RemovePod(pod *v1.Pod) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for key, w := range m.workers {
+		if key.podUID == pod.UID {
+			w.stop()
+			delete(m.workers, key)
+		}
+	}
+	for key := range m.results {
+		if key.podUID == pod.UID {
+			delete(m.results, key)
+		}
+	}
+}
+
+func (m *manager) IsContainerReady(podUID v1.UID, containerName string) bool {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	for _, t := range []probeType{startup, readiness} {
+		key := probeKey{podUID: podUID, containerName: containerName, probeType: t}
+		if _, ok := m.workers[key]; ok && !m.results[key] {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *manager) Updates() <-chan v1.UID {
+	return m.updates
+}
+
+func (m *manager) Stop() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for key, w := range m.workers {
+		w.stop()
+		delete(m.workers, key)
+	}
+}
+
+func (m *manager) isStarted(podUID v1.UID, containerName string) bool {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	key := probeKey{podUID: podUID, containerName: containerName, probeType: startup}
+	if _, ok := m.workers[key]; !ok {
+		return true
+	}
+	return m.results[key]
+}
+
+func (m *manager) setResult(key probeKey, success bool) {
+	m.lock.Lock()
+	if _, ok := m.workers[key]; !ok {
+		// worker已被移除
+		m.lock.Unlock()
+		return
+	}
+	old, ok := m.results[key]
+	m.results[key] = success
+	m.lock.Unlock()
+	if ok && old == success {
+		return
+	}
+	// 启动与就绪状态的变化都会影响Ready condition
+	select {
+	case m.updates <- key.podUID:
+	default:
+		log.Printf("Probe updates channel is full, dropping update for pod %v.", key.podUID)
+	}
+}
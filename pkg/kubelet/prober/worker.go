@@ -0,0 +1,153 @@
+package prober
+
+import (
+	"log"
+	v1 "minikubernetes/pkg/api/v1"
+	"minikubernetes/pkg/kubelet/runtime"
+	"time"
+)
+
+const (
+	defaultTimeoutSeconds   = 1
+	defaultPeriodSeconds    = 10
+	defaultSuccessThreshold = 1
+	defaultFailureThreshold = 3
+)
+
+// worker 周期性地对单个容器执行一种探针
+type worker struct {
+	manager   *manager
+	probeType probeType
+	pod       *v1.Pod
+	container *v1.Container
+	probe     *v1.Probe
+	key       probeKey
+
+	stopCh chan struct{}
+
+	// 当前探测的容器实例，容器原地重启后id不变，需同时比较启动时间
+	containerID        string
+	containerStartedAt time.Time
+	// 首次观察到该容器实例的时间，用于计算initialDelaySeconds
+	startedAt  time.Time
+	lastResult probeResult
+	resultRun  int32
+}
+
+func newWorker(m *manager, t probeType, pod *v1.Pod, c *v1.Container, probe *v1.Probe) *worker {
+	p := *probe
+	if p.TimeoutSeconds <= 0 {
+		p.TimeoutSeconds = defaultTimeoutSeconds
+	}
+	if p.PeriodSeconds <= 0 {
+		p.PeriodSeconds = defaultPeriodSeconds
+	}
+	if p.SuccessThreshold <= 0 {
+		p.SuccessThreshold = defaultSuccessThreshold
+	}
+	if p.FailureThreshold <= 0 {
+		p.FailureThreshold = defaultFailureThreshold
+	}
+	return &worker{
+		manager:   m,
+		probeType: t,
+		pod:       pod,
+		container: c,
+		probe:     &p,
+		key:       probeKey{podUID: pod.UID, containerName: c.Name, probeType: t},
+		stopCh:    make(chan struct{}),
+	}
+}
+
+func (w *worker) run() {
+	ticker := time.NewTicker(time.Duration(w.probe.PeriodSeconds) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.doProbe()
+		case <-w.stopCh:
+			return
+		}
+	}
+}
+
+func (w *worker) stop() {
+	close(w.stopCh)
+}
+
+func (w *worker) doProbe() {
+	status, err := w.manager.cache.Get(w.pod.UID)
+	if err != nil || status == nil {
+		// pod尚未被PLEG发现
+		return
+	}
+	var containerStatus *runtime.ContainerStatus
+	for _, cs := range status.ContainerStatuses {
+		if cs.Name == w.container.Name {
+			containerStatus = cs
+			break
+		}
+	}
+	if containerStatus == nil {
+		return
+	}
+	if containerStatus.ID != w.containerID || !containerStatus.StartedAt.Equal(w.containerStartedAt) {
+		// 新的容器实例，重新开始探测
+		w.containerID = containerStatus.ID
+		w.containerStartedAt = containerStatus.StartedAt
+		w.startedAt = time.Now()
+		w.lastResult = ""
+		w.resultRun = 0
+		if w.probeType != liveness {
+			w.manager.setResult(w.key, false)
+		}
+	}
+	if containerStatus.State != runtime.ContainerStateRunning {
+		if w.probeType == readiness {
+			w.manager.setResult(w.key, false)
+		}
+		return
+	}
+	if w.probeType == startup {
+		if w.manager.isStarted(w.pod.UID, w.container.Name) {
+			return
+		}
+	} else if !w.manager.isStarted(w.pod.UID, w.container.Name) {
+		return
+	}
+	if time.Since(w.startedAt) < time.Duration(w.probe.InitialDelaySeconds)*time.Second {
+		return
+	}
+
+	podIP := ""
+	if len(status.IPs) > 0 {
+		podIP = status.IPs[0]
+	}
+	result, message := w.manager.prober.probe(w.probe, podIP, w.containerID)
+	if result == w.lastResult {
+		w.resultRun++
+	} else {
+		w.lastResult = result
+		w.resultRun = 1
+	}
+	if (result == probeFailure && w.resultRun < w.probe.FailureThreshold) ||
+		(result == probeSuccess && w.resultRun < w.probe.SuccessThreshold) {
+		return
+	}
+
+	if result == probeFailure {
+		log.Printf("%v probe failed for %v/%v: %v", w.probeType, w.pod.Name, w.container.Name, message)
+	}
+	if w.probeType != liveness {
+		w.manager.setResult(w.key, result == probeSuccess)
+	}
+	if result == probeFailure && w.probeType != readiness {
+		// 杀死容器，之后由PLEG产生ContainerDied事件，按重启策略处理
+		log.Printf("Killing container %v/%v due to failed %v probe.", w.pod.Name, w.container.Name, w.probeType)
+		if err := w.manager.prober.runtimeManager.KillContainer(w.containerID); err != nil {
+			log.Printf("Failed to kill container %v/%v: %v", w.pod.Name, w.container.Name, err)
+		}
+		w.resultRun = 0
+	}
+}
@@ -0,0 +1,55 @@
+package prober
+
+import (
+	v1 "minikubernetes/pkg/api/v1"
+	"minikubernetes/pkg/kubelet/runtime"
+	"testing"
+	"time"
+)
+
+func TestWorkerRestartedContainer(t *testing.T) {
+	rm := runtime.NewFakeRuntimeManager()
+	probes := 0
+	rm.ExecFunc = func(containerID string, cmd []string) (int, []byte, error) {
+		probes++
+		return 0, nil, nil
+	}
+	cache := runtime.NewCache()
+	m := NewManager(rm, cache).(*manager)
+	pod := &v1.Pod{}
+	pod.UID = "uid-pod"
+	pod.Spec.Containers = []v1.Container{{Name: "c"}}
+	probe := &v1.Probe{
+		ProbeHandler:        v1.ProbeHandler{Exec: &v1.ExecAction{Command: []string{"true"}}},
+		InitialDelaySeconds: 60,
+	}
+	w := newWorker(m, liveness, pod, &pod.Spec.Containers[0], probe)
+
+	setStatus := func(startedAt time.Time) {
+		cache.Set(pod.UID, &runtime.PodStatus{
+			ID: pod.UID,
+			ContainerStatuses: []*runtime.ContainerStatus{
+				{ID: "c1", Name: "c", State: runtime.ContainerStateRunning, StartedAt: startedAt},
+			},
+		}, nil, time.Now())
+	}
+	started := time.Now()
+	setStatus(started)
+	w.doProbe()
+	if probes != 0 {
+		t.Fatalf("probes = %v during initial delay", probes)
+	}
+	// 跳过initialDelaySeconds
+	w.startedAt = time.Now().Add(-time.Minute)
+	w.doProbe()
+	if probes != 1 {
+		t.Fatalf("probes = %v after initial delay, want 1", probes)
+	}
+
+	// 原地重启后容器id不变，只有启动时间变化，需重新等待initialDelaySeconds
+	setStatus(started.Add(time.Second))
+	w.doProbe()
+	if probes != 1 {
+		t.Errorf("probes = %v, initial delay not applied to the restarted container", probes)
+	}
+}
@@ -0,0 +1,97 @@
+package runtime
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const cgroupRoot = "/sys/fs/cgroup"
+
+// 工作集不包括可回收的文件缓存，cgroup v1的memory.stat中为total_inactive_file
+func memoryWorkingSet(usage uint64, stats map[string]uint64) uint64 {
+	inactive, ok := stats["inactive_file"]
+	if !ok {
+		inactive = stats["total_inactive_file"]
+	}
+	if inactive >= usage {
+		return 0
+	}
+	return usage - inactive
+}
+
+// 读取进程所在memory cgroup的工作集，同时支持cgroup v1与v2
+func cgroupMemoryWorkingSet(pid int) (uint64, error) {
+	dir, usageFile, err := memoryCgroupDir(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return 0, err
+	}
+	content, err := os.ReadFile(filepath.Join(dir, usageFile))
+	if err != nil {
+		return 0, err
+	}
+	usage, err := strconv.ParseUint(strings.TrimSpace(string(content)), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	stats, err := readFlatKeyed(filepath.Join(dir, "memory.stat"))
+	if err != nil {
+		return 0, err
+	}
+	return memoryWorkingSet(usage, stats), nil
+}
+
+// 返回memory cgroup的目录与其中记录内存用量的文件名
+func memoryCgroupDir(procCgroupPath string) (string, string, error) {
+	content, err := os.ReadFile(procCgroupPath)
+	if err != nil {
+		return "", "", err
+	}
+	var unified string
+	for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		// hierarchy-ID:controller-list:cgroup-path
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if parts[0] == "0" && parts[1] == "" {
+			unified = parts[2]
+			continue
+		}
+		for _, controller := range strings.Split(parts[1], ",") {
+			if controller == "memory" {
+				return filepath.Join(cgroupRoot, "memory", parts[2]), "memory.usage_in_bytes", nil
+			}
+		}
+	}
+	if unified != "" {
+		return filepath.Join(cgroupRoot, unified), "memory.current", nil
+	}
+	return "", "", fmt.Errorf("memory cgroup not found in %s", procCgroupPath)
+}
+
+// 读取"key value"形式的cgroup文件
+func readFlatKeyed(path string) (map[string]uint64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	result := make(map[string]uint64)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		value, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		result[fields[0]] = value
+	}
+	return result, scanner.Err()
+}
@@ -0,0 +1,41 @@
+package runtime
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMemoryCgroupDir(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		dir     string
+		file    string
+	}{
+		{"v2", "0::/system.slice/docker-abc.scope\n", "/sys/fs/cgroup/system.slice/docker-abc.scope", "memory.current"},
+		{"v1", "12:cpu,cpuacct:/docker/abc\n9:memory:/docker/abc\n0::/\n", "/sys/fs/cgroup/memory/docker/abc", "memory.usage_in_bytes"},
+	}
+	for _, test := range tests {
+		path := filepath.Join(t.TempDir(), "cgroup")
+		if err := os.WriteFile(path, []byte(test.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		dir, file, err := memoryCgroupDir(path)
+		if err != nil || dir != test.dir || file != test.file {
+			t.Errorf("%s: memoryCgroupDir() = %q, %q, %v", test.name, dir, file, err)
+		}
+	}
+}
+
+func TestMemoryWorkingSet(t *testing.T) {
+	if got := memoryWorkingSet(100, map[string]uint64{"inactive_file": 30}); got != 70 {
+		t.Errorf("v2 working set = %v, want 70", got)
+	}
+	if got := memoryWorkingSet(100, map[string]uint64{"total_inactive_file": 40}); got != 60 {
+		t.Errorf("v1 working set = %v, want 60", got)
+	}
+	if got := memoryWorkingSet(10, map[string]uint64{"inactive_file": 30}); got != 0 {
+		t.Errorf("working set = %v, want 0", got)
+	}
+}
@@ -0,0 +1,1067 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	nw "minikubernetes/pkg/kubelet/network"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/containerd/containerd"
+	apievents "github.com/containerd/containerd/api/events"
+	"github.com/containerd/containerd/cio"
+	"github.com/containerd/containerd/containers"
+	"github.com/containerd/containerd/errdefs"
+	"github.com/containerd/containerd/events"
+	"github.com/containerd/containerd/images"
+	"github.com/containerd/containerd/oci"
+	"github.com/containerd/containerd/reference/docker"
+	remotesdocker "github.com/containerd/containerd/remotes/docker"
+	"github.com/containerd/typeurl/v2"
+	"github.com/google/uuid"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+)
+
+const (
+	// 与其它containerd用户（如k8s的CRI插件）隔离
+	containerdNamespace = "minikubernetes"
+	containerdRootDir   = "/var/lib/minikubernetes/containerd"
+	// 由kubelet维护的容器元数据，保存在containerd的容器标签中
+	sandboxIDLabel = "minikubernetes.io/sandbox-id"
+	netnsLabel     = "minikubernetes.io/netns"
+	ipLabel        = "minikubernetes.io/ip"
+	// sandbox的端口映射，释放网络时需传给portmap插件
+	portMappingsLabel = "minikubernetes.io/port-mappings"
+	startedAtLabel    = "minikubernetes.io/started-at"
+	// cpu limit对应的cfs周期，单位为微秒
+	cfsPeriod = 100000
+	// follow日志时检查新内容的间隔
+	logPollInterval = 200 * time.Millisecond
+)
+
+// 基于containerd的运行时，sandbox为运行在独立网络命名空间中的pause容器，由CNI插件配置网络
+// 容器的输出由shim直接写入日志文件，每次启动时轮转，因此日志不带时间戳，tty也不生效
+type containerdService struct {
+	client *containerd.Client
+}
+
+func newContainerdService(address string) (*containerdService, error) {
+	client, err := containerd.New(address, containerd.WithDefaultNamespace(containerdNamespace))
+	if err != nil {
+		return nil, err
+	}
+	return &containerdService{client: client}, nil
+}
+
+func newContainerID() string {
+	return strings.ReplaceAll(uuid.New().String(), "-", "")
+}
+
+func sandboxDir(sandboxID string) string {
+	return filepath.Join(containerdRootDir, "sandboxes", sandboxID)
+}
+
+func containerLogPath(containerID string, previous bool) string {
+	if previous {
+		return filepath.Join(containerdRootDir, "logs", containerID, "previous.log")
+	}
+	return filepath.Join(containerdRootDir, "logs", containerID, "current.log")
+}
+
+func (cs *containerdService) RunPodSandbox(config *PodSandboxConfig) (string, error) {
+	ctx := context.Background()
+	if err := ensureImage(cs, pauseImage); err != nil {
+		return "", err
+	}
+	img, err := cs.getImage(ctx, pauseImage)
+	if err != nil {
+		return "", err
+	}
+	id := newContainerID()
+	labels := make(map[string]string, len(config.Labels)+4)
+	for k, v := range config.Labels {
+		labels[k] = v
+	}
+	labels[sandboxLabel] = "pause"
+	// hostNetwork的sandbox不创建网络命名空间，netnsLabel为空
+	var nsName string
+	netnsOpt := oci.WithHostNamespace(specs.NetworkNamespace)
+	var portMappings []nw.PortMapping
+	if config.HostNetwork {
+		labels[ipLabel] = config.HostIP
+	} else {
+		nsName = "minik8s-" + id
+		nsPath, err := createNetns(nsName)
+		if err != nil {
+			return "", err
+		}
+		labels[netnsLabel] = nsName
+		netnsOpt = oci.WithLinuxNamespace(specs.LinuxNamespace{Type: specs.NetworkNamespace, Path: nsPath})
+		for _, port := range config.Ports {
+			if port.HostPort != 0 {
+				portMappings = append(portMappings, nw.PortMapping{
+					HostPort:      port.HostPort,
+					ContainerPort: port.ContainerPort,
+					Protocol:      string(port.Protocol),
+				})
+			}
+		}
+		if len(portMappings) != 0 {
+			encoded, _ := json.Marshal(portMappings)
+			labels[portMappingsLabel] = string(encoded)
+		}
+	}
+	cntr, err := cs.client.NewContainer(ctx, id,
+		containerd.WithImage(img),
+		containerd.WithNewSnapshot(id, img),
+		containerd.WithNewSpec(oci.WithImageConfig(img), netnsOpt),
+		containerd.WithContainerLabels(labels),
+	)
+	if err != nil {
+		if nsName != "" {
+			_ = netns.DeleteNamed(nsName)
+		}
+		return "", err
+	}
+	// 容器共享sandbox的网络命名空间，但各自有独立的文件系统，需要挂载同一份resolv.conf
+	if config.DNS != nil {
+		if err = os.MkdirAll(sandboxDir(id), os.ModePerm); err != nil {
+			return "", err
+		}
+		if err = os.WriteFile(filepath.Join(sandboxDir(id), "resolv.conf"), formatResolvConf(config.DNS), 0644); err != nil {
+			return "", err
+		}
+	}
+	if err = cs.startTask(ctx, cntr, cio.NullIO); err != nil {
+		return "", err
+	}
+	if config.HostNetwork {
+		return id, nil
+	}
+	ip, err := nw.AttachNetns(id, filepath.Join("/var/run/netns", nsName), portMappings)
+	if err != nil {
+		return "", err
+	}
+	if _, err = cntr.SetLabels(ctx, map[string]string{ipLabel: ip}); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// 创建命名的网络命名空间并启用lo，返回其路径
+func createNetns(name string) (string, error) {
+	goruntime.LockOSThread()
+	origin, err := netns.Get()
+	if err != nil {
+		goruntime.UnlockOSThread()
+		return "", err
+	}
+	defer origin.Close()
+	// NewNamed会将当前线程切换到新的命名空间
+	handle, err := netns.NewNamed(name)
+	if err != nil {
+		goruntime.UnlockOSThread()
+		return "", err
+	}
+	defer handle.Close()
+	if err = netns.Set(origin); err != nil {
+		// 线程无法恢复时不解锁，goroutine结束后该线程随之销毁
+		return "", fmt.Errorf("failed to restore network namespace: %v", err)
+	}
+	goruntime.UnlockOSThread()
+
+	nlHandle, err := netlink.NewHandleAt(handle)
+	if err != nil {
+		_ = netns.DeleteNamed(name)
+		return "", err
+	}
+	defer nlHandle.Close()
+	lo, err := nlHandle.LinkByName("lo")
+	if err == nil {
+		err = nlHandle.LinkSetUp(lo)
+	}
+	if err != nil {
+		_ = netns.DeleteNamed(name)
+		return "", err
+	}
+	return filepath.Join("/var/run/netns", name), nil
+}
+
+func (cs *containerdService) StopPodSandbox(sandboxID string) error {
+	ctx := context.Background()
+	cntr, err := cs.client.LoadContainer(ctx, sandboxID)
+	if err != nil {
+		return err
+	}
+	labels, err := cntr.Labels(ctx)
+	if err != nil {
+		return err
+	}
+	if labels[ipLabel] != "" && labels[netnsLabel] != "" {
+		var portMappings []nw.PortMapping
+		if encoded := labels[portMappingsLabel]; encoded != "" {
+			if err = json.Unmarshal([]byte(encoded), &portMappings); err != nil {
+				return err
+			}
+		}
+		if err = nw.DetachNetns(sandboxID, filepath.Join("/var/run/netns", labels[netnsLabel]), portMappings); err != nil {
+			return err
+		}
+		if _, err = cntr.SetLabels(ctx, map[string]string{ipLabel: ""}); err != nil {
+			return err
+		}
+	}
+	return cs.StopContainer(sandboxID, 0)
+}
+
+func (cs *containerdService) RemovePodSandbox(sandboxID string) error {
+	ctx := context.Background()
+	cntr, err := cs.client.LoadContainer(ctx, sandboxID)
+	if err != nil {
+		return err
+	}
+	labels, err := cntr.Labels(ctx)
+	if err != nil {
+		return err
+	}
+	if err = cs.RemoveContainer(sandboxID); err != nil {
+		return err
+	}
+	if labels[netnsLabel] != "" {
+		if err = netns.DeleteNamed(labels[netnsLabel]); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.RemoveAll(sandboxDir(sandboxID))
+}
+
+func (cs *containerdService) PodSandboxStatus(sandboxID string) (*PodSandboxStatus, error) {
+	ctx := context.Background()
+	cntr, err := cs.client.LoadContainer(ctx, sandboxID)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := cntr.Labels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	status := &PodSandboxStatus{ID: sandboxID, IP: labels[ipLabel]}
+	task, taskStatus, err := cs.taskStatus(ctx, cntr)
+	if err != nil {
+		return nil, err
+	}
+	status.State = toContainerdState(taskStatus.Status)
+	if task != nil && status.State == ContainerStateRunning {
+		status.Pid = int(task.Pid())
+	}
+	return status, nil
+}
+
+func (cs *containerdService) ListPodSandbox(filter map[string]string) ([]*PodSandbox, error) {
+	containers, err := cs.listContainers(filter, true)
+	if err != nil {
+		return nil, err
+	}
+	var ret []*PodSandbox
+	for _, ct := range containers {
+		ret = append(ret, &PodSandbox{
+			ID:        ct.ID,
+			Labels:    ct.Labels,
+			State:     ct.State,
+			CreatedAt: ct.CreatedAt,
+		})
+	}
+	return ret, nil
+}
+
+func (cs *containerdService) listContainers(filter map[string]string, sandbox bool) ([]*RuntimeContainer, error) {
+	ctx := context.Background()
+	cntrs, err := cs.client.Containers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var ret []*RuntimeContainer
+	for _, cntr := range cntrs {
+		info, err := cntr.Info(ctx, containerd.WithoutRefreshedMetadata)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := info.Labels[sandboxLabel]; ok != sandbox || !matchLabels(info.Labels, filter) {
+			continue
+		}
+		_, status, err := cs.taskStatus(ctx, cntr)
+		if err != nil {
+			return nil, err
+		}
+		var imageID string
+		if img, err := cs.client.ImageService().Get(ctx, info.Image); err == nil {
+			imageID = img.Target.Digest.String()
+		}
+		ret = append(ret, &RuntimeContainer{
+			ID:        info.ID,
+			Image:     info.Image,
+			ImageID:   imageID,
+			Labels:    info.Labels,
+			State:     toContainerdState(status.Status),
+			CreatedAt: info.CreatedAt,
+		})
+	}
+	return ret, nil
+}
+
+func matchLabels(labels map[string]string, filter map[string]string) bool {
+	for k, v := range filter {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// 容器从未启动时没有task，视为created
+func (cs *containerdService) taskStatus(ctx context.Context, cntr containerd.Container) (containerd.Task, containerd.Status, error) {
+	task, err := cntr.Task(ctx, nil)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return nil, containerd.Status{Status: containerd.Created}, nil
+		}
+		return nil, containerd.Status{}, err
+	}
+	status, err := task.Status(ctx)
+	if err != nil {
+		return nil, containerd.Status{}, err
+	}
+	return task, status, nil
+}
+
+func toContainerdState(status containerd.ProcessStatus) ContainerState {
+	switch status {
+	case containerd.Running:
+		return ContainerStateRunning
+	case containerd.Stopped:
+		return ContainerStateExited
+	case containerd.Created:
+		return ContainerStateCreated
+	default:
+		return ContainerStateUnknown
+	}
+}
+
+func (cs *containerdService) CreateContainer(sandboxID string, config *ContainerConfig) (string, error) {
+	ctx := context.Background()
+	sandbox, err := cs.client.LoadContainer(ctx, sandboxID)
+	if err != nil {
+		return "", err
+	}
+	sandboxLabels, err := sandbox.Labels(ctx)
+	if err != nil {
+		return "", err
+	}
+	img, err := cs.getImage(ctx, config.Image)
+	if err != nil {
+		return "", err
+	}
+
+	var specOpts []oci.SpecOpts
+	// 与k8s一致，command覆盖ENTRYPOINT，args覆盖CMD
+	if len(config.Command) == 0 {
+		specOpts = append(specOpts, oci.WithImageConfigArgs(img, config.Args))
+	} else {
+		specOpts = append(specOpts, oci.WithImageConfig(img), withCommand(config.Command, config.Args))
+	}
+	specOpts = append(specOpts, oci.WithEnv(config.Env), withResources(config.Resources))
+	if sandboxLabels[netnsLabel] == "" {
+		// hostNetwork的pod与节点共用网络与hosts
+		specOpts = append(specOpts, oci.WithHostNamespace(specs.NetworkNamespace), oci.WithHostHostsFile)
+	} else {
+		specOpts = append(specOpts, oci.WithLinuxNamespace(specs.LinuxNamespace{
+			Type: specs.NetworkNamespace,
+			Path: filepath.Join("/var/run/netns", sandboxLabels[netnsLabel]),
+		}))
+	}
+	if config.SharePid {
+		task, status, err := cs.taskStatus(ctx, sandbox)
+		if err != nil {
+			return "", err
+		}
+		if task == nil || status.Status != containerd.Running {
+			return "", fmt.Errorf("sandbox %s is not running", sandboxID)
+		}
+		specOpts = append(specOpts, oci.WithLinuxNamespace(specs.LinuxNamespace{
+			Type: specs.PIDNamespace,
+			Path: fmt.Sprintf("/proc/%d/ns/pid", task.Pid()),
+		}))
+	}
+	if config.WorkingDir != "" {
+		specOpts = append(specOpts, oci.WithProcessCwd(config.WorkingDir))
+	}
+	if config.User != "" {
+		specOpts = append(specOpts, oci.WithUser(config.User))
+	}
+	if config.Privileged {
+		specOpts = append(specOpts, oci.WithPrivileged, oci.WithAllDevicesAllowed, oci.WithHostDevices)
+	}
+	var mounts []specs.Mount
+	for _, m := range config.Mounts {
+		options := []string{"rbind", "rw"}
+		if m.ReadOnly {
+			options = []string{"rbind", "ro"}
+		}
+		mounts = append(mounts, specs.Mount{
+			Destination: m.ContainerPath,
+			Type:        "bind",
+			Source:      m.HostPath,
+			Options:     options,
+		})
+	}
+	resolvPath := filepath.Join(sandboxDir(sandboxID), "resolv.conf")
+	if _, err = os.Stat(resolvPath); err == nil {
+		mounts = append(mounts, specs.Mount{
+			Destination: "/etc/resolv.conf",
+			Type:        "bind",
+			Source:      resolvPath,
+			Options:     []string{"rbind", "ro"},
+		})
+	}
+	specOpts = append(specOpts, oci.WithMounts(mounts))
+
+	labels := make(map[string]string, len(config.Labels)+1)
+	for k, v := range config.Labels {
+		labels[k] = v
+	}
+	labels[sandboxIDLabel] = sandboxID
+	id := newContainerID()
+	_, err = cs.client.NewContainer(ctx, id,
+		containerd.WithImage(img),
+		containerd.WithNewSnapshot(id, img),
+		containerd.WithNewSpec(specOpts...),
+		containerd.WithContainerLabels(labels),
+	)
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func withCommand(command []string, args []string) oci.SpecOpts {
+	return func(_ context.Context, _ oci.Client, _ *containers.Container, s *oci.Spec) error {
+		s.Process.Args = append(append([]string{}, command...), args...)
+		return nil
+	}
+}
+
+func withResources(r LinuxContainerResources) oci.SpecOpts {
+	return func(_ context.Context, _ oci.Client, _ *containers.Container, s *oci.Spec) error {
+		if s.Linux == nil {
+			s.Linux = &specs.Linux{}
+		}
+		if s.Linux.Resources == nil {
+			s.Linux.Resources = &specs.LinuxResources{}
+		}
+		cpu := &specs.LinuxCPU{}
+		if r.CPUShares > 0 {
+			shares := uint64(r.CPUShares)
+			cpu.Shares = &shares
+		}
+		if r.NanoCPUs > 0 {
+			period := uint64(cfsPeriod)
+			quota := r.NanoCPUs * cfsPeriod / 1e9
+			cpu.Period, cpu.Quota = &period, &quota
+		}
+		s.Linux.Resources.CPU = cpu
+		memory := &specs.LinuxMemory{}
+		if r.Memory > 0 {
+			memory.Limit = &r.Memory
+		}
+		if r.MemoryReservation > 0 {
+			memory.Reservation = &r.MemoryReservation
+		}
+		s.Linux.Resources.Memory = memory
+		return nil
+	}
+}
+
+// 由spec中的cgroup配置还原容器资源
+func fromSpecResources(resources *specs.LinuxResources) LinuxContainerResources {
+	var r LinuxContainerResources
+	if resources == nil {
+		return r
+	}
+	if cpu := resources.CPU; cpu != nil {
+		if cpu.Shares != nil {
+			r.CPUShares = int64(*cpu.Shares)
+		}
+		if cpu.Quota != nil && cpu.Period != nil && *cpu.Period > 0 {
+			r.NanoCPUs = *cpu.Quota * 1e9 / int64(*cpu.Period)
+		}
+	}
+	if memory := resources.Memory; memory != nil {
+		if memory.Limit != nil {
+			r.Memory = *memory.Limit
+		}
+		if memory.Reservation != nil {
+			r.MemoryReservation = *memory.Reservation
+		}
+	}
+	return r
+}
+
+func (cs *containerdService) StartContainer(containerID string) error {
+	ctx := context.Background()
+	cntr, err := cs.client.LoadContainer(ctx, containerID)
+	if err != nil {
+		return err
+	}
+	// 上一次运行的日志保留为previous
+	current := containerLogPath(containerID, false)
+	if err = os.MkdirAll(filepath.Dir(current), os.ModePerm); err != nil {
+		return err
+	}
+	if _, err = os.Stat(current); err == nil {
+		if err = os.Rename(current, containerLogPath(containerID, true)); err != nil {
+			return err
+		}
+	}
+	return cs.startTask(ctx, cntr, cio.LogFile(current))
+}
+
+// 删除已退出的task后重新创建，实现容器的重启
+func (cs *containerdService) startTask(ctx context.Context, cntr containerd.Container, ioCreator cio.Creator) error {
+	task, status, err := cs.taskStatus(ctx, cntr)
+	if err != nil {
+		return err
+	}
+	if status.Status == containerd.Running {
+		return nil
+	}
+	if task != nil {
+		if _, err = task.Delete(ctx); err != nil {
+			return err
+		}
+	}
+	task, err = cntr.NewTask(ctx, ioCreator)
+	if err != nil {
+		return err
+	}
+	if err = task.Start(ctx); err != nil {
+		_, _ = task.Delete(ctx, containerd.WithProcessKill)
+		return err
+	}
+	_, err = cntr.SetLabels(ctx, map[string]string{startedAtLabel: time.Now().Format(time.RFC3339Nano)})
+	return err
+}
+
+func (cs *containerdService) StopContainer(containerID string, timeout time.Duration) error {
+	ctx := context.Background()
+	cntr, err := cs.client.LoadContainer(ctx, containerID)
+	if err != nil {
+		return err
+	}
+	task, status, err := cs.taskStatus(ctx, cntr)
+	if err != nil || task == nil || status.Status != containerd.Running {
+		return err
+	}
+	exitCh, err := task.Wait(ctx)
+	if err != nil {
+		return err
+	}
+	if timeout > 0 {
+		if err = task.Kill(ctx, syscall.SIGTERM); err != nil && !errdefs.IsNotFound(err) {
+			return err
+		}
+		select {
+		case <-exitCh:
+			return nil
+		case <-time.After(timeout):
+		}
+	}
+	if err = task.Kill(ctx, syscall.SIGKILL, containerd.WithKillAll); err != nil && !errdefs.IsNotFound(err) {
+		return err
+	}
+	<-exitCh
+	return nil
+}
+
+func (cs *containerdService) RemoveContainer(containerID string) error {
+	ctx := context.Background()
+	cntr, err := cs.client.LoadContainer(ctx, containerID)
+	if err != nil {
+		return err
+	}
+	task, _, err := cs.taskStatus(ctx, cntr)
+	if err != nil {
+		return err
+	}
+	if task != nil {
+		if _, err = task.Delete(ctx, containerd.WithProcessKill); err != nil && !errdefs.IsNotFound(err) {
+			return err
+		}
+	}
+	if err = cntr.Delete(ctx, containerd.WithSnapshotCleanup); err != nil {
+		return err
+	}
+	return os.RemoveAll(filepath.Dir(containerLogPath(containerID, false)))
+}
+
+func (cs *containerdService) ListContainers(filter map[string]string) ([]*RuntimeContainer, error) {
+	return cs.listContainers(filter, false)
+}
+
+func (cs *containerdService) ContainerStatus(containerID string) (*ContainerStatus, error) {
+	ctx := context.Background()
+	cntr, err := cs.client.LoadContainer(ctx, containerID)
+	if err != nil {
+		return nil, err
+	}
+	info, err := cntr.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, taskStatus, err := cs.taskStatus(ctx, cntr)
+	if err != nil {
+		return nil, err
+	}
+	status := &ContainerStatus{
+		ID:        containerID,
+		Name:      info.Labels["Name"],
+		Image:     info.Image,
+		State:     toContainerdState(taskStatus.Status),
+		CreatedAt: info.CreatedAt,
+	}
+	if img, err := cntr.Image(ctx); err == nil {
+		status.ImageID = img.Target().Digest.String()
+	}
+	if startedAt, ok := info.Labels[startedAtLabel]; ok {
+		status.StartedAt, _ = time.Parse(time.RFC3339Nano, startedAt)
+	}
+	if status.State == ContainerStateExited {
+		status.ExitCode = int(taskStatus.ExitStatus)
+		status.FinishedAt = taskStatus.ExitTime
+		if status.ExitCode == 0 {
+			status.Reason = "Completed"
+		} else {
+			status.Reason = "Error"
+		}
+	}
+	if spec, err := cntr.Spec(ctx); err == nil && spec.Linux != nil {
+		status.Resources = toContainerResources(fromSpecResources(spec.Linux.Resources))
+	}
+	return status, nil
+}
+
+func (cs *containerdService) WaitContainer(containerID string) (int, error) {
+	ctx := context.Background()
+	cntr, err := cs.client.LoadContainer(ctx, containerID)
+	if err != nil {
+		return -1, err
+	}
+	task, err := cntr.Task(ctx, nil)
+	if err != nil {
+		return -1, err
+	}
+	exitCh, err := task.Wait(ctx)
+	if err != nil {
+		return -1, err
+	}
+	code, _, err := (<-exitCh).Result()
+	if err != nil {
+		return -1, err
+	}
+	return int(code), nil
+}
+
+// stdout与stderr由不同的goroutine写入
+type syncBuffer struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.Write(p)
+}
+
+func (cs *containerdService) ExecSync(containerID string, cmd []string, timeout time.Duration) (int, []byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	var output syncBuffer
+	code, err := cs.Exec(ctx, containerID, cmd, false, nil, &output, &output)
+	if err == nil && ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	return code, output.buf.Bytes(), err
+}
+
+// 读到EOF时关闭exec进程的stdin
+type stdinCloser struct {
+	io.Reader
+	once    sync.Once
+	onClose func()
+}
+
+func (s *stdinCloser) Read(p []byte) (int, error) {
+	n, err := s.Reader.Read(p)
+	if err == io.EOF {
+		s.once.Do(s.onClose)
+	}
+	return n, err
+}
+
+func (cs *containerdService) Exec(ctx context.Context, containerID string, cmd []string, tty bool, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	cntr, err := cs.client.LoadContainer(ctx, containerID)
+	if err != nil {
+		return -1, err
+	}
+	spec, err := cntr.Spec(ctx)
+	if err != nil {
+		return -1, err
+	}
+	task, err := cntr.Task(ctx, nil)
+	if err != nil {
+		return -1, err
+	}
+	processSpec := *spec.Process
+	processSpec.Args = cmd
+	processSpec.Terminal = tty
+
+	// stdin在进程创建前就开始转发，关闭时需等待进程启动
+	var process containerd.Process
+	started := make(chan struct{})
+	var in io.Reader
+	if stdin != nil {
+		in = &stdinCloser{Reader: stdin, onClose: func() {
+			<-started
+			if process != nil {
+				_ = process.CloseIO(context.Background(), containerd.WithStdinCloser)
+			}
+		}}
+	}
+	ioOpts := []cio.Opt{cio.WithStreams(in, stdout, stderr)}
+	if tty {
+		ioOpts = append(ioOpts, cio.WithTerminal)
+	}
+	process, err = task.Exec(ctx, "exec-"+newContainerID(), &processSpec, cio.NewCreator(ioOpts...))
+	if err != nil {
+		close(started)
+		return -1, err
+	}
+	defer process.Delete(context.Background(), containerd.WithProcessKill)
+	exitCh, err := process.Wait(context.Background())
+	if err != nil {
+		close(started)
+		return -1, err
+	}
+	err = process.Start(ctx)
+	close(started)
+	if err != nil {
+		return -1, err
+	}
+	var exitStatus containerd.ExitStatus
+	select {
+	case exitStatus = <-exitCh:
+	case <-ctx.Done():
+		_ = process.Kill(context.Background(), syscall.SIGKILL)
+		exitStatus = <-exitCh
+	}
+	// 等待输出全部转发
+	process.IO().Wait()
+	code, _, err := exitStatus.Result()
+	if err != nil {
+		return -1, err
+	}
+	return int(code), nil
+}
+
+// 日志文件只包含一次运行的输出，Since与Until不生效
+func (cs *containerdService) ContainerLogs(ctx context.Context, containerID string, opts *ContainerLogOptions, stdout, stderr io.Writer) error {
+	if opts.Timestamps {
+		return fmt.Errorf("timestamps are not supported by containerd runtime")
+	}
+	file, err := os.Open(containerLogPath(containerID, opts.Previous))
+	if err != nil {
+		if os.IsNotExist(err) && opts.Previous {
+			return fmt.Errorf("previous log of container %s not found", containerID)
+		}
+		return err
+	}
+	defer file.Close()
+	if opts.TailLines != nil {
+		offset, err := tailOffset(file, *opts.TailLines)
+		if err != nil {
+			return err
+		}
+		if _, err = file.Seek(offset, io.SeekStart); err != nil {
+			return err
+		}
+	}
+	if _, err = io.Copy(stdout, file); err != nil {
+		return err
+	}
+	if !opts.Follow || opts.Previous {
+		return nil
+	}
+	ticker := time.NewTicker(logPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// 客户端断开
+			return nil
+		case <-ticker.C:
+		}
+		n, err := io.Copy(stdout, file)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			continue
+		}
+		status, err := cs.ContainerStatus(containerID)
+		if err != nil {
+			return err
+		}
+		if status.State != ContainerStateRunning {
+			_, err = io.Copy(stdout, file)
+			return err
+		}
+	}
+}
+
+// 返回文件中最后n行的起始位置，末尾的换行不单独算作一行
+func tailOffset(file *os.File, n int64) (int64, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	size := info.Size()
+	if n <= 0 {
+		return size, nil
+	}
+	const chunkSize = 4096
+	buf := make([]byte, chunkSize)
+	var lines int64
+	for end := size; end > 0; {
+		start := end - chunkSize
+		if start < 0 {
+			start = 0
+		}
+		chunk := buf[:end-start]
+		if _, err = file.ReadAt(chunk, start); err != nil && err != io.EOF {
+			return 0, err
+		}
+		for i := len(chunk) - 1; i >= 0; i-- {
+			if chunk[i] != '\n' || start+int64(i) == size-1 {
+				continue
+			}
+			lines++
+			if lines == n {
+				return start + int64(i) + 1, nil
+			}
+		}
+		end = start
+	}
+	return 0, nil
+}
+
+// containerd中的镜像名为完整的引用，如docker.io/library/nginx:latest
+func normalizeImageRef(ref string) (string, error) {
+	named, err := docker.ParseDockerRef(ref)
+	if err != nil {
+		return "", err
+	}
+	return named.String(), nil
+}
+
+func (cs *containerdService) getImage(ctx context.Context, ref string) (containerd.Image, error) {
+	name, err := normalizeImageRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	return cs.client.GetImage(ctx, name)
+}
+
+func (cs *containerdService) PullImage(ref string, auth *AuthConfig) error {
+	name, err := normalizeImageRef(ref)
+	if err != nil {
+		return err
+	}
+	opts := []containerd.RemoteOpt{containerd.WithPullUnpack}
+	if auth != nil {
+		authorizer := remotesdocker.NewDockerAuthorizer(remotesdocker.WithAuthCreds(func(host string) (string, string, error) {
+			return auth.Username, auth.Password, nil
+		}))
+		opts = append(opts, containerd.WithResolver(remotesdocker.NewResolver(remotesdocker.ResolverOptions{
+			Hosts: remotesdocker.ConfigureDefaultRegistries(remotesdocker.WithAuthorizer(authorizer)),
+		})))
+	}
+	_, err = cs.client.Pull(context.Background(), name, opts...)
+	return err
+}
+
+// 以manifest的digest作为镜像id，同一digest的多个名称视为同一镜像的tag
+func (cs *containerdService) ListImages() ([]*Image, error) {
+	ctx := context.Background()
+	imgs, err := cs.client.ListImages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*Image)
+	var ret []*Image
+	for _, img := range imgs {
+		id := img.Target().Digest.String()
+		if existing, ok := byID[id]; ok {
+			existing.RepoTags = append(existing.RepoTags, img.Name())
+			continue
+		}
+		// 包括解压后的快照
+		size, err := img.Usage(ctx, containerd.WithSnapshotUsage())
+		if err != nil {
+			return nil, err
+		}
+		byID[id] = &Image{ID: id, RepoTags: []string{img.Name()}, Size: uint64(size)}
+		ret = append(ret, byID[id])
+	}
+	return ret, nil
+}
+
+func (cs *containerdService) RemoveImage(imageID string) error {
+	ctx := context.Background()
+	cntrs, err := cs.listContainers(nil, false)
+	if err != nil {
+		return err
+	}
+	sandboxes, err := cs.listContainers(nil, true)
+	if err != nil {
+		return err
+	}
+	// containerd不会阻止删除正在使用的镜像
+	for _, cntr := range append(cntrs, sandboxes...) {
+		if cntr.ImageID == imageID {
+			return fmt.Errorf("image %s is being used by container %s", imageID, cntr.ID)
+		}
+	}
+	imgs, err := cs.client.ListImages(ctx)
+	if err != nil {
+		return err
+	}
+	for _, img := range imgs {
+		if img.Target().Digest.String() != imageID {
+			continue
+		}
+		if err = cs.client.ImageService().Delete(ctx, img.Name(), images.SynchronousDelete()); err != nil && !errdefs.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cs *containerdService) ImageStatus(ref string) (bool, error) {
+	_, err := cs.getImage(context.Background(), ref)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// 镜像保存在containerd自身的根目录中
+func (cs *containerdService) ImageFsPath() (string, error) {
+	return "/var/lib/containerd", nil
+}
+
+// 直接读取任务进程所在的cgroup，避免依赖cgroups的protobuf类型
+func (cs *containerdService) ContainerStats(containerID string) (*ContainerStats, error) {
+	ctx := context.Background()
+	cntr, err := cs.client.LoadContainer(ctx, containerID)
+	if err != nil {
+		return nil, err
+	}
+	task, _, err := cs.taskStatus(ctx, cntr)
+	if err != nil {
+		return nil, err
+	}
+	if task == nil {
+		return &ContainerStats{}, nil
+	}
+	workingSet, err := cgroupMemoryWorkingSet(int(task.Pid()))
+	if err != nil {
+		return nil, err
+	}
+	return &ContainerStats{MemoryWorkingSetBytes: workingSet}, nil
+}
+
+// 命名空间中的容器均由kubelet创建
+func (cs *containerdService) ContainerEvents(ctx context.Context) (<-chan *ContainerEvent, <-chan error) {
+	var topicFilters []string
+	for _, topic := range []string{"/tasks/start", "/tasks/exit", "/tasks/oom", "/containers/delete"} {
+		topicFilters = append(topicFilters, fmt.Sprintf(`namespace==%q,topic==%q`, containerdNamespace, topic))
+	}
+	envelopes, errs := cs.client.Subscribe(ctx, topicFilters...)
+	eventCh := make(chan *ContainerEvent)
+	errCh := make(chan error, 1)
+	go func() {
+		defer close(eventCh)
+		for {
+			select {
+			case envelope := <-envelopes:
+				event, err := toContainerEvent(envelope)
+				if err != nil {
+					errCh <- err
+					return
+				}
+				if event == nil {
+					continue
+				}
+				select {
+				case eventCh <- event:
+				case <-ctx.Done():
+					return
+				}
+			case err := <-errs:
+				if ctx.Err() == nil {
+					errCh <- err
+				}
+				return
+			}
+		}
+	}()
+	return eventCh, errCh
+}
+
+// exec进程的退出事件返回nil
+func toContainerEvent(envelope *events.Envelope) (*ContainerEvent, error) {
+	decoded, err := typeurl.UnmarshalAny(envelope.Event)
+	if err != nil {
+		return nil, err
+	}
+	event := &ContainerEvent{Timestamp: envelope.Timestamp}
+	switch e := decoded.(type) {
+	case *apievents.TaskStart:
+		event.ContainerID, event.Type = e.ContainerID, ContainerEventStart
+	case *apievents.TaskExit:
+		if e.ID != e.ContainerID {
+			return nil, nil
+		}
+		event.ContainerID, event.Type = e.ContainerID, ContainerEventDie
+	case *apievents.TaskOOM:
+		event.ContainerID, event.Type = e.ContainerID, ContainerEventOOM
+	case *apievents.ContainerDelete:
+		event.ContainerID, event.Type = e.ID, ContainerEventDestroy
+	default:
+		return nil, nil
+	}
+	return event, nil
+}
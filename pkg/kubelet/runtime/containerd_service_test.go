@@ -0,0 +1,73 @@
+package runtime
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	apievents "github.com/containerd/containerd/api/events"
+	"github.com/containerd/containerd/events"
+	"github.com/containerd/containerd/oci"
+	"github.com/containerd/typeurl/v2"
+)
+
+func TestTailOffset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "current.log")
+	if err := os.WriteFile(path, []byte("a\nbb\nccc\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	for n, want := range map[int64]string{0: "", 1: "ccc\n", 2: "bb\nccc\n", 5: "a\nbb\nccc\n"} {
+		offset, err := tailOffset(file, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(io.NewSectionReader(file, offset, 1<<20))
+		if err != nil || string(content) != want {
+			t.Errorf("tail %d = %q, want %q, err = %v", n, content, want, err)
+		}
+	}
+}
+
+func TestSpecResources(t *testing.T) {
+	r := LinuxContainerResources{NanoCPUs: 500000000, CPUShares: 512, Memory: 64 << 20}
+	spec := &oci.Spec{}
+	if err := withResources(r)(nil, nil, nil, spec); err != nil {
+		t.Fatal(err)
+	}
+	if got := fromSpecResources(spec.Linux.Resources); got != r {
+		t.Errorf("fromSpecResources() = %+v, want %+v", got, r)
+	}
+}
+
+func TestToContainerEvent(t *testing.T) {
+	tests := []struct {
+		event interface{}
+		want  *ContainerEvent
+	}{
+		{&apievents.TaskStart{ContainerID: "c1"}, &ContainerEvent{ContainerID: "c1", Type: ContainerEventStart}},
+		{&apievents.TaskExit{ContainerID: "c1", ID: "c1"}, &ContainerEvent{ContainerID: "c1", Type: ContainerEventDie}},
+		// exec进程退出
+		{&apievents.TaskExit{ContainerID: "c1", ID: "exec-1"}, nil},
+		{&apievents.TaskOOM{ContainerID: "c1"}, &ContainerEvent{ContainerID: "c1", Type: ContainerEventOOM}},
+		{&apievents.ContainerDelete{ID: "c1"}, &ContainerEvent{ContainerID: "c1", Type: ContainerEventDestroy}},
+	}
+	for _, test := range tests {
+		any, err := typeurl.MarshalAny(test.event)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := toContainerEvent(&events.Envelope{Event: any})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (got == nil) != (test.want == nil) || got != nil && *got != *test.want {
+			t.Errorf("toContainerEvent(%T) = %+v, want %+v", test.event, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"io"
+	v1 "minikubernetes/pkg/api/v1"
+	"os"
+	"strings"
+	"time"
+)
+
+// 容器运行时的地址，为空时使用docker，如unix:///run/containerd/containerd.sock
+const RuntimeEndpointEnv = "CONTAINER_RUNTIME_ENDPOINT"
+
+// sandbox容器带有该标签
+const sandboxLabel = "PauseType"
+
+const pauseImage = "registry.aliyuncs.com/google_containers/pause:3.6"
+
+// 与k8s的CRI类似的容器运行时接口，runtimeManager只通过该接口操作容器
+// sandbox即pod的pause容器，持有pod的网络命名空间
+type RuntimeService interface {
+	// 拉取镜像并启动sandbox，完成网络配置
+	RunPodSandbox(config *PodSandboxConfig) (string, error)
+	// 停止sandbox并释放其网络
+	StopPodSandbox(sandboxID string) error
+	RemovePodSandbox(sandboxID string) error
+	PodSandboxStatus(sandboxID string) (*PodSandboxStatus, error)
+	// 列出标签与filter全部匹配的sandbox
+	ListPodSandbox(filter map[string]string) ([]*PodSandbox, error)
+
+	// 在sandbox中创建容器，容器共享sandbox的网络与pid命名空间
+	CreateContainer(sandboxID string, config *ContainerConfig) (string, error)
+	// 启动新创建或已停止的容器
+	StartContainer(containerID string) error
+	// 先发送SIGTERM，超时后强制停止，timeout为0时直接强制停止
+	StopContainer(containerID string, timeout time.Duration) error
+	RemoveContainer(containerID string) error
+	// 列出标签与filter全部匹配的容器，不包括sandbox
+	ListContainers(filter map[string]string) ([]*RuntimeContainer, error)
+	ContainerStatus(containerID string) (*ContainerStatus, error)
+	// 等待容器退出，返回退出码
+	WaitContainer(containerID string) (int, error)
+
+	// 在容器内同步执行命令，返回退出码与stdout、stderr合并的输出
+	ExecSync(containerID string, cmd []string, timeout time.Duration) (int, []byte, error)
+	// 交互式执行命令，stdin为nil时不转发输入
+	Exec(ctx context.Context, containerID string, cmd []string, tty bool, stdin io.Reader, stdout, stderr io.Writer) (int, error)
+	// 读取容器日志，follow时持续输出直到ctx结束或容器停止
+	ContainerLogs(ctx context.Context, containerID string, opts *ContainerLogOptions, stdout, stderr io.Writer) error
+
+	// 运行中容器的资源使用情况
+	ContainerStats(containerID string) (*ContainerStats, error)
+
+	// auth为nil时匿名拉取
+	PullImage(image string, auth *AuthConfig) error
+	// 镜像是否已存在于本地
+	ImageStatus(image string) (bool, error)
+	ListImages() ([]*Image, error)
+	// 删除镜像的所有tag，镜像仍被容器使用时失败
+	RemoveImage(imageID string) error
+	// 镜像所在的目录，用于统计imagefs的可用空间
+	ImageFsPath() (string, error)
+
+	// 订阅kubelet所管理容器（包括sandbox）的生命周期事件，直到ctx结束或出错
+	// 出错时事件流终止，错误通道中返回该错误
+	ContainerEvents(ctx context.Context) (<-chan *ContainerEvent, <-chan error)
+}
+
+type PodSandboxConfig struct {
+	PodID     v1.UID
+	Name      string
+	Namespace string
+	Labels    map[string]string
+	// pod内所有容器声明的端口，hostPort非0时映射到节点上，协议已统一为小写
+	Ports []v1.ContainerPort
+	// 为nil时使用镜像中的resolv.conf
+	DNS *DNSConfig
+	// 使用节点的网络命名空间，此时不配置CNI与端口映射
+	HostNetwork bool
+	// hostNetwork的sandbox以节点ip作为自身的ip
+	HostIP string
+}
+
+// 写入容器resolv.conf的内容
+type DNSConfig struct {
+	Servers  []string
+	Searches []string
+	// name或name:value形式
+	Options []string
+}
+
+type PodSandbox struct {
+	ID        string
+	Labels    map[string]string
+	State     ContainerState
+	CreatedAt time.Time
+}
+
+type PodSandboxStatus struct {
+	ID    string
+	State ContainerState
+	// sandbox进程的pid，用于进入其网络命名空间
+	Pid int
+	// CNI赋予的ip
+	IP string
+}
+
+type ContainerConfig struct {
+	Name   string
+	Image  string
+	Labels map[string]string
+	// 覆盖镜像的ENTRYPOINT
+	Command []string
+	// 覆盖镜像的CMD
+	Args       []string
+	WorkingDir string
+	// KEY=VALUE形式的环境变量
+	Env    []string
+	Mounts []Mount
+	Tty    bool
+	// 为空时使用镜像中的用户
+	User       string
+	Privileged bool
+	Resources  LinuxContainerResources
+	// 是否加入sandbox的pid命名空间
+	SharePid bool
+}
+
+type Mount struct {
+	HostPath      string
+	ContainerPath string
+	ReadOnly      bool
+}
+
+// 容器的cgroup配置
+type LinuxContainerResources struct {
+	// cpu limit，单位为10^-9核
+	NanoCPUs          int64
+	CPUShares         int64
+	Memory            int64
+	MemoryReservation int64
+}
+
+// list接口返回的容器概要
+type RuntimeContainer struct {
+	ID        string
+	Image     string
+	ImageID   string
+	Labels    map[string]string
+	State     ContainerState
+	CreatedAt time.Time
+}
+
+// 镜像仓库的凭据
+type AuthConfig struct {
+	Username string
+	Password string
+	// 仓库地址，如docker.io
+	ServerAddress string
+}
+
+type Image struct {
+	ID       string
+	RepoTags []string
+	// 镜像占用的磁盘空间，单位为字节
+	Size uint64
+}
+
+type ContainerStats struct {
+	// 内存工作集，即usage减去inactive_file，与k8s的驱逐判断一致
+	MemoryWorkingSetBytes uint64
+}
+
+type ContainerEventType string
+
+const (
+	ContainerEventStart   ContainerEventType = "start"
+	ContainerEventDie     ContainerEventType = "die"
+	ContainerEventOOM     ContainerEventType = "oom"
+	ContainerEventDestroy ContainerEventType = "destroy"
+)
+
+// 运行时上报的容器事件
+type ContainerEvent struct {
+	ContainerID string
+	Type        ContainerEventType
+	// 运行时产生该事件的时间
+	Timestamp time.Time
+}
+
+type ContainerLogOptions struct {
+	Follow    bool
+	TailLines *int64
+	// 只输出[Since, Until]之间的日志，零值表示不限制
+	Since      time.Time
+	Until      time.Time
+	Timestamps bool
+	// 读取上一次运行的日志
+	Previous bool
+}
+
+// 根据RuntimeEndpointEnv创建运行时
+func NewRuntimeService() (RuntimeService, error) {
+	endpoint := os.Getenv(RuntimeEndpointEnv)
+	if endpoint == "" {
+		return newDockerService("")
+	}
+	path := strings.TrimPrefix(endpoint, "unix://")
+	if strings.Contains(path, "containerd") {
+		return newContainerdService(path)
+	}
+	if strings.Contains(path, "docker") {
+		return newDockerService("unix://" + path)
+	}
+	return nil, fmt.Errorf("unsupported container runtime endpoint %s", endpoint)
+}
+
+// 本地不存在时拉取镜像
+func ensureImage(service RuntimeService, ref string) error {
+	exist, err := service.ImageStatus(ref)
+	if err != nil || exist {
+		return err
+	}
+	return service.PullImage(ref, nil)
+}
@@ -0,0 +1,141 @@
+package runtime
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	v1 "minikubernetes/pkg/api/v1"
+	"os"
+	"strings"
+)
+
+// dnsPolicy为Default时使用的节点resolv.conf，便于测试替换
+var hostResolvConfPath = "/etc/resolv.conf"
+
+// 与k8s一致，集群dns下短名称先按搜索域解析
+const clusterDNSNdots = "ndots:5"
+
+// 根据dnsPolicy与dnsConfig生成pod的dns配置
+func (rm *runtimeManager) makeDNSConfig(pod *v1.Pod) (*DNSConfig, error) {
+	policy := v1.GetDNSPolicy(pod)
+	// 与k8s一致，hostNetwork的pod只有显式指定ClusterFirstWithHostNet时才使用集群dns
+	if policy == v1.DNSClusterFirst && pod.Spec.HostNetwork {
+		policy = v1.DNSDefault
+	}
+	// 未找到集群dns时退化为Default
+	if (policy == v1.DNSClusterFirst || policy == v1.DNSClusterFirstWithHostNet) && rm.nameserverIP == "" {
+		policy = v1.DNSDefault
+	}
+	config := &DNSConfig{}
+	switch policy {
+	case v1.DNSClusterFirst, v1.DNSClusterFirstWithHostNet:
+		config.Servers = []string{rm.nameserverIP}
+		config.Searches = v1.GetClusterSearches(pod.Namespace)
+		config.Options = []string{clusterDNSNdots}
+	case v1.DNSDefault:
+		var err error
+		config, err = parseResolvConf(hostResolvConfPath)
+		if err != nil {
+			return nil, err
+		}
+	case v1.DNSNone:
+	default:
+		return nil, fmt.Errorf("unsupported dnsPolicy %q", pod.Spec.DNSPolicy)
+	}
+	if pod.Spec.DNSConfig != nil {
+		config.Servers = appendUnique(config.Servers, pod.Spec.DNSConfig.Nameservers...)
+		config.Searches = appendUnique(config.Searches, pod.Spec.DNSConfig.Searches...)
+		config.Options = mergeDNSOptions(config.Options, pod.Spec.DNSConfig.Options)
+	}
+	if len(config.Servers) == 0 {
+		return nil, fmt.Errorf("no nameserver for dnsPolicy %s", policy)
+	}
+	// 节点或dnsConfig中多出的部分被截断
+	if len(config.Servers) > v1.MaxDNSNameservers {
+		config.Servers = config.Servers[:v1.MaxDNSNameservers]
+	}
+	if len(config.Searches) > v1.MaxDNSSearches {
+		config.Searches = config.Searches[:v1.MaxDNSSearches]
+	}
+	return config, nil
+}
+
+func appendUnique(list []string, items ...string) []string {
+	for _, item := range items {
+		found := false
+		for _, existing := range list {
+			if existing == item {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// pod指定的选项覆盖同名选项
+func mergeDNSOptions(options []string, podOptions []v1.PodDNSConfigOption) []string {
+	ret := append([]string{}, options...)
+	for _, option := range podOptions {
+		value := option.Name
+		if option.Value != nil {
+			value += ":" + *option.Value
+		}
+		replaced := false
+		for i, existing := range ret {
+			if name, _, _ := strings.Cut(existing, ":"); name == option.Name {
+				ret[i] = value
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			ret = append(ret, value)
+		}
+	}
+	return ret
+}
+
+// 只解析nameserver、search与options，后出现的search覆盖之前的
+func parseResolvConf(path string) (*DNSConfig, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := &DNSConfig{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], ";") {
+			continue
+		}
+		switch fields[0] {
+		case "nameserver":
+			if len(fields) > 1 {
+				config.Servers = append(config.Servers, fields[1])
+			}
+		case "search", "domain":
+			config.Searches = fields[1:]
+		case "options":
+			config.Options = append(config.Options, fields[1:]...)
+		}
+	}
+	return config, scanner.Err()
+}
+
+func formatResolvConf(config *DNSConfig) []byte {
+	var buf bytes.Buffer
+	for _, server := range config.Servers {
+		fmt.Fprintf(&buf, "nameserver %s\n", server)
+	}
+	if len(config.Searches) > 0 {
+		fmt.Fprintf(&buf, "search %s\n", strings.Join(config.Searches, " "))
+	}
+	if len(config.Options) > 0 {
+		fmt.Fprintf(&buf, "options %s\n", strings.Join(config.Options, " "))
+	}
+	return buf.Bytes()
+}
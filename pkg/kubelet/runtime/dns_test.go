@@ -0,0 +1,69 @@
+package runtime
+
+import (
+	v1 "minikubernetes/pkg/api/v1"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMakeDNSConfig(t *testing.T) {
+	origHostResolvConfPath := hostResolvConfPath
+	t.Cleanup(func() { hostResolvConfPath = origHostResolvConfPath })
+	hostResolvConfPath = filepath.Join(t.TempDir(), "resolv.conf")
+	err := os.WriteFile(hostResolvConfPath, []byte("# host\nnameserver 8.8.8.8\nsearch example.com\noptions ndots:1 rotate\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rm := &runtimeManager{nameserverIP: "10.0.0.10"}
+	two := "2"
+	pod := &v1.Pod{ObjectMeta: v1.ObjectMeta{Name: "p", Namespace: "prod"}}
+	pod.Spec.DNSConfig = &v1.PodDNSConfig{
+		Nameservers: []string{"1.1.1.1"},
+		Searches:    []string{"corp.local"},
+		Options:     []v1.PodDNSConfigOption{{Name: "ndots", Value: &two}, {Name: "edns0"}},
+	}
+
+	cases := []struct {
+		policy      v1.DNSPolicy
+		hostNetwork bool
+		want        *DNSConfig
+	}{
+		{"", false, &DNSConfig{
+			Servers:  []string{"10.0.0.10", "1.1.1.1"},
+			Searches: []string{"prod.svc.cluster.local", "svc.cluster.local", "cluster.local", "corp.local"},
+			Options:  []string{"ndots:2", "edns0"},
+		}},
+		{v1.DNSClusterFirst, true, &DNSConfig{
+			Servers:  []string{"8.8.8.8", "1.1.1.1"},
+			Searches: []string{"example.com", "corp.local"},
+			Options:  []string{"ndots:2", "rotate", "edns0"},
+		}},
+		{v1.DNSClusterFirstWithHostNet, true, &DNSConfig{
+			Servers:  []string{"10.0.0.10", "1.1.1.1"},
+			Searches: []string{"prod.svc.cluster.local", "svc.cluster.local", "cluster.local", "corp.local"},
+			Options:  []string{"ndots:2", "edns0"},
+		}},
+		{v1.DNSNone, false, &DNSConfig{
+			Servers:  []string{"1.1.1.1"},
+			Searches: []string{"corp.local"},
+			Options:  []string{"ndots:2", "edns0"},
+		}},
+	}
+	for _, c := range cases {
+		pod.Spec.DNSPolicy, pod.Spec.HostNetwork = c.policy, c.hostNetwork
+		config, err := rm.makeDNSConfig(pod)
+		if err != nil {
+			t.Fatalf("policy %q: %v", c.policy, err)
+		}
+		if !reflect.DeepEqual(config, c.want) {
+			t.Errorf("policy %q, hostNetwork %v: config = %+v, want %+v", c.policy, c.hostNetwork, config, c.want)
+		}
+	}
+
+	pod.Spec.DNSPolicy, pod.Spec.DNSConfig = v1.DNSNone, nil
+	if _, err = rm.makeDNSConfig(pod); err == nil {
+		t.Errorf("dnsPolicy None without nameservers should fail")
+	}
+}
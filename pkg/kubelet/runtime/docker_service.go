@@ -0,0 +1,595 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	v1 "minikubernetes/pkg/api/v1"
+	nw "minikubernetes/pkg/kubelet/network"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/docker/go-connections/nat"
+)
+
+// hostNetwork的sandbox带有该标签，值为节点ip
+const hostIPLabel = "HostIP"
+
+// 基于docker的运行时，sandbox为pause容器，由weave为其配置网络
+type dockerService struct {
+	cli *client.Client
+	// weave为sandbox分配的ip，docker本身无法查询
+	lock       sync.Mutex
+	sandboxIPs map[string]string
+}
+
+// host为空时使用DOCKER_HOST等环境变量
+func newDockerService(host string) (*dockerService, error) {
+	opts := []client.Opt{client.FromEnv, client.WithAPIVersionNegotiation()}
+	if host != "" {
+		opts = append(opts, client.WithHost(host))
+	}
+	cli, err := client.NewClientWithOpts(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &dockerService{
+		cli:        cli,
+		sandboxIPs: make(map[string]string),
+	}, nil
+}
+
+func (ds *dockerService) RunPodSandbox(config *PodSandboxConfig) (string, error) {
+	ctx := context.Background()
+	if err := ensureImage(ds, pauseImage); err != nil {
+		return "", err
+	}
+	labels := make(map[string]string, len(config.Labels)+1)
+	for k, v := range config.Labels {
+		labels[k] = v
+	}
+	labels[sandboxLabel] = "pause"
+	hostConfig := &container.HostConfig{
+		PortBindings: getPortBindings(config.Ports),
+	}
+	if config.DNS != nil {
+		hostConfig.DNS = config.DNS.Servers
+		hostConfig.DNSSearch = config.DNS.Searches
+		hostConfig.DNSOptions = config.DNS.Options
+	}
+	if config.HostNetwork {
+		// 容器端口直接占用节点端口，无需映射
+		hostConfig.NetworkMode = network.NetworkHost
+		hostConfig.PortBindings = nil
+		labels[hostIPLabel] = config.HostIP
+	}
+	resp, err := ds.cli.ContainerCreate(ctx, &container.Config{
+		Image:        pauseImage,
+		Tty:          false,
+		Labels:       labels,
+		ExposedPorts: getExposedPorts(config.Ports),
+	}, hostConfig, nil, nil, "")
+	if err != nil {
+		return "", err
+	}
+	if err = ds.cli.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
+		return "", err
+	}
+	ip := config.HostIP
+	if !config.HostNetwork {
+		if ip, err = nw.Attach(resp.ID); err != nil {
+			return "", err
+		}
+	}
+	ds.lock.Lock()
+	ds.sandboxIPs[resp.ID] = ip
+	ds.lock.Unlock()
+	return resp.ID, nil
+}
+
+// hostPort非0的端口映射到节点的所有地址上
+func getPortBindings(ports []v1.ContainerPort) nat.PortMap {
+	bindings := make(nat.PortMap)
+	for _, port := range ports {
+		if port.HostPort == 0 {
+			continue
+		}
+		key := nat.Port(fmt.Sprintf("%d/%s", port.ContainerPort, v1.GetPortProtocol(port)))
+		bindings[key] = append(bindings[key], nat.PortBinding{HostPort: fmt.Sprint(port.HostPort)})
+	}
+	return bindings
+}
+
+func getExposedPorts(ports []v1.ContainerPort) nat.PortSet {
+	exposedPorts := nat.PortSet{}
+	for _, port := range ports {
+		var p string
+		switch port.Protocol {
+		case v1.ProtocolUDP:
+			p = "/udp"
+		default:
+			p = "/tcp"
+		}
+		exposedPorts[nat.Port(fmt.Sprint(port.ContainerPort)+p)] = struct{}{}
+	}
+	return exposedPorts
+}
+
+func (ds *dockerService) StopPodSandbox(sandboxID string) error {
+	info, err := ds.cli.ContainerInspect(context.Background(), sandboxID)
+	if err != nil {
+		return err
+	}
+	hostNetwork := info.HostConfig != nil && info.HostConfig.NetworkMode.IsHost()
+	if info.State != nil && info.State.Running && !hostNetwork {
+		if err = nw.Detach(sandboxID); err != nil {
+			return err
+		}
+	}
+	ds.lock.Lock()
+	delete(ds.sandboxIPs, sandboxID)
+	ds.lock.Unlock()
+	return ds.StopContainer(sandboxID, 0)
+}
+
+func (ds *dockerService) RemovePodSandbox(sandboxID string) error {
+	return ds.RemoveContainer(sandboxID)
+}
+
+func (ds *dockerService) PodSandboxStatus(sandboxID string) (*PodSandboxStatus, error) {
+	info, err := ds.cli.ContainerInspect(context.Background(), sandboxID)
+	if err != nil {
+		return nil, err
+	}
+	status := &PodSandboxStatus{ID: sandboxID, State: ContainerStateUnknown}
+	if info.State != nil {
+		status.State = toContainerState(info.State.Status)
+		status.Pid = info.State.Pid
+	}
+	ds.lock.Lock()
+	ip, ok := ds.sandboxIPs[sandboxID]
+	ds.lock.Unlock()
+	// kubelet重启后从weave查询，hostNetwork的sandbox从标签中获取
+	if !ok && status.State == ContainerStateRunning {
+		if info.HostConfig != nil && info.HostConfig.NetworkMode.IsHost() && info.Config != nil {
+			ip = info.Config.Labels[hostIPLabel]
+		} else if ip, err = nw.LookupIP(sandboxID); err != nil {
+			return nil, err
+		}
+		ds.lock.Lock()
+		ds.sandboxIPs[sandboxID] = ip
+		ds.lock.Unlock()
+	}
+	status.IP = ip
+	return status, nil
+}
+
+func (ds *dockerService) ListPodSandbox(filter map[string]string) ([]*PodSandbox, error) {
+	containers, err := ds.listContainers(filter, true)
+	if err != nil {
+		return nil, err
+	}
+	var ret []*PodSandbox
+	for _, ct := range containers {
+		ret = append(ret, &PodSandbox{
+			ID:        ct.ID,
+			Labels:    ct.Labels,
+			State:     toContainerState(ct.State),
+			CreatedAt: time.Unix(ct.Created, 0),
+		})
+	}
+	return ret, nil
+}
+
+func (ds *dockerService) listContainers(filter map[string]string, sandbox bool) ([]types.Container, error) {
+	args := filters.NewArgs()
+	for k, v := range filter {
+		args.Add("label", k+"="+v)
+	}
+	if sandbox {
+		args.Add("label", sandboxLabel)
+	}
+	containers, err := ds.cli.ContainerList(context.Background(), container.ListOptions{All: true, Filters: args})
+	if err != nil {
+		return nil, err
+	}
+	if sandbox {
+		return containers, nil
+	}
+	var ret []types.Container
+	for _, ct := range containers {
+		if _, ok := ct.Labels[sandboxLabel]; !ok {
+			ret = append(ret, ct)
+		}
+	}
+	return ret, nil
+}
+
+func (ds *dockerService) CreateContainer(sandboxID string, config *ContainerConfig) (string, error) {
+	sandboxRef := "container:" + sandboxID
+	var mounts []mount.Mount
+	for _, m := range config.Mounts {
+		mounts = append(mounts, mount.Mount{
+			Type:     mount.TypeBind,
+			Source:   m.HostPath,
+			Target:   m.ContainerPath,
+			ReadOnly: m.ReadOnly,
+		})
+	}
+	containerConfig := &container.Config{
+		Image:      config.Image,
+		Tty:        config.Tty,
+		Labels:     config.Labels,
+		Env:        config.Env,
+		WorkingDir: config.WorkingDir,
+		User:       config.User,
+	}
+	// 与k8s一致，command覆盖ENTRYPOINT，args覆盖CMD
+	if len(config.Command) != 0 {
+		containerConfig.Entrypoint = config.Command
+	}
+	if len(config.Args) != 0 {
+		containerConfig.Cmd = config.Args
+	}
+	hostConfig := &container.HostConfig{
+		NetworkMode: container.NetworkMode(sandboxRef),
+		Mounts:      mounts,
+		Privileged:  config.Privileged,
+		Resources: container.Resources{
+			NanoCPUs:          config.Resources.NanoCPUs,
+			CPUShares:         config.Resources.CPUShares,
+			Memory:            config.Resources.Memory,
+			MemoryReservation: config.Resources.MemoryReservation,
+		},
+	}
+	if config.SharePid {
+		hostConfig.PidMode = container.PidMode(sandboxRef)
+	}
+	resp, err := ds.cli.ContainerCreate(context.Background(), containerConfig, hostConfig, nil, nil, "")
+	if err != nil {
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+func (ds *dockerService) StartContainer(containerID string) error {
+	return ds.cli.ContainerStart(context.Background(), containerID, container.StartOptions{})
+}
+
+func (ds *dockerService) StopContainer(containerID string, timeout time.Duration) error {
+	seconds := int(timeout / time.Second)
+	return ds.cli.ContainerStop(context.Background(), containerID, container.StopOptions{Timeout: &seconds})
+}
+
+func (ds *dockerService) RemoveContainer(containerID string) error {
+	return ds.cli.ContainerRemove(context.Background(), containerID, container.RemoveOptions{Force: true})
+}
+
+func (ds *dockerService) ListContainers(filter map[string]string) ([]*RuntimeContainer, error) {
+	containers, err := ds.listContainers(filter, false)
+	if err != nil {
+		return nil, err
+	}
+	var ret []*RuntimeContainer
+	for _, ct := range containers {
+		ret = append(ret, &RuntimeContainer{
+			ID:        ct.ID,
+			Image:     ct.Image,
+			ImageID:   ct.ImageID,
+			Labels:    ct.Labels,
+			State:     toContainerState(ct.State),
+			CreatedAt: time.Unix(ct.Created, 0),
+		})
+	}
+	return ret, nil
+}
+
+func toContainerState(state string) ContainerState {
+	switch state {
+	case "exited":
+		return ContainerStateExited
+	case "running":
+		return ContainerStateRunning
+	case "created":
+		return ContainerStateCreated
+	default:
+		return ContainerStateUnknown
+	}
+}
+
+func (ds *dockerService) ContainerStatus(containerID string) (*ContainerStatus, error) {
+	info, err := ds.cli.ContainerInspect(context.Background(), containerID)
+	if err != nil {
+		return nil, err
+	}
+	status := &ContainerStatus{
+		ID:      info.ID,
+		ImageID: info.Image,
+		State:   ContainerStateUnknown,
+	}
+	if info.Config != nil {
+		status.Name = info.Config.Labels["Name"]
+		status.Image = info.Config.Image
+	}
+	// docker使用RFC3339Nano格式，未启动的容器时间为零值
+	status.CreatedAt, _ = time.Parse(time.RFC3339Nano, info.Created)
+	if info.State != nil {
+		status.State = toContainerState(info.State.Status)
+		status.StartedAt, _ = time.Parse(time.RFC3339Nano, info.State.StartedAt)
+		status.FinishedAt, _ = time.Parse(time.RFC3339Nano, info.State.FinishedAt)
+		status.Message = info.State.Error
+		if status.State == ContainerStateExited {
+			status.ExitCode = info.State.ExitCode
+			switch {
+			case info.State.OOMKilled:
+				// 超出内存limit被内核杀死
+				status.Reason = "OOMKilled"
+			case status.ExitCode == 0:
+				status.Reason = "Completed"
+			default:
+				status.Reason = "Error"
+			}
+		}
+	}
+	if info.HostConfig != nil {
+		r := info.HostConfig.Resources
+		status.Resources = toContainerResources(LinuxContainerResources{
+			NanoCPUs:          r.NanoCPUs,
+			CPUShares:         r.CPUShares,
+			Memory:            r.Memory,
+			MemoryReservation: r.MemoryReservation,
+		})
+	}
+	return status, nil
+}
+
+func (ds *dockerService) WaitContainer(containerID string) (int, error) {
+	statusCh, errCh := ds.cli.ContainerWait(context.Background(), containerID, container.WaitConditionNotRunning)
+	select {
+	case err := <-errCh:
+		return -1, err
+	case status := <-statusCh:
+		return int(status.StatusCode), nil
+	}
+}
+
+func (ds *dockerService) ExecSync(containerID string, cmd []string, timeout time.Duration) (int, []byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	execResp, err := ds.cli.ContainerExecCreate(ctx, containerID, types.ExecConfig{
+		Cmd:          cmd,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return -1, nil, err
+	}
+	attachResp, err := ds.cli.ContainerExecAttach(ctx, execResp.ID, types.ExecStartCheck{})
+	if err != nil {
+		return -1, nil, err
+	}
+	defer attachResp.Close()
+	var output bytes.Buffer
+	// 输出为stdout与stderr复用的流
+	_, err = stdcopy.StdCopy(&output, &output, attachResp.Reader)
+	if err != nil {
+		return -1, output.Bytes(), err
+	}
+	inspect, err := ds.cli.ContainerExecInspect(ctx, execResp.ID)
+	if err != nil {
+		return -1, output.Bytes(), err
+	}
+	return inspect.ExitCode, output.Bytes(), nil
+}
+
+func (ds *dockerService) Exec(ctx context.Context, containerID string, cmd []string, tty bool, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	execResp, err := ds.cli.ContainerExecCreate(ctx, containerID, types.ExecConfig{
+		Cmd:          cmd,
+		Tty:          tty,
+		AttachStdin:  stdin != nil,
+		AttachStdout: true,
+		AttachStderr: !tty,
+	})
+	if err != nil {
+		return -1, err
+	}
+	attachResp, err := ds.cli.ContainerExecAttach(ctx, execResp.ID, types.ExecStartCheck{Tty: tty})
+	if err != nil {
+		return -1, err
+	}
+	defer attachResp.Close()
+	if stdin != nil {
+		go func() {
+			_, _ = io.Copy(attachResp.Conn, stdin)
+			_ = attachResp.CloseWrite()
+		}()
+	}
+	if tty {
+		_, err = io.Copy(stdout, attachResp.Reader)
+	} else {
+		_, err = stdcopy.StdCopy(stdout, stderr, attachResp.Reader)
+	}
+	if err != nil {
+		return -1, err
+	}
+	inspect, err := ds.cli.ContainerExecInspect(ctx, execResp.ID)
+	if err != nil {
+		return -1, err
+	}
+	return inspect.ExitCode, nil
+}
+
+// docker接受带小数的unix时间戳
+func dockerTimestamp(t time.Time) string {
+	return fmt.Sprintf("%d.%09d", t.Unix(), t.Nanosecond())
+}
+
+// 容器原地重启，docker日志包含所有历次运行的输出，由调用方给出运行的起止时间
+func (ds *dockerService) ContainerLogs(ctx context.Context, containerID string, opts *ContainerLogOptions, stdout, stderr io.Writer) error {
+	info, err := ds.cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return err
+	}
+	logOptions := container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     opts.Follow,
+		Timestamps: opts.Timestamps,
+	}
+	if !opts.Since.IsZero() {
+		logOptions.Since = dockerTimestamp(opts.Since)
+	}
+	if !opts.Until.IsZero() {
+		logOptions.Until = dockerTimestamp(opts.Until)
+	}
+	if opts.TailLines != nil {
+		logOptions.Tail = strconv.FormatInt(*opts.TailLines, 10)
+	}
+	reader, err := ds.cli.ContainerLogs(ctx, containerID, logOptions)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	// tty模式下日志不区分stdout与stderr
+	if info.Config != nil && info.Config.Tty {
+		_, err = io.Copy(stdout, reader)
+	} else {
+		_, err = stdcopy.StdCopy(stdout, stderr, reader)
+	}
+	if err != nil && ctx.Err() != nil {
+		// 客户端断开
+		return nil
+	}
+	return err
+}
+
+func (ds *dockerService) PullImage(ref string, auth *AuthConfig) error {
+	var opts image.PullOptions
+	if auth != nil {
+		encoded, err := registry.EncodeAuthConfig(registry.AuthConfig{
+			Username:      auth.Username,
+			Password:      auth.Password,
+			ServerAddress: auth.ServerAddress,
+		})
+		if err != nil {
+			return err
+		}
+		opts.RegistryAuth = encoded
+	}
+	reader, err := ds.cli.ImagePull(context.Background(), ref, opts)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	// 拉取过程中的错误（如鉴权失败）只出现在进度流中
+	return jsonmessage.DisplayJSONMessagesStream(reader, os.Stdout, 0, false, nil)
+}
+
+func (ds *dockerService) ListImages() ([]*Image, error) {
+	images, err := ds.cli.ImageList(context.Background(), image.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var ret []*Image
+	for _, img := range images {
+		ret = append(ret, &Image{ID: img.ID, RepoTags: img.RepoTags, Size: uint64(img.Size)})
+	}
+	return ret, nil
+}
+
+func (ds *dockerService) RemoveImage(imageID string) error {
+	// 镜像有多个tag时需强制删除，被容器使用时docker仍会拒绝
+	_, err := ds.cli.ImageRemove(context.Background(), imageID, image.RemoveOptions{Force: true, PruneChildren: true})
+	return err
+}
+
+func (ds *dockerService) ImageStatus(ref string) (bool, error) {
+	images, err := ds.cli.ImageList(context.Background(), image.ListOptions{})
+	if err != nil {
+		return false, err
+	}
+	for _, img := range images {
+		for _, rt := range img.RepoTags {
+			if rt == ref {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (ds *dockerService) ImageFsPath() (string, error) {
+	info, err := ds.cli.Info(context.Background())
+	if err != nil {
+		return "", err
+	}
+	return info.DockerRootDir, nil
+}
+
+func (ds *dockerService) ContainerStats(containerID string) (*ContainerStats, error) {
+	resp, err := ds.cli.ContainerStatsOneShot(context.Background(), containerID)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var stats types.StatsJSON
+	if err = json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return nil, err
+	}
+	return &ContainerStats{
+		MemoryWorkingSetBytes: memoryWorkingSet(stats.MemoryStats.Usage, stats.MemoryStats.Stats),
+	}, nil
+}
+
+// 只订阅带有PodID标签的容器，即由kubelet创建的容器与sandbox
+func (ds *dockerService) ContainerEvents(ctx context.Context) (<-chan *ContainerEvent, <-chan error) {
+	args := filters.NewArgs(
+		filters.Arg("type", string(events.ContainerEventType)),
+		filters.Arg("label", "PodID"),
+	)
+	for _, action := range []events.Action{events.ActionStart, events.ActionDie, events.ActionOOM, events.ActionDestroy} {
+		args.Add("event", string(action))
+	}
+	messages, errs := ds.cli.Events(ctx, types.EventsOptions{Filters: args})
+	eventCh := make(chan *ContainerEvent)
+	errCh := make(chan error, 1)
+	go func() {
+		defer close(eventCh)
+		for {
+			select {
+			case msg := <-messages:
+				event := &ContainerEvent{
+					ContainerID: msg.Actor.ID,
+					Type:        ContainerEventType(msg.Action),
+					Timestamp:   time.Unix(0, msg.TimeNano),
+				}
+				select {
+				case eventCh <- event:
+				case <-ctx.Done():
+					return
+				}
+			case err := <-errs:
+				if ctx.Err() == nil {
+					errCh <- err
+				}
+				return
+			}
+		}
+	}()
+	return eventCh, errCh
+}
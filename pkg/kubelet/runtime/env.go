@@ -0,0 +1,130 @@
+package runtime
+
+import (
+	"fmt"
+	v1 "minikubernetes/pkg/api/v1"
+	"minikubernetes/pkg/kubelet/utils"
+	"regexp"
+)
+
+// 未设置limit时以节点容量作为取值，便于测试替换
+var getNodeCapacity = utils.GetNodeCapacity
+
+// metadata.labels['key']形式的fieldPath
+var subscriptFieldPathRegexp = regexp.MustCompile(`^(metadata\.labels|metadata\.annotations)\['([^']+)'\]$`)
+
+// 将容器声明的环境变量解析为docker所需的KEY=VALUE形式
+func makeEnvironmentVariables(pod *v1.Pod, c *v1.Container, podIP string) ([]string, error) {
+	var env []string
+	for _, envVar := range c.Env {
+		value := envVar.Value
+		if envVar.ValueFrom != nil {
+			var err error
+			switch {
+			case envVar.ValueFrom.FieldRef != nil:
+				value, err = PodFieldValue(pod, envVar.ValueFrom.FieldRef.FieldPath, podIP)
+			case envVar.ValueFrom.ResourceFieldRef != nil:
+				value, err = ResourceFieldValue(pod, c, envVar.ValueFrom.ResourceFieldRef)
+			default:
+				err = fmt.Errorf("valueFrom has no source")
+			}
+			if err != nil {
+				return nil, fmt.Errorf("env %s: %v", envVar.Name, err)
+			}
+		}
+		env = append(env, envVar.Name+"="+value)
+	}
+	return env, nil
+}
+
+// PodFieldValue 返回fieldRef引用的pod字段，status.hostIP取自pod.Status.HostIP
+func PodFieldValue(pod *v1.Pod, fieldPath string, podIP string) (string, error) {
+	if m := subscriptFieldPathRegexp.FindStringSubmatch(fieldPath); m != nil {
+		if m[1] == "metadata.labels" {
+			return pod.Labels[m[2]], nil
+		}
+		return pod.Annotations[m[2]], nil
+	}
+	switch fieldPath {
+	case "metadata.name":
+		return pod.Name, nil
+	case "metadata.namespace":
+		return pod.Namespace, nil
+	case "metadata.uid":
+		return string(pod.UID), nil
+	case "spec.nodeName":
+		return pod.Spec.NodeName, nil
+	case "status.podIP":
+		return podIP, nil
+	case "status.hostIP":
+		return pod.Status.HostIP, nil
+	default:
+		return "", fmt.Errorf("unsupported fieldPath %q", fieldPath)
+	}
+}
+
+// ResourceFieldValue 返回resourceFieldRef引用的资源量，c为nil时必须指定containerName
+func ResourceFieldValue(pod *v1.Pod, c *v1.Container, selector *v1.ResourceFieldSelector) (string, error) {
+	target := c
+	if c == nil && selector.ContainerName == "" {
+		return "", fmt.Errorf("containerName is required")
+	}
+	if selector.ContainerName != "" && (c == nil || selector.ContainerName != c.Name) {
+		target = nil
+		for i := range pod.Spec.Containers {
+			if pod.Spec.Containers[i].Name == selector.ContainerName {
+				target = &pod.Spec.Containers[i]
+				break
+			}
+		}
+		if target == nil {
+			return "", fmt.Errorf("container %s not found", selector.ContainerName)
+		}
+	}
+
+	var list v1.ResourceList
+	var resourceName v1.ResourceName
+	switch selector.Resource {
+	case "limits.cpu":
+		list, resourceName = target.Resources.Limits, v1.ResourceCPU
+	case "limits.memory":
+		list, resourceName = target.Resources.Limits, v1.ResourceMemory
+	case "requests.cpu":
+		list, resourceName = target.Resources.Requests, v1.ResourceCPU
+	case "requests.memory":
+		list, resourceName = target.Resources.Requests, v1.ResourceMemory
+	default:
+		return "", fmt.Errorf("unsupported resource %q", selector.Resource)
+	}
+	quantity := list[resourceName]
+	if quantity == "" {
+		// 与k8s一致，未设置时使用节点容量
+		capacity, err := getNodeCapacity()
+		if err != nil {
+			return "", err
+		}
+		quantity = capacity[resourceName]
+	}
+	divisor := selector.Divisor
+	if divisor == "" {
+		divisor = "1"
+	}
+
+	parse := v1.ParseMemory
+	if resourceName == v1.ResourceCPU {
+		parse = v1.ParseCPU
+	}
+	value, err := parse(quantity)
+	if err != nil {
+		return "", err
+	}
+	unit, err := parse(divisor)
+	if err != nil {
+		return "", err
+	}
+	if unit <= 0 {
+		return "", fmt.Errorf("invalid divisor %q", divisor)
+	}
+	// 向上取整，避免0.5核被解析为0
+	return fmt.Sprint((value + unit - 1) / unit), nil
+}
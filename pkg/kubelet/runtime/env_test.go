@@ -0,0 +1,68 @@
+package runtime
+
+import (
+	v1 "minikubernetes/pkg/api/v1"
+	"reflect"
+	"testing"
+)
+
+func TestMakeEnvironmentVariables(t *testing.T) {
+	origGetNodeCapacity := getNodeCapacity
+	t.Cleanup(func() { getNodeCapacity = origGetNodeCapacity })
+	getNodeCapacity = func() (v1.ResourceList, error) {
+		return v1.ResourceList{v1.ResourceCPU: "4", v1.ResourceMemory: "8Gi"}, nil
+	}
+	pod := &v1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: "nginx", Namespace: "default", UID: "uid-1", Labels: map[string]string{"app": "web"}},
+		Spec: v1.PodSpec{
+			NodeName: "node-0",
+			Containers: []v1.Container{{
+				Name: "c",
+				Resources: v1.ResourceRequirements{
+					Limits: v1.ResourceList{v1.ResourceCPU: "500m", v1.ResourceMemory: "128Mi"},
+				},
+				Env: []v1.EnvVar{
+					{Name: "PLAIN", Value: "value"},
+					{Name: "POD_NAME", ValueFrom: &v1.EnvVarSource{FieldRef: &v1.ObjectFieldSelector{FieldPath: "metadata.name"}}},
+					{Name: "POD_IP", ValueFrom: &v1.EnvVarSource{FieldRef: &v1.ObjectFieldSelector{FieldPath: "status.podIP"}}},
+					{Name: "NODE", ValueFrom: &v1.EnvVarSource{FieldRef: &v1.ObjectFieldSelector{FieldPath: "spec.nodeName"}}},
+					{Name: "HOST_IP", ValueFrom: &v1.EnvVarSource{FieldRef: &v1.ObjectFieldSelector{FieldPath: "status.hostIP"}}},
+					{Name: "APP", ValueFrom: &v1.EnvVarSource{FieldRef: &v1.ObjectFieldSelector{FieldPath: "metadata.labels['app']"}}},
+					{Name: "NO_ANNOTATION", ValueFrom: &v1.EnvVarSource{FieldRef: &v1.ObjectFieldSelector{FieldPath: "metadata.annotations['missing']"}}},
+					{Name: "CPU", ValueFrom: &v1.EnvVarSource{ResourceFieldRef: &v1.ResourceFieldSelector{Resource: "limits.cpu"}}},
+					{Name: "CPU_MILLI", ValueFrom: &v1.EnvVarSource{ResourceFieldRef: &v1.ResourceFieldSelector{Resource: "limits.cpu", Divisor: "1m"}}},
+					{Name: "MEM_MI", ValueFrom: &v1.EnvVarSource{ResourceFieldRef: &v1.ResourceFieldSelector{Resource: "limits.memory", Divisor: "1Mi"}}},
+					{Name: "REQ_MEM", ValueFrom: &v1.EnvVarSource{ResourceFieldRef: &v1.ResourceFieldSelector{Resource: "requests.memory", Divisor: "1Gi"}}},
+				},
+			}},
+		},
+		Status: v1.PodStatus{HostIP: "192.168.1.10"},
+	}
+	env, err := makeEnvironmentVariables(pod, &pod.Spec.Containers[0], "10.32.0.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"PLAIN=value",
+		"POD_NAME=nginx",
+		"POD_IP=10.32.0.2",
+		"NODE=node-0",
+		"HOST_IP=192.168.1.10",
+		"APP=web",
+		"NO_ANNOTATION=",
+		"CPU=1",
+		"CPU_MILLI=500",
+		"MEM_MI=128",
+		"REQ_MEM=8",
+	}
+	if !reflect.DeepEqual(env, want) {
+		t.Fatalf("env = %v, want %v", env, want)
+	}
+
+	pod.Spec.Containers[0].Env = []v1.EnvVar{
+		{Name: "BAD", ValueFrom: &v1.EnvVarSource{FieldRef: &v1.ObjectFieldSelector{FieldPath: "metadata.labels"}}},
+	}
+	if _, err = makeEnvironmentVariables(pod, &pod.Spec.Containers[0], ""); err == nil {
+		t.Fatalf("expected error for unsupported fieldPath")
+	}
+}
@@ -0,0 +1,448 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"io"
+	v1 "minikubernetes/pkg/api/v1"
+	"net"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 内存中的RuntimeManager，模拟sandbox、ip分配与容器的状态变化，供kubelet各组件的测试使用
+// 创建后的容器立即处于running状态，退出需由测试调用ExitContainer触发
+type FakeRuntimeManager struct {
+	lock   sync.Mutex
+	pods   map[v1.UID]*fakePod
+	nextID int
+	nextIP int
+	// 可替换的时钟
+	Now func() time.Time
+	// 不为nil时AddPod返回该错误
+	AddPodErr error
+	// ExecInContainer与ExecInContainerStream的行为，为nil时返回退出码0
+	ExecFunc func(containerID string, cmd []string) (int, []byte, error)
+	// 不为nil时WatchContainerEvents立即返回该错误，模拟事件流不可用
+	EventsErr error
+	// PullImage的行为，为nil时拉取总是成功
+	PullImageFunc func(image string, auth *AuthConfig) error
+	// 本地镜像，以镜像名为key，id为"sha256:"加镜像名
+	images map[string]*Image
+	// 按调用顺序记录的操作，如"AddPod name"、"RestartContainer name/c"、"DeletePod name 30s"
+	calls []string
+	// 容器事件的订阅者
+	watchers map[chan *ContainerEvent]struct{}
+}
+
+// sandbox只体现为pod的ip
+type fakePod struct {
+	pod        *v1.Pod
+	ip         string
+	containers []*ContainerStatus
+	// 由SetPodMemory设置
+	memory uint64
+}
+
+func NewFakeRuntimeManager() *FakeRuntimeManager {
+	return &FakeRuntimeManager{
+		pods:     make(map[v1.UID]*fakePod),
+		Now:      time.Now,
+		watchers: make(map[chan *ContainerEvent]struct{}),
+		images:   make(map[string]*Image),
+	}
+}
+
+func (f *FakeRuntimeManager) GetCalls() []string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return append([]string{}, f.calls...)
+}
+
+func (f *FakeRuntimeManager) newID() string {
+	f.nextID++
+	return fmt.Sprintf("fake-%d", f.nextID)
+}
+
+// 调用方需持有锁，订阅者来不及接收时丢弃事件
+func (f *FakeRuntimeManager) emit(containerID string, eventType ContainerEventType) {
+	event := &ContainerEvent{ContainerID: containerID, Type: eventType, Timestamp: f.Now()}
+	for ch := range f.watchers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+func (f *FakeRuntimeManager) AddPod(pod *v1.Pod) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.calls = append(f.calls, "AddPod "+pod.Name)
+	if f.AddPodErr != nil {
+		return f.AddPodErr
+	}
+	if _, ok := f.pods[pod.UID]; ok {
+		return fmt.Errorf("pod %s already exists", pod.UID)
+	}
+	f.nextIP++
+	fp := &fakePod{
+		pod: pod,
+		ip:  fmt.Sprintf("10.32.0.%d", f.nextIP),
+	}
+	now := f.Now()
+	for _, c := range pod.Spec.Containers {
+		fp.containers = append(fp.containers, &ContainerStatus{
+			ID:        f.newID(),
+			Name:      c.Name,
+			Image:     c.Image,
+			ImageID:   "sha256:" + c.Image,
+			State:     ContainerStateRunning,
+			CreatedAt: now,
+			StartedAt: now,
+		})
+		f.emit(fp.containers[len(fp.containers)-1].ID, ContainerEventStart)
+	}
+	f.pods[pod.UID] = fp
+	return nil
+}
+
+func (f *FakeRuntimeManager) GetAllPods() ([]*Pod, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	var ret []*Pod
+	for _, fp := range f.pods {
+		pod := &Pod{
+			ID:        fp.pod.UID,
+			Name:      fp.pod.Name,
+			Namespace: fp.pod.Namespace,
+		}
+		for _, cs := range fp.containers {
+			pod.Containers = append(pod.Containers, &Container{
+				ID:    cs.ID,
+				Name:  cs.Name,
+				Image: cs.Image,
+				State: cs.State,
+			})
+		}
+		ret = append(ret, pod)
+	}
+	return ret, nil
+}
+
+// 内存中的状态不会丢失，直接返回所有pod
+func (f *FakeRuntimeManager) RecoverPods() ([]*Pod, error) {
+	return f.GetAllPods()
+}
+
+func (f *FakeRuntimeManager) GetPodStatus(ID v1.UID, PodName string, PodSpace string) (*PodStatus, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	status := &PodStatus{
+		ID:        ID,
+		Name:      PodName,
+		Namespace: PodSpace,
+		TimeStamp: f.Now(),
+	}
+	fp, ok := f.pods[ID]
+	if !ok {
+		return status, nil
+	}
+	status.IPs = []string{fp.ip}
+	for _, cs := range fp.containers {
+		// 返回副本，避免调用方看到之后的状态变化
+		c := *cs
+		status.ContainerStatuses = append(status.ContainerStatuses, &c)
+	}
+	return status, nil
+}
+
+func (f *FakeRuntimeManager) DeletePod(ID v1.UID, gracePeriod time.Duration) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if fp, ok := f.pods[ID]; ok {
+		// 宽限期按秒取整记录
+		f.calls = append(f.calls, fmt.Sprintf("DeletePod %s %v", fp.pod.Name, gracePeriod.Round(time.Second)))
+		delete(f.pods, ID)
+		for _, cs := range fp.containers {
+			f.emit(cs.ID, ContainerEventDestroy)
+		}
+	}
+	return nil
+}
+
+func (f *FakeRuntimeManager) findContainer(podID v1.UID, containerName string) (*fakePod, *ContainerStatus, error) {
+	fp, ok := f.pods[podID]
+	if !ok {
+		return nil, nil, fmt.Errorf("pod %s not found", podID)
+	}
+	for _, cs := range fp.containers {
+		if cs.Name == containerName {
+			return fp, cs, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("container %s of pod %s not found", containerName, podID)
+}
+
+func (f *FakeRuntimeManager) RestartContainer(podID v1.UID, containerName string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	fp, cs, err := f.findContainer(podID, containerName)
+	if err != nil {
+		return err
+	}
+	f.calls = append(f.calls, "RestartContainer "+fp.pod.Name+"/"+containerName)
+	if cs.State == ContainerStateExited {
+		last := *cs
+		last.LastTermination = nil
+		cs.LastTermination = &last
+	}
+	cs.RestartCount++
+	cs.State = ContainerStateRunning
+	cs.StartedAt = f.Now()
+	cs.FinishedAt = time.Time{}
+	cs.ExitCode = 0
+	cs.Reason = ""
+	f.emit(cs.ID, ContainerEventStart)
+	return nil
+}
+
+func (f *FakeRuntimeManager) CreateMissingContainers(pod *v1.Pod) ([]string, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	fp, ok := f.pods[pod.UID]
+	if !ok {
+		return nil, fmt.Errorf("sandbox of pod %s is not running", pod.Name)
+	}
+	existing := make(map[string]bool)
+	for _, cs := range fp.containers {
+		existing[cs.Name] = true
+	}
+	var created []string
+	now := f.Now()
+	for _, c := range pod.Spec.Containers {
+		if existing[c.Name] {
+			continue
+		}
+		f.calls = append(f.calls, "CreateContainer "+pod.Name+"/"+c.Name)
+		cs := &ContainerStatus{
+			ID:        f.newID(),
+			Name:      c.Name,
+			Image:     c.Image,
+			ImageID:   "sha256:" + c.Image,
+			State:     ContainerStateRunning,
+			CreatedAt: now,
+			StartedAt: now,
+		}
+		fp.containers = append(fp.containers, cs)
+		created = append(created, c.Name)
+		f.emit(cs.ID, ContainerEventStart)
+	}
+	return created, nil
+}
+
+// 模拟容器在kubelet之外被删除，sandbox保留
+func (f *FakeRuntimeManager) RemoveContainer(podID v1.UID, containerName string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	fp, cs, err := f.findContainer(podID, containerName)
+	if err != nil {
+		return err
+	}
+	fp.containers = slices.DeleteFunc(fp.containers, func(c *ContainerStatus) bool {
+		return c == cs
+	})
+	f.emit(cs.ID, ContainerEventDestroy)
+	return nil
+}
+
+// 模拟容器退出
+func (f *FakeRuntimeManager) ExitContainer(podID v1.UID, containerName string, exitCode int) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	_, cs, err := f.findContainer(podID, containerName)
+	if err != nil {
+		return err
+	}
+	f.exit(cs, exitCode)
+	return nil
+}
+
+func (f *FakeRuntimeManager) exit(cs *ContainerStatus, exitCode int) {
+	cs.State = ContainerStateExited
+	cs.ExitCode = exitCode
+	cs.FinishedAt = f.Now()
+	if exitCode == 0 {
+		cs.Reason = "Completed"
+	} else {
+		cs.Reason = "Error"
+	}
+	f.emit(cs.ID, ContainerEventDie)
+}
+
+func (f *FakeRuntimeManager) KillContainer(containerID string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for _, fp := range f.pods {
+		for _, cs := range fp.containers {
+			if cs.ID == containerID {
+				f.calls = append(f.calls, "KillContainer "+fp.pod.Name+"/"+cs.Name)
+				if cs.State == ContainerStateRunning {
+					// 与SIGKILL的退出码一致
+					f.exit(cs, 137)
+				}
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("container %s not found", containerID)
+}
+
+func (f *FakeRuntimeManager) ExecInContainer(containerID string, cmd []string, timeout time.Duration) (int, []byte, error) {
+	if f.ExecFunc == nil {
+		return 0, nil, nil
+	}
+	return f.ExecFunc(containerID, cmd)
+}
+
+func (f *FakeRuntimeManager) GetContainerLogs(ctx context.Context, podID v1.UID, containerName string, opts *LogOptions, stdout, stderr io.Writer) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	_, _, err := f.findContainer(podID, containerName)
+	return err
+}
+
+func (f *FakeRuntimeManager) ExecInContainerStream(ctx context.Context, podID v1.UID, containerName string, cmd []string, tty bool, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	f.lock.Lock()
+	_, cs, err := f.findContainer(podID, containerName)
+	f.lock.Unlock()
+	if err != nil {
+		return -1, err
+	}
+	code, output, err := f.ExecInContainer(cs.ID, cmd, 0)
+	if err != nil {
+		return -1, err
+	}
+	_, err = stdout.Write(output)
+	return code, err
+}
+
+func (f *FakeRuntimeManager) DialPodPort(podID v1.UID, port int32) (net.Conn, error) {
+	return nil, fmt.Errorf("port forwarding is not supported by fake runtime")
+}
+
+func (f *FakeRuntimeManager) WatchContainerEvents(ctx context.Context) (<-chan *ContainerEvent, <-chan error) {
+	errCh := make(chan error, 1)
+	if f.EventsErr != nil {
+		errCh <- f.EventsErr
+		return nil, errCh
+	}
+	ch := make(chan *ContainerEvent, 100)
+	f.lock.Lock()
+	f.watchers[ch] = struct{}{}
+	f.lock.Unlock()
+	go func() {
+		<-ctx.Done()
+		f.lock.Lock()
+		delete(f.watchers, ch)
+		close(ch)
+		f.lock.Unlock()
+	}()
+	return ch, errCh
+}
+
+func (f *FakeRuntimeManager) GetPodStats(ID v1.UID) (*PodStats, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	stats := &PodStats{}
+	if fp, ok := f.pods[ID]; ok {
+		stats.MemoryWorkingSetBytes = fp.memory
+	}
+	return stats, nil
+}
+
+// 设置GetPodStats返回的内存工作集
+func (f *FakeRuntimeManager) SetPodMemory(ID v1.UID, bytes uint64) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if fp, ok := f.pods[ID]; ok {
+		fp.memory = bytes
+	}
+}
+
+func (f *FakeRuntimeManager) ImageFsPath() (string, error) {
+	return os.TempDir(), nil
+}
+
+func (f *FakeRuntimeManager) PullImage(image string, auth *AuthConfig) error {
+	f.lock.Lock()
+	pullFunc := f.PullImageFunc
+	f.calls = append(f.calls, "PullImage "+image)
+	f.lock.Unlock()
+	if pullFunc != nil {
+		if err := pullFunc(image, auth); err != nil {
+			return err
+		}
+	}
+	f.AddImage(image, 0)
+	return nil
+}
+
+// 向本地添加镜像
+func (f *FakeRuntimeManager) AddImage(image string, size uint64) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if _, ok := f.images[image]; !ok {
+		f.images[image] = &Image{ID: "sha256:" + image, RepoTags: []string{image}, Size: size}
+	}
+}
+
+func (f *FakeRuntimeManager) ImageExists(image string) (bool, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	_, ok := f.images[image]
+	return ok, nil
+}
+
+// 按镜像名排序
+func (f *FakeRuntimeManager) ListImages() ([]*Image, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	var ret []*Image
+	for _, img := range f.images {
+		c := *img
+		ret = append(ret, &c)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].RepoTags[0] < ret[j].RepoTags[0]
+	})
+	return ret, nil
+}
+
+func (f *FakeRuntimeManager) RemoveImage(imageID string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for name, img := range f.images {
+		if img.ID == imageID {
+			f.calls = append(f.calls, "RemoveImage "+name)
+			delete(f.images, name)
+			return nil
+		}
+	}
+	return fmt.Errorf("image %s not found", imageID)
+}
+
+func (f *FakeRuntimeManager) GetImagesInUse() (map[string]bool, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	inUse := make(map[string]bool)
+	for _, fp := range f.pods {
+		for _, cs := range fp.containers {
+			inUse[cs.ImageID] = true
+		}
+	}
+	return inUse, nil
+}
@@ -0,0 +1,67 @@
+package runtime
+
+import (
+	"fmt"
+	v1 "minikubernetes/pkg/api/v1"
+	"net"
+	goruntime "runtime"
+	"time"
+
+	"github.com/vishvananda/netns"
+)
+
+// 连接pod端口的超时时间
+const portForwardDialTimeout = 5 * time.Second
+
+// pod内所有容器共享pause容器的网络命名空间，在其中连接本地端口
+func (rm *runtimeManager) DialPodPort(podID v1.UID, port int32) (net.Conn, error) {
+	sandboxes, err := rm.service.ListPodSandbox(map[string]string{"PodID": string(podID)})
+	if err != nil {
+		return nil, err
+	}
+	if len(sandboxes) == 0 {
+		return nil, fmt.Errorf("pause container of pod %s not found", podID)
+	}
+	status, err := rm.service.PodSandboxStatus(sandboxes[0].ID)
+	if err != nil {
+		return nil, err
+	}
+	if status.Pid == 0 {
+		return nil, fmt.Errorf("pause container of pod %s is not running", podID)
+	}
+	return dialInNetns(status.Pid, port)
+}
+
+// socket在创建时绑定所在线程的网络命名空间，连接建立后即可切回原命名空间
+func dialInNetns(pid int, port int32) (net.Conn, error) {
+	goruntime.LockOSThread()
+	origin, err := netns.Get()
+	if err != nil {
+		goruntime.UnlockOSThread()
+		return nil, err
+	}
+	defer origin.Close()
+	target, err := netns.GetFromPid(pid)
+	if err != nil {
+		goruntime.UnlockOSThread()
+		return nil, err
+	}
+	defer target.Close()
+	if err = netns.Set(target); err != nil {
+		goruntime.UnlockOSThread()
+		return nil, err
+	}
+	conn, dialErr := net.DialTimeout("tcp", fmt.Sprintf("127.0.0.1:%d", port), portForwardDialTimeout)
+	if err = netns.Set(origin); err != nil {
+		// 线程无法恢复时不解锁，goroutine结束后该线程随之销毁
+		if conn != nil {
+			conn.Close()
+		}
+		return nil, fmt.Errorf("failed to restore network namespace: %v", err)
+	}
+	goruntime.UnlockOSThread()
+	if dialErr != nil {
+		return nil, dialErr
+	}
+	return conn, nil
+}
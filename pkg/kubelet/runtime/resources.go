@@ -0,0 +1,79 @@
+package runtime
+
+import (
+	"fmt"
+	v1 "minikubernetes/pkg/api/v1"
+)
+
+const (
+	// 与k8s一致，1核对应1024个cpu share，未设置request时使用最小值2
+	sharesPerCPU = 1024
+	minShares    = 2
+	// docker允许的最小内存限制
+	minMemoryLimit = 6 * 1024 * 1024
+)
+
+// 将容器的requests与limits转换为cgroup配置
+// cpu limit对应NanoCPUs，cpu request对应CPUShares，内存limit与request分别对应Memory与MemoryReservation
+func makeContainerResources(c *v1.Container) (LinuxContainerResources, error) {
+	var resources LinuxContainerResources
+	cpuLimit, err := v1.ParseCPU(c.Resources.Limits[v1.ResourceCPU])
+	if err != nil {
+		return resources, err
+	}
+	cpuRequest, err := v1.ParseCPU(c.Resources.Requests[v1.ResourceCPU])
+	if err != nil {
+		return resources, err
+	}
+	memoryLimit, err := v1.ParseMemory(c.Resources.Limits[v1.ResourceMemory])
+	if err != nil {
+		return resources, err
+	}
+	memoryRequest, err := v1.ParseMemory(c.Resources.Requests[v1.ResourceMemory])
+	if err != nil {
+		return resources, err
+	}
+	// 只设置limit时request与limit相同
+	if cpuRequest == 0 {
+		cpuRequest = cpuLimit
+	}
+	if memoryRequest == 0 {
+		memoryRequest = memoryLimit
+	}
+	if cpuLimit > 0 && cpuRequest > cpuLimit {
+		return resources, fmt.Errorf("cpu request %vm exceeds limit %vm", cpuRequest, cpuLimit)
+	}
+	if memoryLimit > 0 && memoryRequest > memoryLimit {
+		return resources, fmt.Errorf("memory request %v exceeds limit %v", memoryRequest, memoryLimit)
+	}
+	if memoryLimit > 0 && memoryLimit < minMemoryLimit {
+		return resources, fmt.Errorf("memory limit %v is less than the minimum %v", memoryLimit, minMemoryLimit)
+	}
+
+	resources.NanoCPUs = cpuLimit * 1000 * 1000
+	resources.CPUShares = cpuRequest * sharesPerCPU / 1000
+	if resources.CPUShares < minShares {
+		resources.CPUShares = minShares
+	}
+	resources.Memory = memoryLimit
+	resources.MemoryReservation = memoryRequest
+	return resources, nil
+}
+
+// 由运行时中实际生效的配置得到容器资源，cpu以毫核、内存以字节表示
+func toContainerResources(r LinuxContainerResources) *ContainerResources {
+	ret := &ContainerResources{}
+	if r.NanoCPUs > 0 {
+		ret.CPULimit = fmt.Sprintf("%vm", r.NanoCPUs/(1000*1000))
+	}
+	if r.CPUShares > minShares {
+		ret.CPURequest = fmt.Sprintf("%vm", r.CPUShares*1000/sharesPerCPU)
+	}
+	if r.Memory > 0 {
+		ret.MemoryLimit = fmt.Sprint(r.Memory)
+	}
+	if r.MemoryReservation > 0 {
+		ret.MemoryRequest = fmt.Sprint(r.MemoryReservation)
+	}
+	return ret
+}
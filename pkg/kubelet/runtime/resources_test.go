@@ -0,0 +1,41 @@
+package runtime
+
+import (
+	v1 "minikubernetes/pkg/api/v1"
+	"testing"
+)
+
+func TestMakeContainerResources(t *testing.T) {
+	c := &v1.Container{Resources: v1.ResourceRequirements{
+		Limits:   v1.ResourceList{v1.ResourceCPU: "1", v1.ResourceMemory: "128Mi"},
+		Requests: v1.ResourceList{v1.ResourceCPU: "250m"},
+	}}
+	r, err := makeContainerResources(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.NanoCPUs != 1000000000 || r.CPUShares != 256 {
+		t.Errorf("cpu: NanoCPUs = %v, CPUShares = %v", r.NanoCPUs, r.CPUShares)
+	}
+	if r.Memory != 128<<20 || r.MemoryReservation != 128<<20 {
+		t.Errorf("memory: Memory = %v, MemoryReservation = %v", r.Memory, r.MemoryReservation)
+	}
+	status := toContainerResources(r)
+	if status.CPULimit != "1000m" || status.CPURequest != "250m" || status.MemoryLimit != "134217728" {
+		t.Errorf("toContainerResources() = %+v", status)
+	}
+
+	r, err = makeContainerResources(&v1.Container{})
+	if err != nil || r.NanoCPUs != 0 || r.Memory != 0 || r.CPUShares != minShares {
+		t.Errorf("best effort: resources = %+v, err = %v", r, err)
+	}
+
+	c.Resources.Requests[v1.ResourceCPU] = "2"
+	if _, err = makeContainerResources(c); err == nil {
+		t.Errorf("request above limit should fail")
+	}
+	c.Resources = v1.ResourceRequirements{Limits: v1.ResourceList{v1.ResourceMemory: "1Mi"}}
+	if _, err = makeContainerResources(c); err == nil {
+		t.Errorf("memory limit below docker minimum should fail")
+	}
+}
@@ -71,7 +71,16 @@ type ContainerStatus struct {
 	FinishedAt time.Time
 	ExitCode   int
 	Image      string
-	Resources  *ContainerResources
+	ImageID    string
+	// 退出原因，如Completed、Error
+	Reason string
+	// 运行时给出的错误信息
+	Message   string
+	Resources *ContainerResources
+	// 容器被kubelet重启的次数
+	RestartCount int
+	// 上一次重启前容器的终止状态
+	LastTermination *ContainerStatus
 }
 
 type ContainerResources struct {
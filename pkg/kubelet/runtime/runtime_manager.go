@@ -4,45 +4,77 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"minikubernetes/pkg/microservice/envoy"
+	"net"
 	"os"
+	"path/filepath"
+	"slices"
 	"strconv"
-	"strings"
 	"sync"
 	"time"
 
-	"github.com/docker/docker/api/types/mount"
-
-	"github.com/docker/docker/api/types"
-	"github.com/docker/docker/api/types/container"
-	"github.com/docker/docker/api/types/image"
-	"github.com/docker/docker/client"
-	"github.com/docker/go-connections/nat"
-
 	v1 "minikubernetes/pkg/api/v1"
-	nw "minikubernetes/pkg/kubelet/network"
 )
 
 type RuntimeManager interface {
 	AddPod(pod *v1.Pod) error
 	GetAllPods() ([]*Pod, error)
 	GetPodStatus(ID v1.UID, PodName string, PodSpace string) (*PodStatus, error)
-	DeletePod(ID v1.UID) error
-	RestartPod(pod *v1.Pod) error
+	// 并行向pod的容器发送SIGTERM，超过gracePeriod后SIGKILL，再删除容器与sandbox
+	DeletePod(ID v1.UID, gracePeriod time.Duration) error
+	// 原地重启pod中的单个容器
+	RestartContainer(podID v1.UID, containerName string) error
+	// 在pod运行中的sandbox内创建并启动缺失的容器，返回创建的容器名
+	CreateMissingContainers(pod *v1.Pod) ([]string, error)
+	// 停止单个容器，之后由重启策略决定是否重启
+	KillContainer(containerID string) error
+	// 在容器内同步执行命令，返回退出码与输出
+	ExecInContainer(containerID string, cmd []string, timeout time.Duration) (int, []byte, error)
+	// 读取容器日志，follow时持续输出直到ctx结束
+	GetContainerLogs(ctx context.Context, podID v1.UID, containerName string, opts *LogOptions, stdout, stderr io.Writer) error
+	// 交互式执行命令，stdin为nil时不转发输入
+	ExecInContainerStream(ctx context.Context, podID v1.UID, containerName string, cmd []string, tty bool, stdin io.Reader, stdout, stderr io.Writer) (int, error)
+	// 在pod的网络命名空间内连接指定端口
+	DialPodPort(podID v1.UID, port int32) (net.Conn, error)
+	// kubelet重启后根据sandbox重建pod ip，返回运行时中已有的pod，包括只剩sandbox的pod
+	RecoverPods() ([]*Pod, error)
+	// 订阅容器生命周期事件，用于及时触发pleg的relist
+	WatchContainerEvents(ctx context.Context) (<-chan *ContainerEvent, <-chan error)
+	// pod中运行的容器的资源使用之和
+	GetPodStats(ID v1.UID) (*PodStats, error)
+	// 镜像所在的目录
+	ImageFsPath() (string, error)
+	// 按auth拉取镜像，auth为nil时匿名拉取
+	PullImage(image string, auth *AuthConfig) error
+	ImageExists(image string) (bool, error)
+	ListImages() ([]*Image, error)
+	RemoveImage(imageID string) error
+	// 被容器（包括已退出的容器）使用的镜像id，pause镜像总是被视为使用中
+	GetImagesInUse() (map[string]bool, error)
+}
+
+type PodStats struct {
+	MemoryWorkingSetBytes uint64
 }
 
 type runtimeManager struct {
 	lock         sync.Mutex
+	service      RuntimeService
 	IpMap        map[v1.UID]string
 	nameserverIP string
+	// pod uid -> 容器名 -> 重启记录
+	restartRecords map[v1.UID]map[string]*restartRecord
+}
+
+type restartRecord struct {
+	count           int
+	lastTermination *ContainerStatus
 }
 
 func (rm *runtimeManager) GetAllPods() ([]*Pod, error) {
 	rm.lock.Lock()
 	defer rm.lock.Unlock()
-	containers, err := rm.getAllContainers()
+	containers, err := rm.service.ListContainers(nil)
 	if err != nil {
-		// panic(err)
 		return nil, err
 	}
 	var ret []*Pod
@@ -53,7 +85,7 @@ func (rm *runtimeManager) GetAllPods() ([]*Pod, error) {
 		tempContainer.ID = container.ID
 		tempContainer.Image = container.Image
 		tempContainer.Name = container.Labels["Name"]
-		rm.checkContainerState(container, tempContainer)
+		tempContainer.State = container.State
 
 		podBelonged, ok := container.Labels["PodName"]
 		if !ok {
@@ -62,14 +94,12 @@ func (rm *runtimeManager) GetAllPods() ([]*Pod, error) {
 
 		for _, podexist := range ret {
 			if podexist.Name == podBelonged {
-				//fmt.Println("yes " + podBelonged)
 				podexist.Containers = append(podexist.Containers, tempContainer)
 				flag = true
 				break
 			}
 		}
 		if !flag {
-			//fmt.Println("no " + podBelonged)
 			tempPod := new(Pod)
 			tempPod.Name = podBelonged
 			tempPod.Namespace = container.Labels["PodNamespace"]
@@ -87,7 +117,6 @@ func (rm *runtimeManager) GetPodStatus(ID v1.UID, PodName string, PodSpace strin
 	defer rm.lock.Unlock()
 	containers, err := rm.getPodContainers(PodName)
 	if err != nil {
-		//panic(err)
 		return nil, err
 	}
 	podStatus := &PodStatus{
@@ -102,34 +131,61 @@ func (rm *runtimeManager) GetPodStatus(ID v1.UID, PodName string, PodSpace strin
 	if ok {
 		podStatus.IPs = append(podStatus.IPs, ip)
 	}
+	for _, cs := range podStatus.ContainerStatuses {
+		if record, ok := rm.restartRecords[ID][cs.Name]; ok {
+			cs.RestartCount = record.count
+			cs.LastTermination = record.lastTermination
+		}
+	}
 	return podStatus, nil
 }
 
 func (rm *runtimeManager) getPodContainers(PodName string) ([]*ContainerStatus, error) {
-	containers, err := rm.getAllContainers()
+	containers, err := rm.service.ListContainers(map[string]string{"PodName": PodName})
 	if err != nil {
-		//panic(err)
 		return nil, err
 	}
 	var ret []*ContainerStatus
 	for _, container := range containers {
-		tempContainer := new(ContainerStatus)
-		if container.Labels["PodName"] == PodName {
-			tempContainer.ID = container.ID
-			tempContainer.Image = container.Image
-			tempContainer.Name = container.Labels["Name"]
-			tempContainer.CreatedAt = time.Unix(container.Created, 0)
-			rm.checkContainerStatus(container, tempContainer)
-
-			ret = append(ret, tempContainer)
+		status, err := rm.service.ContainerStatus(container.ID)
+		if err != nil {
+			return nil, err
 		}
+		ret = append(ret, status)
 	}
 	return ret, nil
 }
 
-func NewRuntimeManager(nameserverIP string) RuntimeManager {
+// 在重启前记录容器的终止状态
+func (rm *runtimeManager) recordRestart(podID v1.UID, status *ContainerStatus) {
+	if rm.restartRecords[podID] == nil {
+		rm.restartRecords[podID] = make(map[string]*restartRecord)
+	}
+	record, ok := rm.restartRecords[podID][status.Name]
+	if !ok {
+		record = &restartRecord{}
+		rm.restartRecords[podID][status.Name] = record
+	}
+	record.count++
+	if status.State == ContainerStateExited {
+		record.lastTermination = status
+	}
+}
+
+// 根据RuntimeEndpointEnv选择docker或containerd
+func NewRuntimeManager(nameserverIP string) (RuntimeManager, error) {
+	service, err := NewRuntimeService()
+	if err != nil {
+		return nil, err
+	}
+	return NewRuntimeManagerWithService(service, nameserverIP), nil
+}
+
+func NewRuntimeManagerWithService(service RuntimeService, nameserverIP string) RuntimeManager {
 	manager := &runtimeManager{}
+	manager.service = service
 	manager.IpMap = make(map[v1.UID]string)
+	manager.restartRecords = make(map[v1.UID]map[string]*restartRecord)
 	manager.nameserverIP = nameserverIP
 	return manager
 }
@@ -137,29 +193,33 @@ func NewRuntimeManager(nameserverIP string) RuntimeManager {
 func (rm *runtimeManager) AddPod(pod *v1.Pod) error {
 	rm.lock.Lock()
 	defer rm.lock.Unlock()
-	//PauseId, err := rm.CreatePauseContainer(pod.UID, pod.Name, pod.Namespace)
-	PauseId, err := rm.CreatePauseContainer(pod)
+	sandboxID, err := rm.createPodSandbox(pod)
 	if err != nil {
-		//panic(err)
 		return err
 	}
 
 	for _, c := range pod.Spec.InitContainers {
-		err = rm.createInitContainer(&c, PauseId)
+		env, err := makeEnvironmentVariables(pod, &c, rm.IpMap[pod.UID])
+		if err != nil {
+			return err
+		}
+		err = rm.runInitContainer(&c, sandboxID, env)
 		if err != nil {
 			return err
 		}
 	}
 
-	containerList := pod.Spec.Containers
-	for _, container := range containerList {
-		volumes, err := rm.createVolumeDir(pod)
+	volumes, err := rm.createVolumeDir(pod)
+	if err != nil {
+		return err
+	}
+	for _, container := range pod.Spec.Containers {
+		env, err := makeEnvironmentVariables(pod, &container, rm.IpMap[pod.UID])
 		if err != nil {
 			return err
 		}
-		_, err = rm.createContainer(&container, PauseId, pod.UID, pod.Name, pod.Namespace, volumes)
+		_, err = rm.createContainer(&container, sandboxID, pod, volumes, env)
 		if err != nil {
-			//panic(err)
 			return err
 		}
 	}
@@ -167,634 +227,416 @@ func (rm *runtimeManager) AddPod(pod *v1.Pod) error {
 	return nil
 }
 
-// func (rm *runtimeManager) CreatePauseContainer(PodID v1.UID, PodName string, PodNameSpace string) (string, error) {
-func (rm *runtimeManager) CreatePauseContainer(pod *v1.Pod) (string, error) {
-	PodID := pod.UID
-	PodName := pod.Name
-	PodNameSpace := pod.Namespace
-	ctx := context.Background()
-	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
-	if err != nil {
-		//panic(err)
-		return "", err
+// hostNetwork的pod以pod.Status.HostIP作为ip，由kubelet在创建前写入
+func (rm *runtimeManager) createPodSandbox(pod *v1.Pod) (string, error) {
+	var ports []v1.ContainerPort
+	for _, c := range pod.Spec.Containers {
+		for _, port := range c.Ports {
+			port.Protocol = v1.GetPortProtocol(port)
+			ports = append(ports, port)
+		}
 	}
-	defer cli.Close()
-	PauseContainerImage := "registry.aliyuncs.com/google_containers/pause:3.6"
-	exi, err := rm.checkImages(PauseContainerImage)
+	dns, err := rm.makeDNSConfig(pod)
 	if err != nil {
-		//panic(err)
 		return "", err
 	}
-	if !exi {
-		//fmt.Println("yes")
-		reader, err := cli.ImagePull(ctx, PauseContainerImage, image.PullOptions{})
-		if err != nil {
-			//panic(err)
-			return "", err
-		}
-		defer reader.Close()
-		io.Copy(os.Stdout, reader)
-	} else {
-		//fmt.Println("already exist")
-	}
-	label := make(map[string]string)
-	label["PodID"] = string(PodID)
-	label["PodName"] = PodName
-	label["PauseType"] = "pause"
-	label["Name"] = PodName + ":PauseContainer"
-	label["PodNamespace"] = PodNameSpace
-	resp, err := cli.ContainerCreate(ctx, &container.Config{
-		Image:        PauseContainerImage,
-		Tty:          false,
-		Labels:       label,
-		ExposedPorts: rm.getExposedPorts(pod.Spec.Containers),
-	}, &container.HostConfig{
-		PortBindings: make(nat.PortMap),
-		DNS:          []string{rm.nameserverIP},
-	}, nil, nil, "")
+	if pod.Spec.HostNetwork && pod.Status.HostIP == "" {
+		return "", fmt.Errorf("host ip of pod %s is unknown", pod.Name)
+	}
+	sandboxID, err := rm.service.RunPodSandbox(&PodSandboxConfig{
+		PodID:     pod.UID,
+		Name:      pod.Name,
+		Namespace: pod.Namespace,
+		Labels: map[string]string{
+			"PodID":        string(pod.UID),
+			"PodName":      pod.Name,
+			"Name":         pod.Name + ":PauseContainer",
+			"PodNamespace": pod.Namespace,
+		},
+		Ports:       ports,
+		DNS:         dns,
+		HostNetwork: pod.Spec.HostNetwork,
+		HostIP:      pod.Status.HostIP,
+	})
 	if err != nil {
-		//panic(err)
 		return "", err
 	}
-
-	if err := cli.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
-		//panic(err)
-		return "", err
-	}
-
-	ip, err := nw.Attach(resp.ID)
+	status, err := rm.service.PodSandboxStatus(sandboxID)
 	if err != nil {
-		//panic(err)
 		return "", err
 	}
-	rm.IpMap[PodID] = ip
-
-	return resp.ID, nil
-}
-
-func (rm *runtimeManager) getExposedPorts(containers []v1.Container) nat.PortSet {
-	exposedPorts := nat.PortSet{}
-	for _, c := range containers {
-		ports := c.Ports
-		portKeys := make(map[string]struct{})
-		for _, port := range ports {
-			var p string
-			switch port.Protocol {
-			case v1.ProtocolTCP:
-				p = "/tcp"
-			case v1.ProtocolUDP:
-				p = "/udp"
-			default:
-				p = "/tcp"
-			}
-			key := fmt.Sprint(port.ContainerPort) + p
-			portKeys[key] = struct{}{}
-		}
-		for key := range portKeys {
-			exposedPorts[nat.Port(key)] = struct{}{}
-		}
-	}
-	return exposedPorts
-}
-
-func (rm *runtimeManager) getPortBindings(containers []v1.Container) nat.PortMap {
-	portBindings := make(nat.PortMap)
-	for _, c := range containers {
-		ports := c.Ports
-		for _, num := range ports {
-			key := nat.Port(fmt.Sprint(num.ContainerPort) + "/tcp")
-			portBindings[key] = []nat.PortBinding{
-				{
-					HostIP:   "0.0.0.0",
-					HostPort: "",
-				},
-			}
-		}
-	}
-	return portBindings
+	rm.IpMap[pod.UID] = status.IP
+	return sandboxID, nil
 }
 
 // 目前init container只是为了支持sidecar，简化了很多逻辑
-func (rm *runtimeManager) createInitContainer(c *v1.Container, pauseID string) error {
-	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+func (rm *runtimeManager) runInitContainer(c *v1.Container, sandboxID string, env []string) error {
+	resources, err := makeContainerResources(c)
 	if err != nil {
-		return err
-	}
-	defer cli.Close()
-	exist, err := rm.checkImages(c.Image)
+		return fmt.Errorf("container %s: %v", c.Name, err)
+	}
+	config := &ContainerConfig{
+		Name:       c.Name,
+		Image:      c.Image,
+		Command:    c.Command,
+		Args:       c.Args,
+		WorkingDir: c.WorkingDir,
+		Env:        env,
+		Tty:        true,
+		Resources:  resources,
+	}
+	if c.SecurityContext != nil && c.SecurityContext.Privileged != nil {
+		config.Privileged = *c.SecurityContext.Privileged
+	}
+	containerID, err := rm.service.CreateContainer(sandboxID, config)
 	if err != nil {
 		return err
 	}
-	if !exist {
-		readCloser, err := cli.ImagePull(context.Background(), c.Image, image.PullOptions{})
-		if err != nil {
-			return err
-		}
-		// 读取pull的输出
-		_, _ = io.ReadAll(readCloser)
-		_ = readCloser.Close()
-	}
-	hostConfig := &container.HostConfig{
-		NetworkMode: container.NetworkMode("container:" + pauseID),
-	}
-	if c.SecurityContext != nil {
-		if c.SecurityContext.Privileged != nil && *c.SecurityContext.Privileged {
-			hostConfig.Privileged = *c.SecurityContext.Privileged
-		}
-	}
-	// run container
-	resp, err := cli.ContainerCreate(context.Background(), &container.Config{
-		Image: c.Image,
-		Tty:   true,
-	}, hostConfig, nil, nil, "")
-	if err != nil {
+	if err = rm.service.StartContainer(containerID); err != nil {
 		return err
 	}
-	if err = cli.ContainerStart(context.Background(), resp.ID, container.StartOptions{}); err != nil {
+	exitCode, err := rm.service.WaitContainer(containerID)
+	if err != nil {
 		return err
 	}
-	// wait for init container to finish
-	statusCh, errCh := cli.ContainerWait(context.Background(), resp.ID, container.WaitConditionNotRunning)
-	select {
-	case err = <-errCh:
-		if err != nil {
-			return err
-		}
-	case status := <-statusCh:
-		if status.StatusCode != 0 {
-			return fmt.Errorf("init container exited with status %d", status.StatusCode)
-		}
+	if exitCode != 0 {
+		return fmt.Errorf("init container exited with status %d", exitCode)
 	}
-	// delete init container
-	if err = cli.ContainerRemove(context.Background(), resp.ID, container.RemoveOptions{}); err != nil {
-		return err
-	}
-	return nil
+	return rm.service.RemoveContainer(containerID)
 }
 
-func (rm *runtimeManager) createContainer(ct *v1.Container, PauseId string, PodID v1.UID, PodName string, PodNameSpace string, volumes map[string]string) (string, error) {
-	repotag := ct.Image
-	cmd := ct.Command
-	ctx := context.Background()
-	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
-	if err != nil {
-		//panic(err)
-		return "", err
-	}
-	defer cli.Close()
-
-	exi, err := rm.checkImages(repotag)
-	if err != nil {
-		//panic(err)
-		return "", err
-	}
-	if !exi {
-		//fmt.Println("yes")
-		reader, err := cli.ImagePull(ctx, repotag, image.PullOptions{})
-		if err != nil {
-			//panic(err)
-			return "", err
-		}
-		defer reader.Close()
-		io.Copy(os.Stdout, reader)
-	} else {
-		//fmt.Println("already exist")
-	}
-	//
-	//ports := ct.Ports
-	//exposedPortSet := nat.PortSet{}
-	//portKeys := make(map[string]struct{})
-	//
-	//for _, num := range ports {
-	//	//fmt.Println(num.ContainerPort)
-	//
-	//	key := fmt.Sprint(num.ContainerPort) + "/tcp"
-	//	//fmt.Println(key)
-	//	portKeys[key] = struct{}{}
-	//}
-	//for key := range portKeys {
-	//	exposedPortSet[nat.Port(key)] = struct{}{}
-	//}
-
-	pauseRef := "container:" + PauseId
-	label := make(map[string]string)
-	label["PodID"] = string(PodID)
-	label["PodName"] = PodName
-	label["Name"] = ct.Name
-	label["PodNamespace"] = PodNameSpace
-
-	var mounts []mount.Mount
+// 镜像已由kubelet按拉取策略准备好
+func (rm *runtimeManager) createContainer(ct *v1.Container, sandboxID string, pod *v1.Pod, volumes map[string]string, env []string) (string, error) {
+	var mounts []Mount
 	for _, volume := range ct.VolumeMounts {
-		if dir, ok := volumes[volume.Name]; !ok {
+		dir, ok := volumes[volume.Name]
+		if !ok {
 			return "", fmt.Errorf("create container: volume %s not declared", volume.Name)
-		} else {
-			mounts = append(mounts, mount.Mount{
-				Type:   mount.TypeBind,
-				Source: dir,
-				Target: volume.MountPath,
-			})
 		}
+		if volume.SubPath != "" {
+			if err := v1.ValidateSubPath(volume.SubPath); err != nil {
+				return "", fmt.Errorf("create container: volume %s: %v", volume.Name, err)
+			}
+			dir = filepath.Join(dir, volume.SubPath)
+			if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+				return "", fmt.Errorf("create container: failed to create subPath %s: %v", dir, err)
+			}
+		}
+		mounts = append(mounts, Mount{
+			HostPath:      dir,
+			ContainerPath: volume.MountPath,
+			ReadOnly:      volume.ReadOnly,
+		})
 	}
 
-	config := &container.Config{
-		Image:  repotag,
-		Cmd:    cmd,
-		Tty:    true,
-		Labels: label,
-	}
-
-	hostConfig := &container.HostConfig{
-		NetworkMode: container.NetworkMode(pauseRef),
-		PidMode:     container.PidMode(pauseRef),
-		Mounts:      mounts,
+	resources, err := makeContainerResources(ct)
+	if err != nil {
+		return "", fmt.Errorf("create container %s: %v", ct.Name, err)
+	}
+	config := &ContainerConfig{
+		Name:  ct.Name,
+		Image: ct.Image,
+		Labels: map[string]string{
+			"PodID":        string(pod.UID),
+			"PodName":      pod.Name,
+			"Name":         ct.Name,
+			"PodNamespace": pod.Namespace,
+		},
+		Command:    ct.Command,
+		Args:       ct.Args,
+		WorkingDir: ct.WorkingDir,
+		Env:        env,
+		Mounts:     mounts,
+		Tty:        true,
+		Resources:  resources,
+		SharePid:   true,
 	}
-
 	if ct.SecurityContext != nil {
 		if ct.SecurityContext.Privileged != nil && *ct.SecurityContext.Privileged {
-			hostConfig.Privileged = *ct.SecurityContext.Privileged
+			config.Privileged = true
 		} else if ct.SecurityContext.RunAsUser != nil {
 			config.User = strconv.FormatInt(*ct.SecurityContext.RunAsUser, 10)
 		}
 	}
 
-	resp, err := cli.ContainerCreate(ctx, config, hostConfig, nil, nil, "")
+	containerID, err := rm.service.CreateContainer(sandboxID, config)
 	if err != nil {
-		//panic(err)
 		return "", err
 	}
-
-	if err := cli.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
-		//panic(err)
+	if err = rm.service.StartContainer(containerID); err != nil {
 		return "", err
 	}
-
-	// statusCh, errCh := cli.ContainerWait(ctx, resp.ID, container.WaitConditionNotRunning)
-	// select {
-	// case err := <-errCh:
-	// 	if err != nil {
-	// 		panic(err)
-	// 	}
-	// case <-statusCh:
-	// }
-
-	//out, err := cli.ContainerLogs(ctx, resp.ID, container.LogsOptions{ShowStdout: true})
-	//if err != nil {
-	//	//panic(err)
-	//	return "", err
-	//}
-	//
-	//stdcopy.StdCopy(os.Stdout, os.Stderr, out)
-	return resp.ID, nil
+	return containerID, nil
 }
 
-func (rm *runtimeManager) checkImages(repotag string) (bool, error) {
-	ctx := context.Background()
-	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+// 停止容器可能耗尽整个宽限期，期间不持有锁，避免阻塞其他pod的操作与状态查询
+func (rm *runtimeManager) DeletePod(ID v1.UID, gracePeriod time.Duration) error {
+	filter := map[string]string{"PodID": string(ID)}
+	rm.lock.Lock()
+	containers, err := rm.service.ListContainers(filter)
+	rm.lock.Unlock()
 	if err != nil {
-		//panic(err)
-		return false, err
+		return err
 	}
-	defer cli.Close()
-	images, err := cli.ImageList(ctx, image.ListOptions{})
-	if err != nil {
-		//fmt.Println("fail to get images", err)
-		//panic(err)
-		return false, err
-	}
-	//fmt.Println("Docker Images:")
-	for _, image := range images {
-		//fmt.Printf("ID: %s\n", image.ID)
-		//fmt.Printf("RepoTags: %v\n", image.RepoTags)
-		//if len(image.RepoTags) == 0 {
-		//	continue
-		//}
-		//if image.RepoTags[0] == repotag {
-		//	return true, nil
-		//}
-		for _, rt := range image.RepoTags {
-			if rt == repotag {
-				return true, nil
-			}
+	// 各容器共用同一宽限期，因此并行停止
+	var wg sync.WaitGroup
+	errs := make([]error, len(containers))
+	for i, container := range containers {
+		if container.State != ContainerStateRunning {
+			continue
 		}
-		//fmt.Printf("Size: %d\n", image.Size)
-		//fmt.Println("------------------------")
+		wg.Add(1)
+		go func(i int, containerID string) {
+			defer wg.Done()
+			errs[i] = rm.service.StopContainer(containerID, gracePeriod)
+		}(i, container.ID)
 	}
-	return false, nil
-}
-
-// func (rm *runtimeManager) getAllRunningContainers() ([]types.Container, error) {
-// 	ctx := context.Background()
-// 	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
-// 	if err != nil {
-// 		panic(err)
-// 	}
-// 	defer cli.Close()
-
-// 	containers, err := cli.ContainerList(ctx, container.ListOptions{})
-// 	if err != nil {
-// 		panic(err)
-// 	}
-
-// 	return containers, nil
-
-// }
-
-func (rm *runtimeManager) getAllContainers() ([]types.Container, error) {
-	ctx := context.Background()
-	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
-	if err != nil {
-		//panic(err)
-		return nil, err
-	}
-	defer cli.Close()
-
-	containers, err := cli.ContainerList(ctx, container.ListOptions{All: true})
-	if err != nil {
-		//panic(err)
-		return nil, err
-	}
-	var ret []types.Container
-	for _, container := range containers {
-		//fmt.Println("container's state" + container.State)
-		//fmt.Println("container's status" + container.Status)
-		if _, ok := container.Labels["PauseType"]; !ok {
-			ret = append(ret, container)
+	wg.Wait()
+	for i, container := range containers {
+		if errs[i] != nil {
+			return errs[i]
+		}
+		if err = rm.service.RemoveContainer(container.ID); err != nil {
+			return err
 		}
 	}
-
-	return ret, nil
-}
-
-func (rm *runtimeManager) checkContainerState(ct types.Container, ct_todo *Container) {
-	state := ct.State
-	//status := ct.Status
-	if state == "exited" {
-		ct_todo.State = ContainerStateExited
-		return
-	}
-	if state == "running" {
-		ct_todo.State = ContainerStateRunning
-		return
-	}
-	if state == "created" {
-		ct_todo.State = ContainerStateCreated
-		return
+	sandboxes, err := rm.service.ListPodSandbox(filter)
+	if err != nil {
+		return err
 	}
-	ct_todo.State = ContainerStateUnknown
-}
-
-func (rm *runtimeManager) checkContainerStatus(ct types.Container, ct_todo *ContainerStatus) {
-	state := ct.State
-	status := ct.Status
-	if state == "exited" {
-		ct_todo.State = ContainerStateExited
-
-		left := strings.Index(status, "(")
-		if left == -1 {
-			return
+	for _, sandbox := range sandboxes {
+		if err = rm.service.StopPodSandbox(sandbox.ID); err != nil {
+			return err
 		}
-		right := strings.Index(status, ")")
-		if right == -1 {
-			return
+		if err = rm.service.RemovePodSandbox(sandbox.ID); err != nil {
+			return err
 		}
-		strNumber := status[left+1 : right]
-		number, _ := strconv.Atoi(strNumber)
-
-		ct_todo.ExitCode = number
-		return
-	}
-	if state == "running" {
-		ct_todo.State = ContainerStateRunning
-		return
-	}
-	if state == "created" {
-		ct_todo.State = ContainerStateCreated
-		return
 	}
-	ct_todo.State = ContainerStateUnknown
+	rm.lock.Lock()
+	defer rm.lock.Unlock()
+	delete(rm.IpMap, ID)
+	delete(rm.restartRecords, ID)
+	return nil
 }
 
-func (rm *runtimeManager) DeletePod(ID v1.UID) error {
+// 原地重启单个容器，sandbox与pod ip保持不变
+func (rm *runtimeManager) RestartContainer(podID v1.UID, containerName string) error {
 	rm.lock.Lock()
 	defer rm.lock.Unlock()
-	containers, err := rm.getAllContainersIncludingPause()
+	containerID, err := rm.findContainerID(podID, containerName)
 	if err != nil {
 		return err
 	}
-	for _, container := range containers {
-		if container.Labels["PodID"] == string(ID) {
-			if _, ok := container.Labels["PauseType"]; ok {
-				// log.Printf("Container ID: %s\n", container.ID)
-				err = nw.Detach(container.ID)
-				if err != nil {
-					return err
-				}
-				delete(rm.IpMap, v1.UID(container.Labels["PodID"]))
-			}
-			err = rm.deleteContainer(container)
-			if err != nil {
-				return err
-			}
-		}
-		//if _, ok := container.Labels["IsEnvoy"]; ok {
-		//	if container.Labels["EnvoyPodID"] == string(ID) {
-		//		err = rm.deleteContainer(container)
-		//		if err != nil {
-		//			return err
-		//		}
-		//	}
-		//}
-	}
-	return nil
-}
-
-func (rm *runtimeManager) getAllContainersIncludingPause() ([]types.Container, error) {
-	ctx := context.Background()
-	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	status, err := rm.service.ContainerStatus(containerID)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer cli.Close()
-
-	containers, err := cli.ContainerList(ctx, container.ListOptions{All: true})
-	if err != nil {
-		return nil, err
+	rm.recordRestart(podID, status)
+	if err = rm.service.StopContainer(containerID, 0); err != nil {
+		return err
 	}
-	//for _, container := range containers {
-	//	if _, ok := container.Labels["PauseType"]; ok {
-	//		log.Printf("Container ID: %s\n", container.ID)
-	//		err := nw.Detach(container.ID)
-	//		if err != nil {
-	//			panic(err)
-	//		}
-	//		delete(rm.IpMap, v1.UID(container.Labels["PodID"]))
-	//	}
-	//}
-
-	return containers, nil
+	return rm.service.StartContainer(containerID)
 }
 
-func (rm *runtimeManager) deleteContainer(ct types.Container) error {
-	ctx := context.Background()
-	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+// kubelet停止期间被删除的容器在原sandbox中重新创建，其余容器不受影响
+func (rm *runtimeManager) CreateMissingContainers(pod *v1.Pod) ([]string, error) {
+	rm.lock.Lock()
+	defer rm.lock.Unlock()
+	filter := map[string]string{"PodID": string(pod.UID)}
+	sandboxes, err := rm.service.ListPodSandbox(filter)
 	if err != nil {
-		//panic(err)
-		return err
+		return nil, err
 	}
-	defer cli.Close()
-	noWaitTimeout := 0
-	if ct.State == "running" {
-		if err := cli.ContainerStop(ctx, ct.ID, container.StopOptions{Timeout: &noWaitTimeout}); err != nil {
-			//panic(err)
-			return err
+	sandboxID := ""
+	for _, sandbox := range sandboxes {
+		if sandbox.State == ContainerStateRunning {
+			sandboxID = sandbox.ID
+			break
 		}
 	}
-	if err := cli.ContainerRemove(ctx, ct.ID, container.RemoveOptions{}); err != nil {
-		//panic(err)
-		return err
+	if sandboxID == "" {
+		return nil, fmt.Errorf("sandbox of pod %s is not running", pod.Name)
 	}
-
-	return nil
-}
-
-func (rm *runtimeManager) RestartPod(pod *v1.Pod) error {
-	//err := rm.DeletePod(pod.UID)
-	//if err != nil {
-	//	return err
-	//}
-	//err = rm.AddPod(pod)
-	//if err != nil {
-	//	return err
-	//}
-	//return nil
-	rm.lock.Lock()
-	defer rm.lock.Unlock()
-	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	containers, err := rm.service.ListContainers(filter)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	existing := make(map[string]bool)
+	for _, container := range containers {
+		existing[container.Labels["Name"]] = true
 	}
-	defer cli.Close()
-	containers, err := rm.getAllContainers()
+	volumes, err := rm.createVolumeDir(pod)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	noWaitTimeout := 0
-	for _, ct := range containers {
-		if ct.Labels["PodID"] == string(pod.UID) {
-			err = cli.ContainerRestart(context.Background(), ct.ID, container.StopOptions{Timeout: &noWaitTimeout})
-			if err != nil {
-				return err
-			}
+	var created []string
+	for _, container := range pod.Spec.Containers {
+		if existing[container.Name] {
+			continue
+		}
+		env, err := makeEnvironmentVariables(pod, &container, rm.IpMap[pod.UID])
+		if err != nil {
+			return created, err
 		}
+		if _, err = rm.createContainer(&container, sandboxID, pod, volumes, env); err != nil {
+			return created, err
+		}
+		created = append(created, container.Name)
 	}
-	return nil
+	return created, nil
+}
+
+func (rm *runtimeManager) KillContainer(containerID string) error {
+	return rm.service.StopContainer(containerID, 0)
+}
+
+func (rm *runtimeManager) ExecInContainer(containerID string, cmd []string, timeout time.Duration) (int, []byte, error) {
+	return rm.service.ExecSync(containerID, cmd, timeout)
+}
+
+// 各pod的非hostPath卷所在的目录
+const VolumesRootDir = "/tmp/minikubernetes/volumes"
+
+// pod的非hostPath卷在主机上的目录
+func GetVolumeDir(podUID v1.UID, volumeName string) string {
+	return filepath.Join(VolumesRootDir, string(podUID), volumeName)
 }
 
-// volume在主机上的管理由kubelet负责
+// volume在主机上的管理由kubelet负责，configMap、secret和downwardAPI卷的文件由kubelet在创建pod前写入
 func (rm *runtimeManager) createVolumeDir(pod *v1.Pod) (map[string]string, error) {
 	ret := make(map[string]string)
 	for _, volume := range pod.Spec.Volumes {
-		if volume.EmptyDir == nil && volume.HostPath == nil {
+		sources := 0
+		for _, set := range []bool{volume.EmptyDir != nil, volume.HostPath != nil, volume.ConfigMap != nil, volume.Secret != nil, volume.DownwardAPI != nil} {
+			if set {
+				sources++
+			}
+		}
+		if sources == 0 {
 			return nil, fmt.Errorf("create volume dir: volume %s has no source", volume.Name)
 		}
-		if volume.HostPath != nil && volume.EmptyDir != nil {
-			return nil, fmt.Errorf("create volume dir: volume %s cannot have both hostPath and emptyDir", volume.Name)
+		if sources > 1 {
+			return nil, fmt.Errorf("create volume dir: volume %s cannot have more than one source", volume.Name)
 		}
-		if volume.EmptyDir != nil {
-			dir := fmt.Sprintf("/tmp/minikubernetes/volumes/%s/%s", string(pod.UID), volume.Name)
-			// MkdirAll会创建所有父目录，若目标目录已存在也不会报错
-			err := os.MkdirAll(dir, os.ModePerm)
-			if err != nil {
-				return nil, fmt.Errorf("create volume dir: failed to create volume dir %s: %v", dir, err)
-			}
-			ret[volume.Name] = dir
-		} else {
-			dir := volume.HostPath.Path
-			err := os.MkdirAll(dir, os.ModePerm)
-			if err != nil {
-				return nil, fmt.Errorf("create volume dir: failed to create volume dir %s: %v", dir, err)
-			}
-			ret[volume.Name] = dir
+		dir := GetVolumeDir(pod.UID, volume.Name)
+		if volume.HostPath != nil {
+			dir = volume.HostPath.Path
+		}
+		// MkdirAll会创建所有父目录，若目标目录已存在也不会报错
+		err := os.MkdirAll(dir, os.ModePerm)
+		if err != nil {
+			return nil, fmt.Errorf("create volume dir: failed to create volume dir %s: %v", dir, err)
 		}
+		ret[volume.Name] = dir
 	}
 	return ret, nil
 }
 
-func (rm *runtimeManager) injectSideCar(pauseID string, tag string, pod *v1.Pod) error {
-	if tag == "" {
-		tag = "latest"
-	}
-	envoyInitImage := "sjtuzc/envoy-init:" + tag
-	envoyImage := "sjtuzc/envoy:" + tag
-	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
-	defer cli.Close()
-
-	exist, err := rm.checkImages(envoyInitImage)
+func (rm *runtimeManager) GetPodStats(ID v1.UID) (*PodStats, error) {
+	containers, err := rm.service.ListContainers(map[string]string{"PodID": string(ID)})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if !exist {
-		_, err = cli.ImagePull(context.Background(), envoyInitImage, image.PullOptions{})
+	stats := &PodStats{}
+	for _, container := range containers {
+		if container.State != ContainerStateRunning {
+			continue
+		}
+		cs, err := rm.service.ContainerStats(container.ID)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		stats.MemoryWorkingSetBytes += cs.MemoryWorkingSetBytes
 	}
-	exist, err = rm.checkImages(envoyImage)
+	return stats, nil
+}
+
+func (rm *runtimeManager) ImageFsPath() (string, error) {
+	return rm.service.ImageFsPath()
+}
+
+func (rm *runtimeManager) PullImage(image string, auth *AuthConfig) error {
+	return rm.service.PullImage(image, auth)
+}
+
+func (rm *runtimeManager) ImageExists(image string) (bool, error) {
+	return rm.service.ImageStatus(image)
+}
+
+func (rm *runtimeManager) ListImages() ([]*Image, error) {
+	return rm.service.ListImages()
+}
+
+func (rm *runtimeManager) RemoveImage(imageID string) error {
+	return rm.service.RemoveImage(imageID)
+}
+
+func (rm *runtimeManager) GetImagesInUse() (map[string]bool, error) {
+	containers, err := rm.service.ListContainers(nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if !exist {
-		_, err = cli.ImagePull(context.Background(), envoyImage, image.PullOptions{})
-		if err != nil {
-			return err
+	inUse := make(map[string]bool)
+	for _, c := range containers {
+		if c.ImageID != "" {
+			inUse[c.ImageID] = true
 		}
 	}
-
-	// docker run --rm --net container:pauseID --privileged envoy-init
-	resp, err := cli.ContainerCreate(context.Background(), &container.Config{
-		Image: envoyInitImage,
-		Tty:   true,
-	}, &container.HostConfig{
-		NetworkMode: container.NetworkMode("container:" + pauseID),
-		Privileged:  true,
-	}, nil, nil, "")
+	// sandbox不体现镜像，按名称保留pause镜像
+	images, err := rm.service.ListImages()
 	if err != nil {
-		return err
-	}
-	if err = cli.ContainerStart(context.Background(), resp.ID, container.StartOptions{}); err != nil {
-		return err
+		return nil, err
 	}
-	// wait for envoy-init to finish
-	statusCh, errCh := cli.ContainerWait(context.Background(), resp.ID, container.WaitConditionNotRunning)
-	select {
-	case err = <-errCh:
-		if err != nil {
-			return err
-		}
-	case status := <-statusCh:
-		if status.StatusCode != 0 {
-			return fmt.Errorf("envoy-init exited with status %d", status.StatusCode)
+	for _, img := range images {
+		if slices.Contains(img.RepoTags, pauseImage) {
+			inUse[img.ID] = true
 		}
 	}
+	return inUse, nil
+}
 
-	// docker run --net container:pauseID -u 1337 envoy
-	labels := make(map[string]string)
-	labels["IsEnvoy"] = "true"
-	labels["EnvoyPodID"] = string(pod.UID)
-	resp, err = cli.ContainerCreate(context.Background(), &container.Config{
-		Image:  envoyImage,
-		Tty:    true,
-		User:   envoy.UID,
-		Labels: labels,
-	}, &container.HostConfig{
-		NetworkMode: container.NetworkMode("container:" + pauseID),
-	}, nil, nil, "")
+func (rm *runtimeManager) WatchContainerEvents(ctx context.Context) (<-chan *ContainerEvent, <-chan error) {
+	return rm.service.ContainerEvents(ctx)
+}
+
+// 重启计数等记录无法恢复，从0开始
+func (rm *runtimeManager) RecoverPods() ([]*Pod, error) {
+	pods, err := rm.GetAllPods()
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	if err = cli.ContainerStart(context.Background(), resp.ID, container.StartOptions{}); err != nil {
-		return err
+	sandboxes, err := rm.service.ListPodSandbox(nil)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	rm.lock.Lock()
+	defer rm.lock.Unlock()
+	for _, sandbox := range sandboxes {
+		podID := v1.UID(sandbox.Labels["PodID"])
+		if podID == "" {
+			continue
+		}
+		if sandbox.State == ContainerStateRunning {
+			status, err := rm.service.PodSandboxStatus(sandbox.ID)
+			if err != nil {
+				return nil, err
+			}
+			rm.IpMap[podID] = status.IP
+		}
+		found := false
+		for _, pod := range pods {
+			if pod.ID == podID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			pods = append(pods, &Pod{
+				ID:        podID,
+				Name:      sandbox.Labels["PodName"],
+				Namespace: sandbox.Labels["PodNamespace"],
+			})
+		}
+	}
+	return pods, nil
 }
@@ -0,0 +1,82 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"io"
+	v1 "minikubernetes/pkg/api/v1"
+	"time"
+)
+
+// 读取容器日志的选项
+type LogOptions struct {
+	// 持续输出新日志
+	Follow bool
+	// 只输出最后若干行，nil表示全部
+	TailLines *int64
+	// 只输出该时间之后的日志
+	SinceTime *time.Time
+	// 每行前加上时间戳
+	Timestamps bool
+	// 读取上一次重启前的日志
+	Previous bool
+}
+
+func (rm *runtimeManager) findContainerID(podID v1.UID, containerName string) (string, error) {
+	containers, err := rm.service.ListContainers(map[string]string{"PodID": string(podID), "Name": containerName})
+	if err != nil {
+		return "", err
+	}
+	if len(containers) == 0 {
+		return "", fmt.Errorf("container %s of pod %s not found", containerName, podID)
+	}
+	return containers[0].ID, nil
+}
+
+// 容器原地重启，日志按运行的起止时间截取
+func (rm *runtimeManager) GetContainerLogs(ctx context.Context, podID v1.UID, containerName string, opts *LogOptions, stdout, stderr io.Writer) error {
+	containerID, err := rm.findContainerID(podID, containerName)
+	if err != nil {
+		return err
+	}
+	status, err := rm.service.ContainerStatus(containerID)
+	if err != nil {
+		return err
+	}
+
+	logOptions := &ContainerLogOptions{
+		Follow:     opts.Follow,
+		TailLines:  opts.TailLines,
+		Timestamps: opts.Timestamps,
+		Previous:   opts.Previous,
+	}
+	if opts.Previous {
+		rm.lock.Lock()
+		record, ok := rm.restartRecords[podID][containerName]
+		var last *ContainerStatus
+		if ok {
+			last = record.lastTermination
+		}
+		rm.lock.Unlock()
+		if last == nil {
+			return fmt.Errorf("previous terminated container %s of pod %s not found", containerName, podID)
+		}
+		logOptions.Since, logOptions.Until = last.StartedAt, last.FinishedAt
+		logOptions.Follow = false
+	} else {
+		logOptions.Since = status.StartedAt
+	}
+	if opts.SinceTime != nil && opts.SinceTime.After(logOptions.Since) {
+		logOptions.Since = *opts.SinceTime
+	}
+	return rm.service.ContainerLogs(ctx, containerID, logOptions, stdout, stderr)
+}
+
+// 在容器内执行命令并转发标准输入输出，返回退出码
+func (rm *runtimeManager) ExecInContainerStream(ctx context.Context, podID v1.UID, containerName string, cmd []string, tty bool, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	containerID, err := rm.findContainerID(podID, containerName)
+	if err != nil {
+		return -1, err
+	}
+	return rm.service.Exec(ctx, containerID, cmd, tty, stdin, stdout, stderr)
+}
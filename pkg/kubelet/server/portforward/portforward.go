@@ -0,0 +1,117 @@
+package portforward
+
+// port-forward使用的websocket协议：每个websocket连接对应pod内的一个tcp连接
+// 每条二进制消息的首字节为通道号，data通道的空消息表示一端已关闭写入，error通道携带错误信息
+
+import (
+	"errors"
+	"io"
+	"net"
+	"sync"
+
+	"golang.org/x/net/websocket"
+)
+
+const Protocol = "v1.portforward.minikubernetes.io"
+
+const (
+	DataChannel byte = iota
+	ErrorChannel
+)
+
+// 将websocket包装为字节流
+type Stream struct {
+	ws *websocket.Conn
+	// 未读完的数据
+	buf       []byte
+	writeLock sync.Mutex
+}
+
+func NewStream(ws *websocket.Conn) *Stream {
+	ws.PayloadType = websocket.BinaryFrame
+	return &Stream{ws: ws}
+}
+
+func (s *Stream) Read(p []byte) (int, error) {
+	for len(s.buf) == 0 {
+		var frame []byte
+		if err := websocket.Message.Receive(s.ws, &frame); err != nil {
+			return 0, err
+		}
+		if len(frame) == 0 {
+			continue
+		}
+		switch frame[0] {
+		case DataChannel:
+			if len(frame) == 1 {
+				return 0, io.EOF
+			}
+			s.buf = frame[1:]
+		case ErrorChannel:
+			return 0, errors.New(string(frame[1:]))
+		}
+	}
+	n := copy(p, s.buf)
+	s.buf = s.buf[n:]
+	return n, nil
+}
+
+func (s *Stream) send(channel byte, p []byte) error {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+	frame := make([]byte, len(p)+1)
+	frame[0] = channel
+	copy(frame[1:], p)
+	return websocket.Message.Send(s.ws, frame)
+}
+
+func (s *Stream) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if err := s.send(DataChannel, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// 通知对端不再有数据
+func (s *Stream) CloseWrite() error {
+	return s.send(DataChannel, nil)
+}
+
+// 向对端报告错误，例如连接pod端口失败
+func (s *Stream) WriteError(err error) error {
+	return s.send(ErrorChannel, []byte(err.Error()))
+}
+
+func (s *Stream) Close() error {
+	return s.ws.Close()
+}
+
+// 在stream与tcp连接间双向转发数据，两个方向都结束后关闭二者
+func Proxy(stream *Stream, conn net.Conn) error {
+	var wg sync.WaitGroup
+	var readErr error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, readErr = io.Copy(conn, stream)
+		if tcpConn, ok := conn.(*net.TCPConn); ok {
+			_ = tcpConn.CloseWrite()
+		} else {
+			_ = conn.Close()
+		}
+	}()
+	_, err := io.Copy(stream, conn)
+	if err == nil {
+		err = stream.CloseWrite()
+	}
+	wg.Wait()
+	_ = conn.Close()
+	_ = stream.Close()
+	if err != nil {
+		return err
+	}
+	return readErr
+}
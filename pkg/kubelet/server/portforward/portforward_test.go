@@ -0,0 +1,76 @@
+package portforward
+
+import (
+	"io"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/websocket"
+)
+
+func TestProxy(t *testing.T) {
+	// pod内的服务：读完请求后回复大写内容
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		data, _ := io.ReadAll(conn)
+		_, _ = conn.Write([]byte(strings.ToUpper(string(data))))
+	}()
+
+	server := httptest.NewServer(websocket.Server{Handler: func(ws *websocket.Conn) {
+		stream := NewStream(ws)
+		conn, err := net.Dial("tcp", listener.Addr().String())
+		if err != nil {
+			_ = stream.WriteError(err)
+			return
+		}
+		_ = Proxy(stream, conn)
+	}})
+	defer server.Close()
+
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http"), Protocol, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream := NewStream(ws)
+	defer stream.Close()
+	if _, err = stream.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	if err = stream.CloseWrite(); err != nil {
+		t.Fatal(err)
+	}
+	reply, err := io.ReadAll(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(reply) != "HELLO" {
+		t.Errorf("reply = %q, want %q", reply, "HELLO")
+	}
+}
+
+func TestStreamError(t *testing.T) {
+	server := httptest.NewServer(websocket.Server{Handler: func(ws *websocket.Conn) {
+		_ = NewStream(ws).WriteError(io.ErrUnexpectedEOF)
+	}})
+	defer server.Close()
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http"), Protocol, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream := NewStream(ws)
+	defer stream.Close()
+	if _, err = io.ReadAll(stream); err == nil || err.Error() != io.ErrUnexpectedEOF.Error() {
+		t.Errorf("err = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
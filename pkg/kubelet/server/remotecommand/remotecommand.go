@@ -0,0 +1,145 @@
+package remotecommand
+
+// exec使用的websocket协议：每条二进制消息的首字节为通道号，其余为数据
+// stdin通道收到空数据表示输入结束；命令结束时服务端在error通道发送json格式的Status后关闭连接
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"golang.org/x/net/websocket"
+)
+
+const Protocol = "v1.channel.minikubernetes.io"
+
+const (
+	StdinChannel byte = iota
+	StdoutChannel
+	StderrChannel
+	ErrorChannel
+)
+
+// 命令的执行结果
+type Status struct {
+	ExitCode int    `json:"exitCode"`
+	Error    string `json:"error,omitempty"`
+}
+
+type channelWriter struct {
+	ws      *websocket.Conn
+	channel byte
+	lock    *sync.Mutex
+}
+
+func (w *channelWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	frame := make([]byte, len(p)+1)
+	frame[0] = w.channel
+	copy(frame[1:], p)
+	if err := websocket.Message.Send(w.ws, frame); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// 服务端的一次exec会话
+type ServerStreams struct {
+	ws *websocket.Conn
+	// 同一连接上的写入需要互斥
+	lock   sync.Mutex
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// 开始从连接读取stdin，stdin为false时丢弃客户端发来的输入
+func NewServerStreams(ws *websocket.Conn, stdin bool) *ServerStreams {
+	s := &ServerStreams{ws: ws}
+	s.Stdout = &channelWriter{ws: ws, channel: StdoutChannel, lock: &s.lock}
+	s.Stderr = &channelWriter{ws: ws, channel: StderrChannel, lock: &s.lock}
+	if stdin {
+		reader, writer := io.Pipe()
+		s.Stdin = reader
+		go readStdin(ws, writer)
+	}
+	return s
+}
+
+func readStdin(ws *websocket.Conn, writer *io.PipeWriter) {
+	for {
+		var frame []byte
+		if err := websocket.Message.Receive(ws, &frame); err != nil {
+			writer.CloseWithError(err)
+			return
+		}
+		if len(frame) == 0 || frame[0] != StdinChannel {
+			continue
+		}
+		if len(frame) == 1 {
+			writer.Close()
+			return
+		}
+		if _, err := writer.Write(frame[1:]); err != nil {
+			return
+		}
+	}
+}
+
+// 发送执行结果，之后连接应被关闭
+func (s *ServerStreams) Finish(status Status) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	_, err = (&channelWriter{ws: s.ws, channel: ErrorChannel, lock: &s.lock}).Write(data)
+	return err
+}
+
+// 客户端转发标准输入输出，直到服务端发送执行结果
+func Stream(ws *websocket.Conn, stdin io.Reader, stdout, stderr io.Writer) (*Status, error) {
+	if stdin != nil {
+		writer := &channelWriter{ws: ws, channel: StdinChannel, lock: &sync.Mutex{}}
+		go func() {
+			_, _ = io.Copy(writer, stdin)
+			// 空数据通知服务端输入结束
+			writer.lock.Lock()
+			defer writer.lock.Unlock()
+			_ = websocket.Message.Send(ws, []byte{StdinChannel})
+		}()
+	}
+	for {
+		var frame []byte
+		if err := websocket.Message.Receive(ws, &frame); err != nil {
+			if err == io.EOF {
+				return nil, fmt.Errorf("connection closed before exec finished")
+			}
+			return nil, err
+		}
+		if len(frame) == 0 {
+			continue
+		}
+		data := frame[1:]
+		switch frame[0] {
+		case StdoutChannel:
+			if stdout != nil {
+				_, _ = stdout.Write(data)
+			}
+		case StderrChannel:
+			if stderr != nil {
+				_, _ = stderr.Write(data)
+			}
+		case ErrorChannel:
+			var status Status
+			if err := json.Unmarshal(data, &status); err != nil {
+				return nil, err
+			}
+			return &status, nil
+		}
+	}
+}
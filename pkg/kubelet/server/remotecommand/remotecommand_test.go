@@ -0,0 +1,44 @@
+package remotecommand
+
+import (
+	"bytes"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/websocket"
+)
+
+func TestStream(t *testing.T) {
+	// 服务端将stdin原样写回stdout，并在stderr输出字节数
+	server := httptest.NewServer(websocket.Server{Handler: func(ws *websocket.Conn) {
+		streams := NewServerStreams(ws, true)
+		data, err := io.ReadAll(streams.Stdin)
+		if err != nil {
+			_ = streams.Finish(Status{ExitCode: -1, Error: err.Error()})
+			return
+		}
+		_, _ = streams.Stdout.Write(data)
+		_, _ = io.WriteString(streams.Stderr, "done")
+		_ = streams.Finish(Status{ExitCode: 3})
+	}})
+	defer server.Close()
+
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http"), Protocol, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	var stdout, stderr bytes.Buffer
+	status, err := Stream(ws, strings.NewReader("hello"), &stdout, &stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.ExitCode != 3 || status.Error != "" {
+		t.Errorf("status = %+v", status)
+	}
+	if stdout.String() != "hello" || stderr.String() != "done" {
+		t.Errorf("stdout = %q, stderr = %q", stdout.String(), stderr.String())
+	}
+}
@@ -0,0 +1,220 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"log"
+	"minikubernetes/pkg/kubelet/runtime"
+	"minikubernetes/pkg/kubelet/server/portforward"
+	"minikubernetes/pkg/kubelet/server/remotecommand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+// kubelet server监听的端口
+const KubeletPort = 10250
+
+const (
+	ContainerLogsURL = "/containerLogs/:namespace/:podname/:containername"
+	ExecURL          = "/exec/:namespace/:podname/:containername"
+	PortForwardURL   = "/portForward/:namespace/:podname"
+	MetricsURL       = "/metrics"
+)
+
+// kubelet server依赖的kubelet能力
+type HostInterface interface {
+	GetContainerLogs(ctx context.Context, namespace, podName, containerName string, opts *runtime.LogOptions, stdout, stderr io.Writer) error
+	ExecInContainer(ctx context.Context, namespace, podName, containerName string, cmd []string, tty bool, stdin io.Reader, stdout, stderr io.Writer) (int, error)
+	DialPodPort(namespace, podName string, port int32) (net.Conn, error)
+	GetMetrics() []*Metric
+}
+
+// kubelet自身的运行指标
+type Metric struct {
+	Name string
+	Help string
+	// counter或gauge
+	Type  string
+	Value float64
+}
+
+type Server struct {
+	host   HostInterface
+	token  string
+	router *gin.Engine
+}
+
+// token为apiserver访问kubelet时使用的Bearer token
+func NewServer(host HostInterface, token string) *Server {
+	s := &Server{
+		host:   host,
+		token:  token,
+		router: gin.Default(),
+	}
+	s.router.Use(s.authenticate)
+	s.router.GET(ContainerLogsURL, s.getContainerLogs)
+	s.router.GET(ExecURL, s.exec)
+	s.router.GET(PortForwardURL, s.portForward)
+	s.router.GET(MetricsURL, s.getMetrics)
+	return s
+}
+
+func (s *Server) ListenAndServe() error {
+	return s.router.Run(fmt.Sprintf(":%d", KubeletPort))
+}
+
+func (s *Server) Handler() http.Handler {
+	return s.router
+}
+
+// 未配置token时拒绝所有请求，避免暴露未认证的exec接口
+func (s *Server) authenticate(c *gin.Context) {
+	expected := "Bearer " + s.token
+	got := c.GetHeader("Authorization")
+	if s.token == "" || subtle.ConstantTimeCompare([]byte(got), []byte(expected)) != 1 {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthorized",
+		})
+		return
+	}
+	c.Next()
+}
+
+// 解析与k8s相同的查询参数：follow、tailLines、sinceSeconds、sinceTime、timestamps、previous
+func parseLogOptions(c *gin.Context) (*runtime.LogOptions, error) {
+	opts := &runtime.LogOptions{
+		Follow:     c.Query("follow") == "true",
+		Timestamps: c.Query("timestamps") == "true",
+		Previous:   c.Query("previous") == "true",
+	}
+	if tail := c.Query("tailLines"); tail != "" {
+		lines, err := strconv.ParseInt(tail, 10, 64)
+		if err != nil || lines < 0 {
+			return nil, fmt.Errorf("invalid tailLines %q", tail)
+		}
+		opts.TailLines = &lines
+	}
+	if sinceSeconds := c.Query("sinceSeconds"); sinceSeconds != "" {
+		seconds, err := strconv.ParseInt(sinceSeconds, 10, 64)
+		if err != nil || seconds <= 0 {
+			return nil, fmt.Errorf("invalid sinceSeconds %q", sinceSeconds)
+		}
+		since := time.Now().Add(-time.Duration(seconds) * time.Second)
+		opts.SinceTime = &since
+	}
+	if sinceTime := c.Query("sinceTime"); sinceTime != "" {
+		if opts.SinceTime != nil {
+			return nil, fmt.Errorf("at most one of sinceSeconds or sinceTime may be specified")
+		}
+		since, err := time.Parse(time.RFC3339, sinceTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sinceTime %q", sinceTime)
+		}
+		opts.SinceTime = &since
+	}
+	return opts, nil
+}
+
+// 每次写入后立即flush，使follow的日志及时送达
+type flushWriter struct {
+	w gin.ResponseWriter
+}
+
+func (fw *flushWriter) Write(p []byte) (int, error) {
+	n, err := fw.w.Write(p)
+	fw.w.Flush()
+	return n, err
+}
+
+func (s *Server) getContainerLogs(c *gin.Context) {
+	opts, err := parseLogOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	writer := &flushWriter{w: c.Writer}
+	err = s.host.GetContainerLogs(c.Request.Context(), c.Param("namespace"), c.Param("podname"), c.Param("containername"), opts, writer, writer)
+	if err != nil {
+		if !c.Writer.Written() {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		log.Printf("Failed to stream logs: %v\n", err)
+	}
+}
+
+// 命令由多个command查询参数给出，stdin与tty为true时转发输入并分配终端
+func (s *Server) exec(c *gin.Context) {
+	cmd := c.QueryArray("command")
+	if len(cmd) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "command is required",
+		})
+		return
+	}
+	stdin := c.Query("stdin") == "true"
+	tty := c.Query("tty") == "true"
+	namespace, podName, containerName := c.Param("namespace"), c.Param("podname"), c.Param("containername")
+	websocket.Server{Handler: func(ws *websocket.Conn) {
+		defer ws.Close()
+		ws.PayloadType = websocket.BinaryFrame
+		streams := remotecommand.NewServerStreams(ws, stdin)
+		exitCode, err := s.host.ExecInContainer(c.Request.Context(), namespace, podName, containerName, cmd, tty,
+			streams.Stdin, streams.Stdout, streams.Stderr)
+		status := remotecommand.Status{ExitCode: exitCode}
+		if err != nil {
+			status.Error = err.Error()
+		}
+		if err = streams.Finish(status); err != nil {
+			log.Printf("Failed to send exec status: %v\n", err)
+		}
+	}}.ServeHTTP(c.Writer, c.Request)
+}
+
+// 每个websocket连接转发到pod内port端口的一个tcp连接
+func (s *Server) portForward(c *gin.Context) {
+	port, err := strconv.ParseInt(c.Query("port"), 10, 32)
+	if err != nil || port <= 0 || port > 65535 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("invalid port %q", c.Query("port")),
+		})
+		return
+	}
+	namespace, podName := c.Param("namespace"), c.Param("podname")
+	websocket.Server{Handler: func(ws *websocket.Conn) {
+		stream := portforward.NewStream(ws)
+		defer stream.Close()
+		conn, err := s.host.DialPodPort(namespace, podName, int32(port))
+		if err != nil {
+			_ = stream.WriteError(fmt.Errorf("failed to connect to port %d of pod %s/%s: %v", port, namespace, podName, err))
+			return
+		}
+		if err = portforward.Proxy(stream, conn); err != nil {
+			log.Printf("Port forward to pod %s/%s:%d ended: %v\n", namespace, podName, port, err)
+		}
+	}}.ServeHTTP(c.Writer, c.Request)
+}
+
+// 以prometheus文本格式输出
+func (s *Server) getMetrics(c *gin.Context) {
+	var builder strings.Builder
+	for _, m := range s.host.GetMetrics() {
+		fmt.Fprintf(&builder, "# HELP %s %s\n", m.Name, m.Help)
+		fmt.Fprintf(&builder, "# TYPE %s %s\n", m.Name, m.Type)
+		fmt.Fprintf(&builder, "%s %s\n", m.Name, strconv.FormatFloat(m.Value, 'g', -1, 64))
+	}
+	c.Data(http.StatusOK, "text/plain; version=0.0.4", []byte(builder.String()))
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"context"
+	"io"
+	"minikubernetes/pkg/kubelet/runtime"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fakeHost struct {
+	opts *runtime.LogOptions
+}
+
+func (h *fakeHost) GetContainerLogs(ctx context.Context, namespace, podName, containerName string, opts *runtime.LogOptions, stdout, stderr io.Writer) error {
+	h.opts = opts
+	_, err := io.WriteString(stdout, namespace+"/"+podName+"/"+containerName)
+	return err
+}
+
+func (h *fakeHost) ExecInContainer(ctx context.Context, namespace, podName, containerName string, cmd []string, tty bool, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	return 0, nil
+}
+
+func (h *fakeHost) DialPodPort(namespace, podName string, port int32) (net.Conn, error) {
+	return nil, io.EOF
+}
+
+func (h *fakeHost) GetMetrics() []*Metric {
+	return []*Metric{{Name: "kubelet_pleg_relist_total", Help: "Number of relists.", Type: "counter", Value: 3}}
+}
+
+func TestContainerLogs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	host := &fakeHost{}
+	handler := NewServer(host, "secret").Handler()
+	path := "/containerLogs/default/pod/c?tailLines=10&previous=true"
+
+	// 未携带token
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	if recorder.Code != http.StatusUnauthorized {
+		t.Fatalf("status without token = %v, want %v", recorder.Code, http.StatusUnauthorized)
+	}
+
+	recorder = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	handler.ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "default/pod/c" {
+		t.Fatalf("status = %v, body = %q", recorder.Code, recorder.Body.String())
+	}
+	if host.opts.TailLines == nil || *host.opts.TailLines != 10 || !host.opts.Previous || host.opts.Follow {
+		t.Errorf("log options = %+v", host.opts)
+	}
+
+	recorder = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/containerLogs/default/pod/c?sinceSeconds=1&sinceTime=2024-01-01T00:00:00Z", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	handler.ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("status with both since options = %v, want %v", recorder.Code, http.StatusBadRequest)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewServer(&fakeHost{}, "secret").Handler()
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	handler.ServeHTTP(recorder, req)
+	want := "# HELP kubelet_pleg_relist_total Number of relists.\n" +
+		"# TYPE kubelet_pleg_relist_total counter\n" +
+		"kubelet_pleg_relist_total 3\n"
+	if recorder.Code != http.StatusOK || recorder.Body.String() != want {
+		t.Errorf("status = %v, body = %q", recorder.Code, recorder.Body.String())
+	}
+}
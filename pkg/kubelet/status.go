@@ -0,0 +1,210 @@
+package kubelet
+
+import (
+	"fmt"
+	v1 "minikubernetes/pkg/api/v1"
+	"minikubernetes/pkg/kubelet/eviction"
+	"minikubernetes/pkg/kubelet/runtime"
+	"time"
+)
+
+// 将运行时状态转换为api status
+func (kl *Kubelet) computeApiStatus(pod *v1.Pod, podStatus *runtime.PodStatus) *v1.PodStatus {
+	oldStatus := kl.getLastApiStatus(pod.UID)
+	apiStatus := &v1.PodStatus{
+		HostIP:    kl.hostIP,
+		StartTime: time.Now(),
+		QOSClass:  v1.GetPodQOS(pod),
+	}
+	if oldStatus != nil && !oldStatus.StartTime.IsZero() {
+		apiStatus.StartTime = oldStatus.StartTime
+	}
+	if len(podStatus.IPs) > 0 {
+		apiStatus.PodIP = podStatus.IPs[0]
+	}
+
+	runtimeStatuses := make(map[string]*runtime.ContainerStatus)
+	for _, cs := range podStatus.ContainerStatuses {
+		runtimeStatuses[cs.Name] = cs
+	}
+	// init container在所有应用容器创建前运行完毕并被删除
+	initialized := len(podStatus.ContainerStatuses) > 0
+	for _, c := range pod.Spec.InitContainers {
+		status := v1.ContainerStatus{Name: c.Name, Image: c.Image}
+		if initialized {
+			status.State.Terminated = &v1.ContainerStateTerminated{ExitCode: 0, Reason: "Completed"}
+		} else if waiting := kl.getImageWaitingState(pod.UID, c.Name); waiting != nil {
+			status.State.Waiting = waiting
+		} else {
+			status.State.Waiting = &v1.ContainerStateWaiting{Reason: "PodInitializing"}
+		}
+		apiStatus.InitContainerStatuses = append(apiStatus.InitContainerStatuses, status)
+	}
+	for _, c := range pod.Spec.Containers {
+		cs, ok := runtimeStatuses[c.Name]
+		if !ok {
+			waiting := kl.getImageWaitingState(pod.UID, c.Name)
+			if waiting == nil {
+				waiting = &v1.ContainerStateWaiting{Reason: "ContainerCreating"}
+				if !initialized && len(pod.Spec.InitContainers) > 0 {
+					waiting.Reason = "PodInitializing"
+				}
+			}
+			apiStatus.ContainerStatuses = append(apiStatus.ContainerStatuses, v1.ContainerStatus{
+				Name:  c.Name,
+				Image: c.Image,
+				State: v1.ContainerState{Waiting: waiting},
+			})
+			continue
+		}
+		status := v1.ContainerStatus{
+			Name:         c.Name,
+			State:        toApiContainerState(cs),
+			Ready:        cs.State == runtime.ContainerStateRunning && kl.probeManager.IsContainerReady(pod.UID, c.Name),
+			RestartCount: int32(cs.RestartCount),
+			Image:        cs.Image,
+			ImageID:      cs.ImageID,
+			ContainerID:  cs.ID,
+		}
+		if cs.LastTermination != nil {
+			status.LastTerminationState = toApiContainerState(cs.LastTermination)
+		}
+		key := backOffKey(pod.UID, c.Name)
+		if shouldRestartContainer(pod, cs) && kl.backOff.IsInBackOffSince(key, cs.FinishedAt) {
+			// 等待重启的容器显示为CrashLoopBackOff
+			status.LastTerminationState = status.State
+			status.State = v1.ContainerState{Waiting: &v1.ContainerStateWaiting{
+				Reason:  "CrashLoopBackOff",
+				Message: fmt.Sprintf("back-off %v restarting failed container=%v pod=%v", kl.backOff.Get(key), c.Name, pod.Name),
+			}}
+		}
+		apiStatus.ContainerStatuses = append(apiStatus.ContainerStatuses, status)
+	}
+
+	apiStatus.Phase = getPhase(pod, apiStatus.ContainerStatuses)
+	if message, evicted := kl.getEvictionMessage(pod.UID); evicted {
+		apiStatus.Phase = v1.PodFailed
+		apiStatus.Reason = eviction.Reason
+		apiStatus.Message = message
+	}
+	apiStatus.Conditions = computePodConditions(pod, apiStatus, oldStatus, initialized)
+	return apiStatus
+}
+
+func toApiContainerState(cs *runtime.ContainerStatus) v1.ContainerState {
+	switch cs.State {
+	case runtime.ContainerStateRunning:
+		return v1.ContainerState{Running: &v1.ContainerStateRunning{StartedAt: cs.StartedAt}}
+	case runtime.ContainerStateExited:
+		return v1.ContainerState{Terminated: &v1.ContainerStateTerminated{
+			ExitCode:   int32(cs.ExitCode),
+			Reason:     cs.Reason,
+			Message:    cs.Message,
+			StartedAt:  cs.StartedAt,
+			FinishedAt: cs.FinishedAt,
+		}}
+	case runtime.ContainerStateCreated:
+		return v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ContainerCreating"}}
+	default:
+		return v1.ContainerState{}
+	}
+}
+
+// 有容器未启动时为Pending，有容器运行时为Running，全部退出后根据重启策略与退出码判断
+func getPhase(pod *v1.Pod, statuses []v1.ContainerStatus) v1.PodPhase {
+	running, waiting, stopped, succeeded := 0, 0, 0, 0
+	for _, status := range statuses {
+		switch {
+		case status.State.Running != nil:
+			running++
+		case status.State.Terminated != nil:
+			stopped++
+			if status.State.Terminated.ExitCode == 0 {
+				succeeded++
+			}
+		case status.LastTerminationState.Terminated != nil:
+			// 退避中等待重启的容器
+			stopped++
+			if status.LastTerminationState.Terminated.ExitCode == 0 {
+				succeeded++
+			}
+		default:
+			waiting++
+		}
+	}
+	switch {
+	case waiting > 0:
+		return v1.PodPending
+	case running > 0:
+		return v1.PodRunning
+	case pod.Spec.RestartPolicy != v1.RestartPolicyNever && pod.Spec.RestartPolicy != v1.RestartPolicyOnFailure:
+		// Always策略下容器总会被重启
+		return v1.PodRunning
+	case stopped == succeeded:
+		return v1.PodSucceeded
+	case pod.Spec.RestartPolicy == v1.RestartPolicyNever:
+		return v1.PodFailed
+	default:
+		// OnFailure策略下失败的容器会被重启
+		return v1.PodRunning
+	}
+}
+
+// 所有容器运行且通过readiness探测时pod才就绪
+func computePodConditions(pod *v1.Pod, apiStatus *v1.PodStatus, oldStatus *v1.PodStatus, initialized bool) []v1.PodCondition {
+	initCondition := v1.PodCondition{Type: v1.PodInitialized, Status: v1.ConditionTrue}
+	if !initialized && len(pod.Spec.InitContainers) > 0 {
+		initCondition.Status = v1.ConditionFalse
+		initCondition.Reason = "ContainersNotInitialized"
+	}
+
+	var notReady []string
+	for _, status := range apiStatus.ContainerStatuses {
+		if !status.Ready {
+			notReady = append(notReady, status.Name)
+		}
+	}
+	readyStatus := v1.ConditionTrue
+	reason, message := "", ""
+	if len(notReady) > 0 {
+		readyStatus = v1.ConditionFalse
+		reason = "ContainersNotReady"
+		message = fmt.Sprintf("containers with unready status: %v", notReady)
+	}
+
+	conditions := []v1.PodCondition{
+		initCondition,
+		{Type: v1.ContainersReady, Status: readyStatus, Reason: reason, Message: message},
+		{Type: v1.PodReady, Status: readyStatus, Reason: reason, Message: message},
+	}
+	now := time.Now()
+	for i := range conditions {
+		conditions[i].LastTransitionTime = now
+		if oldStatus == nil {
+			continue
+		}
+		old := v1.GetPodCondition(oldStatus, conditions[i].Type)
+		if old != nil && old.Status == conditions[i].Status {
+			conditions[i].LastTransitionTime = old.LastTransitionTime
+		}
+	}
+	return conditions
+}
+
+func (kl *Kubelet) getLastApiStatus(uid v1.UID) *v1.PodStatus {
+	kl.statusLock.Lock()
+	defer kl.statusLock.Unlock()
+	return kl.lastStatuses[uid]
+}
+
+func (kl *Kubelet) setLastApiStatus(uid v1.UID, status *v1.PodStatus) {
+	kl.statusLock.Lock()
+	defer kl.statusLock.Unlock()
+	kl.lastStatuses[uid] = status
+}
+
+func (kl *Kubelet) deleteLastApiStatus(uid v1.UID) {
+	kl.statusLock.Lock()
+	defer kl.statusLock.Unlock()
+	delete(kl.lastStatuses, uid)
+}
@@ -0,0 +1,60 @@
+package kubelet
+
+import (
+	"context"
+	"fmt"
+	"io"
+	v1 "minikubernetes/pkg/api/v1"
+	"minikubernetes/pkg/kubelet/runtime"
+	"minikubernetes/pkg/kubelet/utils"
+	"net"
+)
+
+// 供kubelet server使用，按namespace与名称定位本节点上的容器
+
+func (kl *Kubelet) getPod(namespace, podName string) (*v1.Pod, error) {
+	pod, ok := kl.podManger.GetPodByFullName(utils.GetPodFullName(&v1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: podName, Namespace: namespace},
+	}))
+	if !ok {
+		return nil, fmt.Errorf("pod %s/%s not found on node %s", namespace, podName, kl.nodeName)
+	}
+	return pod, nil
+}
+
+func (kl *Kubelet) getPodContainer(namespace, podName, containerName string) (*v1.Pod, error) {
+	pod, err := kl.getPod(namespace, podName)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range pod.Spec.Containers {
+		if c.Name == containerName {
+			return pod, nil
+		}
+	}
+	return nil, fmt.Errorf("container %s not found in pod %s/%s", containerName, namespace, podName)
+}
+
+func (kl *Kubelet) GetContainerLogs(ctx context.Context, namespace, podName, containerName string, opts *runtime.LogOptions, stdout, stderr io.Writer) error {
+	pod, err := kl.getPodContainer(namespace, podName, containerName)
+	if err != nil {
+		return err
+	}
+	return kl.runtimeManager.GetContainerLogs(ctx, pod.UID, containerName, opts, stdout, stderr)
+}
+
+func (kl *Kubelet) ExecInContainer(ctx context.Context, namespace, podName, containerName string, cmd []string, tty bool, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	pod, err := kl.getPodContainer(namespace, podName, containerName)
+	if err != nil {
+		return -1, err
+	}
+	return kl.runtimeManager.ExecInContainerStream(ctx, pod.UID, containerName, cmd, tty, stdin, stdout, stderr)
+}
+
+func (kl *Kubelet) DialPodPort(namespace, podName string, port int32) (net.Conn, error) {
+	pod, err := kl.getPod(namespace, podName)
+	if err != nil {
+		return nil, err
+	}
+	return kl.runtimeManager.DialPodPort(pod.UID, port)
+}
@@ -0,0 +1,137 @@
+package kubelet
+
+import (
+	"log"
+	v1 "minikubernetes/pkg/api/v1"
+	"minikubernetes/pkg/kubelet/runtime"
+	"sync"
+	"time"
+)
+
+const (
+	// preStop耗尽宽限期时，仍给容器留出处理SIGTERM的时间
+	minimumGracePeriod = 2 * time.Second
+	// kubelet重启后删除孤儿pod时使用的宽限期，此时pod的spec已不可知
+	orphanPodGracePeriod = 2 * time.Second
+	// postStart回调的超时时间
+	postStartTimeout = 30 * time.Second
+)
+
+// 执行preStop后停止容器，宽限期由preStop与SIGTERM共用，超时后SIGKILL
+// apiserver中标记为正在终止的pod在容器停止后由kubelet确认删除
+func (kl *Kubelet) killPod(pod *v1.Pod) error {
+	if err := kl.stopPod(pod, time.Duration(v1.GetPodGracePeriodSeconds(pod))*time.Second); err != nil {
+		return err
+	}
+	// 容器已删除，卷不再被使用
+	if err := kl.volumeManager.TearDownPodVolumes(pod.UID); err != nil {
+		log.Printf("Failed to clean up volumes of pod %v: %v\n", pod.Name, err)
+	}
+	if v1.IsPodTerminating(pod) && !v1.IsStaticPod(pod) {
+		if err := kl.kubeClient.DeletePod(pod.Name, pod.Namespace); err != nil {
+			log.Printf("Failed to finalize deletion of pod %v: %v\n", pod.Name, err)
+		}
+	}
+	return nil
+}
+
+func (kl *Kubelet) stopPod(pod *v1.Pod, gracePeriod time.Duration) error {
+	start := time.Now()
+	if gracePeriod > 0 {
+		kl.runPreStopHooks(pod, gracePeriod)
+		gracePeriod -= time.Since(start)
+		if gracePeriod < minimumGracePeriod {
+			gracePeriod = minimumGracePeriod
+		}
+	}
+	return kl.runtimeManager.DeletePod(pod.UID, gracePeriod)
+}
+
+// 并行执行运行中容器的preStop，最多等待gracePeriod
+func (kl *Kubelet) runPreStopHooks(pod *v1.Pod, gracePeriod time.Duration) {
+	podStatus, err := kl.runtimeManager.GetPodStatus(pod.UID, pod.Name, pod.Namespace)
+	if err != nil {
+		log.Printf("Failed to get status of pod %v before running preStop hooks: %v\n", pod.Name, err)
+		return
+	}
+	var wg sync.WaitGroup
+	for i := range pod.Spec.Containers {
+		c := &pod.Spec.Containers[i]
+		if c.Lifecycle == nil || c.Lifecycle.PreStop == nil {
+			continue
+		}
+		cs := findContainerStatus(podStatus, c.Name)
+		if cs == nil || cs.State != runtime.ContainerStateRunning {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := kl.hookRunner.Run(c.Lifecycle.PreStop, cs.ID, podIP(podStatus), gracePeriod); err != nil {
+				log.Printf("PreStop hook for container %v in pod %v failed: %v\n", c.Name, pod.Name, err)
+			}
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(gracePeriod):
+		log.Printf("PreStop hooks of pod %v did not finish within %v.\n", pod.Name, gracePeriod)
+	}
+}
+
+// 在后台对指定的容器执行postStart，不阻塞pod worker，失败时杀死容器，之后由重启策略处理
+func (kl *Kubelet) runPostStartHooks(pod *v1.Pod, containerNames ...string) {
+	podStatus, err := kl.runtimeManager.GetPodStatus(pod.UID, pod.Name, pod.Namespace)
+	if err != nil {
+		log.Printf("Failed to get status of pod %v before running postStart hooks: %v\n", pod.Name, err)
+		return
+	}
+	for _, name := range containerNames {
+		c := findContainer(pod, name)
+		if c == nil || c.Lifecycle == nil || c.Lifecycle.PostStart == nil {
+			continue
+		}
+		cs := findContainerStatus(podStatus, name)
+		if cs == nil || cs.State != runtime.ContainerStateRunning {
+			continue
+		}
+		go func() {
+			if err := kl.hookRunner.Run(c.Lifecycle.PostStart, cs.ID, podIP(podStatus), postStartTimeout); err != nil {
+				log.Printf("PostStart hook for container %v in pod %v failed: %v, killing container.\n", c.Name, pod.Name, err)
+				if err = kl.runtimeManager.KillContainer(cs.ID); err != nil {
+					log.Printf("Failed to kill container %v in pod %v: %v\n", c.Name, pod.Name, err)
+				}
+			}
+		}()
+	}
+}
+
+func findContainer(pod *v1.Pod, name string) *v1.Container {
+	for i := range pod.Spec.Containers {
+		if pod.Spec.Containers[i].Name == name {
+			return &pod.Spec.Containers[i]
+		}
+	}
+	return nil
+}
+
+func findContainerStatus(podStatus *runtime.PodStatus, name string) *runtime.ContainerStatus {
+	for _, cs := range podStatus.ContainerStatuses {
+		if cs.Name == name {
+			return cs
+		}
+	}
+	return nil
+}
+
+func podIP(podStatus *runtime.PodStatus) string {
+	if len(podStatus.IPs) == 0 {
+		return ""
+	}
+	return podStatus.IPs[0]
+}
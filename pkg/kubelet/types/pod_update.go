@@ -18,9 +18,16 @@ type PodUpdate struct {
 type SyncPodType string
 
 const (
-	SyncPodCreate   SyncPodType = "SyncPodCreate"
-	SyncPodUpdate   SyncPodType = "SyncPodUpdate"
-	SyncPodKill     SyncPodType = "SyncPodKill"
-	SyncPodSync     SyncPodType = "SyncPodSync"
+	SyncPodCreate SyncPodType = "SyncPodCreate"
+	SyncPodUpdate SyncPodType = "SyncPodUpdate"
+	SyncPodKill   SyncPodType = "SyncPodKill"
+	SyncPodSync   SyncPodType = "SyncPodSync"
+	// 按重启策略重启已退出的容器，不重建pause容器
 	SyncPodRecreate SyncPodType = "SyncPodRecreate"
+	// 仅重新计算并上报状态，不等待新的运行时状态
+	SyncPodStatus SyncPodType = "SyncPodStatus"
+	// 接管kubelet重启前已创建的pod，只启动pod worker并同步状态
+	SyncPodAdopt SyncPodType = "SyncPodAdopt"
+	// 镜像未就绪的pod重新尝试创建
+	SyncPodRetryCreate SyncPodType = "SyncPodRetryCreate"
 )
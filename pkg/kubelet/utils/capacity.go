@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	v1 "minikubernetes/pkg/api/v1"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// GetNodeCapacity 读取本机cpu核数与内存总量
+func GetNodeCapacity() (v1.ResourceList, error) {
+	file, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// MemTotal:       16314824 kB
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "MemTotal:" {
+			return v1.ResourceList{
+				v1.ResourceCPU:    fmt.Sprint(runtime.NumCPU()),
+				v1.ResourceMemory: fields[1] + "Ki",
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("MemTotal not found in /proc/meminfo")
+}
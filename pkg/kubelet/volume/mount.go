@@ -0,0 +1,70 @@
+package volume
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Mounter 挂载与卸载主机上的文件系统
+type Mounter interface {
+	Mount(source, target, fstype string, options []string) error
+	Unmount(target string) error
+	// 返回当前所有挂载点
+	List() ([]string, error)
+}
+
+// 通过mount与umount命令操作挂载点
+type execMounter struct{}
+
+func NewMounter() Mounter {
+	return &execMounter{}
+}
+
+func (m *execMounter) Mount(source, target, fstype string, options []string) error {
+	args := []string{"-t", fstype}
+	if len(options) > 0 {
+		args = append(args, "-o", strings.Join(options, ","))
+	}
+	args = append(args, source, target)
+	output, err := exec.Command("mount", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("mount %s on %s failed: %v, output: %s", source, target, err, string(output))
+	}
+	return nil
+}
+
+func (m *execMounter) Unmount(target string) error {
+	output, err := exec.Command("umount", target).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("unmount %s failed: %v, output: %s", target, err, string(output))
+	}
+	return nil
+}
+
+func (m *execMounter) List() ([]string, error) {
+	file, err := os.Open("/proc/mounts")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var mountPoints []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// tmpfs /tmp/minikubernetes/volumes/uid/cache tmpfs rw,relatime,size=65536k 0 0
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		mountPoints = append(mountPoints, unescapeMountPath(fields[1]))
+	}
+	return mountPoints, scanner.Err()
+}
+
+// /proc/mounts中空格等字符被转义为八进制
+func unescapeMountPath(path string) string {
+	replacer := strings.NewReplacer(`\040`, " ", `\011`, "\t", `\012`, "\n", `\134`, `\`)
+	return replacer.Replace(path)
+}
@@ -0,0 +1,154 @@
+package volume
+
+import (
+	"fmt"
+	"log"
+	v1 "minikubernetes/pkg/api/v1"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Manager 管理各pod在主机上的卷目录，hostPath卷不由其管理
+type Manager interface {
+	// 创建pod的emptyDir目录，medium为Memory时挂载tmpfs
+	SetUpPodVolumes(pod *v1.Pod) error
+	// 卸载并删除pod的所有卷目录，目录不存在时不报错
+	TearDownPodVolumes(podUID v1.UID) error
+	// 删除不属于activePods的pod的卷目录，用于清理kubelet停止期间被删除的pod
+	CleanupOrphanedPodVolumes(activePods map[v1.UID]bool) error
+}
+
+type manager struct {
+	// 各pod的卷目录位于rootDir/<podUID>/<volumeName>
+	rootDir string
+	mounter Mounter
+	lock    sync.Mutex
+}
+
+func NewManager(rootDir string, mounter Mounter) Manager {
+	return &manager{rootDir: rootDir, mounter: mounter}
+}
+
+func (m *manager) podDir(podUID v1.UID) string {
+	return filepath.Join(m.rootDir, string(podUID))
+}
+
+func (m *manager) SetUpPodVolumes(pod *v1.Pod) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	var mountPoints map[string]bool
+	for _, volume := range pod.Spec.Volumes {
+		if volume.EmptyDir == nil {
+			continue
+		}
+		dir := filepath.Join(m.podDir(pod.UID), volume.Name)
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return fmt.Errorf("volume %s: %v", volume.Name, err)
+		}
+		if volume.EmptyDir.Medium != v1.StorageMediumMemory {
+			continue
+		}
+		if mountPoints == nil {
+			var err error
+			if mountPoints, err = m.listMountPoints(); err != nil {
+				return err
+			}
+		}
+		// kubelet重启或重试创建时tmpfs可能已挂载
+		if mountPoints[dir] {
+			continue
+		}
+		limit, err := v1.GetEmptyDirSizeLimit(volume.EmptyDir)
+		if err != nil {
+			return fmt.Errorf("volume %s: %v", volume.Name, err)
+		}
+		// 与k8s一致，未设置sizeLimit时tmpfs默认为内存的一半
+		var options []string
+		if limit > 0 {
+			options = append(options, fmt.Sprintf("size=%d", limit))
+		}
+		if err = m.mounter.Mount("tmpfs", dir, "tmpfs", options); err != nil {
+			return fmt.Errorf("volume %s: %v", volume.Name, err)
+		}
+		log.Printf("Mounted tmpfs for volume %v of pod %v.\n", volume.Name, pod.Name)
+	}
+	return nil
+}
+
+func (m *manager) TearDownPodVolumes(podUID v1.UID) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.tearDown(podUID)
+}
+
+func (m *manager) tearDown(podUID v1.UID) error {
+	dir := m.podDir(podUID)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil
+	}
+	mountPoints, err := m.listMountPoints()
+	if err != nil {
+		return err
+	}
+	var toUnmount []string
+	for mountPoint := range mountPoints {
+		if strings.HasPrefix(mountPoint, dir+string(filepath.Separator)) {
+			toUnmount = append(toUnmount, mountPoint)
+		}
+	}
+	// 先卸载内层的挂载点
+	sort.Sort(sort.Reverse(sort.StringSlice(toUnmount)))
+	for _, mountPoint := range toUnmount {
+		if err = m.mounter.Unmount(mountPoint); err != nil {
+			// 未卸载时删除目录会删除仍在使用的数据
+			return err
+		}
+	}
+	if err = os.RemoveAll(dir); err != nil {
+		return err
+	}
+	log.Printf("Volumes of pod %v cleaned up.\n", podUID)
+	return nil
+}
+
+func (m *manager) CleanupOrphanedPodVolumes(activePods map[v1.UID]bool) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	entries, err := os.ReadDir(m.rootDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	var errs []string
+	for _, entry := range entries {
+		uid := v1.UID(entry.Name())
+		if !entry.IsDir() || activePods[uid] {
+			continue
+		}
+		log.Printf("Cleaning up volumes of orphaned pod %v.\n", uid)
+		if err = m.tearDown(uid); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to clean up orphaned pod volumes: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (m *manager) listMountPoints() (map[string]bool, error) {
+	list, err := m.mounter.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mount points: %v", err)
+	}
+	mountPoints := make(map[string]bool, len(list))
+	for _, mountPoint := range list {
+		mountPoints[filepath.Clean(mountPoint)] = true
+	}
+	return mountPoints, nil
+}
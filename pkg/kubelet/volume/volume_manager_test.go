@@ -0,0 +1,97 @@
+package volume
+
+import (
+	v1 "minikubernetes/pkg/api/v1"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type fakeMounter struct {
+	mountPoints []string
+	calls       []string
+}
+
+func (m *fakeMounter) Mount(source, target, fstype string, options []string) error {
+	m.mountPoints = append(m.mountPoints, target)
+	m.calls = append(m.calls, "mount "+target+" "+fstype+" "+strings.Join(options, ","))
+	return nil
+}
+
+func (m *fakeMounter) Unmount(target string) error {
+	m.mountPoints = slices.DeleteFunc(m.mountPoints, func(p string) bool { return p == target })
+	m.calls = append(m.calls, "umount "+target)
+	return nil
+}
+
+func (m *fakeMounter) List() ([]string, error) {
+	return slices.Clone(m.mountPoints), nil
+}
+
+func newVolumeTestPod(uid string) *v1.Pod {
+	pod := &v1.Pod{ObjectMeta: v1.ObjectMeta{Name: uid, UID: v1.UID(uid)}}
+	pod.Spec.Volumes = []v1.Volume{
+		{Name: "cache", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{Medium: v1.StorageMediumMemory, SizeLimit: "64Mi"}}},
+		{Name: "data", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}},
+		{Name: "host", VolumeSource: v1.VolumeSource{HostPath: &v1.HostPathVolumeSource{Path: "/var/log"}}},
+	}
+	return pod
+}
+
+func TestSetUpAndTearDownPodVolumes(t *testing.T) {
+	root := t.TempDir()
+	mounter := &fakeMounter{}
+	m := NewManager(root, mounter)
+	pod := newVolumeTestPod("uid-0")
+	// 重复调用时不会重复挂载
+	for i := 0; i < 2; i++ {
+		if err := m.SetUpPodVolumes(pod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cache := filepath.Join(root, "uid-0", "cache")
+	if !slices.Equal(mounter.calls, []string{"mount " + cache + " tmpfs size=67108864"}) {
+		t.Errorf("mount calls = %v", mounter.calls)
+	}
+	if _, err := os.Stat(filepath.Join(root, "uid-0", "data")); err != nil {
+		t.Errorf("emptyDir not created: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "uid-0", "host")); !os.IsNotExist(err) {
+		t.Errorf("hostPath volume should not be created under the pod dir")
+	}
+
+	if err := m.TearDownPodVolumes(pod.UID); err != nil {
+		t.Fatal(err)
+	}
+	if len(mounter.mountPoints) != 0 {
+		t.Errorf("mount points left: %v", mounter.mountPoints)
+	}
+	if _, err := os.Stat(filepath.Join(root, "uid-0")); !os.IsNotExist(err) {
+		t.Errorf("pod volume dir not removed")
+	}
+	if err := m.TearDownPodVolumes(pod.UID); err != nil {
+		t.Errorf("tearing down twice: %v", err)
+	}
+}
+
+func TestCleanupOrphanedPodVolumes(t *testing.T) {
+	root := t.TempDir()
+	mounter := &fakeMounter{}
+	m := NewManager(root, mounter)
+	for _, uid := range []string{"active", "orphan"} {
+		if err := m.SetUpPodVolumes(newVolumeTestPod(uid)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.CleanupOrphanedPodVolumes(map[v1.UID]bool{"active": true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "orphan")); !os.IsNotExist(err) {
+		t.Errorf("orphaned pod volumes not removed")
+	}
+	if !slices.Equal(mounter.mountPoints, []string{filepath.Join(root, "active", "cache")}) {
+		t.Errorf("mount points = %v", mounter.mountPoints)
+	}
+}
@@ -138,7 +138,7 @@ func (ps *ProxyServer) updateService() {
 			if !isSelectorMatched(pod, service) {
 				continue
 			}
-			if pod.Status.Phase != v1.PodRunning {
+			if !v1.IsPodReady(pod) {
 				continue
 			}
 			if pod.Status.PodIP == "" {
@@ -308,8 +308,9 @@ func getMockPods() []*v1.Pod {
 					},
 				},
 				Status: v1.PodStatus{
-					Phase: v1.PodRunning,
-					PodIP: "10.32.0.1",
+					Phase:      v1.PodRunning,
+					PodIP:      "10.32.0.1",
+					Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}},
 				},
 			},
 		}
@@ -337,8 +338,9 @@ func getMockPods() []*v1.Pod {
 				},
 			},
 			Status: v1.PodStatus{
-				Phase: v1.PodRunning,
-				PodIP: "10.32.0.1",
+				Phase:      v1.PodRunning,
+				PodIP:      "10.32.0.1",
+				Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}},
 			},
 		},
 	}
@@ -278,6 +278,8 @@ func (p *Proxy) deleteCorednsHosts(dns *v1.DNS) error {
 	return err
 }
 
+// service的完整域名与短名称都解析到cluster ip，pod通过搜索域解析svc与svc.ns
+// 短名称不区分namespace，只保留第一个同名的service
 func (p *Proxy) addServiceNameDNS(service *v1.Service) error {
 	filename := "/etc/coredns/hosts"
 	file, err := os.Open(filename)
@@ -302,10 +304,16 @@ func (p *Proxy) addServiceNameDNS(service *v1.Service) error {
 	if err != nil {
 		return err
 	}
-	if _, ok := hosts[service.Name]; ok {
-		return fmt.Errorf("service name %s already exists in coredns hosts", service.Name)
-	} else if service.Spec.ClusterIP == "" {
+	if service.Spec.ClusterIP == "" {
 		return fmt.Errorf("service %s has no cluster ip", service.Name)
+	}
+	domainName := v1.GetServiceDomainName(service)
+	if _, ok := hosts[domainName]; ok {
+		return fmt.Errorf("service domain name %s already exists in coredns hosts", domainName)
+	}
+	content += fmt.Sprintf("%s %s\n", service.Spec.ClusterIP, domainName)
+	if _, ok := hosts[service.Name]; ok {
+		log.Printf("Service name %s already exists in coredns hosts, only %s is added.", service.Name, domainName)
 	} else {
 		content += fmt.Sprintf("%s %s\n", service.Spec.ClusterIP, service.Name)
 	}
@@ -313,6 +321,7 @@ func (p *Proxy) addServiceNameDNS(service *v1.Service) error {
 	return err
 }
 
+// 短名称属于其他namespace的同名service时保留
 func (p *Proxy) deleteServiceNameDNS(service *v1.Service) error {
 	filename := "/etc/coredns/hosts"
 	file, err := os.Open(filename)
@@ -320,6 +329,7 @@ func (p *Proxy) deleteServiceNameDNS(service *v1.Service) error {
 		return err
 	}
 	scanner := bufio.NewScanner(file)
+	domainName := v1.GetServiceDomainName(service)
 	content := ""
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -328,11 +338,13 @@ func (p *Proxy) deleteServiceNameDNS(service *v1.Service) error {
 			return fmt.Errorf("invalid coredns hosts file: %s", line)
 		}
 		host := fields[1]
-		if host != service.Name {
-			content += line + "\n"
-		} else if fields[0] != service.Spec.ClusterIP {
-			return fmt.Errorf("cluster ip mismatch for service %s", service.Name)
+		if host == domainName && fields[0] != service.Spec.ClusterIP {
+			return fmt.Errorf("cluster ip mismatch for service %s", domainName)
+		}
+		if host == domainName || (host == service.Name && fields[0] == service.Spec.ClusterIP) {
+			continue
 		}
+		content += line + "\n"
 	}
 	err = file.Close()
 	if err != nil {
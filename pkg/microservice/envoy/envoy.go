@@ -24,6 +24,12 @@ const (
 	GID          = "1337"
 )
 
+// envoy-init读取的环境变量，值为逗号分隔的端口列表，对应端口的流量不被劫持
+const (
+	ExcludeInboundPortsEnv  = "EXCLUDE_INBOUND_PORTS"
+	ExcludeOutboundPortsEnv = "EXCLUDE_OUTBOUND_PORTS"
+)
+
 type Envoy struct {
 	inboundRouter  *gin.Engine
 	outboundRouter *gin.Engine
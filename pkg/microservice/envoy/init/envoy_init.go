@@ -1,12 +1,18 @@
 package init
 
 import (
+	"fmt"
 	"github.com/coreos/go-iptables/iptables"
+	v1 "minikubernetes/pkg/api/v1"
 	"minikubernetes/pkg/microservice/envoy"
+	"os"
 )
 
 type EnvoyInit struct {
 	ipt *iptables.IPTables
+	// 不被劫持的入站与出站目的端口
+	excludeInboundPorts  []int32
+	excludeOutboundPorts []int32
 }
 
 func NewEnvoyInit() (*EnvoyInit, error) {
@@ -16,6 +22,14 @@ func NewEnvoyInit() (*EnvoyInit, error) {
 		return nil, err
 	}
 	e.ipt = ipt
+	e.excludeInboundPorts, err = v1.ParsePortList(os.Getenv(envoy.ExcludeInboundPortsEnv))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", envoy.ExcludeInboundPortsEnv, err)
+	}
+	e.excludeOutboundPorts, err = v1.ParsePortList(os.Getenv(envoy.ExcludeOutboundPortsEnv))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", envoy.ExcludeOutboundPortsEnv, err)
+	}
 	return e, nil
 }
 
@@ -51,7 +65,13 @@ func (e *EnvoyInit) Init() error {
 	if err != nil {
 		return err
 	}
-	// 没做：ssh端口22
+	// 排除的入站端口直接返回，不经过Envoy
+	for _, port := range e.excludeInboundPorts {
+		err = e.ipt.Append("nat", "MISTIO_INBOUND", "-p", "tcp", "--dport", fmt.Sprint(port), "-j", "RETURN")
+		if err != nil {
+			return err
+		}
+	}
 	// 在MISTIO_INBOUND链中追加一条规则，将所有入站流量重定向到MISTIO_IN_REDIRECT链
 	err = e.ipt.Append("nat", "MISTIO_INBOUND", "-p", "tcp", "-j", "MISTIO_IN_REDIRECT")
 	if err != nil {
@@ -126,6 +146,13 @@ func (e *EnvoyInit) Init() error {
 	if err != nil {
 		return err
 	}
+	// 排除的出站端口直接返回，不经过Envoy
+	for _, port := range e.excludeOutboundPorts {
+		err = e.ipt.Append("nat", "MISTIO_OUTPUT", "-p", "tcp", "--dport", fmt.Sprint(port), "-j", "RETURN")
+		if err != nil {
+			return err
+		}
+	}
 	// 剩余的数据包重定向到MISTIO_REDIRECT链
 	err = e.ipt.Append("nat", "MISTIO_OUTPUT", "-j", "MISTIO_REDIRECT")
 	return nil
@@ -365,7 +365,7 @@ func (p *pilot) syncLoopIteration() error {
 				if !p.isSelectorMatched(pod, service) {
 					continue
 				}
-				if pod.Status.Phase != v1.PodRunning {
+				if !v1.IsPodReady(pod) {
 					continue
 				}
 				if pod.Status.PodIP == "" {
@@ -454,7 +454,7 @@ func (p *pilot) getEndpoints(pods []*v1.Pod, services []*v1.Service) map[v1.UID]
 			if !p.isSelectorMatched(pod, service) {
 				continue
 			}
-			if pod.Status.Phase != v1.PodRunning {
+			if !v1.IsPodReady(pod) {
 				continue
 			}
 			if pod.Status.PodIP == "" {
@@ -0,0 +1,131 @@
+package scheduler
+
+import (
+	v1 "minikubernetes/pkg/api/v1"
+	"sort"
+)
+
+// 调度流水线：先由filter插件过滤掉不可用节点，再由score插件为剩余节点打分
+
+// ClusterState 一次调度所依据的集群快照
+type ClusterState struct {
+	Nodes []*v1.Node
+	// 所有pod，包括尚未调度的
+	Pods []*v1.Pod
+	// 用于将使用pvc的pod调度到pv所在的节点
+	PersistentVolumes      []*v1.PersistentVolume
+	PersistentVolumeClaims []*v1.PersistentVolumeClaim
+}
+
+// PodsOnNode 返回已绑定到节点的pod
+func (cs *ClusterState) PodsOnNode(nodeName string) []*v1.Pod {
+	var pods []*v1.Pod
+	for _, pod := range cs.Pods {
+		if pod.Spec.NodeName == nodeName {
+			pods = append(pods, pod)
+		}
+	}
+	return pods
+}
+
+type FilterPlugin interface {
+	Name() string
+	// 节点不可用时返回false及原因
+	Filter(state *ClusterState, pod *v1.Pod, node *v1.Node) (bool, string)
+}
+
+type ScorePlugin interface {
+	Name() string
+	// 分数范围0~100，越高越优先
+	Score(state *ClusterState, pod *v1.Pod, node *v1.Node) int64
+}
+
+type NodeScore struct {
+	NodeName string `json:"nodeName"`
+	// 总分
+	Total int64 `json:"total"`
+	// 各score插件的得分
+	PluginScores map[string]int64 `json:"pluginScores,omitempty"`
+}
+
+type FilteredNode struct {
+	NodeName string `json:"nodeName"`
+	// 各filter插件给出的原因
+	Reasons []string `json:"reasons"`
+}
+
+type ScheduleResult struct {
+	// 按总分降序排列的候选节点
+	Candidates []*NodeScore `json:"candidates"`
+	// 被过滤掉的节点
+	Filtered []*FilteredNode `json:"filtered,omitempty"`
+}
+
+type Framework struct {
+	filters []FilterPlugin
+	scores  []ScorePlugin
+}
+
+func NewFramework(filters []FilterPlugin, scores []ScorePlugin) *Framework {
+	return &Framework{
+		filters: filters,
+		scores:  scores,
+	}
+}
+
+// NewFrameworkForPolicy 按调度策略组装插件
+func NewFrameworkForPolicy(policy string) *Framework {
+	filters := []FilterPlugin{&nodePressure{}, &taintToleration{}, &nodeResourcesFit{}, &nodePorts{}, &volumeBinding{}, &podTopologySpread{}}
+	scores := []ScorePlugin{&podTopologySpread{}}
+	if policy == NodeAffinity_Policy {
+		scores = append(scores, &nodeLabelMatch{})
+	}
+	return NewFramework(filters, scores)
+}
+
+func (f *Framework) Run(state *ClusterState, pod *v1.Pod) *ScheduleResult {
+	result := &ScheduleResult{
+		Candidates: make([]*NodeScore, 0),
+	}
+	for _, node := range state.Nodes {
+		var reasons []string
+		for _, filter := range f.filters {
+			if ok, reason := filter.Filter(state, pod, node); !ok {
+				reasons = append(reasons, filter.Name()+": "+reason)
+			}
+		}
+		if len(reasons) != 0 {
+			result.Filtered = append(result.Filtered, &FilteredNode{NodeName: node.Name, Reasons: reasons})
+			continue
+		}
+		nodeScore := &NodeScore{
+			NodeName:     node.Name,
+			PluginScores: make(map[string]int64),
+		}
+		for _, score := range f.scores {
+			s := score.Score(state, pod, node)
+			nodeScore.PluginScores[score.Name()] = s
+			nodeScore.Total += s
+		}
+		result.Candidates = append(result.Candidates, nodeScore)
+	}
+	sort.SliceStable(result.Candidates, func(i, j int) bool {
+		if result.Candidates[i].Total != result.Candidates[j].Total {
+			return result.Candidates[i].Total > result.Candidates[j].Total
+		}
+		return result.Candidates[i].NodeName < result.Candidates[j].NodeName
+	})
+	return result
+}
+
+// TopCandidates 返回得分最高的所有节点
+func (r *ScheduleResult) TopCandidates() []*NodeScore {
+	if len(r.Candidates) == 0 {
+		return nil
+	}
+	i := 1
+	for i < len(r.Candidates) && r.Candidates[i].Total == r.Candidates[0].Total {
+		i++
+	}
+	return r.Candidates[:i]
+}
@@ -0,0 +1,195 @@
+package scheduler
+
+import (
+	"fmt"
+	v1 "minikubernetes/pkg/api/v1"
+	"testing"
+)
+
+func newTestNode(name string, labels map[string]string, cpu, memory string) *v1.Node {
+	node := &v1.Node{ObjectMeta: v1.ObjectMeta{Name: name, Labels: labels}}
+	if cpu != "" || memory != "" {
+		node.Status.Capacity = v1.ResourceList{v1.ResourceCPU: cpu, v1.ResourceMemory: memory}
+	}
+	return node
+}
+
+func newTestPod(name, nodeName string, labels map[string]string, cpu, memory string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: name, UID: v1.UID(name), Labels: labels},
+		Spec: v1.PodSpec{
+			NodeName: nodeName,
+			Containers: []v1.Container{{
+				Name: "c",
+				Resources: v1.ResourceRequirements{
+					Requests: v1.ResourceList{v1.ResourceCPU: cpu, v1.ResourceMemory: memory},
+				},
+			}},
+		},
+	}
+}
+
+func TestFrameworkFilterByResources(t *testing.T) {
+	state := &ClusterState{
+		Nodes: []*v1.Node{
+			newTestNode("node-0", nil, "2", "1Gi"),
+			newTestNode("node-1", nil, "2", "1Gi"),
+		},
+		Pods: []*v1.Pod{
+			newTestPod("busy", "node-0", nil, "1500m", "128Mi"),
+		},
+	}
+	pod := newTestPod("new", "", nil, "1", "256Mi")
+	result := NewFrameworkForPolicy(Round_Policy).Run(state, pod)
+	if len(result.Candidates) != 1 || result.Candidates[0].NodeName != "node-1" {
+		t.Fatalf("candidates = %v, want only node-1", result.Candidates)
+	}
+	if len(result.Filtered) != 1 || result.Filtered[0].NodeName != "node-0" || len(result.Filtered[0].Reasons) != 1 {
+		t.Fatalf("filtered = %v, want node-0 with one reason", result.Filtered)
+	}
+}
+
+func TestFrameworkFilterByPressure(t *testing.T) {
+	memoryPressure := newTestNode("node-0", nil, "", "")
+	memoryPressure.Status.Conditions = []v1.NodeCondition{{Type: v1.NodeMemoryPressure, Status: v1.ConditionTrue}}
+	diskPressure := newTestNode("node-1", nil, "", "")
+	diskPressure.Status.Conditions = []v1.NodeCondition{{Type: v1.NodeDiskPressure, Status: v1.ConditionTrue}}
+	state := &ClusterState{Nodes: []*v1.Node{memoryPressure, diskPressure, newTestNode("node-2", nil, "", "")}}
+
+	bestEffort := newTestPod("best-effort", "", nil, "", "")
+	result := NewFrameworkForPolicy(Round_Policy).Run(state, bestEffort)
+	if len(result.Candidates) != 1 || result.Candidates[0].NodeName != "node-2" {
+		t.Errorf("best-effort candidates = %v, want only node-2", result.Candidates)
+	}
+	burstable := newTestPod("burstable", "", nil, "100m", "64Mi")
+	result = NewFrameworkForPolicy(Round_Policy).Run(state, burstable)
+	if len(result.Candidates) != 2 || len(result.Filtered) != 1 || result.Filtered[0].NodeName != "node-1" {
+		t.Errorf("burstable candidates = %v, filtered = %v", result.Candidates, result.Filtered)
+	}
+}
+
+func TestFrameworkFilterByTaintsAndHostPorts(t *testing.T) {
+	tainted := newTestNode("node-0", nil, "", "")
+	tainted.Spec.Taints = []v1.Taint{{Key: "dedicated", Value: "gpu", Effect: v1.TaintEffectNoSchedule}}
+	preferred := newTestNode("node-1", nil, "", "")
+	preferred.Spec.Taints = []v1.Taint{{Key: "dedicated", Value: "gpu", Effect: v1.TaintEffectPreferNoSchedule}}
+	existing := newTestPod("existing", "node-1", nil, "", "")
+	existing.Spec.Containers[0].Ports = []v1.ContainerPort{{ContainerPort: 80, HostPort: 8080}}
+	state := &ClusterState{
+		Nodes: []*v1.Node{tainted, preferred, newTestNode("node-2", nil, "", "")},
+		Pods:  []*v1.Pod{existing},
+	}
+
+	pod := newTestPod("new", "", nil, "", "")
+	pod.Spec.Containers[0].Ports = []v1.ContainerPort{{ContainerPort: 80, HostPort: 8080, Protocol: v1.ProtocolTCP}}
+	result := NewFrameworkForPolicy(Round_Policy).Run(state, pod)
+	if len(result.Candidates) != 1 || result.Candidates[0].NodeName != "node-2" {
+		t.Errorf("candidates = %v, want only node-2", result.Candidates)
+	}
+	// 不同协议的hostPort不冲突
+	pod.Spec.Containers[0].Ports[0].Protocol = v1.ProtocolUDP
+	pod.Spec.Tolerations = []v1.Toleration{{Key: "dedicated", Operator: v1.TolerationOpExists}}
+	result = NewFrameworkForPolicy(Round_Policy).Run(state, pod)
+	if len(result.Candidates) != 3 {
+		t.Errorf("candidates = %v, want all nodes", result.Candidates)
+	}
+	// hostNetwork的pod占用containerPort，协议不区分大小写
+	pod.Spec.HostNetwork = true
+	pod.Spec.Containers[0].Ports = []v1.ContainerPort{{ContainerPort: 8080, Protocol: "TCP"}}
+	result = NewFrameworkForPolicy(Round_Policy).Run(state, pod)
+	if len(result.Candidates) != 2 {
+		t.Errorf("candidates = %v, want node-0 and node-2", result.Candidates)
+	}
+}
+
+func TestFrameworkFilterByVolumeBinding(t *testing.T) {
+	state := &ClusterState{
+		Nodes: []*v1.Node{newTestNode("node-0", nil, "", ""), newTestNode("node-1", nil, "", "")},
+		PersistentVolumes: []*v1.PersistentVolume{{
+			ObjectMeta: v1.ObjectMeta{Name: "pv-0"},
+			Spec:       v1.PersistentVolumeSpec{NodeName: "node-1"},
+		}},
+		PersistentVolumeClaims: []*v1.PersistentVolumeClaim{{
+			ObjectMeta: v1.ObjectMeta{Name: "data", Namespace: "default"},
+			Status:     v1.PersistentVolumeClaimStatus{Phase: v1.ClaimPending},
+		}},
+	}
+	pod := newTestPod("new", "", nil, "", "")
+	pod.Namespace = "default"
+	pod.Spec.Volumes = []v1.Volume{{
+		Name:         "data",
+		VolumeSource: v1.VolumeSource{PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: "data"}},
+	}}
+	result := NewFrameworkForPolicy(Round_Policy).Run(state, pod)
+	if len(result.Candidates) != 0 {
+		t.Errorf("candidates = %v, want none before the claim is bound", result.Candidates)
+	}
+	claim := state.PersistentVolumeClaims[0]
+	claim.Spec.VolumeName = "pv-0"
+	claim.Status.Phase = v1.ClaimBound
+	result = NewFrameworkForPolicy(Round_Policy).Run(state, pod)
+	if len(result.Candidates) != 1 || result.Candidates[0].NodeName != "node-1" {
+		t.Errorf("candidates = %v, want only node-1", result.Candidates)
+	}
+}
+
+func TestFrameworkScoreByLabels(t *testing.T) {
+	state := &ClusterState{
+		Nodes: []*v1.Node{
+			newTestNode("node-0", nil, "", ""),
+			newTestNode("node-1", map[string]string{"app": "nginx"}, "", ""),
+			newTestNode("node-2", map[string]string{"app": "nginx", "zone": "a"}, "", ""),
+		},
+	}
+	pod := newTestPod("new", "", map[string]string{"app": "nginx"}, "", "")
+	result := NewFrameworkForPolicy(NodeAffinity_Policy).Run(state, pod)
+	top := result.TopCandidates()
+	if len(top) != 2 || top[0].NodeName != "node-1" || top[1].NodeName != "node-2" {
+		t.Fatalf("top candidates = %v, want node-1 and node-2", top)
+	}
+	if result.Candidates[2].NodeName != "node-0" || result.Candidates[2].PluginScores["NodeLabelMatch"] != 0 {
+		t.Fatalf("last candidate = %v, want node-0 with score 0", result.Candidates[2])
+	}
+}
+
+func TestFrameworkTopologySpread(t *testing.T) {
+	state := &ClusterState{
+		Nodes: []*v1.Node{
+			newTestNode("node-0", map[string]string{"zone": "a"}, "", ""),
+			newTestNode("node-1", map[string]string{"zone": "a"}, "", ""),
+			newTestNode("node-2", map[string]string{"zone": "b"}, "", ""),
+			newTestNode("node-3", map[string]string{"zone": "c"}, "", ""),
+			newTestNode("node-4", nil, "", ""),
+		},
+	}
+	for i := 0; i < 6; i++ {
+		pod := newTestPod(fmt.Sprintf("nginx-%d", i), "", map[string]string{"app": "nginx"}, "", "")
+		pod.Spec.TopologySpreadConstraints = []v1.TopologySpreadConstraint{{
+			MaxSkew:           1,
+			TopologyKey:       "zone",
+			WhenUnsatisfiable: v1.DoNotSchedule,
+			LabelSelector:     &v1.LabelSelector{MatchLabels: map[string]string{"app": "nginx"}},
+		}}
+		state.Pods = append(state.Pods, pod)
+	}
+	// 模拟调度循环：每次绑定到第一个最高分节点
+	framework := NewFrameworkForPolicy(Round_Policy)
+	for _, pod := range state.Pods {
+		top := framework.Run(state, pod).TopCandidates()
+		if len(top) == 0 {
+			t.Fatalf("no candidate for pod %v", pod.Name)
+		}
+		pod.Spec.NodeName = top[0].NodeName
+	}
+	zoneCounts := make(map[string]int)
+	for _, pod := range state.Pods {
+		for _, node := range state.Nodes {
+			if node.Name == pod.Spec.NodeName {
+				zoneCounts[node.Labels["zone"]]++
+			}
+		}
+	}
+	if zoneCounts["a"] != 2 || zoneCounts["b"] != 2 || zoneCounts["c"] != 2 {
+		t.Fatalf("zone counts = %v, want 2/2/2", zoneCounts)
+	}
+}
@@ -0,0 +1,274 @@
+package scheduler
+
+import (
+	"fmt"
+	v1 "minikubernetes/pkg/api/v1"
+)
+
+// 节点剩余资源需满足pod的requests，节点未上报capacity时不做限制
+type nodeResourcesFit struct{}
+
+func (p *nodeResourcesFit) Name() string {
+	return "NodeResourcesFit"
+}
+
+func (p *nodeResourcesFit) Filter(state *ClusterState, pod *v1.Pod, node *v1.Node) (bool, string) {
+	allocatable := v1.GetNodeAllocatable(node)
+	if allocatable == nil {
+		return true, ""
+	}
+	cpu, memory := v1.GetPodRequests(pod)
+	for _, other := range state.PodsOnNode(node.Name) {
+		if other.UID == pod.UID || isPodFinished(other) {
+			continue
+		}
+		c, m := v1.GetPodRequests(other)
+		cpu += c
+		memory += m
+	}
+	if s, ok := allocatable[v1.ResourceCPU]; ok {
+		capacity, err := v1.ParseCPU(s)
+		if err == nil && cpu > capacity {
+			return false, fmt.Sprintf("insufficient cpu, requested %vm, allocatable %vm", cpu, capacity)
+		}
+	}
+	if s, ok := allocatable[v1.ResourceMemory]; ok {
+		capacity, err := v1.ParseMemory(s)
+		if err == nil && memory > capacity {
+			return false, fmt.Sprintf("insufficient memory, requested %v, allocatable %v", memory, capacity)
+		}
+	}
+	return true, ""
+}
+
+// 与k8s的污点一致，处于DiskPressure的节点不接收pod，处于MemoryPressure的节点不接收BestEffort pod
+type nodePressure struct{}
+
+func (p *nodePressure) Name() string {
+	return "NodePressure"
+}
+
+func (p *nodePressure) Filter(state *ClusterState, pod *v1.Pod, node *v1.Node) (bool, string) {
+	if v1.IsNodeUnderPressure(node, v1.NodeDiskPressure) {
+		return false, "node has disk pressure"
+	}
+	if v1.IsNodeUnderPressure(node, v1.NodeMemoryPressure) && v1.GetPodQOS(pod) == v1.PodQOSBestEffort {
+		return false, "node has memory pressure"
+	}
+	return true, ""
+}
+
+// pod需容忍节点上effect为NoSchedule与NoExecute的污点
+type taintToleration struct{}
+
+func (p *taintToleration) Name() string {
+	return "TaintToleration"
+}
+
+func (p *taintToleration) Filter(state *ClusterState, pod *v1.Pod, node *v1.Node) (bool, string) {
+	if taint, ok := v1.FindUntoleratedTaint(node.Spec.Taints, pod.Spec.Tolerations, v1.IsHardTaint); ok {
+		return false, fmt.Sprintf("node has untolerated taint %v", taint.String())
+	}
+	return true, ""
+}
+
+// 同一节点上相同协议的hostPort只能被一个pod使用
+type nodePorts struct{}
+
+func (p *nodePorts) Name() string {
+	return "NodePorts"
+}
+
+func (p *nodePorts) Filter(state *ClusterState, pod *v1.Pod, node *v1.Node) (bool, string) {
+	ports := v1.GetHostPorts(pod)
+	if len(ports) == 0 {
+		return true, ""
+	}
+	for _, other := range state.PodsOnNode(node.Name) {
+		if other.UID == pod.UID || isPodFinished(other) {
+			continue
+		}
+		for _, used := range v1.GetHostPorts(other) {
+			for _, port := range ports {
+				if port.HostPort == used.HostPort && port.Protocol == used.Protocol {
+					return false, fmt.Sprintf("host port %v/%v is already in use", port.HostPort, port.Protocol)
+				}
+			}
+		}
+	}
+	return true, ""
+}
+
+// pod使用的pvc需已绑定，pod只能被调度到pv所在的节点
+type volumeBinding struct{}
+
+func (p *volumeBinding) Name() string {
+	return "VolumeBinding"
+}
+
+func (p *volumeBinding) Filter(state *ClusterState, pod *v1.Pod, node *v1.Node) (bool, string) {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim == nil {
+			continue
+		}
+		claimName := volume.PersistentVolumeClaim.ClaimName
+		var claim *v1.PersistentVolumeClaim
+		for _, c := range state.PersistentVolumeClaims {
+			if c.Namespace == pod.Namespace && c.Name == claimName {
+				claim = c
+				break
+			}
+		}
+		if claim == nil {
+			return false, fmt.Sprintf("persistentvolumeclaim %q not found", claimName)
+		}
+		if claim.Status.Phase != v1.ClaimBound {
+			return false, fmt.Sprintf("persistentvolumeclaim %q is not bound", claimName)
+		}
+		var pv *v1.PersistentVolume
+		for _, v := range state.PersistentVolumes {
+			if v.Name == claim.Spec.VolumeName {
+				pv = v
+				break
+			}
+		}
+		if pv == nil {
+			return false, fmt.Sprintf("persistentvolume %q not found", claim.Spec.VolumeName)
+		}
+		if pv.Spec.NodeName != node.Name {
+			return false, fmt.Sprintf("persistentvolume %q is on node %v", pv.Name, pv.Spec.NodeName)
+		}
+	}
+	return true, ""
+}
+
+// 节点label包含pod全部label时得满分
+type nodeLabelMatch struct{}
+
+func (p *nodeLabelMatch) Name() string {
+	return "NodeLabelMatch"
+}
+
+func (p *nodeLabelMatch) Score(state *ClusterState, pod *v1.Pod, node *v1.Node) int64 {
+	if len(pod.Labels) == 0 || node.Labels == nil {
+		return 0
+	}
+	for key, value := range pod.Labels {
+		if v, ok := node.Labels[key]; !ok || v != value {
+			return 0
+		}
+	}
+	return 100
+}
+
+func isPodFinished(pod *v1.Pod) bool {
+	return pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed
+}
+
+// 按节点label划分拓扑域，使匹配pod在各域之间均匀分布
+type podTopologySpread struct{}
+
+func (p *podTopologySpread) Name() string {
+	return "PodTopologySpread"
+}
+
+func (p *podTopologySpread) Filter(state *ClusterState, pod *v1.Pod, node *v1.Node) (bool, string) {
+	for _, constraint := range pod.Spec.TopologySpreadConstraints {
+		if constraint.WhenUnsatisfiable == v1.ScheduleAnyway {
+			continue
+		}
+		value, ok := node.Labels[constraint.TopologyKey]
+		if !ok {
+			return false, fmt.Sprintf("node does not have label %v", constraint.TopologyKey)
+		}
+		counts := countPodsInDomains(state, pod, &constraint)
+		minCount := minDomainCount(counts)
+		maxSkew := constraint.MaxSkew
+		if maxSkew < 1 {
+			maxSkew = 1
+		}
+		if skew := counts[value] + 1 - minCount; skew > maxSkew {
+			return false, fmt.Sprintf("%v=%v would have skew %v, exceeding maxSkew %v", constraint.TopologyKey, value, skew, maxSkew)
+		}
+	}
+	return true, ""
+}
+
+func (p *podTopologySpread) Score(state *ClusterState, pod *v1.Pod, node *v1.Node) int64 {
+	if len(pod.Spec.TopologySpreadConstraints) == 0 {
+		return 0
+	}
+	var total int64
+	for _, constraint := range pod.Spec.TopologySpreadConstraints {
+		value, ok := node.Labels[constraint.TopologyKey]
+		if !ok {
+			continue
+		}
+		counts := countPodsInDomains(state, pod, &constraint)
+		var maxCount int32
+		for _, count := range counts {
+			if count > maxCount {
+				maxCount = count
+			}
+		}
+		// 匹配pod越少的拓扑域得分越高
+		if maxCount == 0 {
+			total += 100
+		} else {
+			total += int64(100 * (maxCount - counts[value]) / maxCount)
+		}
+	}
+	return total / int64(len(pod.Spec.TopologySpreadConstraints))
+}
+
+// 统计各拓扑域中与约束匹配的pod数量，包含所有带该label的节点
+func countPodsInDomains(state *ClusterState, pod *v1.Pod, constraint *v1.TopologySpreadConstraint) map[string]int32 {
+	selector := pod.Labels
+	if constraint.LabelSelector != nil {
+		selector = constraint.LabelSelector.MatchLabels
+	}
+	counts := make(map[string]int32)
+	nodeDomains := make(map[string]string)
+	for _, node := range state.Nodes {
+		if value, ok := node.Labels[constraint.TopologyKey]; ok {
+			counts[value] = 0
+			nodeDomains[node.Name] = value
+		}
+	}
+	for _, other := range state.Pods {
+		if other.UID == pod.UID || other.Namespace != pod.Namespace || isPodFinished(other) {
+			continue
+		}
+		value, ok := nodeDomains[other.Spec.NodeName]
+		if !ok || !labelsMatch(selector, other.Labels) {
+			continue
+		}
+		counts[value]++
+	}
+	return counts
+}
+
+func minDomainCount(counts map[string]int32) int32 {
+	first := true
+	var minCount int32
+	for _, count := range counts {
+		if first || count < minCount {
+			minCount = count
+			first = false
+		}
+	}
+	return minCount
+}
+
+// selector中所有label均出现在labels中时匹配，空selector不匹配任何pod
+func labelsMatch(selector, labels map[string]string) bool {
+	if len(selector) == 0 {
+		return false
+	}
+	for key, value := range selector {
+		if v, ok := labels[key]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
@@ -1,6 +1,7 @@
 package scheduler
 
 import (
+	"fmt"
 	"log"
 	"math/rand"
 	v1 "minikubernetes/pkg/api/v1"
@@ -22,6 +23,7 @@ type scheduler struct {
 	client          kubeclient.Client
 	roundRobinCount int
 	policy          string
+	framework       *Framework
 }
 
 func NewScheduler(apiServerIP string, policy string) Scheduler {
@@ -29,6 +31,7 @@ func NewScheduler(apiServerIP string, policy string) Scheduler {
 	manager.client = kubeclient.NewClient(apiServerIP)
 	manager.roundRobinCount = 0
 	manager.policy = policy
+	manager.framework = NewFrameworkForPolicy(policy)
 	return manager
 }
 
@@ -49,81 +52,56 @@ func (sc *scheduler) syncLoop() error {
 	if err != nil {
 		return err
 	}
+	if len(pods) == 0 {
+		return nil
+	}
+	state, err := getClusterState(sc.client)
+	if err != nil {
+		return err
+	}
 	for _, pod := range pods {
-		policy := sc.policy
-
-		nodes, err := sc.informNodes()
-		if err != nil {
-			return err
+		result := sc.framework.Run(state, pod)
+		selectedNode := sc.selectNode(state, result)
+		if selectedNode == nil {
+			log.Printf("no feasible node for pod %v/%v", pod.Namespace, pod.Name)
+			continue
 		}
-		reqs, limit, err := sc.getResources(pod.Spec.Containers)
+		err = sc.addPodToNode(selectedNode, pod)
 		if err != nil {
-			return err
+			// binding失败（如pod已被其他调度器绑定）不应终止调度循环
+			log.Printf("failed to bind pod %v/%v to node %v: %v", pod.Namespace, pod.Name, selectedNode.Name, err)
+			continue
 		}
-		//filteredNodes, err := sc.filterNodes(reqs, limit, nodes)
-		//if err != nil {
-		//	return err
-		//}
-		switch policy {
-		case Round_Policy:
-			SelectedNodes, err := sc.nodesInRoundPolicy(reqs, limit, nodes)
-
-			err = sc.addPodToNode(SelectedNodes, pod)
-			if err != nil {
-				return err
-			}
-			break
-		case Random_Policy:
-			SelectedNodes, err := sc.nodesInRandomPolicy(reqs, limit, nodes)
-			if err != nil {
-				return err
+		// 同一轮中后续pod的调度需要看到本次绑定
+		for _, p := range state.Pods {
+			if p.UID == pod.UID {
+				p.Spec.NodeName = selectedNode.Name
 			}
-			err = sc.addPodToNode(SelectedNodes, pod)
-			if err != nil {
-				return err
-			}
-			break
-		case NodeAffinity_Policy:
-			SelectedNodes, err := sc.nodesInNodeAffinityPolicy(reqs, limit, nodes, pod)
-			if err != nil {
-				return err
-			}
-			err = sc.addPodToNode(SelectedNodes, pod)
-			if err != nil {
-				return err
-			}
-			break
 		}
-
 	}
 	return nil
 }
 
-func (sc *scheduler) nodesInNodeAffinityPolicy(rqs []v1.ResourceList, lim []v1.ResourceList, nodes []*v1.Node, pod *v1.Pod) (*v1.Node, error) {
-	if pod.Labels == nil {
-		return sc.nodesInRandomPolicy(rqs, lim, nodes)
+// 从得分最高的节点中按策略选出一个
+func (sc *scheduler) selectNode(state *ClusterState, result *ScheduleResult) *v1.Node {
+	top := result.TopCandidates()
+	if len(top) == 0 {
+		return nil
 	}
-	var selectedNode *v1.Node
-	for _, node := range nodes {
-		if node.Labels == nil {
-			continue
-		}
-		allLabelsMatch := true
-		for key, value := range pod.Labels {
-			if v, ok := node.Labels[key]; !ok || v != value {
-				allLabelsMatch = false
-				break
-			}
-		}
-		if allLabelsMatch {
-			selectedNode = node
-			break
-		}
+	var selected *NodeScore
+	switch sc.policy {
+	case Round_Policy:
+		selected = top[sc.roundRobinCount%len(top)]
+		sc.roundRobinCount++
+	default:
+		selected = top[rand.Intn(len(top))]
 	}
-	if selectedNode == nil {
-		return sc.nodesInRandomPolicy(rqs, lim, nodes)
+	for _, node := range state.Nodes {
+		if node.Name == selected.NodeName {
+			return node
+		}
 	}
-	return selectedNode, nil
+	return nil
 }
 
 func (sc *scheduler) informPods() ([]*v1.Pod, error) {
@@ -140,55 +118,29 @@ func (sc *scheduler) informPods() ([]*v1.Pod, error) {
 	return pods, nil
 }
 
-func (sc *scheduler) informNodes() ([]*v1.Node, error) {
-
-	nodes, err := sc.client.GetAllNodes()
+func getClusterState(client kubeclient.Client) (*ClusterState, error) {
+	nodes, err := client.GetAllNodes()
 	if err != nil {
 		return nil, err
 	}
-	return nodes, nil
-}
-
-func (sc *scheduler) getResources(cts []v1.Container) ([]v1.ResourceList, []v1.ResourceList, error) {
-	var reqs []v1.ResourceList
-	var limit []v1.ResourceList
-	for _, ct := range cts {
-		reqs = append(reqs, ct.Resources.Requests)
-		limit = append(limit, ct.Resources.Limits)
-	}
-	return reqs, limit, nil
-}
-
-func (sc *scheduler) filterNodes(rqs []v1.ResourceList, lim []v1.ResourceList, nodes []*v1.Node) ([]*v1.Node, error) {
-	var res []*v1.Node
-	for _, node := range nodes {
-		//TODO：进行过滤
-		res = append(res, node)
+	allPods, err := client.GetAllPods()
+	if err != nil {
+		return nil, err
 	}
-	return res, nil
-}
-
-func (sc *scheduler) nodesInRoundPolicy(rqs []v1.ResourceList, lim []v1.ResourceList, nodes []*v1.Node) (*v1.Node, error) {
-	if nodes == nil {
-		return nil, nil
+	volumes, err := client.GetAllPersistentVolumes()
+	if err != nil {
+		return nil, err
 	}
-	lens := len(nodes)
-	if lens == 0 {
-		return nil, nil
+	claims, err := client.GetAllPersistentVolumeClaims()
+	if err != nil {
+		return nil, err
 	}
-	num := sc.roundRobinCount % lens
-	sc.roundRobinCount++
-	return nodes[num], nil
-}
-
-func (sc *scheduler) nodesInRandomPolicy(rqs []v1.ResourceList, lim []v1.ResourceList, nodes []*v1.Node) (*v1.Node, error) {
-	if nodes == nil {
-		return nil, nil
-	}
-	lens := len(nodes)
-	rand.Seed(time.Now().UnixNano())
-	num := rand.Intn(lens)
-	return nodes[num], nil
+	return &ClusterState{
+		Nodes:                  nodes,
+		Pods:                   allPods,
+		PersistentVolumes:      volumes,
+		PersistentVolumeClaims: claims,
+	}, nil
 }
 
 func (sc *scheduler) addPodToNode(node *v1.Node, pod *v1.Pod) error {
@@ -201,3 +153,17 @@ func (sc *scheduler) addPodToNode(node *v1.Node, pod *v1.Pod) error {
 	}
 	return nil
 }
+
+// Simulate 以当前集群状态试调度pod，不做任何绑定
+func Simulate(apiServerIP string, policy string, pod *v1.Pod) (*ScheduleResult, error) {
+	switch policy {
+	case Round_Policy, Random_Policy, NodeAffinity_Policy:
+	default:
+		return nil, fmt.Errorf("invalid policy %s", policy)
+	}
+	state, err := getClusterState(kubeclient.NewClient(apiServerIP))
+	if err != nil {
+		return nil, err
+	}
+	return NewFrameworkForPolicy(policy).Run(state, pod), nil
+}
@@ -24,6 +24,9 @@ func main() {
 	pod := &v1.Pod{
 		Spec: *podSpec,
 	}
-	rm := rt.NewRuntimeManager("1.1.1.1")
+	rm, err := rt.NewRuntimeManager("1.1.1.1")
+	if err != nil {
+		panic(err)
+	}
 	rt.RuntimeManager.AddPod(rm, pod)
 }
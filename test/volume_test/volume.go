@@ -75,8 +75,11 @@ func main() {
 			},
 		},
 	}
-	runtimeManager := runtime.NewRuntimeManager("1.1.1.1")
-	err := runtimeManager.AddPod(pod1)
+	runtimeManager, err := runtime.NewRuntimeManager("1.1.1.1")
+	if err != nil {
+		panic(err)
+	}
+	err = runtimeManager.AddPod(pod1)
 	if err != nil {
 		panic(err)
 	}